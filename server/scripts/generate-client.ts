@@ -0,0 +1,22 @@
+/**
+ * Regenerate web/lib/api/generated.ts and the OpenAPI document from the
+ * API spec. Run with `npm run generate:client`.
+ */
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath } from 'url';
+import { generateClient } from '../src/api/client-generator.js';
+import { buildOpenApiDocument } from '../src/api/openapi.js';
+import { API_V1_SPEC } from '../src/api/v1/spec.js';
+
+const __dirname = path.dirname(fileURLToPath(import.meta.url));
+
+const CLIENT_PATH = path.resolve(__dirname, '../../web/lib/api/generated.ts');
+const OPENAPI_PATH = path.resolve(__dirname, '../openapi.json');
+
+await fs.mkdir(path.dirname(CLIENT_PATH), { recursive: true });
+await fs.writeFile(CLIENT_PATH, generateClient(API_V1_SPEC));
+await fs.writeFile(OPENAPI_PATH, JSON.stringify(buildOpenApiDocument(API_V1_SPEC), null, 2) + '\n');
+
+console.log(`Wrote ${path.relative(process.cwd(), CLIENT_PATH)}`);
+console.log(`Wrote ${path.relative(process.cwd(), OPENAPI_PATH)}`);
@@ -0,0 +1,228 @@
+import { ApiSpec, RouteSpec } from './router.js';
+import { JsonSchema, refName } from './schema.js';
+
+const HEADER = `// Code generated by server/scripts/generate-client.ts from the API spec. DO NOT EDIT.
+// Regenerate with \`npm run generate:client\` in server/.
+`;
+
+const RUNTIME = `export type ApiErrorCode = ApiError["error"]["code"];
+
+/**
+ * Raised for non-2xx responses; carries the server's error code and details
+ */
+export class ApiRequestError extends Error {
+  constructor(
+    public status: number,
+    public code: ApiErrorCode,
+    message: string,
+    public details?: string[],
+  ) {
+    super(message);
+    this.name = "ApiRequestError";
+  }
+}
+
+export interface ApiClientOptions {
+  // Base URL including the version prefix, e.g. http://localhost:3001/api/v1
+  baseUrl: string;
+  // Bearer token sent with every request when the server requires auth
+  getToken?: () => string | undefined;
+  // Extra headers sent with every request
+  headers?: () => Record<string, string>;
+  fetch?: typeof fetch;
+}
+
+interface RequestOptions {
+  query?: object;
+  body?: unknown;
+}
+`;
+
+const REQUEST_METHOD = `  private async request<T>(
+    method: string,
+    path: string,
+    options: RequestOptions = {},
+  ): Promise<T> {
+    let url = \`\${this.options.baseUrl}\${path}\`;
+    if (options.query) {
+      const search = new URLSearchParams();
+      for (const [key, value] of Object.entries(options.query)) {
+        if (value !== undefined) search.set(key, String(value));
+      }
+      const queryString = search.toString();
+      if (queryString) url += \`?\${queryString}\`;
+    }
+
+    const headers: Record<string, string> = { ...this.options.headers?.() };
+    const token = this.options.getToken?.();
+    if (token) headers.Authorization = \`Bearer \${token}\`;
+    if (options.body !== undefined) headers["Content-Type"] = "application/json";
+
+    const fetchImpl = this.options.fetch ?? globalThis.fetch.bind(globalThis);
+    const response = await fetchImpl(url, {
+      method,
+      headers,
+      body: options.body !== undefined ? JSON.stringify(options.body) : undefined,
+    });
+
+    const text = await response.text();
+    let data: any;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      data = undefined;
+    }
+
+    if (!response.ok) {
+      const error = data?.error;
+      throw new ApiRequestError(
+        response.status,
+        error?.code ?? "internal_error",
+        error?.message ?? \`Request failed with status \${response.status}\`,
+        error?.details,
+      );
+    }
+    return data as T;
+  }
+`;
+
+function pascalCase(name: string): string {
+  return name.charAt(0).toUpperCase() + name.slice(1);
+}
+
+function isIdentifier(name: string): boolean {
+  return /^[A-Za-z_$][\w$]*$/.test(name);
+}
+
+/**
+ * Render a schema as a TypeScript type expression
+ */
+export function schemaToTs(schema: JsonSchema, indent = ''): string {
+  let type: string;
+
+  if (schema.$ref) {
+    type = refName(schema.$ref);
+  } else if (schema.enum) {
+    type = schema.enum.map(value => JSON.stringify(value)).join(' | ');
+  } else {
+    switch (schema.type) {
+      case 'string':
+        type = 'string';
+        break;
+      case 'number':
+      case 'integer':
+        type = 'number';
+        break;
+      case 'boolean':
+        type = 'boolean';
+        break;
+      case 'array': {
+        const item = schema.items ? schemaToTs(schema.items, indent) : 'unknown';
+        type = /^[\w.]+$/.test(item) ? `${item}[]` : `Array<${item}>`;
+        break;
+      }
+      case 'object':
+        if (schema.properties) {
+          type = objectBody(schema, indent);
+        } else if (typeof schema.additionalProperties === 'object') {
+          type = `Record<string, ${schemaToTs(schema.additionalProperties, indent)}>`;
+        } else {
+          type = 'Record<string, unknown>';
+        }
+        break;
+      default:
+        type = 'unknown';
+    }
+  }
+
+  return schema.nullable ? `${type} | null` : type;
+}
+
+function objectBody(schema: JsonSchema, indent: string): string {
+  const inner = indent + '  ';
+  const lines = Object.entries(schema.properties || {}).map(([name, prop]) => {
+    const key = isIdentifier(name) ? name : JSON.stringify(name);
+    const optional = schema.required?.includes(name) ? '' : '?';
+    const doc = prop.description ? `${inner}/** ${prop.description} */\n` : '';
+    return `${doc}${inner}${key}${optional}: ${schemaToTs(prop, inner)};`;
+  });
+  return `{\n${lines.join('\n')}\n${indent}}`;
+}
+
+function declareType(name: string, schema: JsonSchema): string {
+  if (schema.type === 'object' && schema.properties && !schema.nullable) {
+    return `export interface ${name} ${objectBody(schema, '')}\n`;
+  }
+  return `export type ${name} = ${schemaToTs(schema)};\n`;
+}
+
+function requestTypes(route: RouteSpec): string[] {
+  const declarations: string[] = [];
+  if (route.query) {
+    declarations.push(declareType(`${pascalCase(route.operationId)}Query`, route.query));
+  }
+  if (route.body) {
+    declarations.push(declareType(`${pascalCase(route.operationId)}Body`, route.body));
+  }
+  return declarations;
+}
+
+function clientMethod(route: RouteSpec): string {
+  const args: string[] = [];
+  const paramNames = Object.keys(route.params || {});
+  for (const name of paramNames) {
+    args.push(`${name}: ${schemaToTs(route.params![name])}`);
+  }
+
+  const queryOptional = !route.query?.required || route.query.required.length === 0;
+  if (route.query) {
+    args.push(`query${queryOptional ? '?' : ''}: ${pascalCase(route.operationId)}Query`);
+  }
+  if (route.body) {
+    const bodyOptional = !route.body.required || route.body.required.length === 0;
+    args.push(`body${bodyOptional ? '?' : ''}: ${pascalCase(route.operationId)}Body`);
+  }
+
+  const path = route.path.replace(/\{(\w+)\}/g, (_, name) => `\${encodeURIComponent(${name})}`);
+  const options = [route.query ? 'query' : '', route.body ? 'body' : ''].filter(Boolean);
+  const optionsArg = options.length > 0 ? `, { ${options.join(', ')} }` : '';
+
+  return `  /** ${route.summary} */
+  ${route.operationId}(${args.join(', ')}): Promise<${schemaToTs(route.response, '  ')}> {
+    return this.request("${route.method.toUpperCase()}", \`${path}\`${optionsArg});
+  }
+`;
+}
+
+/**
+ * Generate the TypeScript client used by the web app
+ */
+export function generateClient(spec: ApiSpec): string {
+  const parts: string[] = [HEADER];
+  parts.push(`export const API_VERSION = ${JSON.stringify(spec.version)};\n`);
+  parts.push(`export const API_BASE_PATH = ${JSON.stringify(spec.basePath)};\n`);
+
+  for (const [name, schema] of Object.entries(spec.components)) {
+    parts.push(declareType(name, schema));
+  }
+  // Event streams are consumed with EventSource rather than the JSON client
+  const routes = spec.routes.filter(route => !route.stream);
+  for (const route of routes) {
+    parts.push(...requestTypes(route));
+  }
+
+  parts.push(RUNTIME);
+
+  const methods = routes.map(clientMethod);
+  parts.push(`/**
+ * Typed client for ${spec.title} ${spec.version}
+ */
+export class ApiClient {
+  constructor(private options: ApiClientOptions) {}
+
+${methods.join('\n')}
+${REQUEST_METHOD}}
+`);
+
+  return parts.join('\n');
+}
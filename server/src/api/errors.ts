@@ -0,0 +1,101 @@
+export type ApiErrorCode =
+  | 'bad_request'
+  | 'validation_failed'
+  | 'unauthorized'
+  | 'access_denied'
+  | 'not_found'
+  | 'conflict'
+  | 'payload_too_large'
+  | 'internal_error';
+
+/**
+ * Body of every error response: { error: { code, message, details? } }
+ */
+export interface ApiErrorBody {
+  error: {
+    code: ApiErrorCode;
+    message: string;
+    details?: string[];
+  };
+}
+
+/**
+ * An error that maps directly onto an HTTP response
+ */
+export class ApiError extends Error {
+  constructor(
+    public status: number,
+    public code: ApiErrorCode,
+    message: string,
+    public details?: string[]
+  ) {
+    super(message);
+    this.name = 'ApiError';
+  }
+
+  toBody(): ApiErrorBody {
+    return {
+      error: {
+        code: this.code,
+        message: this.message,
+        ...(this.details && this.details.length > 0 ? { details: this.details } : {})
+      }
+    };
+  }
+
+  static badRequest(message: string): ApiError {
+    return new ApiError(400, 'bad_request', message);
+  }
+
+  static validation(details: string[]): ApiError {
+    return new ApiError(400, 'validation_failed', 'Request validation failed', details);
+  }
+
+  static forbidden(message: string): ApiError {
+    return new ApiError(403, 'access_denied', message);
+  }
+
+  static notFound(message: string): ApiError {
+    return new ApiError(404, 'not_found', message);
+  }
+
+  static conflict(message: string): ApiError {
+    return new ApiError(409, 'conflict', message);
+  }
+
+  static tooLarge(message: string): ApiError {
+    return new ApiError(413, 'payload_too_large', message);
+  }
+}
+
+/**
+ * Map anything thrown by a handler to an ApiError. Errors from the file
+ * system layer are recognised by their messages and error codes.
+ */
+export function toApiError(error: unknown): ApiError {
+  if (error instanceof ApiError) {
+    return error;
+  }
+
+  const err = error as NodeJS.ErrnoException & { type?: string; status?: number };
+  const message = err?.message || 'Internal server error';
+
+  // Raised by express.json()
+  if (err?.type === 'entity.too.large') {
+    return ApiError.tooLarge('Request body is too large');
+  }
+  if (err?.type === 'entity.parse.failed') {
+    return ApiError.badRequest('Request body is not valid JSON');
+  }
+
+  if (message.startsWith('Access denied')) {
+    return new ApiError(403, 'access_denied', message);
+  }
+  if (err?.code === 'ENOENT' || message.startsWith('Failed to read file') || message.startsWith('Failed to delete')) {
+    return ApiError.notFound(message);
+  }
+  if (err?.code === 'EEXIST' || err?.code === 'ENOTEMPTY') {
+    return ApiError.conflict(message);
+  }
+  return new ApiError(500, 'internal_error', message);
+}
@@ -0,0 +1,75 @@
+import { ApiSpec } from './router.js';
+
+const STATUS_DESCRIPTIONS: Record<number, string> = {
+  400: 'Invalid request',
+  401: 'Authentication required',
+  403: 'Access denied',
+  404: 'Not found',
+  409: 'Conflict',
+  413: 'Payload too large',
+  500: 'Internal server error'
+};
+
+/**
+ * Build an OpenAPI 3.0 document from an API spec
+ */
+export function buildOpenApiDocument(spec: ApiSpec, serverUrl?: string): Record<string, unknown> {
+  const paths: Record<string, Record<string, unknown>> = {};
+  const errorResponse = (status: number) => ({
+    description: STATUS_DESCRIPTIONS[status] ?? 'Error',
+    content: { 'application/json': { schema: { $ref: '#/components/schemas/ApiError' } } }
+  });
+
+  for (const route of spec.routes) {
+    const parameters: unknown[] = [];
+    for (const [name, schema] of Object.entries(route.params || {})) {
+      parameters.push({ name, in: 'path', required: true, schema });
+    }
+    for (const [name, schema] of Object.entries(route.query?.properties || {})) {
+      parameters.push({
+        name,
+        in: 'query',
+        required: route.query?.required?.includes(name) ?? false,
+        schema
+      });
+    }
+
+    const responses: Record<string, unknown> = {
+      200: {
+        description: 'Success',
+        content: { [route.stream ? 'text/event-stream' : 'application/json']: { schema: route.response } }
+      }
+    };
+    const statuses = new Set([400, 401, ...(route.admin ? [403] : []), ...(route.errors || []), 500]);
+    for (const status of Array.from(statuses).sort()) {
+      responses[status] = errorResponse(status);
+    }
+
+    paths[route.path] ??= {};
+    paths[route.path][route.method] = {
+      operationId: route.operationId,
+      summary: route.summary,
+      tags: [route.tag],
+      ...(parameters.length > 0 ? { parameters } : {}),
+      ...(route.body
+        ? { requestBody: { required: true, content: { 'application/json': { schema: route.body } } } }
+        : {}),
+      responses
+    };
+  }
+
+  return {
+    openapi: '3.0.3',
+    info: { title: spec.title, version: spec.version },
+    servers: [{ url: serverUrl ?? spec.basePath }],
+    tags: Array.from(new Set(spec.routes.map(route => route.tag))).map(name => ({ name })),
+    paths,
+    components: {
+      schemas: spec.components,
+      securitySchemes: {
+        bearerAuth: { type: 'http', scheme: 'bearer' }
+      }
+    },
+    security: [{}, { bearerAuth: [] }]
+  };
+}
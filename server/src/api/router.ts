@@ -0,0 +1,170 @@
+import express, { Request, Response, NextFunction, Router } from 'express';
+import { ApiError, toApiError } from './errors.js';
+import { JsonSchema, SchemaComponents, coerceQuery, validateSchema } from './schema.js';
+import { ANONYMOUS_USER, getClientLabel } from '../auth/tokens.js';
+
+export type HttpMethod = 'get' | 'post' | 'put' | 'patch' | 'delete';
+
+/**
+ * Declarative description of one API operation. The same definitions drive
+ * request validation, the OpenAPI document and the generated web client.
+ */
+export interface RouteSpec {
+  operationId: string;
+  method: HttpMethod;
+  // OpenAPI-style path relative to the API base, e.g. /bookmarks/{id}
+  path: string;
+  summary: string;
+  tag: string;
+  params?: Record<string, JsonSchema>;
+  query?: JsonSchema;
+  body?: JsonSchema;
+  response: JsonSchema;
+  // Status codes (besides 400/401/500) the operation may return
+  errors?: number[];
+  // Restricted to admin users (see auth.tokens[].admin)
+  admin?: boolean;
+  // Responds with text/event-stream; each event is a `response` object.
+  // The handler writes the response itself and no client method is generated.
+  stream?: boolean;
+}
+
+export interface ApiSpec {
+  title: string;
+  version: string;
+  basePath: string;
+  components: SchemaComponents;
+  routes: RouteSpec[];
+}
+
+/**
+ * Validated request data handed to a route handler
+ */
+export interface ApiRequest {
+  params: Record<string, string>;
+  query: Record<string, any>;
+  body: any;
+  // Authenticated user (see auth/tokens.ts)
+  user: string;
+  admin: boolean;
+  // Browser session or remote address, for the audit log
+  client: string;
+}
+
+export type RouteHandler = (request: ApiRequest, req: Request, res: Response) => Promise<unknown> | unknown;
+
+/**
+ * Binds handlers to the operations of an ApiSpec and produces an express
+ * router that validates requests and reports errors consistently.
+ */
+export class ApiRouter {
+  private handlers: Map<string, RouteHandler> = new Map();
+
+  constructor(private spec: ApiSpec) {}
+
+  /**
+   * Register the handler for an operation
+   */
+  handle(operationId: string, handler: RouteHandler): this {
+    if (!this.spec.routes.some(route => route.operationId === operationId)) {
+      throw new Error(`Unknown API operation: ${operationId}`);
+    }
+    this.handlers.set(operationId, handler);
+    return this;
+  }
+
+  /**
+   * Build the express router. Every operation in the spec must have a handler.
+   */
+  build(): Router {
+    const router = express.Router();
+
+    for (const route of this.spec.routes) {
+      const handler = this.handlers.get(route.operationId);
+      if (!handler) {
+        throw new Error(`No handler registered for API operation: ${route.operationId}`);
+      }
+
+      const expressPath = route.path.replace(/\{(\w+)\}/g, ':$1');
+      router[route.method](expressPath, async (req: Request, res: Response) => {
+        try {
+          if (route.admin && !res.locals.admin) {
+            throw new ApiError(403, 'access_denied', 'Administrator access required');
+          }
+          const request = this.validate(route, req, res);
+          const result = await handler(request, req, res);
+          if (!res.headersSent) {
+            res.json(result);
+          }
+        } catch (error) {
+          sendApiError(res, error, route.operationId);
+        }
+      });
+    }
+
+    // Unknown routes under the API base get a proper error instead of the SPA fallback
+    router.use((req: Request, res: Response) => {
+      sendApiError(res, ApiError.notFound(`No such endpoint: ${req.method} ${req.baseUrl}${req.path}`));
+    });
+
+    return router;
+  }
+
+  private validate(route: RouteSpec, req: Request, res: Response): ApiRequest {
+    const issues: string[] = [];
+    const components = this.spec.components;
+
+    const params = req.params as Record<string, string>;
+    for (const [name, schema] of Object.entries(route.params || {})) {
+      issues.push(...validateSchema(schema, params[name], `params.${name}`, components));
+    }
+
+    let query: Record<string, any> = {};
+    if (route.query) {
+      query = coerceQuery(route.query, req.query as Record<string, unknown>);
+      issues.push(...validateSchema(route.query, query, 'query', components));
+    }
+
+    let body: any = undefined;
+    if (route.body) {
+      body = req.body ?? {};
+      issues.push(...validateSchema(route.body, body, 'body', components));
+    }
+
+    if (issues.length > 0) {
+      throw ApiError.validation(issues);
+    }
+
+    return {
+      params,
+      query,
+      body,
+      user: res.locals.user ?? ANONYMOUS_USER,
+      admin: res.locals.admin ?? false,
+      client: getClientLabel(req)
+    };
+  }
+}
+
+/**
+ * Send an error using the standard { error: { code, message } } shape
+ */
+export function sendApiError(res: Response, error: unknown, operationId?: string): void {
+  const apiError = toApiError(error);
+  if (apiError.status >= 500) {
+    console.error(`[API] ${operationId ?? 'request'} failed:`, error);
+  }
+  res.status(apiError.status).json(apiError.toBody());
+}
+
+/**
+ * Express error middleware for failures raised before a route runs
+ * (malformed JSON, oversized bodies)
+ */
+export function apiErrorMiddleware(error: unknown, req: Request, res: Response, next: NextFunction): void {
+  if (res.headersSent) {
+    next(error);
+    return;
+  }
+  sendApiError(res, error);
+}
@@ -0,0 +1,171 @@
+/**
+ * The subset of JSON Schema (OpenAPI 3.0 flavour) used to describe API routes
+ */
+export interface JsonSchema {
+  type?: 'string' | 'number' | 'integer' | 'boolean' | 'object' | 'array';
+  description?: string;
+  nullable?: boolean;
+  enum?: Array<string | number>;
+  // string
+  minLength?: number;
+  maxLength?: number;
+  pattern?: string;
+  // number / integer
+  minimum?: number;
+  maximum?: number;
+  // object
+  properties?: Record<string, JsonSchema>;
+  required?: string[];
+  additionalProperties?: boolean | JsonSchema;
+  // array
+  items?: JsonSchema;
+  minItems?: number;
+  // reference to a named schema: '#/components/schemas/Name'
+  $ref?: string;
+}
+
+export type SchemaComponents = Record<string, JsonSchema>;
+
+const REF_PREFIX = '#/components/schemas/';
+
+/**
+ * Get the component name a $ref points at
+ */
+export function refName(ref: string): string {
+  return ref.startsWith(REF_PREFIX) ? ref.slice(REF_PREFIX.length) : ref;
+}
+
+/**
+ * Validate a value against a schema, returning one message per problem.
+ * `location` prefixes messages, e.g. "body.line: must be >= 1".
+ */
+export function validateSchema(
+  schema: JsonSchema,
+  value: unknown,
+  location: string,
+  components: SchemaComponents = {}
+): string[] {
+  if (schema.$ref) {
+    const target = components[refName(schema.$ref)];
+    if (!target) {
+      return [`${location}: unknown schema ${schema.$ref}`];
+    }
+    return validateSchema(target, value, location, components);
+  }
+
+  if (value === null) {
+    return schema.nullable ? [] : [`${location}: must not be null`];
+  }
+
+  const issues: string[] = [];
+
+  if (schema.enum && !schema.enum.includes(value as string | number)) {
+    return [`${location}: must be one of ${schema.enum.map(v => JSON.stringify(v)).join(', ')}`];
+  }
+
+  switch (schema.type) {
+    case 'string':
+      if (typeof value !== 'string') {
+        return [`${location}: must be a string`];
+      }
+      if (schema.minLength !== undefined && value.length < schema.minLength) {
+        issues.push(schema.minLength === 1
+          ? `${location}: must not be empty`
+          : `${location}: must be at least ${schema.minLength} characters`);
+      }
+      if (schema.maxLength !== undefined && value.length > schema.maxLength) {
+        issues.push(`${location}: must be at most ${schema.maxLength} characters`);
+      }
+      if (schema.pattern !== undefined && !new RegExp(schema.pattern).test(value)) {
+        issues.push(`${location}: must match ${schema.pattern}`);
+      }
+      break;
+
+    case 'number':
+    case 'integer':
+      if (typeof value !== 'number' || Number.isNaN(value)) {
+        return [`${location}: must be a number`];
+      }
+      if (schema.type === 'integer' && !Number.isInteger(value)) {
+        return [`${location}: must be an integer`];
+      }
+      if (schema.minimum !== undefined && value < schema.minimum) {
+        issues.push(`${location}: must be >= ${schema.minimum}`);
+      }
+      if (schema.maximum !== undefined && value > schema.maximum) {
+        issues.push(`${location}: must be <= ${schema.maximum}`);
+      }
+      break;
+
+    case 'boolean':
+      if (typeof value !== 'boolean') {
+        return [`${location}: must be a boolean`];
+      }
+      break;
+
+    case 'array':
+      if (!Array.isArray(value)) {
+        return [`${location}: must be an array`];
+      }
+      if (schema.minItems !== undefined && value.length < schema.minItems) {
+        issues.push(`${location}: must contain at least ${schema.minItems} items`);
+      }
+      if (schema.items) {
+        value.forEach((item, index) => {
+          issues.push(...validateSchema(schema.items!, item, `${location}[${index}]`, components));
+        });
+      }
+      break;
+
+    case 'object': {
+      if (typeof value !== 'object' || Array.isArray(value)) {
+        return [`${location}: must be an object`];
+      }
+      const record = value as Record<string, unknown>;
+      const properties = schema.properties || {};
+      for (const name of schema.required || []) {
+        if (record[name] === undefined) {
+          issues.push(`${location}.${name}: is required`);
+        }
+      }
+      for (const [name, propValue] of Object.entries(record)) {
+        if (propValue === undefined) continue;
+        const propSchema = properties[name];
+        if (propSchema) {
+          issues.push(...validateSchema(propSchema, propValue, `${location}.${name}`, components));
+        } else if (schema.additionalProperties === false) {
+          issues.push(`${location}.${name}: unknown property`);
+        } else if (typeof schema.additionalProperties === 'object') {
+          issues.push(...validateSchema(schema.additionalProperties, propValue, `${location}.${name}`, components));
+        }
+      }
+      break;
+    }
+  }
+
+  return issues;
+}
+
+/**
+ * Convert query-string values to the types their schema expects so they
+ * can be validated like JSON bodies. Unconvertible values are left alone
+ * and reported by validation.
+ */
+export function coerceQuery(schema: JsonSchema, query: Record<string, unknown>): Record<string, unknown> {
+  const result: Record<string, unknown> = {};
+  for (const [name, raw] of Object.entries(query)) {
+    const propSchema = schema.properties?.[name];
+    if (typeof raw !== 'string' || !propSchema) {
+      result[name] = raw;
+      continue;
+    }
+    if ((propSchema.type === 'integer' || propSchema.type === 'number') && raw.trim() !== '' && !Number.isNaN(Number(raw))) {
+      result[name] = Number(raw);
+    } else if (propSchema.type === 'boolean' && (raw === 'true' || raw === 'false')) {
+      result[name] = raw === 'true';
+    } else {
+      result[name] = raw;
+    }
+  }
+  return result;
+}
@@ -0,0 +1,607 @@
+import { Router } from 'express';
+import { RealFileSystem } from '../../fs/real.js';
+import { TemplateManager } from '../../templates/manager.js';
+import { BookmarkStore } from '../../workspace/bookmarks.js';
+import { CommentStore } from '../../comments/store.js';
+import { WorkspaceState } from '../../workspace/state.js';
+import { TodoScanner } from '../../todo/scanner.js';
+import { LimitsConfig } from '../../config/config.js';
+import { ApiError } from '../errors.js';
+import { ApiRequest, ApiRouter } from '../router.js';
+import { AuditAction, AuditLog } from '../../audit/log.js';
+import { EventBus, WORKSPACE_EVENT_TYPES, WorkspaceEventType } from '../../events/bus.js';
+import { openEventStream } from '../../events/stream.js';
+import { PluginHost } from '../../plugins/host.js';
+import { SchemaRegistry } from '../../schemas/registry.js';
+import { GoModuleService } from '../../gomod/service.js';
+import { NpmService } from '../../npm/service.js';
+import { CoverageStore } from '../../coverage/store.js';
+import { LinterService } from '../../linters/service.js';
+import { RunService } from '../../run/service.js';
+import { PortDetector } from '../../ports/detector.js';
+import { getPreviewPath } from '../../ports/proxy.js';
+import { HttpClientService } from '../../restclient/service.js';
+import { SnapshotFile, SnapshotHover, SnapshotStore, summarize } from '../../snapshots/store.js';
+import { getLanguageIdForPath } from '../../lsp/languages.js';
+import { TaskInfo, TaskManager } from '../../tasks/manager.js';
+import { EditedFile, FileOperationKind, FileOperations } from '../../lsp/fileOperations.js';
+import { VirtualDocumentProvider } from '../../fs/virtual.js';
+import { workspacePathToUri } from '../../fs/uris.js';
+import { API_V1_SPEC } from './spec.js';
+
+export interface V1Dependencies {
+  fileSystem: RealFileSystem;
+  templateManager: TemplateManager;
+  bookmarkStore: BookmarkStore;
+  comments: CommentStore;
+  workspaceState: WorkspaceState;
+  todoScanner: TodoScanner;
+  // Undefined when auditing is disabled
+  auditLog?: AuditLog;
+  events: EventBus;
+  plugins: PluginHost;
+  schemas: SchemaRegistry;
+  goModules: GoModuleService;
+  npm: NpmService;
+  coverage: CoverageStore;
+  linters: LinterService;
+  run: RunService;
+  ports: PortDetector;
+  http: HttpClientService;
+  snapshots: SnapshotStore;
+  taskManager: TaskManager;
+  fileOperations: FileOperations;
+  virtualDocuments: VirtualDocumentProvider;
+  limits: LimitsConfig;
+}
+
+// Snapshots are for sharing a few files, not whole projects
+const MAX_SNAPSHOT_FILES = 20;
+const MAX_SNAPSHOT_HOVERS = 5000;
+const DEFAULT_SNAPSHOT_DAYS = 7;
+
+/**
+ * Build the /api/v1 router
+ */
+export function createV1Router(deps: V1Dependencies): Router {
+  const { fileSystem, templateManager, bookmarkStore, comments, workspaceState, todoScanner, auditLog, events, plugins, schemas, goModules, npm, coverage, linters, run, ports, http, snapshots, taskManager, fileOperations, virtualDocuments, limits } = deps;
+  const api = new ApiRouter(API_V1_SPEC);
+
+  const audit = (request: ApiRequest, action: AuditAction, path?: string, details?: Record<string, unknown>) => {
+    auditLog?.recordInBackground({ user: request.user, client: request.client, action, path, details });
+  };
+
+  // Commands run on the server can download packages and run their scripts,
+  // so the exact command line is recorded, not just what was asked for
+  const commandLine = (task: { command: string; args: string[] }) => [task.command, ...task.args].join(' ');
+  const recordCommand = (request: ApiRequest, path: string, task: TaskInfo, details?: Record<string, unknown>) => {
+    audit(request, 'command.run', path, { command: commandLine(task), ...details });
+  };
+
+  // Files language servers edited ahead of a file operation, e.g. imports of
+  // a moved file. Edits to open files reach the disk when the editor saves.
+  const recordServerEdits = (request: ApiRequest, operation: FileOperationKind, edited: EditedFile[]) => {
+    for (const { path: editedPath, written } of edited) {
+      audit(request, 'edit.apply', editedPath, { operation, written });
+      if (written) {
+        events.emit('file.saved', { path: editedPath, user: request.user });
+        void todoScanner.refreshPath(editedPath);
+      }
+    }
+  };
+
+  // Files
+
+  api.handle('listFiles', () => fileSystem.listFileTree());
+
+  api.handle('readFile', async ({ query }) => {
+    const size = await fileSystem.getFileSize(query.path);
+    if (size === undefined) {
+      throw ApiError.notFound(`File not found: ${query.path}`);
+    }
+    if (size > limits.maxFileSizeBytes) {
+      throw ApiError.tooLarge(`File is too large to open (${size} bytes, limit ${limits.maxFileSizeBytes})`);
+    }
+    const content = await fileSystem.readFileContent(query.path);
+    return { path: query.path, content, size };
+  });
+
+  api.handle('createFile', async (request) => {
+    const { body } = request;
+    const content: string = body.content ?? '';
+    if (Buffer.byteLength(content, 'utf-8') > limits.maxFileSizeBytes) {
+      throw ApiError.tooLarge(`File content exceeds the ${limits.maxFileSizeBytes} byte limit`);
+    }
+    fileSystem.resolveWorkspacePath(body.path);
+    const uri = workspacePathToUri(body.path);
+    if (await fileSystem.hasFile(uri)) {
+      throw ApiError.conflict(`File already exists: ${body.path}`);
+    }
+
+    recordServerEdits(request, 'create', await fileOperations.willCreate([body.path]));
+    await fileSystem.createFile(uri, content, body.languageId ?? 'plaintext');
+    void fileOperations.didCreate([body.path]);
+    audit(request, 'file.create', body.path);
+    events.emit('file.created', { path: body.path, user: request.user });
+    void todoScanner.refreshPath(body.path);
+    return { path: body.path };
+  });
+
+  api.handle('createFolder', async (request) => {
+    const { body } = request;
+    await fileSystem.createDirectory(body.path);
+    audit(request, 'folder.create', body.path);
+    events.emit('folder.created', { path: body.path, user: request.user });
+    return { path: body.path };
+  });
+
+  api.handle('deletePath', async (request) => {
+    const { query } = request;
+    const isFolder = await fileOperations.isFolder(query.path);
+    recordServerEdits(request, 'delete', await fileOperations.willDelete([query.path]));
+    await fileSystem.deletePath(query.path);
+    void fileOperations.didDelete([query.path], isFolder ? [query.path] : []);
+    audit(request, 'path.delete', query.path);
+    events.emit('file.deleted', { path: query.path, user: request.user });
+    await bookmarkStore.removePath(query.path);
+    await comments.removePath(query.path);
+    void todoScanner.refreshPath(query.path);
+    return { path: query.path };
+  });
+
+  api.handle('renamePath', async (request) => {
+    const { oldPath, newPath } = request.body;
+    // Language servers update imports of the moved files before they move
+    recordServerEdits(request, 'rename', await fileOperations.willRename([{ oldPath, newPath }]));
+    await fileSystem.renamePath(oldPath, newPath);
+    // Right away: it moves the editors' open documents to the new path first
+    void fileOperations.didRename([{ oldPath, newPath }]);
+    audit(request, 'path.rename', oldPath, { newPath });
+    events.emit('file.renamed', { oldPath, newPath, user: request.user });
+    await bookmarkStore.renamePath(oldPath, newPath);
+    await comments.renamePath(oldPath, newPath);
+    void todoScanner.refreshPath(oldPath);
+    void todoScanner.refreshPath(newPath);
+    return { oldPath, newPath };
+  });
+
+  api.handle('readVirtualDocument', async ({ query }) => {
+    try {
+      return await virtualDocuments.read(query.uri);
+    } catch (error) {
+      if ((error as Error).message.startsWith('File is too large')) {
+        throw ApiError.tooLarge((error as Error).message);
+      }
+      throw error;
+    }
+  });
+
+  // Templates
+
+  api.handle('listTemplates', () => templateManager.listTemplates());
+
+  api.handle('createProject', async (request) => {
+    const { params, body } = request;
+    if (!templateManager.getTemplate(params.id)) {
+      throw ApiError.notFound(`Unknown template: ${params.id}`);
+    }
+
+    try {
+      const result = await templateManager.createProject(params.id, body.targetPath, body.variables || {});
+      const failedHook = result.hooks.find(h => h.task.status !== 'succeeded' && !h.optional);
+      audit(request, 'project.create', result.path, {
+        template: params.id,
+        files: result.files.length,
+        hooks: result.hooks.map(h => ({ command: commandLine(h.task), status: h.task.status }))
+      });
+      return { success: !failedHook, ...result };
+    } catch (error) {
+      const message = (error as Error).message;
+      if (message.startsWith('Missing value') || message.startsWith('Invalid value')) {
+        throw ApiError.badRequest(message);
+      }
+      if (message.startsWith('Target directory is not empty')) {
+        throw ApiError.conflict(message);
+      }
+      throw error;
+    }
+  });
+
+  // Bookmarks
+
+  api.handle('listBookmarks', ({ query }) => bookmarkStore.list(query.path));
+
+  api.handle('toggleBookmark', async ({ body }) => {
+    const bookmark = await bookmarkStore.toggle(body.path, body.line, body.label);
+    return { added: bookmark !== undefined, bookmark, bookmarks: await bookmarkStore.list() };
+  });
+
+  api.handle('updateBookmark', async ({ params, body }) => {
+    const bookmark = await bookmarkStore.setLabel(params.id, body.label);
+    if (!bookmark) {
+      throw ApiError.notFound(`Bookmark not found: ${params.id}`);
+    }
+    return bookmark;
+  });
+
+  api.handle('deleteBookmark', async ({ params }) => {
+    if (!(await bookmarkStore.remove(params.id))) {
+      throw ApiError.notFound(`Bookmark not found: ${params.id}`);
+    }
+    return { success: true };
+  });
+
+  // Review comments
+
+  api.handle('listCommentThreads', ({ query }) => comments.list(query));
+
+  api.handle('createCommentThread', async ({ body, user }) => {
+    const thread = await comments.create({
+      path: body.path,
+      startLine: body.startLine,
+      endLine: body.endLine ?? body.startLine,
+      author: user,
+      body: body.body
+    });
+    if (!thread) {
+      throw ApiError.notFound(`File not found: ${body.path}`);
+    }
+    return thread;
+  });
+
+  api.handle('replyToCommentThread', async ({ params, body, user }) => {
+    const thread = await comments.reply(params.id, user, body.body);
+    if (!thread) {
+      throw ApiError.notFound(`Comment thread not found: ${params.id}`);
+    }
+    return thread;
+  });
+
+  api.handle('updateCommentThread', async ({ params, body, user }) => {
+    const thread = await comments.setStatus(params.id, body.status, user);
+    if (!thread) {
+      throw ApiError.notFound(`Comment thread not found: ${params.id}`);
+    }
+    return thread;
+  });
+
+  api.handle('deleteCommentThread', async ({ params, user, admin }) => {
+    const thread = await comments.get(params.id);
+    if (!thread) {
+      throw ApiError.notFound(`Comment thread not found: ${params.id}`);
+    }
+    if (!admin && thread.comments[0]?.author !== user) {
+      throw ApiError.forbidden('Only whoever started a comment thread or an admin can delete it');
+    }
+    await comments.remove(params.id);
+    return { success: true };
+  });
+
+  // TODOs
+
+  api.handle('listTodos', () => ({
+    tags: todoScanner.getTags(),
+    items: todoScanner.getItems()
+  }));
+
+  api.handle('setTodoTags', async (request) => {
+    const { body } = request;
+    await workspaceState.writeJson('todo.json', { tags: body.tags });
+    audit(request, 'settings.update', undefined, { setting: 'todo.tags', tags: body.tags });
+    await todoScanner.setTags(body.tags);
+    return { tags: todoScanner.getTags(), items: todoScanner.getItems() };
+  });
+
+  // Schemas
+
+  const schemaIndex = async () => ({ schemas: await schemas.list(), mappings: schemas.getMappings() });
+
+  api.handle('listSchemas', () => schemaIndex());
+
+  api.handle('getSchema', async ({ query }) => {
+    try {
+      return await schemas.getSchema(query.id);
+    } catch (error) {
+      throw ApiError.notFound((error as Error).message);
+    }
+  });
+
+  api.handle('setSchemaMappings', async (request) => {
+    const { body } = request;
+    try {
+      await schemas.setMappings(body.mappings);
+    } catch (error) {
+      throw ApiError.badRequest((error as Error).message);
+    }
+    audit(request, 'settings.update', undefined, { setting: 'schemas.mappings', mappings: body.mappings });
+    return schemaIndex();
+  });
+
+  // Go modules
+
+  api.handle('listGoModules', () => goModules.listModules());
+
+  api.handle('runGoModuleCommand', async (request) => {
+    const { body } = request;
+    const readModule = async () => {
+      try {
+        return await goModules.getModule(body.path);
+      } catch (error) {
+        if ((error as Error).message.startsWith('Access denied')) {
+          throw error;
+        }
+        throw ApiError.notFound(`No go.mod in ${body.path}`);
+      }
+    };
+    await readModule();
+
+    const { task, output } = await goModules.runCommand(body.path, body.command, body.dependency);
+    recordCommand(request, body.path, task, { status: task.status });
+    return { task, output, module: await readModule() };
+  });
+
+  // npm
+
+  api.handle('listNpmPackages', () => npm.listPackages());
+
+  api.handle('runNpmScript', async (request) => {
+    const { body } = request;
+    let task;
+    try {
+      task = await npm.runScript(body.path, body.script);
+    } catch (error) {
+      throw ApiError.notFound((error as Error).message);
+    }
+    recordCommand(request, body.path, task);
+    return task;
+  });
+
+  api.handle('runNpmCommand', async (request) => {
+    const { body } = request;
+    let task;
+    try {
+      task = await npm.runCommand(body.path, body.command, body.command === 'update' ? body.dependency : undefined);
+    } catch (error) {
+      throw ApiError.notFound((error as Error).message);
+    }
+    recordCommand(request, body.path, task);
+    return task;
+  });
+
+  // Run
+
+  api.handle('runFile', async (request) => {
+    const { body } = request;
+    let task;
+    try {
+      task = await run.run({ uri: body.uri, args: body.args, env: body.env, client: request.client });
+    } catch (error) {
+      const message = (error as Error).message;
+      if (message.startsWith('Access denied')) {
+        throw error;
+      }
+      throw /not found|not open/i.test(message) ? ApiError.notFound(message) : ApiError.badRequest(message);
+    }
+    recordCommand(request, body.uri, task);
+    return task;
+  });
+
+  // Ports
+
+  api.handle('listPorts', async ({ query }) => {
+    const listening = query.refresh ? await ports.refresh() : ports.getPorts();
+    return listening.map(port => ({ ...port, previewPath: getPreviewPath(port.port) }));
+  });
+
+  // HTTP requests (.http files)
+
+  // Errors of the .http file itself, as opposed to a failed request, which is a normal response
+  const httpError = (error: unknown): Error => {
+    const message = (error as Error).message;
+    if (message.startsWith('Access denied')) {
+      return error as Error;
+    }
+    return /not found|not open/i.test(message) ? ApiError.notFound(message) : ApiError.badRequest(message);
+  };
+
+  api.handle('sendHttpRequest', async (request) => {
+    const { body } = request;
+    let exchange;
+    try {
+      exchange = await http.send({ uri: body.uri, line: body.line, env: body.env, client: request.client });
+    } catch (error) {
+      throw httpError(error);
+    }
+    audit(request, 'http.request', body.uri, {
+      method: exchange.method,
+      url: exchange.url,
+      status: exchange.status
+    });
+    return exchange;
+  });
+
+  api.handle('listHttpEnvironments', async ({ query, ...request }) => {
+    try {
+      return await http.listEnvironments(query.uri, request.client);
+    } catch (error) {
+      throw httpError(error);
+    }
+  });
+
+  api.handle('listHttpHistory', () => http.listHistory());
+
+  api.handle('getHttpHistoryEntry', async ({ params }) => {
+    const entry = await http.getHistoryEntry(params.id);
+    if (!entry) {
+      throw ApiError.notFound(`History entry not found: ${params.id}`);
+    }
+    return entry;
+  });
+
+  api.handle('clearHttpHistory', async (request) => {
+    await http.clearHistory();
+    audit(request, 'http.history.clear');
+    return { success: true };
+  });
+
+  // Snapshots
+
+  api.handle('createSnapshot', async (request) => {
+    const { body } = request;
+    if (body.files.length > MAX_SNAPSHOT_FILES) {
+      throw ApiError.badRequest(`A snapshot can hold at most ${MAX_SNAPSHOT_FILES} files`);
+    }
+    const files: SnapshotFile[] = [];
+    let totalSize = 0;
+    for (const file of body.files as Array<{ path: string; hovers?: SnapshotHover[] }>) {
+      const size = await fileSystem.getFileSize(file.path);
+      if (size === undefined) {
+        throw ApiError.notFound(`File not found: ${file.path}`);
+      }
+      totalSize += size;
+      if (totalSize > limits.maxFileSizeBytes) {
+        throw ApiError.tooLarge(`Snapshot files exceed the ${limits.maxFileSizeBytes} byte limit`);
+      }
+      files.push({
+        path: file.path,
+        languageId: getLanguageIdForPath(file.path),
+        content: await fileSystem.readFileContent(file.path),
+        hovers: (file.hovers ?? []).slice(0, MAX_SNAPSHOT_HOVERS)
+      });
+    }
+
+    const snapshot = await snapshots.create({
+      title: body.title,
+      expiresInDays: body.expiresInDays ?? DEFAULT_SNAPSHOT_DAYS,
+      createdBy: request.user,
+      files
+    });
+    audit(request, 'snapshot.create', files[0].path, { id: snapshot.id, files: files.map(file => file.path) });
+    return summarize(snapshot);
+  });
+
+  api.handle('listSnapshots', ({ user, admin }) => snapshots.list(admin ? undefined : user));
+
+  api.handle('getSnapshot', async ({ params }) => {
+    const snapshot = await snapshots.get(params.id);
+    if (!snapshot) {
+      throw ApiError.notFound(`Snapshot not found: ${params.id}`);
+    }
+    return snapshot;
+  });
+
+  api.handle('revokeSnapshot', async (request) => {
+    const { params } = request;
+    const snapshot = await snapshots.get(params.id);
+    if (!snapshot) {
+      throw ApiError.notFound(`Snapshot not found: ${params.id}`);
+    }
+    if (!request.admin && snapshot.createdBy !== request.user) {
+      throw ApiError.forbidden('Only the creator of a snapshot or an admin can revoke it');
+    }
+    await snapshots.revoke(params.id);
+    audit(request, 'snapshot.revoke', snapshot.files[0]?.path, { id: snapshot.id });
+    return { success: true };
+  });
+
+  // Coverage
+
+  api.handle('getCoverage', () => coverage.getSummary());
+
+  api.handle('getFileCoverage', ({ query }) => {
+    const file = coverage.getFile(query.path);
+    if (!file) {
+      throw ApiError.notFound(`No coverage for ${query.path}`);
+    }
+    return file;
+  });
+
+  api.handle('importCoverage', async ({ body }) => {
+    try {
+      return await coverage.import(body.path, body.format);
+    } catch (error) {
+      const message = (error as Error).message;
+      if (message.startsWith('Access denied')) {
+        throw error;
+      }
+      throw /not found|no coverage report/i.test(message) ? ApiError.notFound(message) : ApiError.badRequest(message);
+    }
+  });
+
+  api.handle('clearCoverage', async () => {
+    await coverage.clear();
+    return { success: true };
+  });
+
+  // Linters
+
+  api.handle('listLinters', () => linters.getLinters());
+
+  api.handle('listLintDiagnostics', () => linters.listDiagnostics());
+
+  api.handle('lintFile', async ({ body }) => {
+    if ((await fileSystem.getFileSize(body.path)) === undefined) {
+      throw ApiError.notFound(`File not found: ${body.path}`);
+    }
+    return linters.lint(body.path);
+  });
+
+  // Tasks
+
+  api.handle('listTasks', () => taskManager.listTasks());
+
+  api.handle('getTask', ({ params }) => {
+    const task = taskManager.getTask(params.id);
+    if (!task) {
+      throw ApiError.notFound(`Task not found: ${params.id}`);
+    }
+    return { task, output: taskManager.getOutput(params.id) ?? '' };
+  });
+
+  api.handle('killTask', (request) => {
+    const task = taskManager.getTask(request.params.id);
+    if (!task) {
+      throw ApiError.notFound(`Task not found: ${request.params.id}`);
+    }
+    if (!taskManager.kill(task.id)) {
+      throw ApiError.conflict(`Task is not running: ${task.id}`);
+    }
+    audit(request, 'task.kill', undefined, { task: task.id, command: commandLine(task) });
+    return { success: true };
+  });
+
+  // Extensions
+
+  api.handle('listWebExtensions', () =>
+    plugins.list()
+      .filter(plugin => plugin.status === 'active' && plugin.webExtension)
+      .map(plugin => ({ name: plugin.name, version: plugin.version, url: plugin.webExtension! }))
+  );
+
+  // Events
+
+  api.handle('streamEvents', ({ query }, req, res) => {
+    let types: WorkspaceEventType[] | undefined;
+    if (query.types) {
+      types = String(query.types).split(',').map(type => type.trim()).filter(Boolean) as WorkspaceEventType[];
+      const unknown = types.filter(type => !WORKSPACE_EVENT_TYPES.includes(type));
+      if (unknown.length > 0) {
+        throw ApiError.badRequest(`Unknown event type(s): ${unknown.join(', ')}`);
+      }
+    }
+    openEventStream(events, req, res, types);
+  });
+
+  // Administration
+
+  api.handle('listAuditEntries', ({ query }) => {
+    if (!auditLog) {
+      throw ApiError.notFound('Audit logging is disabled (audit.enabled is false)');
+    }
+    return auditLog.query(query);
+  });
+
+  api.handle('listPlugins', () => plugins.list());
+
+  return api.build();
+}
@@ -0,0 +1,1355 @@
+import { ApiSpec } from '../router.js';
+import { JsonSchema } from '../schema.js';
+import { AuditAction } from '../../audit/log.js';
+import { WORKSPACE_EVENT_TYPES } from '../../events/bus.js';
+
+const ref = (name: string): JsonSchema => ({ $ref: `#/components/schemas/${name}` });
+
+// Workspace paths are absolute within the workspace, e.g. /src/main.go
+const workspacePath: JsonSchema = {
+  type: 'string',
+  minLength: 1,
+  pattern: '^/',
+  description: 'Workspace path starting with /'
+};
+
+const AUDIT_ACTIONS: AuditAction[] = [
+  'file.create', 'file.save', 'folder.create', 'path.delete', 'path.rename', 'project.create', 'settings.update', 'command.run',
+  'http.request', 'http.history.clear', 'task.kill', 'snapshot.create', 'snapshot.revoke', 'edit.apply'
+];
+
+const commentBody: JsonSchema = { type: 'string', minLength: 1, maxLength: 10000, description: 'Markdown' };
+
+const pathQuery: JsonSchema = {
+  type: 'object',
+  properties: { path: workspacePath },
+  required: ['path'],
+  additionalProperties: false
+};
+
+// Fields of a sent .http request shared by the history list and the full entry
+const httpExchangeSummary: Required<Pick<JsonSchema, 'properties' | 'required'>> = {
+  properties: {
+    id: { type: 'string' },
+    timestamp: { type: 'string' },
+    uri: { type: 'string', description: 'Editor URI of the .http file' },
+    line: { type: 'integer', description: '1-based line of the request line' },
+    name: { type: 'string', description: 'From a # @name comment' },
+    env: { type: 'string' },
+    method: { type: 'string' },
+    url: { type: 'string', description: 'With variables resolved' },
+    status: { type: 'integer', description: '0 when no response was received' },
+    statusText: { type: 'string' },
+    size: { type: 'integer', description: 'Response body size in bytes' },
+    truncated: { type: 'boolean', description: 'The body was cut short' },
+    durationMs: { type: 'integer' },
+    error: { type: 'string', description: 'Why no response was received' }
+  },
+  required: ['id', 'timestamp', 'uri', 'line', 'method', 'url', 'status', 'statusText', 'size', 'truncated', 'durationMs']
+};
+
+/**
+ * Version 1 of the REST API, served under /api/v1
+ */
+export const API_V1_SPEC: ApiSpec = {
+  title: 'Online Code Editor API',
+  version: '1.0.0',
+  basePath: '/api/v1',
+
+  components: {
+    FileTreeNode: {
+      type: 'object',
+      properties: {
+        name: { type: 'string' },
+        path: { type: 'string' },
+        type: { type: 'string', enum: ['file', 'directory'] },
+        children: { type: 'array', items: ref('FileTreeNode') }
+      },
+      required: ['name', 'path', 'type']
+    },
+    FileContent: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        content: { type: 'string' },
+        size: { type: 'integer', description: 'Size in bytes' }
+      },
+      required: ['path', 'content', 'size']
+    },
+    VirtualDocument: {
+      type: 'object',
+      description: 'Read-only file outside the workspace, such as the Go standard library',
+      properties: {
+        uri: { type: 'string', description: 'deps:///<root>/<path>' },
+        name: { type: 'string', description: 'Root name and path, e.g. goroot/src/fmt/print.go' },
+        content: { type: 'string' },
+        size: { type: 'integer', description: 'Size in bytes' }
+      },
+      required: ['uri', 'name', 'content', 'size']
+    },
+    PathResult: {
+      type: 'object',
+      properties: { path: { type: 'string' } },
+      required: ['path']
+    },
+    RenameResult: {
+      type: 'object',
+      properties: {
+        oldPath: { type: 'string' },
+        newPath: { type: 'string' }
+      },
+      required: ['oldPath', 'newPath']
+    },
+    OkResult: {
+      type: 'object',
+      properties: { success: { type: 'boolean' } },
+      required: ['success']
+    },
+    TemplateVariable: {
+      type: 'object',
+      properties: {
+        name: { type: 'string' },
+        description: { type: 'string' },
+        default: { type: 'string' },
+        pattern: { type: 'string' },
+        required: { type: 'boolean' }
+      },
+      required: ['name']
+    },
+    TemplateHook: {
+      type: 'object',
+      properties: {
+        command: { type: 'string' },
+        args: { type: 'array', items: { type: 'string' } },
+        optional: { type: 'boolean' }
+      },
+      required: ['command']
+    },
+    ProjectTemplate: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        name: { type: 'string' },
+        description: { type: 'string' },
+        language: { type: 'string' },
+        variables: { type: 'array', items: ref('TemplateVariable') },
+        hooks: { type: 'array', items: ref('TemplateHook') }
+      },
+      required: ['id', 'name', 'description', 'variables', 'hooks']
+    },
+    TaskInfo: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        label: { type: 'string' },
+        command: { type: 'string' },
+        args: { type: 'array', items: { type: 'string' } },
+        cwd: { type: 'string' },
+        status: { type: 'string', enum: ['running', 'succeeded', 'failed', 'killed'] },
+        exitCode: { type: 'integer', nullable: true },
+        startedAt: { type: 'string' },
+        finishedAt: { type: 'string' }
+      },
+      required: ['id', 'label', 'command', 'args', 'cwd', 'status', 'exitCode', 'startedAt']
+    },
+    ListeningPort: {
+      type: 'object',
+      properties: {
+        port: { type: 'integer' },
+        host: { type: 'string', description: 'Address the proxy connects to' },
+        pid: { type: 'integer' },
+        command: { type: 'string', description: 'Name of the listening process' },
+        taskId: { type: 'string' },
+        taskLabel: { type: 'string' },
+        previewPath: { type: 'string', description: 'Path of the preview proxy, e.g. /preview/3000/' }
+      },
+      required: ['port', 'host', 'pid', 'command', 'previewPath']
+    },
+    HttpExchangeSummary: {
+      type: 'object',
+      ...httpExchangeSummary
+    },
+    HttpExchange: {
+      type: 'object',
+      properties: {
+        ...httpExchangeSummary.properties,
+        requestHeaders: { type: 'object', additionalProperties: { type: 'string' } },
+        requestBody: { type: 'string' },
+        headers: { type: 'object', additionalProperties: { type: 'string' } },
+        body: { type: 'string' }
+      },
+      required: [...httpExchangeSummary.required, 'requestHeaders', 'headers', 'body']
+    },
+    HttpEnvironments: {
+      type: 'object',
+      properties: {
+        file: { type: 'string', description: 'Workspace-relative path of the env file' },
+        environments: { type: 'array', items: { type: 'string' } }
+      },
+      required: ['environments']
+    },
+    SnapshotHover: {
+      type: 'object',
+      properties: {
+        line: { type: 'integer', minimum: 1 },
+        startColumn: { type: 'integer', minimum: 1 },
+        endColumn: { type: 'integer', minimum: 1 },
+        contents: { type: 'string', description: 'Markdown' }
+      },
+      required: ['line', 'startColumn', 'endColumn', 'contents'],
+      additionalProperties: false
+    },
+    SnapshotFile: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        languageId: { type: 'string' },
+        content: { type: 'string' },
+        hovers: { type: 'array', items: ref('SnapshotHover') }
+      },
+      required: ['path', 'languageId', 'content', 'hovers']
+    },
+    Snapshot: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        title: { type: 'string' },
+        createdAt: { type: 'string' },
+        expiresAt: { type: 'string', description: 'Unset when the snapshot never expires' },
+        createdBy: { type: 'string' },
+        files: { type: 'array', items: ref('SnapshotFile') }
+      },
+      required: ['id', 'createdAt', 'createdBy', 'files']
+    },
+    SnapshotSummary: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        title: { type: 'string' },
+        createdAt: { type: 'string' },
+        expiresAt: { type: 'string' },
+        createdBy: { type: 'string' },
+        files: { type: 'array', items: { type: 'string' }, description: 'Paths of the files' }
+      },
+      required: ['id', 'createdAt', 'createdBy', 'files']
+    },
+    HookResult: {
+      type: 'object',
+      properties: {
+        task: ref('TaskInfo'),
+        output: { type: 'string' },
+        optional: { type: 'boolean' }
+      },
+      required: ['task', 'output', 'optional']
+    },
+    CreateProjectResult: {
+      type: 'object',
+      properties: {
+        success: { type: 'boolean', description: 'False when a required hook failed' },
+        path: { type: 'string' },
+        files: { type: 'array', items: { type: 'string' } },
+        hooks: { type: 'array', items: ref('HookResult') }
+      },
+      required: ['success', 'path', 'files', 'hooks']
+    },
+    Bookmark: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        path: { type: 'string' },
+        line: { type: 'integer' },
+        label: { type: 'string' },
+        createdAt: { type: 'string' }
+      },
+      required: ['id', 'path', 'line', 'createdAt']
+    },
+    ToggleBookmarkResult: {
+      type: 'object',
+      properties: {
+        added: { type: 'boolean' },
+        bookmark: ref('Bookmark'),
+        bookmarks: { type: 'array', items: ref('Bookmark') }
+      },
+      required: ['added', 'bookmarks']
+    },
+    ReviewComment: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        author: { type: 'string' },
+        body: { type: 'string', description: 'Markdown' },
+        createdAt: { type: 'string' }
+      },
+      required: ['id', 'author', 'body', 'createdAt']
+    },
+    CommentThread: {
+      type: 'object',
+      properties: {
+        id: { type: 'string' },
+        path: { type: 'string' },
+        startLine: { type: 'integer', description: '1-based; follows the text as the file changes' },
+        endLine: { type: 'integer', description: 'Inclusive' },
+        text: { type: 'string', description: 'The lines as they were when the thread was started' },
+        outdated: { type: 'boolean', description: 'The lines were changed or removed since' },
+        status: { type: 'string', enum: ['open', 'resolved'] },
+        resolvedBy: { type: 'string' },
+        resolvedAt: { type: 'string' },
+        createdAt: { type: 'string' },
+        updatedAt: { type: 'string' },
+        comments: { type: 'array', items: ref('ReviewComment') }
+      },
+      required: ['id', 'path', 'startLine', 'endLine', 'text', 'outdated', 'status', 'createdAt', 'updatedAt', 'comments']
+    },
+    TodoItem: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        line: { type: 'integer' },
+        column: { type: 'integer' },
+        tag: { type: 'string' },
+        text: { type: 'string' }
+      },
+      required: ['path', 'line', 'column', 'tag', 'text']
+    },
+    TodoIndex: {
+      type: 'object',
+      properties: {
+        tags: { type: 'array', items: { type: 'string' } },
+        items: { type: 'array', items: ref('TodoItem') }
+      },
+      required: ['tags', 'items']
+    },
+    SchemaInfo: {
+      type: 'object',
+      properties: {
+        id: { type: 'string', description: 'Built-in schema ID, or the workspace path of the schema file' },
+        source: { type: 'string', enum: ['builtin', 'workspace'] },
+        title: { type: 'string' },
+        fileMatch: { type: 'array', items: { type: 'string' } }
+      },
+      required: ['id', 'source', 'fileMatch']
+    },
+    SchemaMapping: {
+      type: 'object',
+      properties: {
+        fileMatch: {
+          type: 'array',
+          items: { type: 'string', minLength: 1 },
+          minItems: 1,
+          description: 'Glob patterns such as "deploy/*.json"; "!" excludes'
+        },
+        schema: { type: 'string', minLength: 1, description: 'Built-in schema ID or workspace path of a schema file' }
+      },
+      required: ['fileMatch', 'schema'],
+      additionalProperties: false
+    },
+    SchemaIndex: {
+      type: 'object',
+      properties: {
+        schemas: { type: 'array', items: ref('SchemaInfo') },
+        mappings: { type: 'array', items: ref('SchemaMapping') }
+      },
+      required: ['schemas', 'mappings']
+    },
+    GoVulnerability: {
+      type: 'object',
+      properties: {
+        id: { type: 'string', description: 'OSV ID, e.g. GO-2023-1234' },
+        summary: { type: 'string' },
+        aliases: { type: 'array', items: { type: 'string' } },
+        fixed: { type: 'string', description: 'First version without the vulnerability' }
+      },
+      required: ['id', 'aliases']
+    },
+    GoDependency: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        version: { type: 'string' },
+        indirect: { type: 'boolean' },
+        line: { type: 'integer', description: 'Line of the requirement in go.mod' },
+        checksum: { type: 'boolean', description: 'Whether go.sum has a checksum for this version' },
+        latest: { type: 'string', description: 'Newer version available from the local module proxy' },
+        replace: { type: 'string', description: 'Replacement module or directory' },
+        vulnerabilities: { type: 'array', items: ref('GoVulnerability') }
+      },
+      required: ['path', 'version', 'indirect', 'line', 'checksum', 'vulnerabilities']
+    },
+    GoModuleDiagnostic: {
+      type: 'object',
+      properties: {
+        line: { type: 'integer' },
+        column: { type: 'integer' },
+        endColumn: { type: 'integer' },
+        severity: { type: 'string', enum: ['warning', 'info'] },
+        message: { type: 'string' },
+        code: { type: 'string' }
+      },
+      required: ['line', 'column', 'endColumn', 'severity', 'message', 'code']
+    },
+    GoModule: {
+      type: 'object',
+      properties: {
+        path: { type: 'string', description: 'Workspace path of the module directory' },
+        module: { type: 'string' },
+        goVersion: { type: 'string' },
+        dependencies: { type: 'array', items: ref('GoDependency') },
+        diagnostics: { type: 'array', items: ref('GoModuleDiagnostic') }
+      },
+      required: ['path', 'module', 'dependencies', 'diagnostics']
+    },
+    GoModuleCommandResult: {
+      type: 'object',
+      properties: {
+        task: ref('TaskInfo'),
+        output: { type: 'string' },
+        module: ref('GoModule')
+      },
+      required: ['task', 'output', 'module']
+    },
+    TaskOutput: {
+      type: 'object',
+      properties: {
+        task: ref('TaskInfo'),
+        output: { type: 'string', description: 'Captured stdout and stderr, up to the last 1MB' }
+      },
+      required: ['task', 'output']
+    },
+    NpmDependency: {
+      type: 'object',
+      properties: {
+        name: { type: 'string' },
+        type: { type: 'string', enum: ['dependencies', 'devDependencies', 'optionalDependencies', 'peerDependencies'] },
+        declared: { type: 'string', description: 'Range declared in package.json' },
+        installed: { type: 'string', description: 'Version in node_modules' },
+        locked: { type: 'string', description: 'Version pinned by the lockfile' },
+        status: { type: 'string', enum: ['ok', 'missing', 'mismatch', 'unknown'] }
+      },
+      required: ['name', 'type', 'declared', 'status']
+    },
+    NpmPackage: {
+      type: 'object',
+      properties: {
+        path: { type: 'string', description: 'Workspace path of the package directory' },
+        name: { type: 'string' },
+        version: { type: 'string' },
+        packageManager: { type: 'string', enum: ['npm', 'yarn', 'pnpm'] },
+        lockfile: { type: 'string', description: 'Workspace path of the lockfile in use' },
+        scripts: { type: 'object', additionalProperties: { type: 'string' } },
+        dependencies: { type: 'array', items: ref('NpmDependency') }
+      },
+      required: ['path', 'packageManager', 'scripts', 'dependencies']
+    },
+    CoverageFileSummary: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        covered: { type: 'integer', description: 'Lines that ran at least once' },
+        total: { type: 'integer', description: 'Lines with code' },
+        percent: { type: 'number' }
+      },
+      required: ['path', 'covered', 'total', 'percent']
+    },
+    CoverageSummary: {
+      type: 'object',
+      properties: {
+        source: { type: 'string', description: 'Workspace path of the report; unset when there is no coverage' },
+        format: { type: 'string', enum: ['go', 'lcov', 'istanbul'] },
+        updatedAt: { type: 'string' },
+        covered: { type: 'integer' },
+        total: { type: 'integer' },
+        percent: { type: 'number' },
+        files: { type: 'array', items: ref('CoverageFileSummary') }
+      },
+      required: ['covered', 'total', 'percent', 'files']
+    },
+    FileCoverage: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        covered: { type: 'array', items: { type: 'integer' } },
+        uncovered: { type: 'array', items: { type: 'integer' } }
+      },
+      required: ['path', 'covered', 'uncovered']
+    },
+    LintEdit: {
+      type: 'object',
+      description: 'A text edit; lines and columns are 1-based and the end is exclusive',
+      properties: {
+        startLine: { type: 'integer' },
+        startColumn: { type: 'integer' },
+        endLine: { type: 'integer' },
+        endColumn: { type: 'integer' },
+        text: { type: 'string' }
+      },
+      required: ['startLine', 'startColumn', 'endLine', 'endColumn', 'text']
+    },
+    LintFix: {
+      type: 'object',
+      properties: {
+        title: { type: 'string' },
+        edits: { type: 'array', items: ref('LintEdit') }
+      },
+      required: ['title', 'edits']
+    },
+    LintDiagnostic: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        line: { type: 'integer' },
+        column: { type: 'integer' },
+        endLine: { type: 'integer' },
+        endColumn: { type: 'integer' },
+        severity: { type: 'string', enum: ['error', 'warning', 'info'] },
+        message: { type: 'string' },
+        code: { type: 'string', description: 'Rule or linter that reported the problem' },
+        source: { type: 'string', description: 'Name of the configured linter' },
+        fixes: { type: 'array', items: ref('LintFix') }
+      },
+      required: ['path', 'line', 'column', 'endLine', 'endColumn', 'severity', 'message', 'source', 'fixes']
+    },
+    LintFileDiagnostics: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        diagnostics: { type: 'array', items: ref('LintDiagnostic') }
+      },
+      required: ['path', 'diagnostics']
+    },
+    LintResult: {
+      type: 'object',
+      properties: {
+        path: { type: 'string' },
+        linters: { type: 'array', items: { type: 'string' }, description: 'Linters that ran for the file' },
+        diagnostics: { type: 'array', items: ref('LintDiagnostic') },
+        errors: { type: 'array', items: { type: 'string' }, description: 'Linters that could not run' }
+      },
+      required: ['path', 'linters', 'diagnostics', 'errors']
+    },
+    AuditEntry: {
+      type: 'object',
+      properties: {
+        id: { type: 'integer' },
+        timestamp: { type: 'string' },
+        user: { type: 'string' },
+        client: { type: 'string' },
+        action: { type: 'string', enum: AUDIT_ACTIONS },
+        path: { type: 'string' },
+        details: { type: 'object' }
+      },
+      required: ['id', 'timestamp', 'user', 'client', 'action']
+    },
+    WorkspaceEvent: {
+      type: 'object',
+      properties: {
+        id: { type: 'integer' },
+        type: { type: 'string', enum: WORKSPACE_EVENT_TYPES },
+        timestamp: { type: 'string' },
+        data: { type: 'object' }
+      },
+      required: ['id', 'type', 'timestamp', 'data']
+    },
+    PluginInfo: {
+      type: 'object',
+      properties: {
+        name: { type: 'string' },
+        version: { type: 'string' },
+        source: { type: 'string' },
+        status: { type: 'string', enum: ['active', 'failed', 'inactive'] },
+        error: { type: 'string' },
+        errorCount: { type: 'integer' },
+        routes: { type: 'array', items: { type: 'string' } },
+        methods: { type: 'array', items: { type: 'string' } },
+        languageServers: { type: 'array', items: { type: 'string' } },
+        webExtension: { type: 'string' }
+      },
+      required: ['name', 'source', 'status', 'errorCount', 'routes', 'methods', 'languageServers']
+    },
+    WebExtensionInfo: {
+      type: 'object',
+      properties: {
+        name: { type: 'string', description: 'Name of the server plugin providing the extension' },
+        version: { type: 'string' },
+        url: { type: 'string', description: 'Bundle URL relative to the server' }
+      },
+      required: ['name', 'url']
+    },
+    AuditQueryResult: {
+      type: 'object',
+      properties: {
+        entries: { type: 'array', items: ref('AuditEntry') },
+        hasMore: { type: 'boolean' }
+      },
+      required: ['entries', 'hasMore']
+    },
+    ApiError: {
+      type: 'object',
+      properties: {
+        error: {
+          type: 'object',
+          properties: {
+            code: {
+              type: 'string',
+              enum: [
+                'bad_request', 'validation_failed', 'unauthorized', 'access_denied',
+                'not_found', 'conflict', 'payload_too_large', 'internal_error'
+              ]
+            },
+            message: { type: 'string' },
+            details: { type: 'array', items: { type: 'string' } }
+          },
+          required: ['code', 'message']
+        }
+      },
+      required: ['error']
+    }
+  },
+
+  routes: [
+    // Files
+    {
+      operationId: 'listFiles',
+      method: 'get',
+      path: '/files',
+      summary: 'Get the workspace file tree',
+      tag: 'files',
+      response: { type: 'array', items: ref('FileTreeNode') }
+    },
+    {
+      operationId: 'readFile',
+      method: 'get',
+      path: '/files/content',
+      summary: 'Read a file',
+      tag: 'files',
+      query: pathQuery,
+      response: ref('FileContent'),
+      errors: [403, 404, 413]
+    },
+    {
+      operationId: 'createFile',
+      method: 'post',
+      path: '/files',
+      summary: 'Create a file; fails if it already exists',
+      tag: 'files',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          content: { type: 'string' },
+          languageId: { type: 'string' }
+        },
+        required: ['path'],
+        additionalProperties: false
+      },
+      response: ref('PathResult'),
+      errors: [403, 409, 413]
+    },
+    {
+      operationId: 'createFolder',
+      method: 'post',
+      path: '/folders',
+      summary: 'Create a folder (and any missing parents)',
+      tag: 'files',
+      body: {
+        type: 'object',
+        properties: { path: workspacePath },
+        required: ['path'],
+        additionalProperties: false
+      },
+      response: ref('PathResult'),
+      errors: [403]
+    },
+    {
+      operationId: 'deletePath',
+      method: 'delete',
+      path: '/paths',
+      summary: 'Delete a file or folder recursively',
+      tag: 'files',
+      query: pathQuery,
+      response: ref('PathResult'),
+      errors: [403, 404]
+    },
+    {
+      operationId: 'renamePath',
+      method: 'post',
+      path: '/paths/rename',
+      summary: 'Rename or move a file or folder',
+      tag: 'files',
+      body: {
+        type: 'object',
+        properties: {
+          oldPath: workspacePath,
+          newPath: workspacePath
+        },
+        required: ['oldPath', 'newPath'],
+        additionalProperties: false
+      },
+      response: ref('RenameResult'),
+      errors: [403, 404]
+    },
+    {
+      operationId: 'readVirtualDocument',
+      method: 'get',
+      path: '/virtual-documents',
+      summary: 'Read a file outside the workspace that a definition points into (GOROOT, GOMODCACHE, TypeScript libraries)',
+      tag: 'files',
+      query: {
+        type: 'object',
+        properties: { uri: { type: 'string', pattern: '^deps:' } },
+        required: ['uri'],
+        additionalProperties: false
+      },
+      response: ref('VirtualDocument'),
+      errors: [403, 404, 413]
+    },
+
+    // Templates
+    {
+      operationId: 'listTemplates',
+      method: 'get',
+      path: '/templates',
+      summary: 'List project templates',
+      tag: 'templates',
+      response: { type: 'array', items: ref('ProjectTemplate') }
+    },
+    {
+      operationId: 'createProject',
+      method: 'post',
+      path: '/templates/{id}/projects',
+      summary: 'Create a project from a template and run its hooks',
+      tag: 'templates',
+      params: { id: { type: 'string', minLength: 1 } },
+      body: {
+        type: 'object',
+        properties: {
+          targetPath: workspacePath,
+          variables: { type: 'object', additionalProperties: { type: 'string' } }
+        },
+        required: ['targetPath'],
+        additionalProperties: false
+      },
+      response: ref('CreateProjectResult'),
+      errors: [403, 404, 409]
+    },
+
+    // Bookmarks
+    {
+      operationId: 'listBookmarks',
+      method: 'get',
+      path: '/bookmarks',
+      summary: 'List bookmarks, optionally for a single file',
+      tag: 'bookmarks',
+      query: {
+        type: 'object',
+        properties: { path: workspacePath },
+        additionalProperties: false
+      },
+      response: { type: 'array', items: ref('Bookmark') }
+    },
+    {
+      operationId: 'toggleBookmark',
+      method: 'post',
+      path: '/bookmarks/toggle',
+      summary: 'Add a bookmark to a line, or remove the existing one',
+      tag: 'bookmarks',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          line: { type: 'integer', minimum: 1 },
+          label: { type: 'string' }
+        },
+        required: ['path', 'line'],
+        additionalProperties: false
+      },
+      response: ref('ToggleBookmarkResult')
+    },
+    {
+      operationId: 'updateBookmark',
+      method: 'patch',
+      path: '/bookmarks/{id}',
+      summary: 'Set or clear a bookmark label',
+      tag: 'bookmarks',
+      params: { id: { type: 'string', minLength: 1 } },
+      body: {
+        type: 'object',
+        properties: { label: { type: 'string' } },
+        additionalProperties: false
+      },
+      response: ref('Bookmark'),
+      errors: [404]
+    },
+    {
+      operationId: 'deleteBookmark',
+      method: 'delete',
+      path: '/bookmarks/{id}',
+      summary: 'Remove a bookmark',
+      tag: 'bookmarks',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('OkResult'),
+      errors: [404]
+    },
+
+    // Review comments
+    {
+      operationId: 'listCommentThreads',
+      method: 'get',
+      path: '/comments',
+      summary: 'List review comment threads, re-anchored to the current file contents',
+      tag: 'comments',
+      query: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          author: { type: 'string', minLength: 1, description: 'Threads the user started or replied to' },
+          status: { type: 'string', enum: ['open', 'resolved'] }
+        },
+        additionalProperties: false
+      },
+      response: { type: 'array', items: ref('CommentThread') }
+    },
+    {
+      operationId: 'createCommentThread',
+      method: 'post',
+      path: '/comments',
+      summary: 'Start a comment thread on a range of lines',
+      tag: 'comments',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          startLine: { type: 'integer', minimum: 1 },
+          endLine: { type: 'integer', minimum: 1, description: 'Defaults to startLine' },
+          body: commentBody
+        },
+        required: ['path', 'startLine', 'body'],
+        additionalProperties: false
+      },
+      response: ref('CommentThread'),
+      errors: [404]
+    },
+    {
+      operationId: 'replyToCommentThread',
+      method: 'post',
+      path: '/comments/{id}/replies',
+      summary: 'Add a comment to a thread',
+      tag: 'comments',
+      params: { id: { type: 'string', minLength: 1 } },
+      body: {
+        type: 'object',
+        properties: { body: commentBody },
+        required: ['body'],
+        additionalProperties: false
+      },
+      response: ref('CommentThread'),
+      errors: [404]
+    },
+    {
+      operationId: 'updateCommentThread',
+      method: 'patch',
+      path: '/comments/{id}',
+      summary: 'Resolve or reopen a comment thread',
+      tag: 'comments',
+      params: { id: { type: 'string', minLength: 1 } },
+      body: {
+        type: 'object',
+        properties: { status: { type: 'string', enum: ['open', 'resolved'] } },
+        required: ['status'],
+        additionalProperties: false
+      },
+      response: ref('CommentThread'),
+      errors: [404]
+    },
+    {
+      operationId: 'deleteCommentThread',
+      method: 'delete',
+      path: '/comments/{id}',
+      summary: 'Delete a comment thread (whoever started it or an admin)',
+      tag: 'comments',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('OkResult'),
+      errors: [403, 404]
+    },
+
+    // TODOs
+    {
+      operationId: 'listTodos',
+      method: 'get',
+      path: '/todos',
+      summary: 'List TODO-style comments in the workspace',
+      tag: 'todos',
+      response: ref('TodoIndex')
+    },
+    {
+      operationId: 'setTodoTags',
+      method: 'put',
+      path: '/todos/tags',
+      summary: 'Change which comment tags are indexed for this workspace',
+      tag: 'todos',
+      body: {
+        type: 'object',
+        properties: {
+          tags: { type: 'array', items: { type: 'string', pattern: '^[A-Za-z][\\w-]*$' } }
+        },
+        required: ['tags'],
+        additionalProperties: false
+      },
+      response: ref('TodoIndex')
+    },
+
+    // Schemas
+    {
+      operationId: 'listSchemas',
+      method: 'get',
+      path: '/schemas',
+      summary: 'List JSON schemas and the files they apply to',
+      tag: 'schemas',
+      response: ref('SchemaIndex')
+    },
+    {
+      operationId: 'getSchema',
+      method: 'get',
+      path: '/schemas/content',
+      summary: 'Get a JSON schema document',
+      tag: 'schemas',
+      query: {
+        type: 'object',
+        properties: { id: { type: 'string', minLength: 1 } },
+        required: ['id'],
+        additionalProperties: false
+      },
+      response: { type: 'object' },
+      errors: [404]
+    },
+    {
+      operationId: 'setSchemaMappings',
+      method: 'put',
+      path: '/schemas/mappings',
+      summary: 'Change which files of this workspace are validated against which schema',
+      tag: 'schemas',
+      body: {
+        type: 'object',
+        properties: {
+          mappings: { type: 'array', items: ref('SchemaMapping') }
+        },
+        required: ['mappings'],
+        additionalProperties: false
+      },
+      response: ref('SchemaIndex'),
+      errors: [400]
+    },
+
+    // Go modules
+    {
+      operationId: 'listGoModules',
+      method: 'get',
+      path: '/go/modules',
+      summary: 'List Go modules in the workspace with their dependencies',
+      tag: 'go',
+      response: { type: 'array', items: ref('GoModule') }
+    },
+    {
+      operationId: 'runGoModuleCommand',
+      method: 'post',
+      path: '/go/modules/command',
+      summary: 'Run go get -u, go mod tidy or go mod vendor in a module',
+      tag: 'go',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          command: { type: 'string', enum: ['update', 'tidy', 'vendor'] },
+          dependency: { type: 'string', minLength: 1, pattern: '^[^-\\s][^\\s]*$', description: 'Only update this module (update only)' }
+        },
+        required: ['path', 'command'],
+        additionalProperties: false
+      },
+      response: ref('GoModuleCommandResult'),
+      errors: [404]
+    },
+
+    // npm
+    {
+      operationId: 'listNpmPackages',
+      method: 'get',
+      path: '/npm/packages',
+      summary: 'List package.json files in the workspace with their scripts and dependency versions',
+      tag: 'npm',
+      response: { type: 'array', items: ref('NpmPackage') }
+    },
+    {
+      operationId: 'runNpmScript',
+      method: 'post',
+      path: '/npm/scripts/run',
+      summary: 'Start a package.json script as a task',
+      tag: 'npm',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          script: { type: 'string', minLength: 1 }
+        },
+        required: ['path', 'script'],
+        additionalProperties: false
+      },
+      response: ref('TaskInfo'),
+      errors: [404]
+    },
+    {
+      operationId: 'runNpmCommand',
+      method: 'post',
+      path: '/npm/packages/command',
+      summary: 'Start an install or update with the package\'s package manager',
+      tag: 'npm',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath,
+          command: { type: 'string', enum: ['install', 'update'] },
+          dependency: { type: 'string', minLength: 1, pattern: '^[^-\\s][^\\s]*$', description: 'Only update this dependency (update only)' }
+        },
+        required: ['path', 'command'],
+        additionalProperties: false
+      },
+      response: ref('TaskInfo'),
+      errors: [404]
+    },
+
+    // Run
+    {
+      operationId: 'runFile',
+      method: 'post',
+      path: '/run',
+      summary: 'Start running a Go, JavaScript or TypeScript file (go run, node or tsx) as a task',
+      tag: 'run',
+      body: {
+        type: 'object',
+        properties: {
+          uri: { type: 'string', pattern: '^(workspace|untitled):', description: 'Editor URI of a workspace file or an open untitled buffer' },
+          args: { type: 'array', items: { type: 'string' }, description: 'Program arguments' },
+          env: { type: 'object', additionalProperties: { type: 'string' }, description: 'Added to the server\'s environment' }
+        },
+        required: ['uri'],
+        additionalProperties: false
+      },
+      response: ref('TaskInfo'),
+      errors: [400, 403, 404]
+    },
+
+    // Ports
+    {
+      operationId: 'listPorts',
+      method: 'get',
+      path: '/ports',
+      summary: 'List TCP ports that processes started from the workspace listen on',
+      tag: 'ports',
+      query: {
+        type: 'object',
+        properties: {
+          refresh: { type: 'boolean', description: 'Scan now instead of returning the latest scan' }
+        },
+        additionalProperties: false
+      },
+      response: { type: 'array', items: ref('ListeningPort') }
+    },
+
+    // HTTP requests (.http files)
+    {
+      operationId: 'sendHttpRequest',
+      method: 'post',
+      path: '/http/send',
+      summary: 'Send the request at a line of a .http file from the server and record the response',
+      tag: 'http',
+      body: {
+        type: 'object',
+        properties: {
+          uri: { type: 'string', pattern: '^(workspace|untitled):', description: 'Editor URI of the .http file' },
+          line: { type: 'integer', minimum: 1, description: 'Any 1-based line of the request' },
+          env: { type: 'string', description: 'Environment from http-client.env.json' }
+        },
+        required: ['uri', 'line'],
+        additionalProperties: false
+      },
+      response: ref('HttpExchange'),
+      errors: [400, 403, 404]
+    },
+    {
+      operationId: 'listHttpEnvironments',
+      method: 'get',
+      path: '/http/environments',
+      summary: 'List the environments of the env file nearest to a .http file',
+      tag: 'http',
+      query: {
+        type: 'object',
+        properties: {
+          uri: { type: 'string', pattern: '^(workspace|untitled):' }
+        },
+        required: ['uri'],
+        additionalProperties: false
+      },
+      response: ref('HttpEnvironments'),
+      errors: [400, 403, 404]
+    },
+    {
+      operationId: 'listHttpHistory',
+      method: 'get',
+      path: '/http/history',
+      summary: 'List requests sent from .http files, newest first',
+      tag: 'http',
+      response: { type: 'array', items: ref('HttpExchangeSummary') }
+    },
+    {
+      operationId: 'getHttpHistoryEntry',
+      method: 'get',
+      path: '/http/history/{id}',
+      summary: 'Get a past request with its response',
+      tag: 'http',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('HttpExchange'),
+      errors: [404]
+    },
+    {
+      operationId: 'clearHttpHistory',
+      method: 'delete',
+      path: '/http/history',
+      summary: 'Forget all requests sent from .http files',
+      tag: 'http',
+      response: ref('OkResult')
+    },
+
+    // Snapshots
+    {
+      operationId: 'createSnapshot',
+      method: 'post',
+      path: '/snapshots',
+      summary: 'Store a read-only copy of workspace files, with hovers captured by the browser, under a random ID',
+      tag: 'snapshots',
+      body: {
+        type: 'object',
+        properties: {
+          title: { type: 'string', maxLength: 200 },
+          expiresInDays: { type: 'integer', minimum: 0, maximum: 365, description: 'Default 7; 0 keeps the snapshot until it is revoked' },
+          files: {
+            type: 'array',
+            minItems: 1,
+            items: {
+              type: 'object',
+              properties: {
+                path: workspacePath,
+                hovers: { type: 'array', items: ref('SnapshotHover') }
+              },
+              required: ['path'],
+              additionalProperties: false
+            }
+          }
+        },
+        required: ['files'],
+        additionalProperties: false
+      },
+      response: ref('SnapshotSummary'),
+      errors: [400, 403, 404, 413]
+    },
+    {
+      operationId: 'listSnapshots',
+      method: 'get',
+      path: '/snapshots',
+      summary: 'List live snapshots, newest first: your own, or all of them for admins',
+      tag: 'snapshots',
+      response: { type: 'array', items: ref('SnapshotSummary') }
+    },
+    {
+      operationId: 'getSnapshot',
+      method: 'get',
+      path: '/snapshots/{id}',
+      summary: 'Get a snapshot with its files and hovers',
+      tag: 'snapshots',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('Snapshot'),
+      errors: [404]
+    },
+    {
+      operationId: 'revokeSnapshot',
+      method: 'delete',
+      path: '/snapshots/{id}',
+      summary: 'Delete a snapshot so its link stops working (its creator or an admin)',
+      tag: 'snapshots',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('OkResult'),
+      errors: [403, 404]
+    },
+
+    // Coverage
+    {
+      operationId: 'getCoverage',
+      method: 'get',
+      path: '/coverage',
+      summary: 'Get per-file percentages of the latest coverage report',
+      tag: 'coverage',
+      response: ref('CoverageSummary')
+    },
+    {
+      operationId: 'getFileCoverage',
+      method: 'get',
+      path: '/coverage/file',
+      summary: 'Get the covered and uncovered lines of a file',
+      tag: 'coverage',
+      query: pathQuery,
+      response: ref('FileCoverage'),
+      errors: [404]
+    },
+    {
+      operationId: 'importCoverage',
+      method: 'post',
+      path: '/coverage/import',
+      summary: 'Replace the workspace\'s coverage with a Go, lcov or istanbul report from the workspace',
+      tag: 'coverage',
+      body: {
+        type: 'object',
+        properties: {
+          path: { ...workspacePath, description: 'Report to import (default: the first of coverage.out, cover.out, coverage/lcov.info, lcov.info and coverage/coverage-final.json)' },
+          format: { type: 'string', enum: ['go', 'lcov', 'istanbul'], description: 'Detected from the content when omitted' }
+        },
+        additionalProperties: false
+      },
+      response: ref('CoverageSummary'),
+      errors: [400, 403, 404]
+    },
+    {
+      operationId: 'clearCoverage',
+      method: 'delete',
+      path: '/coverage',
+      summary: 'Forget the workspace\'s coverage',
+      tag: 'coverage',
+      response: ref('OkResult')
+    },
+
+    // Linters
+    {
+      operationId: 'listLinters',
+      method: 'get',
+      path: '/lint/linters',
+      summary: 'List the names of the configured external linters',
+      tag: 'lint',
+      response: { type: 'array', items: { type: 'string' } }
+    },
+    {
+      operationId: 'listLintDiagnostics',
+      method: 'get',
+      path: '/lint/diagnostics',
+      summary: 'Get the latest external linter diagnostics of every file with problems',
+      tag: 'lint',
+      response: { type: 'array', items: ref('LintFileDiagnostics') }
+    },
+    {
+      operationId: 'lintFile',
+      method: 'post',
+      path: '/lint',
+      summary: 'Run the external linters configured for a file',
+      tag: 'lint',
+      body: {
+        type: 'object',
+        properties: {
+          path: workspacePath
+        },
+        required: ['path'],
+        additionalProperties: false
+      },
+      response: ref('LintResult'),
+      errors: [403, 404]
+    },
+
+    // Tasks
+    {
+      operationId: 'listTasks',
+      method: 'get',
+      path: '/tasks',
+      summary: 'List running and recently finished tasks, most recent first',
+      tag: 'tasks',
+      response: { type: 'array', items: ref('TaskInfo') }
+    },
+    {
+      operationId: 'getTask',
+      method: 'get',
+      path: '/tasks/{id}',
+      summary: 'Get a task and its captured output',
+      tag: 'tasks',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('TaskOutput'),
+      errors: [404]
+    },
+    {
+      operationId: 'killTask',
+      method: 'post',
+      path: '/tasks/{id}/kill',
+      summary: 'Stop a running task',
+      tag: 'tasks',
+      params: { id: { type: 'string', minLength: 1 } },
+      response: ref('OkResult'),
+      errors: [404, 409]
+    },
+
+    // Extensions
+
+    {
+      operationId: 'listWebExtensions',
+      method: 'get',
+      path: '/extensions',
+      summary: 'List web extension bundles provided by active server plugins',
+      tag: 'extensions',
+      response: { type: 'array', items: ref('WebExtensionInfo') }
+    },
+
+    // Events
+
+    {
+      operationId: 'streamEvents',
+      method: 'get',
+      path: '/events',
+      summary: 'Stream workspace events as server-sent events',
+      tag: 'events',
+      stream: true,
+      query: {
+        type: 'object',
+        properties: {
+          types: { type: 'string', description: 'Comma-separated event types to receive (default: all)' }
+        },
+        additionalProperties: false
+      },
+      response: ref('WorkspaceEvent')
+    },
+
+    // Administration
+    {
+      operationId: 'listAuditEntries',
+      method: 'get',
+      path: '/admin/audit',
+      summary: 'Query the audit log of workspace changes, newest first',
+      tag: 'admin',
+      admin: true,
+      query: {
+        type: 'object',
+        properties: {
+          user: { type: 'string' },
+          action: { type: 'string', enum: AUDIT_ACTIONS },
+          path: workspacePath,
+          since: { type: 'string', description: 'ISO timestamp' },
+          until: { type: 'string', description: 'ISO timestamp' },
+          before: { type: 'integer', description: 'Only entries with a smaller id (paging cursor)' },
+          limit: { type: 'integer', minimum: 1, maximum: 1000 }
+        },
+        additionalProperties: false
+      },
+      response: ref('AuditQueryResult'),
+      errors: [404]
+    },
+    {
+      operationId: 'listPlugins',
+      method: 'get',
+      path: '/admin/plugins',
+      summary: 'List server plugins and their status',
+      tag: 'admin',
+      admin: true,
+      response: { type: 'array', items: ref('PluginInfo') }
+    }
+  ]
+};
@@ -0,0 +1,163 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+
+export type AuditAction =
+  | 'file.create'
+  | 'file.save'
+  | 'folder.create'
+  | 'path.delete'
+  | 'path.rename'
+  | 'project.create'
+  | 'settings.update'
+  | 'command.run'
+  | 'http.request'
+  | 'http.history.clear'
+  | 'task.kill'
+  | 'snapshot.create'
+  | 'snapshot.revoke'
+  | 'edit.apply';
+
+export interface AuditEntry {
+  // Monotonic sequence number, usable as a paging cursor
+  id: number;
+  timestamp: string;
+  user: string;
+  // Browser session (X-Client-Id / WebSocket client) or remote address
+  client: string;
+  action: AuditAction;
+  path?: string;
+  details?: Record<string, unknown>;
+}
+
+export type AuditRecord = Omit<AuditEntry, 'id' | 'timestamp'>;
+
+export interface AuditQuery {
+  user?: string;
+  action?: AuditAction;
+  // Matches the path itself and anything below it; also matches rename targets
+  path?: string;
+  since?: string;
+  until?: string;
+  // Only entries with a smaller id (for paging backwards)
+  before?: number;
+  limit?: number;
+}
+
+export interface AuditQueryResult {
+  entries: AuditEntry[];
+  hasMore: boolean;
+}
+
+const DEFAULT_QUERY_LIMIT = 100;
+const MAX_QUERY_LIMIT = 1000;
+
+/**
+ * Append-only log of mutating workspace operations, stored as JSON lines.
+ * Entries are never rewritten; queries scan the file newest first.
+ */
+export class AuditLog {
+  private nextId: number | undefined;
+  private writeChain: Promise<void> = Promise.resolve();
+
+  constructor(private filePath: string) {}
+
+  /**
+   * Get the path of the log file
+   */
+  getFilePath(): string {
+    return this.filePath;
+  }
+
+  /**
+   * Append an entry. Writes are serialized so ids stay in file order.
+   */
+  record(record: AuditRecord): Promise<AuditEntry> {
+    const result = this.writeChain.then(async () => {
+      if (this.nextId === undefined) {
+        const existing = await this.readAll();
+        this.nextId = existing.length > 0 ? existing[existing.length - 1].id + 1 : 1;
+      }
+
+      const entry: AuditEntry = {
+        id: this.nextId++,
+        timestamp: new Date().toISOString(),
+        ...record
+      };
+      await fs.mkdir(path.dirname(this.filePath), { recursive: true });
+      await fs.appendFile(this.filePath, JSON.stringify(entry) + '\n', { encoding: 'utf-8', mode: 0o600 });
+      return entry;
+    });
+
+    this.writeChain = result.then(() => undefined, () => undefined);
+    return result;
+  }
+
+  /**
+   * Record an entry without waiting, logging failures. Auditing must never
+   * fail the operation being audited.
+   */
+  recordInBackground(record: AuditRecord): void {
+    this.record(record).catch((error) => {
+      console.error('[Audit] Failed to write audit entry:', error);
+    });
+  }
+
+  /**
+   * Find entries matching a filter, newest first
+   */
+  async query(query: AuditQuery = {}): Promise<AuditQueryResult> {
+    await this.writeChain;
+    const limit = Math.min(Math.max(query.limit ?? DEFAULT_QUERY_LIMIT, 1), MAX_QUERY_LIMIT);
+    const since = query.since ? Date.parse(query.since) : undefined;
+    const until = query.until ? Date.parse(query.until) : undefined;
+    const underPath = (candidate: unknown) =>
+      typeof candidate === 'string' && query.path !== undefined &&
+      (candidate === query.path || candidate.startsWith(query.path.replace(/\/$/, '') + '/'));
+
+    const entries = await this.readAll();
+    const matches: AuditEntry[] = [];
+    let hasMore = false;
+
+    for (let i = entries.length - 1; i >= 0; i--) {
+      const entry = entries[i];
+      if (query.before !== undefined && entry.id >= query.before) continue;
+      if (query.user && entry.user !== query.user) continue;
+      if (query.action && entry.action !== query.action) continue;
+      if (query.path && !underPath(entry.path) && !underPath(entry.details?.newPath)) continue;
+      const time = Date.parse(entry.timestamp);
+      if (since !== undefined && time < since) continue;
+      if (until !== undefined && time > until) continue;
+
+      if (matches.length === limit) {
+        hasMore = true;
+        break;
+      }
+      matches.push(entry);
+    }
+
+    return { entries: matches, hasMore };
+  }
+
+  private async readAll(): Promise<AuditEntry[]> {
+    let raw: string;
+    try {
+      raw = await fs.readFile(this.filePath, 'utf-8');
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+        return [];
+      }
+      throw error;
+    }
+
+    const entries: AuditEntry[] = [];
+    for (const line of raw.split('\n')) {
+      if (!line.trim()) continue;
+      try {
+        entries.push(JSON.parse(line));
+      } catch {
+        // Skip a torn final line from an interrupted write
+      }
+    }
+    return entries;
+  }
+}
@@ -0,0 +1,90 @@
+import { IncomingMessage } from 'http';
+import { timingSafeEqual } from 'crypto';
+import { Request, Response, NextFunction, RequestHandler } from 'express';
+import { AuthConfig } from '../config/config.js';
+import { ApiError } from '../api/errors.js';
+
+// User name attributed to requests when auth is disabled
+export const ANONYMOUS_USER = 'anonymous';
+
+export interface Identity {
+  user: string;
+  admin: boolean;
+}
+
+/**
+ * Find the user a token belongs to, comparing in constant time
+ */
+export function findUserForToken(auth: AuthConfig, token: string | undefined): Identity | undefined {
+  if (!token) {
+    return undefined;
+  }
+  const candidate = Buffer.from(token);
+  for (const entry of auth.tokens) {
+    const expected = Buffer.from(entry.token);
+    if (expected.length === candidate.length && timingSafeEqual(expected, candidate)) {
+      return { user: entry.user, admin: entry.admin ?? false };
+    }
+  }
+  return undefined;
+}
+
+/**
+ * Extract a token from the Authorization header or, for WebSocket upgrades
+ * where browsers can't set headers, the `token` query parameter
+ */
+export function extractToken(req: IncomingMessage): string | undefined {
+  const header = req.headers.authorization;
+  if (header && header.startsWith('Bearer ')) {
+    return header.slice('Bearer '.length).trim();
+  }
+  const url = new URL(req.url || '/', 'http://localhost');
+  return url.searchParams.get('token') || undefined;
+}
+
+/**
+ * Resolve the identity for a request: the anonymous user (with admin rights,
+ * as there is nobody to distinguish) when auth is disabled, undefined when a
+ * token is required but missing or unknown
+ */
+export function authenticateRequest(auth: AuthConfig, req: IncomingMessage): Identity | undefined {
+  if (auth.mode === 'none') {
+    return { user: ANONYMOUS_USER, admin: true };
+  }
+  return findUserForToken(auth, extractToken(req));
+}
+
+/**
+ * Identify the client a request comes from: the browser session id sent in
+ * X-Client-Id (or the `client` query parameter), else the remote address
+ */
+export function getClientLabel(req: IncomingMessage): string {
+  const header = req.headers['x-client-id'];
+  if (typeof header === 'string' && header) {
+    return header.slice(0, 64);
+  }
+  const url = new URL(req.url || '/', 'http://localhost');
+  const param = url.searchParams.get('client');
+  if (param) {
+    return param.slice(0, 64);
+  }
+  return req.socket.remoteAddress || 'unknown';
+}
+
+/**
+ * Express middleware rejecting unauthenticated requests with 401.
+ * The authenticated user is available as res.locals.user (and res.locals.admin).
+ */
+export function createAuthMiddleware(auth: AuthConfig): RequestHandler {
+  return (req: Request, res: Response, next: NextFunction) => {
+    const identity = authenticateRequest(auth, req);
+    if (!identity) {
+      const error = new ApiError(401, 'unauthorized', 'Authentication required');
+      res.status(error.status).json(error.toBody());
+      return;
+    }
+    res.locals.user = identity.user;
+    res.locals.admin = identity.admin;
+    next();
+  };
+}
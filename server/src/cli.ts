@@ -0,0 +1,256 @@
+import * as fs from 'fs/promises';
+import { constants as fsConstants } from 'fs';
+import * as path from 'path';
+import { parseArgs } from 'util';
+import { ConfigError, ConfigOverrides, DEFAULT_CONFIG_FILE, ServerConfig, loadConfig } from './config/config.js';
+import { LanguageServerConfig, LanguageServerManager } from './lsp/manager.js';
+import { exportWorkspace } from './workspace/export.js';
+
+const USAGE = `Usage: oneline-editor <command> [options]
+
+Commands:
+  serve                 Start the editor server (default)
+  check                 Validate configuration, workspace and language servers
+  languages list        Show configured language servers and whether they are installed
+  workspace export      Write the workspace to a .tar.gz archive
+
+Options:
+  -c, --config <file>     Config file (default: ./${DEFAULT_CONFIG_FILE} if present)
+  -w, --workspace <dir>   Workspace root directory
+  -p, --port <n>          Port to listen on
+      --host <addr>       Address to bind
+  -o, --output <file>     Archive path for "workspace export"
+      --include-state     Include editor state (bookmarks, settings) in the export
+  -h, --help              Show this help
+
+Environment variables (PORT, WORKSPACE_ROOT, CORS_ORIGIN, ...) are still honoured;
+the config file overrides them and command-line options override both.`;
+
+interface ParsedArgs {
+  command: string[];
+  configPath?: string;
+  overrides: ConfigOverrides;
+  output?: string;
+  includeState: boolean;
+  help: boolean;
+}
+
+function parseCliArgs(argv: string[]): ParsedArgs {
+  const { values, positionals } = parseArgs({
+    args: argv,
+    allowPositionals: true,
+    options: {
+      config: { type: 'string', short: 'c' },
+      workspace: { type: 'string', short: 'w' },
+      port: { type: 'string', short: 'p' },
+      host: { type: 'string' },
+      output: { type: 'string', short: 'o' },
+      'include-state': { type: 'boolean' },
+      help: { type: 'boolean', short: 'h' }
+    }
+  });
+
+  const overrides: ConfigOverrides = {};
+  if (values.workspace !== undefined) overrides.workspace = values.workspace;
+  if (values.host !== undefined) overrides.host = values.host;
+  if (values.port !== undefined) {
+    overrides.port = /^\d+$/.test(values.port) ? parseInt(values.port, 10) : NaN;
+  }
+
+  return {
+    command: positionals,
+    configPath: values.config,
+    overrides,
+    output: values.output,
+    includeState: values['include-state'] ?? false,
+    help: values.help ?? false
+  };
+}
+
+/**
+ * Locate an executable the way the shell would: absolute/relative paths are
+ * checked directly, bare names are searched on PATH
+ */
+export async function resolveCommand(command: string): Promise<string | undefined> {
+  const isExecutable = async (candidate: string) => {
+    try {
+      const stats = await fs.stat(candidate);
+      if (!stats.isFile()) return false;
+      await fs.access(candidate, fsConstants.X_OK);
+      return true;
+    } catch {
+      return false;
+    }
+  };
+
+  if (command.includes('/') || command.includes(path.sep)) {
+    const resolved = path.resolve(command);
+    return (await isExecutable(resolved)) ? resolved : undefined;
+  }
+
+  const extensions = process.platform === 'win32'
+    ? (process.env.PATHEXT || '.EXE;.CMD;.BAT').split(';')
+    : [''];
+  for (const dir of (process.env.PATH || '').split(path.delimiter).filter(Boolean)) {
+    for (const ext of extensions) {
+      const candidate = path.join(dir, command + ext);
+      if (await isExecutable(candidate)) {
+        return candidate;
+      }
+    }
+  }
+  return undefined;
+}
+
+function getLanguageServers(config: ServerConfig): LanguageServerConfig[] {
+  return new LanguageServerManager(config.workspace.root, config.languageServers).getConfigs();
+}
+
+async function checkWorkspace(root: string): Promise<string | undefined> {
+  try {
+    const stats = await fs.stat(root);
+    if (!stats.isDirectory()) {
+      return `Workspace root is not a directory: ${root}`;
+    }
+    await fs.access(root, fsConstants.R_OK | fsConstants.W_OK);
+    return undefined;
+  } catch (error) {
+    const code = (error as NodeJS.ErrnoException).code;
+    if (code === 'ENOENT') {
+      return `Workspace root does not exist: ${root} (create it or pass --workspace)`;
+    }
+    return `Workspace root is not readable and writable: ${root}`;
+  }
+}
+
+async function commandServe(config: ServerConfig): Promise<number> {
+  const workspaceError = await checkWorkspace(config.workspace.root);
+  if (workspaceError) {
+    console.error(`[Server] ${workspaceError}`);
+    return 1;
+  }
+
+  // Imported lazily so the other subcommands don't pull in the server stack
+  const { startServer } = await import('./server.js');
+  try {
+    await startServer(config);
+  } catch (error) {
+    console.error(`[Server] Failed to start: ${(error as Error).message}`);
+    return 1;
+  }
+  // The open server keeps the process alive until a signal handler exits it
+  return 0;
+}
+
+async function commandCheck(config: ServerConfig): Promise<number> {
+  let failed = false;
+
+  console.log(`Configuration OK (port ${config.server.port}, auth ${config.auth.mode})`);
+
+  const workspaceError = await checkWorkspace(config.workspace.root);
+  if (workspaceError) {
+    console.log(`✗ ${workspaceError}`);
+    failed = true;
+  } else {
+    console.log(`✓ Workspace root: ${config.workspace.root}`);
+  }
+
+  if (config.workspace.templatesDir) {
+    try {
+      await fs.access(config.workspace.templatesDir, fsConstants.R_OK);
+      console.log(`✓ Templates directory: ${config.workspace.templatesDir}`);
+    } catch {
+      console.log(`✗ Templates directory is not readable: ${config.workspace.templatesDir}`);
+      failed = true;
+    }
+  }
+
+  for (const server of getLanguageServers(config)) {
+    const resolved = await resolveCommand(server.command);
+    if (resolved) {
+      console.log(`✓ ${server.languageId}: ${resolved}`);
+    } else {
+      // A missing language server only disables features for that language
+      console.log(`! ${server.languageId}: "${server.command}" not found; language features will be unavailable`);
+    }
+  }
+
+  return failed ? 1 : 0;
+}
+
+async function commandLanguagesList(config: ServerConfig): Promise<number> {
+  const rows = await Promise.all(getLanguageServers(config).map(async server => [
+    server.languageId,
+    [server.command, ...server.args].join(' '),
+    server.fileExtensions.join(' '),
+    (await resolveCommand(server.command)) ? 'installed' : 'missing'
+  ]));
+
+  const table = [['LANGUAGE', 'COMMAND', 'EXTENSIONS', 'STATUS'], ...rows];
+  const widths = table[0].map((_, column) => Math.max(...table.map(row => row[column].length)));
+  for (const row of table) {
+    console.log(row.map((cell, column) => cell.padEnd(widths[column])).join('  ').trimEnd());
+  }
+  return 0;
+}
+
+async function commandWorkspaceExport(config: ServerConfig, args: ParsedArgs): Promise<number> {
+  const workspaceError = await checkWorkspace(config.workspace.root);
+  if (workspaceError) {
+    console.error(workspaceError);
+    return 1;
+  }
+
+  const stamp = new Date().toISOString().replace(/[:.]/g, '-');
+  const output = path.resolve(args.output || `workspace-${stamp}.tar.gz`);
+  const result = await exportWorkspace(config.workspace.root, output, { includeState: args.includeState });
+  console.log(`Exported ${result.files} files (${result.bytes} bytes) to ${output}`);
+  return 0;
+}
+
+/**
+ * Run the command line interface; resolves with the process exit code
+ */
+export async function runCli(argv: string[]): Promise<number> {
+  let args: ParsedArgs;
+  try {
+    args = parseCliArgs(argv);
+  } catch (error) {
+    console.error(`${(error as Error).message}\n\n${USAGE}`);
+    return 2;
+  }
+
+  if (args.help) {
+    console.log(USAGE);
+    return 0;
+  }
+
+  const command = args.command.join(' ') || 'serve';
+  const known = ['serve', 'check', 'languages list', 'workspace export'];
+  if (!known.includes(command)) {
+    console.error(`Unknown command: ${command}\n\n${USAGE}`);
+    return 2;
+  }
+
+  let config: ServerConfig;
+  try {
+    config = await loadConfig(args.configPath, args.overrides);
+  } catch (error) {
+    if (error instanceof ConfigError) {
+      console.error(error.message);
+      return 1;
+    }
+    throw error;
+  }
+
+  switch (command) {
+    case 'check':
+      return commandCheck(config);
+    case 'languages list':
+      return commandLanguagesList(config);
+    case 'workspace export':
+      return commandWorkspaceExport(config, args);
+    default:
+      return commandServe(config);
+  }
+}
@@ -0,0 +1,134 @@
+// Above this many cells in the LCS table the changed region is treated as
+// rewritten, and threads fall back to finding their text
+const MAX_DIFF_CELLS = 4_000_000;
+
+/**
+ * Lines a comment thread is attached to, 1-based and inclusive, with the
+ * text they had when the thread was started
+ */
+export interface LineAnchor {
+  startLine: number;
+  endLine: number;
+  text: string;
+}
+
+export interface AnchorResult {
+  startLine: number;
+  endLine: number;
+  // The anchored text no longer appears in the file
+  outdated: boolean;
+}
+
+/**
+ * Line diff of two versions of a file: for each old line (0-based), the
+ * index of the same line in the new version, or -1 if it was removed or
+ * changed
+ */
+export function diffLineMap(oldLines: string[], newLines: string[]): number[] {
+  const map = new Array<number>(oldLines.length).fill(-1);
+
+  // Edits are usually local; only diff what lies between the common ends
+  let prefix = 0;
+  while (prefix < oldLines.length && prefix < newLines.length && oldLines[prefix] === newLines[prefix]) {
+    map[prefix] = prefix;
+    prefix++;
+  }
+  let suffix = 0;
+  while (
+    suffix < oldLines.length - prefix &&
+    suffix < newLines.length - prefix &&
+    oldLines[oldLines.length - 1 - suffix] === newLines[newLines.length - 1 - suffix]
+  ) {
+    map[oldLines.length - 1 - suffix] = newLines.length - 1 - suffix;
+    suffix++;
+  }
+
+  const oldCount = oldLines.length - prefix - suffix;
+  const newCount = newLines.length - prefix - suffix;
+  if (oldCount === 0 || newCount === 0 || oldCount * newCount > MAX_DIFF_CELLS) {
+    return map;
+  }
+
+  // Longest common subsequence of the middle, filled from the end so the
+  // walk below can go forwards
+  const width = newCount + 1;
+  const lengths = new Uint32Array((oldCount + 1) * width);
+  for (let i = oldCount - 1; i >= 0; i--) {
+    for (let j = newCount - 1; j >= 0; j--) {
+      lengths[i * width + j] = oldLines[prefix + i] === newLines[prefix + j]
+        ? lengths[(i + 1) * width + j + 1] + 1
+        : Math.max(lengths[(i + 1) * width + j], lengths[i * width + j + 1]);
+    }
+  }
+  let i = 0;
+  let j = 0;
+  while (i < oldCount && j < newCount) {
+    if (oldLines[prefix + i] === newLines[prefix + j]) {
+      map[prefix + i] = prefix + j;
+      i++;
+      j++;
+    } else if (lengths[(i + 1) * width + j] >= lengths[i * width + j + 1]) {
+      i++;
+    } else {
+      j++;
+    }
+  }
+  return map;
+}
+
+/**
+ * Start (0-based) of the occurrence of a block of lines closest to a line
+ */
+function findNearest(lines: string[], block: string[], near: number): number {
+  let best = -1;
+  for (let start = 0; start + block.length <= lines.length; start++) {
+    if (block.every((line, offset) => lines[start + offset] === line) &&
+        (best < 0 || Math.abs(start - near) < Math.abs(best - near))) {
+      best = start;
+    }
+  }
+  return best;
+}
+
+/**
+ * Move an anchor to where its lines are in a new version of the file.
+ * Surviving lines are followed through the diff; if the anchored text was
+ * changed it is looked for elsewhere, e.g. after being moved, and otherwise
+ * the anchor stays where its lines were and is marked outdated.
+ */
+export function reanchor(anchor: LineAnchor, lineMap: number[], newLines: string[]): AnchorResult {
+  const mapped: number[] = [];
+  for (let line = anchor.startLine; line <= anchor.endLine; line++) {
+    if (lineMap[line - 1] >= 0) {
+      mapped.push(lineMap[line - 1]);
+    }
+  }
+
+  let startLine: number;
+  let endLine: number;
+  if (mapped.length > 0) {
+    startLine = mapped[0] + 1;
+    endLine = mapped[mapped.length - 1] + 1;
+  } else {
+    // All lines went away: stay just after the closest line above that didn't
+    let above = anchor.startLine - 2;
+    while (above >= 0 && !(lineMap[above] >= 0)) {
+      above--;
+    }
+    startLine = above >= 0 ? lineMap[above] + 2 : 1;
+    endLine = startLine;
+  }
+  const lastLine = Math.max(newLines.length, 1);
+  startLine = Math.min(startLine, lastLine);
+  endLine = Math.min(Math.max(endLine, startLine), lastLine);
+
+  const block = anchor.text.split('\n');
+  if (newLines.slice(startLine - 1, endLine).join('\n') === anchor.text) {
+    return { startLine, endLine, outdated: false };
+  }
+  const found = findNearest(newLines, block, startLine - 1);
+  if (found >= 0) {
+    return { startLine: found + 1, endLine: found + block.length, outdated: false };
+  }
+  return { startLine, endLine, outdated: true };
+}
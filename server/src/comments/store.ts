@@ -0,0 +1,314 @@
+import { RealFileSystem } from '../fs/real.js';
+import { WorkspaceState } from '../workspace/state.js';
+import { diffLineMap, reanchor } from './anchor.js';
+
+export type CommentThreadStatus = 'open' | 'resolved';
+
+export interface ReviewComment {
+  id: string;
+  author: string;
+  // Markdown
+  body: string;
+  createdAt: string;
+}
+
+export interface CommentThread {
+  id: string;
+  path: string;
+  // 1-based and inclusive, following the text as the file changes
+  startLine: number;
+  endLine: number;
+  // The lines as they were when the thread was started
+  text: string;
+  // The lines were changed or removed; the thread stays near where they were
+  outdated: boolean;
+  status: CommentThreadStatus;
+  resolvedBy?: string;
+  resolvedAt?: string;
+  createdAt: string;
+  updatedAt: string;
+  comments: ReviewComment[];
+}
+
+export interface CommentThreadFilter {
+  path?: string;
+  // Threads the user started or replied to
+  author?: string;
+  status?: CommentThreadStatus;
+}
+
+export interface CreateThreadOptions {
+  path: string;
+  startLine: number;
+  endLine: number;
+  author: string;
+  body: string;
+}
+
+interface StoredComments {
+  threads: CommentThread[];
+  // Content of each commented file when its threads were last anchored;
+  // the next version is diffed against it
+  baselines: Record<string, string>;
+}
+
+type ChangeListener = (paths: string[]) => void;
+
+const COMMENTS_FILE = 'comments.json';
+
+function splitLines(content: string): string[] {
+  return content.split(/\r?\n/);
+}
+
+function isUnder(filePath: string, targetPath: string): boolean {
+  return filePath === targetPath || filePath.startsWith(targetPath + '/');
+}
+
+/**
+ * CommentStore keeps review comment threads attached to line ranges of
+ * workspace files, persisted in the workspace state directory. Threads are
+ * re-anchored by diffing each file against the version they were last
+ * anchored to whenever it is saved or the threads are listed.
+ */
+export class CommentStore {
+  private stored: StoredComments | null = null;
+  private loading: Promise<StoredComments> | null = null;
+  private listeners: ChangeListener[] = [];
+
+  constructor(
+    private state: WorkspaceState,
+    private fileSystem: RealFileSystem
+  ) {}
+
+  /**
+   * List threads by file and line, after re-anchoring them to
+   * the files' current content
+   */
+  async list(filter: CommentThreadFilter = {}): Promise<CommentThread[]> {
+    const { threads } = await this.load();
+    const paths = new Set(threads.filter(t => !filter.path || t.path === filter.path).map(t => t.path));
+    for (const filePath of paths) {
+      await this.sync(filePath);
+    }
+    return (await this.load()).threads
+      .filter(t => !filter.path || t.path === filter.path)
+      .filter(t => !filter.status || t.status === filter.status)
+      .filter(t => !filter.author || t.comments.some(c => c.author === filter.author))
+      .sort((a, b) => a.path.localeCompare(b.path) || a.startLine - b.startLine || b.createdAt.localeCompare(a.createdAt));
+  }
+
+  /**
+   * Get a thread by ID
+   */
+  async get(id: string): Promise<CommentThread | undefined> {
+    const { threads } = await this.load();
+    return threads.find(t => t.id === id);
+  }
+
+  /**
+   * Start a thread on a range of lines. Returns undefined when the file
+   * doesn't exist.
+   */
+  async create({ path: filePath, startLine, endLine, author, body }: CreateThreadOptions): Promise<CommentThread | undefined> {
+    const content = await this.readFile(filePath);
+    if (content === undefined) {
+      return undefined;
+    }
+    // Bring existing threads up to date so the baseline matches the new one
+    await this.sync(filePath, content);
+
+    const lines = splitLines(content);
+    const start = Math.min(Math.max(startLine, 1), lines.length);
+    const end = Math.min(Math.max(endLine, start), lines.length);
+    const now = new Date().toISOString();
+    const thread: CommentThread = {
+      id: this.generateId('ct'),
+      path: filePath,
+      startLine: start,
+      endLine: end,
+      text: lines.slice(start - 1, end).join('\n'),
+      outdated: false,
+      status: 'open',
+      createdAt: now,
+      updatedAt: now,
+      comments: [{ id: this.generateId('c'), author, body, createdAt: now }]
+    };
+
+    const stored = await this.load();
+    stored.threads.push(thread);
+    stored.baselines[filePath] = content;
+    await this.save([filePath]);
+    return thread;
+  }
+
+  /**
+   * Add a comment to a thread
+   */
+  async reply(id: string, author: string, body: string): Promise<CommentThread | undefined> {
+    const thread = await this.get(id);
+    if (!thread) {
+      return undefined;
+    }
+    const now = new Date().toISOString();
+    thread.comments.push({ id: this.generateId('c'), author, body, createdAt: now });
+    thread.updatedAt = now;
+    await this.save([thread.path]);
+    return thread;
+  }
+
+  /**
+   * Resolve or reopen a thread
+   */
+  async setStatus(id: string, status: CommentThreadStatus, user: string): Promise<CommentThread | undefined> {
+    const thread = await this.get(id);
+    if (!thread) {
+      return undefined;
+    }
+    if (thread.status !== status) {
+      thread.status = status;
+      thread.resolvedBy = status === 'resolved' ? user : undefined;
+      thread.resolvedAt = status === 'resolved' ? new Date().toISOString() : undefined;
+      thread.updatedAt = new Date().toISOString();
+      await this.save([thread.path]);
+    }
+    return thread;
+  }
+
+  /**
+   * Delete a thread and its comments
+   */
+  async remove(id: string): Promise<boolean> {
+    const stored = await this.load();
+    const thread = stored.threads.find(t => t.id === id);
+    if (!thread) {
+      return false;
+    }
+    stored.threads = stored.threads.filter(t => t !== thread);
+    this.dropUnusedBaselines();
+    await this.save([thread.path]);
+    return true;
+  }
+
+  /**
+   * Re-anchor the threads of a file to its current content. Returns whether
+   * any thread moved.
+   */
+  async sync(filePath: string, content?: string): Promise<boolean> {
+    const stored = await this.load();
+    const threads = stored.threads.filter(t => t.path === filePath);
+    if (threads.length === 0) {
+      return false;
+    }
+    const current = content ?? await this.readFile(filePath);
+    const baseline = stored.baselines[filePath];
+    if (current === undefined || current === baseline) {
+      return false;
+    }
+
+    const newLines = splitLines(current);
+    // Without a baseline only the threads' own text can place them
+    const lineMap = baseline === undefined ? [] : diffLineMap(splitLines(baseline), newLines);
+    let moved = false;
+    for (const thread of threads) {
+      const result = reanchor(thread, lineMap, newLines);
+      if (result.startLine !== thread.startLine || result.endLine !== thread.endLine || result.outdated !== thread.outdated) {
+        Object.assign(thread, result);
+        moved = true;
+      }
+    }
+    stored.baselines[filePath] = current;
+    await this.save(moved ? [filePath] : []);
+    return moved;
+  }
+
+  /**
+   * Move threads along with a renamed file or directory
+   */
+  async renamePath(oldPath: string, newPath: string): Promise<void> {
+    const stored = await this.load();
+    const changed = new Set<string>();
+    for (const thread of stored.threads) {
+      if (isUnder(thread.path, oldPath)) {
+        changed.add(thread.path);
+        thread.path = newPath + thread.path.substring(oldPath.length);
+        changed.add(thread.path);
+      }
+    }
+    for (const filePath of Object.keys(stored.baselines)) {
+      if (isUnder(filePath, oldPath)) {
+        stored.baselines[newPath + filePath.substring(oldPath.length)] = stored.baselines[filePath];
+        delete stored.baselines[filePath];
+      }
+    }
+    if (changed.size > 0) {
+      await this.save([...changed]);
+    }
+  }
+
+  /**
+   * Drop threads of a deleted file or directory
+   */
+  async removePath(targetPath: string): Promise<void> {
+    const stored = await this.load();
+    const removed = stored.threads.filter(t => isUnder(t.path, targetPath));
+    if (removed.length > 0) {
+      stored.threads = stored.threads.filter(t => !isUnder(t.path, targetPath));
+      this.dropUnusedBaselines();
+      await this.save(removed.map(t => t.path));
+    }
+  }
+
+  /**
+   * Register a listener for threads that were added, changed or moved
+   */
+  onChange(listener: ChangeListener): void {
+    this.listeners.push(listener);
+  }
+
+  private async readFile(filePath: string): Promise<string | undefined> {
+    try {
+      return await this.fileSystem.readFileContent(filePath);
+    } catch {
+      return undefined;
+    }
+  }
+
+  private dropUnusedBaselines(): void {
+    const stored = this.stored!;
+    const paths = new Set(stored.threads.map(t => t.path));
+    for (const filePath of Object.keys(stored.baselines)) {
+      if (!paths.has(filePath)) {
+        delete stored.baselines[filePath];
+      }
+    }
+  }
+
+  private async load(): Promise<StoredComments> {
+    if (this.stored) {
+      return this.stored;
+    }
+    // Share a single read between concurrent callers
+    if (!this.loading) {
+      this.loading = this.state.readJson<Partial<StoredComments>>(COMMENTS_FILE, {}).then(stored => {
+        this.stored = {
+          threads: Array.isArray(stored.threads) ? stored.threads : [],
+          baselines: stored.baselines && typeof stored.baselines === 'object' ? stored.baselines : {}
+        };
+        return this.stored;
+      });
+    }
+    return this.loading;
+  }
+
+  private async save(changedPaths: string[]): Promise<void> {
+    await this.state.writeJson(COMMENTS_FILE, this.stored || { threads: [], baselines: {} });
+    if (changedPaths.length > 0) {
+      const paths = [...new Set(changedPaths)];
+      this.listeners.forEach(listener => listener(paths));
+    }
+  }
+
+  private generateId(prefix: string): string {
+    return `${prefix}-${Date.now()}-${Math.random().toString(36).substring(7)}`;
+  }
+}
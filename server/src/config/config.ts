@@ -0,0 +1,544 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { LanguageServerConfig } from '../lsp/manager.js';
+import { DEFAULT_TODO_TAGS } from '../todo/scanner.js';
+import { WORKSPACE_EVENT_TYPES } from '../events/bus.js';
+import { WebhookConfig } from '../events/webhooks.js';
+import { LinterConfig } from '../linters/service.js';
+
+export type LogLevel = 'error' | 'warning' | 'info' | 'debug';
+
+export interface AuthToken {
+  user: string;
+  token: string;
+  // Admins can use administrative endpoints such as the audit log
+  admin?: boolean;
+}
+
+export interface AuthConfig {
+  // 'none' accepts every request; 'token' requires a bearer token from the list
+  mode: 'none' | 'token';
+  tokens: AuthToken[];
+}
+
+export interface LimitsConfig {
+  maxRequestBodyBytes: number;
+  maxFileSizeBytes: number;
+  maxClients: number;
+  languageServerIdleTimeoutMs: number;
+}
+
+export interface AuditConfig {
+  enabled: boolean;
+  // Defaults to .oneline-editor/audit.jsonl in the workspace
+  path?: string;
+}
+
+export interface PluginsConfig {
+  // Directory of server plugins; plugins are disabled when unset
+  dir?: string;
+  // Per-plugin settings, passed to the plugin as context.options
+  options: Record<string, Record<string, unknown>>;
+}
+
+export interface GoConfig {
+  // GOPROXY for module commands; defaults to the local module cache
+  proxy?: string;
+  // Offline vulnerability database (JSON array of OSV entries)
+  vulnDb?: string;
+}
+
+export interface NpmConfig {
+  // Registry mirror for installs and updates; defaults to the user's npm config
+  registry?: string;
+}
+
+export interface ServerConfig {
+  server: {
+    host: string;
+    port: number;
+  };
+  workspace: {
+    root: string;
+    templatesDir?: string;
+    todoTags: string[];
+  };
+  cors: {
+    origins: string[];
+    credentials: boolean;
+  };
+  // Undefined means "use the built-in language servers"
+  languageServers?: LanguageServerConfig[];
+  auth: AuthConfig;
+  audit: AuditConfig;
+  webhooks: WebhookConfig[];
+  // External linters such as golangci-lint and eslint; none by default
+  linters: LinterConfig[];
+  plugins: PluginsConfig;
+  go: GoConfig;
+  npm: NpmConfig;
+  limits: LimitsConfig;
+  logLevel: LogLevel;
+}
+
+export interface ConfigOverrides {
+  workspace?: string;
+  port?: number;
+  host?: string;
+}
+
+/**
+ * Raised when configuration is invalid; carries one message per problem
+ */
+export class ConfigError extends Error {
+  constructor(public source: string, public issues: string[]) {
+    super(`Invalid configuration in ${source}:\n${issues.map(issue => `  - ${issue}`).join('\n')}`);
+    this.name = 'ConfigError';
+  }
+}
+
+export const DEFAULT_CONFIG_FILE = 'oneline-editor.config.json';
+
+const LOG_LEVELS: LogLevel[] = ['error', 'warning', 'info', 'debug'];
+
+/**
+ * Build the default configuration, honouring the legacy environment variables
+ */
+export function getDefaultConfig(env: NodeJS.ProcessEnv = process.env): ServerConfig {
+  return {
+    server: {
+      host: env.HOST || '0.0.0.0',
+      port: parseInt(env.PORT || '3001', 10)
+    },
+    workspace: {
+      root: env.WORKSPACE_ROOT || '/tmp/online-editor',
+      templatesDir: env.TEMPLATES_DIR || undefined,
+      todoTags: env.TODO_TAGS
+        ? env.TODO_TAGS.split(',').map(tag => tag.trim()).filter(Boolean)
+        : DEFAULT_TODO_TAGS
+    },
+    cors: {
+      origins: (env.CORS_ORIGIN || 'http://localhost:3000').split(',').map(origin => origin.trim()).filter(Boolean),
+      credentials: true
+    },
+    languageServers: undefined,
+    auth: {
+      mode: 'none',
+      tokens: []
+    },
+    audit: {
+      enabled: true,
+      path: env.AUDIT_LOG || undefined
+    },
+    webhooks: [],
+    linters: [],
+    plugins: {
+      dir: env.PLUGINS_DIR || undefined,
+      options: {}
+    },
+    go: {
+      proxy: env.GO_MODULE_PROXY || undefined,
+      vulnDb: env.GO_VULN_DB || undefined
+    },
+    npm: {
+      registry: env.NPM_REGISTRY || undefined
+    },
+    limits: {
+      maxRequestBodyBytes: 1024 * 1024,
+      maxFileSizeBytes: 5 * 1024 * 1024,
+      maxClients: 50,
+      languageServerIdleTimeoutMs: 5 * 60 * 1000
+    },
+    logLevel: LOG_LEVELS.includes(env.LOG_LEVEL as LogLevel) ? env.LOG_LEVEL as LogLevel : 'info'
+  };
+}
+
+/**
+ * Validate a parsed config file. Every key is optional, but unknown keys and
+ * wrongly typed values are reported so typos don't go unnoticed.
+ */
+export function validateConfigFile(raw: unknown): string[] {
+  const issues: string[] = [];
+
+  const isObject = (value: unknown): value is Record<string, any> =>
+    typeof value === 'object' && value !== null && !Array.isArray(value);
+  const checkKeys = (value: Record<string, any>, allowed: string[], prefix: string) => {
+    for (const key of Object.keys(value)) {
+      if (!allowed.includes(key)) {
+        issues.push(`${prefix}${key}: unknown key`);
+      }
+    }
+  };
+  const checkString = (value: unknown, name: string) => {
+    if (value !== undefined && (typeof value !== 'string' || value.length === 0)) {
+      issues.push(`${name}: must be a non-empty string`);
+    }
+  };
+  const checkStringArray = (value: unknown, name: string) => {
+    if (value !== undefined && (!Array.isArray(value) || value.some(v => typeof v !== 'string' || v.length === 0))) {
+      issues.push(`${name}: must be an array of non-empty strings`);
+    }
+  };
+  const checkInteger = (value: unknown, name: string, min: number, max: number) => {
+    if (value !== undefined && (!Number.isInteger(value) || (value as number) < min || (value as number) > max)) {
+      issues.push(`${name}: must be an integer between ${min} and ${max}`);
+    }
+  };
+  const checkBoolean = (value: unknown, name: string) => {
+    if (value !== undefined && typeof value !== 'boolean') {
+      issues.push(`${name}: must be a boolean`);
+    }
+  };
+
+  if (!isObject(raw)) {
+    return ['config file must contain a JSON object'];
+  }
+
+  checkKeys(raw, ['server', 'workspace', 'cors', 'languageServers', 'auth', 'audit', 'webhooks', 'linters', 'plugins', 'go', 'npm', 'limits', 'logLevel', '$schema'], '');
+
+  if (raw.server !== undefined) {
+    if (!isObject(raw.server)) {
+      issues.push('server: must be an object');
+    } else {
+      checkKeys(raw.server, ['host', 'port'], 'server.');
+      checkString(raw.server.host, 'server.host');
+      checkInteger(raw.server.port, 'server.port', 1, 65535);
+    }
+  }
+
+  if (raw.workspace !== undefined) {
+    if (!isObject(raw.workspace)) {
+      issues.push('workspace: must be an object');
+    } else {
+      checkKeys(raw.workspace, ['root', 'templatesDir', 'todoTags'], 'workspace.');
+      checkString(raw.workspace.root, 'workspace.root');
+      checkString(raw.workspace.templatesDir, 'workspace.templatesDir');
+      checkStringArray(raw.workspace.todoTags, 'workspace.todoTags');
+    }
+  }
+
+  if (raw.cors !== undefined) {
+    if (!isObject(raw.cors)) {
+      issues.push('cors: must be an object');
+    } else {
+      checkKeys(raw.cors, ['origins', 'credentials'], 'cors.');
+      checkStringArray(raw.cors.origins, 'cors.origins');
+      checkBoolean(raw.cors.credentials, 'cors.credentials');
+    }
+  }
+
+  if (raw.languageServers !== undefined) {
+    if (!Array.isArray(raw.languageServers)) {
+      issues.push('languageServers: must be an array');
+    } else {
+      const seen = new Set<string>();
+      raw.languageServers.forEach((server: unknown, index: number) => {
+        const prefix = `languageServers[${index}]`;
+        if (!isObject(server)) {
+          issues.push(`${prefix}: must be an object`);
+          return;
+        }
+        checkKeys(server, ['languageId', 'command', 'args', 'fileExtensions', 'initializationOptions', 'settings'], `${prefix}.`);
+        if (server.languageId === undefined) {
+          issues.push(`${prefix}.languageId: is required`);
+        }
+        if (server.command === undefined) {
+          issues.push(`${prefix}.command: is required`);
+        }
+        checkString(server.languageId, `${prefix}.languageId`);
+        checkString(server.command, `${prefix}.command`);
+        checkStringArray(server.args, `${prefix}.args`);
+        checkStringArray(server.fileExtensions, `${prefix}.fileExtensions`);
+        if (Array.isArray(server.fileExtensions) && server.fileExtensions.some((ext: unknown) => typeof ext === 'string' && !ext.startsWith('.'))) {
+          issues.push(`${prefix}.fileExtensions: extensions must start with "."`);
+        }
+        for (const key of ['initializationOptions', 'settings']) {
+          if (server[key] !== undefined && !isObject(server[key])) {
+            issues.push(`${prefix}.${key}: must be an object`);
+          }
+        }
+        if (typeof server.languageId === 'string') {
+          if (seen.has(server.languageId)) {
+            issues.push(`${prefix}.languageId: duplicate language "${server.languageId}"`);
+          }
+          seen.add(server.languageId);
+        }
+      });
+    }
+  }
+
+  if (raw.auth !== undefined) {
+    if (!isObject(raw.auth)) {
+      issues.push('auth: must be an object');
+    } else {
+      checkKeys(raw.auth, ['mode', 'tokens'], 'auth.');
+      if (raw.auth.mode !== undefined && raw.auth.mode !== 'none' && raw.auth.mode !== 'token') {
+        issues.push('auth.mode: must be "none" or "token"');
+      }
+      if (raw.auth.tokens !== undefined) {
+        if (!Array.isArray(raw.auth.tokens)) {
+          issues.push('auth.tokens: must be an array');
+        } else {
+          raw.auth.tokens.forEach((entry: unknown, index: number) => {
+            const prefix = `auth.tokens[${index}]`;
+            if (!isObject(entry)) {
+              issues.push(`${prefix}: must be an object`);
+              return;
+            }
+            checkKeys(entry, ['user', 'token', 'admin'], `${prefix}.`);
+            checkBoolean(entry.admin, `${prefix}.admin`);
+            if (typeof entry.user !== 'string' || entry.user.length === 0) {
+              issues.push(`${prefix}.user: must be a non-empty string`);
+            }
+            if (typeof entry.token !== 'string' || entry.token.length < 16) {
+              issues.push(`${prefix}.token: must be a string of at least 16 characters`);
+            }
+          });
+        }
+      }
+      if (raw.auth.mode === 'token' && (!Array.isArray(raw.auth.tokens) || raw.auth.tokens.length === 0)) {
+        issues.push('auth.tokens: at least one token is required when auth.mode is "token"');
+      }
+    }
+  }
+
+  if (raw.audit !== undefined) {
+    if (!isObject(raw.audit)) {
+      issues.push('audit: must be an object');
+    } else {
+      checkKeys(raw.audit, ['enabled', 'path'], 'audit.');
+      checkBoolean(raw.audit.enabled, 'audit.enabled');
+      checkString(raw.audit.path, 'audit.path');
+    }
+  }
+
+  if (raw.webhooks !== undefined) {
+    if (!Array.isArray(raw.webhooks)) {
+      issues.push('webhooks: must be an array');
+    } else {
+      raw.webhooks.forEach((webhook: unknown, index: number) => {
+        const prefix = `webhooks[${index}]`;
+        if (!isObject(webhook)) {
+          issues.push(`${prefix}: must be an object`);
+          return;
+        }
+        checkKeys(webhook, ['url', 'secret', 'events', 'maxAttempts'], `${prefix}.`);
+        if (typeof webhook.url !== 'string' || !/^https?:\/\/\S+$/.test(webhook.url)) {
+          issues.push(`${prefix}.url: must be an http:// or https:// URL`);
+        }
+        checkString(webhook.secret, `${prefix}.secret`);
+        checkStringArray(webhook.events, `${prefix}.events`);
+        if (Array.isArray(webhook.events)) {
+          for (const type of webhook.events) {
+            if (typeof type === 'string' && !WORKSPACE_EVENT_TYPES.includes(type as any)) {
+              issues.push(`${prefix}.events: unknown event type "${type}"`);
+            }
+          }
+        }
+        checkInteger(webhook.maxAttempts, `${prefix}.maxAttempts`, 1, 20);
+      });
+    }
+  }
+
+  if (raw.linters !== undefined) {
+    if (!Array.isArray(raw.linters)) {
+      issues.push('linters: must be an array');
+    } else {
+      const seen = new Set<string>();
+      raw.linters.forEach((linter: unknown, index: number) => {
+        const prefix = `linters[${index}]`;
+        if (!isObject(linter)) {
+          issues.push(`${prefix}: must be an object`);
+          return;
+        }
+        checkKeys(linter, ['name', 'format', 'command', 'args', 'fileExtensions', 'runOnSave'], `${prefix}.`);
+        if (linter.name === undefined) {
+          issues.push(`${prefix}.name: is required`);
+        }
+        checkString(linter.name, `${prefix}.name`);
+        if (linter.format !== 'golangci-lint' && linter.format !== 'eslint') {
+          issues.push(`${prefix}.format: must be "golangci-lint" or "eslint"`);
+        }
+        checkString(linter.command, `${prefix}.command`);
+        checkStringArray(linter.args, `${prefix}.args`);
+        checkStringArray(linter.fileExtensions, `${prefix}.fileExtensions`);
+        if (Array.isArray(linter.fileExtensions) && linter.fileExtensions.some((ext: unknown) => typeof ext === 'string' && !ext.startsWith('.'))) {
+          issues.push(`${prefix}.fileExtensions: extensions must start with "."`);
+        }
+        checkBoolean(linter.runOnSave, `${prefix}.runOnSave`);
+        if (typeof linter.name === 'string') {
+          if (seen.has(linter.name)) {
+            issues.push(`${prefix}.name: duplicate linter "${linter.name}"`);
+          }
+          seen.add(linter.name);
+        }
+      });
+    }
+  }
+
+  if (raw.plugins !== undefined) {
+    if (!isObject(raw.plugins)) {
+      issues.push('plugins: must be an object');
+    } else {
+      checkKeys(raw.plugins, ['dir', 'options'], 'plugins.');
+      checkString(raw.plugins.dir, 'plugins.dir');
+      if (raw.plugins.options !== undefined) {
+        if (!isObject(raw.plugins.options)) {
+          issues.push('plugins.options: must be an object');
+        } else {
+          for (const [name, options] of Object.entries(raw.plugins.options)) {
+            if (!isObject(options)) {
+              issues.push(`plugins.options.${name}: must be an object`);
+            }
+          }
+        }
+      }
+    }
+  }
+
+  if (raw.go !== undefined) {
+    if (!isObject(raw.go)) {
+      issues.push('go: must be an object');
+    } else {
+      checkKeys(raw.go, ['proxy', 'vulnDb'], 'go.');
+      checkString(raw.go.proxy, 'go.proxy');
+      checkString(raw.go.vulnDb, 'go.vulnDb');
+    }
+  }
+
+  if (raw.npm !== undefined) {
+    if (!isObject(raw.npm)) {
+      issues.push('npm: must be an object');
+    } else {
+      checkKeys(raw.npm, ['registry'], 'npm.');
+      if (raw.npm.registry !== undefined && (typeof raw.npm.registry !== 'string' || !/^https?:\/\/\S+$/.test(raw.npm.registry))) {
+        issues.push('npm.registry: must be an http:// or https:// URL');
+      }
+    }
+  }
+
+  if (raw.limits !== undefined) {
+    if (!isObject(raw.limits)) {
+      issues.push('limits: must be an object');
+    } else {
+      checkKeys(raw.limits, ['maxRequestBodyBytes', 'maxFileSizeBytes', 'maxClients', 'languageServerIdleTimeoutMs'], 'limits.');
+      checkInteger(raw.limits.maxRequestBodyBytes, 'limits.maxRequestBodyBytes', 1024, 1024 * 1024 * 1024);
+      checkInteger(raw.limits.maxFileSizeBytes, 'limits.maxFileSizeBytes', 1024, 1024 * 1024 * 1024);
+      checkInteger(raw.limits.maxClients, 'limits.maxClients', 1, 10000);
+      checkInteger(raw.limits.languageServerIdleTimeoutMs, 'limits.languageServerIdleTimeoutMs', 1000, 24 * 60 * 60 * 1000);
+    }
+  }
+
+  if (raw.logLevel !== undefined && !LOG_LEVELS.includes(raw.logLevel)) {
+    issues.push(`logLevel: must be one of ${LOG_LEVELS.join(', ')}`);
+  }
+
+  return issues;
+}
+
+/**
+ * Merge a validated config file over a base configuration.
+ * Relative paths are resolved against the config file's directory.
+ */
+export function mergeConfig(base: ServerConfig, file: Record<string, any>, baseDir: string): ServerConfig {
+  const resolvePath = (p: string | undefined) => (p ? path.resolve(baseDir, p) : p);
+
+  return {
+    server: { ...base.server, ...file.server },
+    workspace: {
+      ...base.workspace,
+      ...file.workspace,
+      root: resolvePath(file.workspace?.root) || base.workspace.root,
+      templatesDir: resolvePath(file.workspace?.templatesDir) || base.workspace.templatesDir
+    },
+    cors: { ...base.cors, ...file.cors },
+    languageServers: file.languageServers
+      ? file.languageServers.map((server: Record<string, any>) => ({
+          languageId: server.languageId,
+          command: server.command,
+          args: server.args || [],
+          fileExtensions: server.fileExtensions || [],
+          initializationOptions: server.initializationOptions,
+          settings: server.settings
+        }))
+      : base.languageServers,
+    auth: { ...base.auth, ...file.auth },
+    audit: {
+      ...base.audit,
+      ...file.audit,
+      path: resolvePath(file.audit?.path) || base.audit.path
+    },
+    webhooks: file.webhooks || base.webhooks,
+    linters: file.linters || base.linters,
+    plugins: {
+      dir: resolvePath(file.plugins?.dir) || base.plugins.dir,
+      options: { ...base.plugins.options, ...file.plugins?.options }
+    },
+    go: {
+      proxy: file.go?.proxy || base.go.proxy,
+      vulnDb: resolvePath(file.go?.vulnDb) || base.go.vulnDb
+    },
+    npm: {
+      registry: file.npm?.registry || base.npm.registry
+    },
+    limits: { ...base.limits, ...file.limits },
+    logLevel: file.logLevel || base.logLevel
+  };
+}
+
+/**
+ * Load configuration: defaults and environment, then the config file (if any),
+ * then command-line overrides. Throws ConfigError describing every problem found.
+ *
+ * When no path is given, ./oneline-editor.config.json is used if it exists.
+ */
+export async function loadConfig(configPath?: string, overrides: ConfigOverrides = {}): Promise<ServerConfig> {
+  let config = getDefaultConfig();
+
+  const candidate = configPath ? path.resolve(configPath) : path.resolve(DEFAULT_CONFIG_FILE);
+  let raw: string | undefined;
+  try {
+    raw = await fs.readFile(candidate, 'utf-8');
+  } catch (error) {
+    if (configPath) {
+      throw new ConfigError(candidate, [`cannot read config file: ${(error as Error).message}`]);
+    }
+  }
+
+  if (raw !== undefined) {
+    let parsed: unknown;
+    try {
+      parsed = JSON.parse(raw);
+    } catch (error) {
+      throw new ConfigError(candidate, [`invalid JSON: ${(error as Error).message}`]);
+    }
+    const issues = validateConfigFile(parsed);
+    if (issues.length > 0) {
+      throw new ConfigError(candidate, issues);
+    }
+    config = mergeConfig(config, parsed as Record<string, any>, path.dirname(candidate));
+  }
+
+  const overrideIssues: string[] = [];
+  if (overrides.port !== undefined) {
+    if (!Number.isInteger(overrides.port) || overrides.port < 1 || overrides.port > 65535) {
+      overrideIssues.push('--port: must be an integer between 1 and 65535');
+    }
+    config.server.port = overrides.port;
+  }
+  if (overrides.host !== undefined) {
+    config.server.host = overrides.host;
+  }
+  if (overrides.workspace !== undefined) {
+    config.workspace.root = path.resolve(overrides.workspace);
+  }
+  if (overrideIssues.length === 0 && (!Number.isInteger(config.server.port) || config.server.port < 1 || config.server.port > 65535)) {
+    overrideIssues.push(`port: ${config.server.port} is not a valid port (check PORT)`);
+  }
+  if (overrideIssues.length > 0) {
+    throw new ConfigError('command line', overrideIssues);
+  }
+
+  config.workspace.root = path.resolve(config.workspace.root);
+  return config;
+}
@@ -0,0 +1,114 @@
+export type CoverageFormat = 'go' | 'lcov' | 'istanbul';
+
+/**
+ * Hit counts per line (1-based) for each file named in a report. File names
+ * are as written by the tool: Go import paths, or absolute or relative paths.
+ */
+export type LineHits = Map<string, Map<number, number>>;
+
+function record(hits: LineHits, file: string, line: number, count: number): void {
+  let lines = hits.get(file);
+  if (!lines) {
+    lines = new Map();
+    hits.set(file, lines);
+  }
+  // A line shared by several blocks or statements counts as run if any of them ran
+  lines.set(line, Math.max(lines.get(line) ?? 0, count));
+}
+
+/**
+ * Guess the format of a coverage report from its content
+ */
+export function detectCoverageFormat(content: string): CoverageFormat | undefined {
+  const start = content.trimStart();
+  if (start.startsWith('mode:')) {
+    return 'go';
+  }
+  if (start.startsWith('{')) {
+    return 'istanbul';
+  }
+  if (/^(TN|SF):/m.test(content)) {
+    return 'lcov';
+  }
+  return undefined;
+}
+
+/**
+ * Parse a `go test -coverprofile` profile. Each block
+ * ("pkg/file.go:12.34,15.2 3 1") marks all lines from its start to its end.
+ */
+export function parseGoCoverProfile(content: string): LineHits {
+  const hits: LineHits = new Map();
+  for (const line of content.split(/\r?\n/)) {
+    const match = /^(.+):(\d+)\.\d+,(\d+)\.\d+ \d+ (\d+)$/.exec(line.trim());
+    if (!match) {
+      continue;
+    }
+    const [, file, start, end, count] = match;
+    for (let n = Number(start); n <= Number(end); n++) {
+      record(hits, file, n, Number(count));
+    }
+  }
+  return hits;
+}
+
+/**
+ * Parse an lcov tracefile (SF:/DA: records), as written by c8, nyc, Jest and vitest
+ */
+export function parseLcov(content: string): LineHits {
+  const hits: LineHits = new Map();
+  let file: string | undefined;
+  for (const line of content.split(/\r?\n/)) {
+    if (line.startsWith('SF:')) {
+      file = line.slice(3).trim();
+    } else if (line.startsWith('DA:') && file) {
+      const [lineNumber, count] = line.slice(3).split(',');
+      if (/^\d+$/.test(lineNumber) && /^\d+$/.test(count)) {
+        record(hits, file, Number(lineNumber), Number(count));
+      }
+    } else if (line.trim() === 'end_of_record') {
+      file = undefined;
+    }
+  }
+  return hits;
+}
+
+/**
+ * Parse an istanbul coverage-final.json. Like istanbul's own line metric, a
+ * statement counts for the line it starts on.
+ */
+export function parseIstanbulJson(content: string): LineHits {
+  const report = JSON.parse(content);
+  if (!report || typeof report !== 'object' || Array.isArray(report)) {
+    throw new Error('Expected an object keyed by file path');
+  }
+  const hits: LineHits = new Map();
+  for (const [key, entry] of Object.entries<any>(report)) {
+    // Some tools wrap each entry in { data: ... }
+    const fileCoverage = entry?.data ?? entry;
+    const statements = fileCoverage?.statementMap;
+    const counts = fileCoverage?.s;
+    if (!statements || !counts) {
+      continue;
+    }
+    const file = typeof fileCoverage.path === 'string' ? fileCoverage.path : key;
+    for (const [id, statement] of Object.entries<any>(statements)) {
+      const line = statement?.start?.line;
+      if (typeof line === 'number' && typeof counts[id] === 'number') {
+        record(hits, file, line, counts[id]);
+      }
+    }
+  }
+  return hits;
+}
+
+export function parseCoverage(format: CoverageFormat, content: string): LineHits {
+  switch (format) {
+    case 'go':
+      return parseGoCoverProfile(content);
+    case 'lcov':
+      return parseLcov(content);
+    case 'istanbul':
+      return parseIstanbulJson(content);
+  }
+}
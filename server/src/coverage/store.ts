@@ -0,0 +1,324 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { parseGoMod } from '../gomod/parser.js';
+import { WorkspaceState } from '../workspace/state.js';
+import { CoverageFormat, LineHits, detectCoverageFormat, parseCoverage } from './parsers.js';
+
+// Workspace state file holding the latest coverage
+export const COVERAGE_FILE = 'coverage.json';
+
+// Reports looked for when none is named, in order of preference
+export const DEFAULT_COVERAGE_REPORTS = [
+  '/coverage.out',
+  '/cover.out',
+  '/coverage/lcov.info',
+  '/lcov.info',
+  '/coverage/coverage-final.json'
+];
+
+// Directories that never contain Go modules worth mapping
+const IGNORED_DIRECTORIES = new Set(['node_modules', 'vendor']);
+
+export interface FileCoverage {
+  path: string;
+  // Lines that ran at least once, and lines that never ran
+  covered: number[];
+  uncovered: number[];
+}
+
+export interface CoverageFileSummary {
+  path: string;
+  covered: number;
+  total: number;
+  percent: number;
+}
+
+export interface CoverageSummary {
+  // Workspace path of the report, unset when there is no coverage
+  source?: string;
+  format?: CoverageFormat;
+  updatedAt?: string;
+  covered: number;
+  total: number;
+  percent: number;
+  files: CoverageFileSummary[];
+}
+
+interface StoredCoverage {
+  source: string;
+  format: CoverageFormat;
+  updatedAt: string;
+  files: Record<string, { covered: number[]; uncovered: number[] }>;
+}
+
+type ChangeListener = (summary: CoverageSummary) => void;
+
+function percentage(covered: number, total: number): number {
+  return total === 0 ? 0 : Math.round((covered / total) * 1000) / 10;
+}
+
+/**
+ * CoverageStore keeps the latest coverage report of the workspace, mapped
+ * onto workspace paths, so the editor can show which lines tests ran
+ */
+export class CoverageStore {
+  private coverage: StoredCoverage | undefined;
+  private listeners: ChangeListener[] = [];
+
+  constructor(
+    private workspaceRoot: string,
+    private state: WorkspaceState
+  ) {}
+
+  /**
+   * Load the coverage stored for this workspace
+   */
+  async load(): Promise<void> {
+    const stored = await this.state.readJson<Partial<StoredCoverage>>(COVERAGE_FILE, {});
+    this.coverage = stored.source && stored.files ? stored as StoredCoverage : undefined;
+  }
+
+  getSummary(): CoverageSummary {
+    if (!this.coverage) {
+      return { covered: 0, total: 0, percent: 0, files: [] };
+    }
+    const files = Object.entries(this.coverage.files)
+      .map(([filePath, lines]) => {
+        const total = lines.covered.length + lines.uncovered.length;
+        return { path: filePath, covered: lines.covered.length, total, percent: percentage(lines.covered.length, total) };
+      })
+      .sort((a, b) => a.path.localeCompare(b.path));
+    const covered = files.reduce((sum, file) => sum + file.covered, 0);
+    const total = files.reduce((sum, file) => sum + file.total, 0);
+    return {
+      source: this.coverage.source,
+      format: this.coverage.format,
+      updatedAt: this.coverage.updatedAt,
+      covered,
+      total,
+      percent: percentage(covered, total),
+      files
+    };
+  }
+
+  /**
+   * Line coverage of one file, or undefined when the report doesn't cover it
+   */
+  getFile(filePath: string): FileCoverage | undefined {
+    const lines = this.coverage?.files[filePath];
+    return lines ? { path: filePath, covered: [...lines.covered], uncovered: [...lines.uncovered] } : undefined;
+  }
+
+  /**
+   * Replace the workspace's coverage with a report from the workspace. Without
+   * a path, the first of the usual report locations that exists is used.
+   */
+  async import(reportPath?: string, format?: CoverageFormat): Promise<CoverageSummary> {
+    const source = reportPath ?? await this.findDefaultReport();
+    if (!source) {
+      throw new Error(`No coverage report found; looked for ${DEFAULT_COVERAGE_REPORTS.join(', ')}`);
+    }
+    const absolute = this.resolveWorkspacePath(source);
+    let content: string;
+    try {
+      content = await fs.readFile(absolute, 'utf-8');
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+        throw new Error(`Coverage report not found: ${source}`);
+      }
+      throw error;
+    }
+
+    const detected = format ?? detectCoverageFormat(content);
+    if (!detected) {
+      throw new Error(`Unrecognised coverage report format: ${source}`);
+    }
+    let hits: LineHits;
+    try {
+      hits = parseCoverage(detected, content);
+    } catch (error) {
+      throw new Error(`Invalid ${detected} coverage report ${source}: ${(error as Error).message}`);
+    }
+
+    const resolveName = detected === 'go'
+      ? await this.createGoResolver()
+      : (name: string) => this.resolveReportedPath(name, path.dirname(absolute));
+    // Several reported names can map to one file, e.g. with go test -coverpkg
+    const byPath = new Map<string, Map<number, number>>();
+    let skipped = 0;
+    for (const [name, lines] of hits) {
+      const filePath = await resolveName(name);
+      if (!filePath) {
+        skipped++;
+        continue;
+      }
+      const merged = byPath.get(filePath) ?? new Map<number, number>();
+      lines.forEach((count, line) => merged.set(line, Math.max(merged.get(line) ?? 0, count)));
+      byPath.set(filePath, merged);
+    }
+
+    const files: StoredCoverage['files'] = {};
+    for (const [filePath, lines] of byPath) {
+      const sorted = Array.from(lines.entries()).sort((a, b) => a[0] - b[0]);
+      files[filePath] = {
+        covered: sorted.filter(([, count]) => count > 0).map(([line]) => line),
+        uncovered: sorted.filter(([, count]) => count === 0).map(([line]) => line)
+      };
+    }
+    if (skipped > 0) {
+      console.warn(`[Coverage] ${skipped} file(s) in ${source} are outside the workspace and were skipped`);
+    }
+
+    this.coverage = { source: this.normalizeWorkspacePath(source), format: detected, updatedAt: new Date().toISOString(), files };
+    await this.state.writeJson(COVERAGE_FILE, this.coverage);
+    console.log(`[Coverage] Imported ${Object.keys(files).length} file(s) from ${source}`);
+    return this.notify();
+  }
+
+  /**
+   * Import the current report again if it changed since it was imported,
+   * e.g. after a test run. Returns whether coverage changed.
+   */
+  async refresh(): Promise<boolean> {
+    if (!this.coverage) {
+      return false;
+    }
+    try {
+      const stat = await fs.stat(this.resolveWorkspacePath(this.coverage.source));
+      if (stat.mtime.getTime() <= Date.parse(this.coverage.updatedAt)) {
+        return false;
+      }
+      await this.import(this.coverage.source, this.coverage.format);
+      return true;
+    } catch (error) {
+      console.warn(`[Coverage] Failed to refresh ${this.coverage.source}: ${(error as Error).message}`);
+      return false;
+    }
+  }
+
+  /**
+   * Forget the workspace's coverage
+   */
+  async clear(): Promise<void> {
+    this.coverage = undefined;
+    await this.state.writeJson(COVERAGE_FILE, {});
+    this.notify();
+  }
+
+  /**
+   * Register a listener for imported or cleared coverage
+   */
+  onChange(listener: ChangeListener): void {
+    this.listeners.push(listener);
+  }
+
+  private notify(): CoverageSummary {
+    const summary = this.getSummary();
+    this.listeners.forEach(listener => listener(summary));
+    return summary;
+  }
+
+  private async findDefaultReport(): Promise<string | undefined> {
+    for (const candidate of DEFAULT_COVERAGE_REPORTS) {
+      try {
+        await fs.access(this.resolveWorkspacePath(candidate));
+        return candidate;
+      } catch {
+        // Try the next location
+      }
+    }
+    return undefined;
+  }
+
+  /**
+   * Go profiles name files by import path; map them through the go.mod
+   * files of the workspace, preferring the longest module path
+   */
+  private async createGoResolver(): Promise<(name: string) => Promise<string | undefined>> {
+    const modules: { module: string; dir: string }[] = [];
+    await this.findGoModules(path.resolve(this.workspaceRoot), modules);
+    modules.sort((a, b) => b.module.length - a.module.length);
+
+    return async (name: string) => {
+      // GOPATH-mode packages outside a module are written as _/abs/path
+      if (name.startsWith('_/')) {
+        return this.resolveReportedPath(name.slice(1), this.workspaceRoot);
+      }
+      const module = modules.find(m => name === m.module || name.startsWith(m.module + '/'));
+      if (module) {
+        return this.toWorkspacePath(path.join(module.dir, name.slice(module.module.length)));
+      }
+      return this.resolveReportedPath(name, this.workspaceRoot);
+    };
+  }
+
+  private async findGoModules(dir: string, result: { module: string; dir: string }[]): Promise<void> {
+    let entries;
+    try {
+      entries = await fs.readdir(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+    if (entries.some(entry => entry.isFile() && entry.name === 'go.mod')) {
+      try {
+        const { module } = parseGoMod(await fs.readFile(path.join(dir, 'go.mod'), 'utf-8'));
+        if (module) {
+          result.push({ module, dir });
+        }
+      } catch {
+        // Unreadable go.mod; its files stay unmapped
+      }
+    }
+    for (const entry of entries) {
+      if (entry.isDirectory() && !entry.name.startsWith('.') && !IGNORED_DIRECTORIES.has(entry.name)) {
+        await this.findGoModules(path.join(dir, entry.name), result);
+      }
+    }
+  }
+
+  /**
+   * Map a path written in a report to a workspace path. Relative paths are
+   * tried against the report's directory and each parent up to the root,
+   * since tools write them relative to wherever they ran.
+   */
+  private async resolveReportedPath(name: string, reportDir: string): Promise<string | undefined> {
+    if (path.isAbsolute(name)) {
+      return this.toWorkspacePath(name);
+    }
+    const root = path.resolve(this.workspaceRoot);
+    for (let dir = path.resolve(reportDir); ; dir = path.dirname(dir)) {
+      const candidate = path.resolve(dir, name);
+      try {
+        await fs.access(candidate);
+        return this.toWorkspacePath(candidate);
+      } catch {
+        // Not relative to this directory
+      }
+      if (dir === root || path.dirname(dir) === dir) {
+        return undefined;
+      }
+    }
+  }
+
+  private toWorkspacePath(absolute: string): string | undefined {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(absolute);
+    if (!resolved.startsWith(root + path.sep)) {
+      return undefined;
+    }
+    return '/' + path.relative(root, resolved).split(path.sep).join('/');
+  }
+
+  private normalizeWorkspacePath(workspacePath: string): string {
+    return this.toWorkspacePath(this.resolveWorkspacePath(workspacePath)) ?? workspacePath;
+  }
+
+  private resolveWorkspacePath(workspacePath: string): string {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(root, workspacePath.replace(/^[/\\]+/, ''));
+    if (!resolved.startsWith(root + path.sep)) {
+      throw new Error('Access denied: path outside workspace');
+    }
+    return resolved;
+  }
+}
@@ -0,0 +1,89 @@
+export type WorkspaceEventType =
+  | 'file.saved'
+  | 'file.created'
+  | 'file.deleted'
+  | 'file.renamed'
+  | 'folder.created'
+  | 'diagnostics.changed'
+  | 'task.finished'
+  | 'client.connected'
+  | 'client.disconnected';
+
+export const WORKSPACE_EVENT_TYPES: WorkspaceEventType[] = [
+  'file.saved',
+  'file.created',
+  'file.deleted',
+  'file.renamed',
+  'folder.created',
+  'diagnostics.changed',
+  'task.finished',
+  'client.connected',
+  'client.disconnected'
+];
+
+export interface WorkspaceEvent {
+  // Monotonic per server process; used as the SSE event id
+  id: number;
+  type: WorkspaceEventType;
+  timestamp: string;
+  data: Record<string, unknown>;
+}
+
+type EventListener = (event: WorkspaceEvent) => void;
+
+/**
+ * In-process publish/subscribe hub for workspace activity. Webhooks and the
+ * event stream endpoint subscribe here; producers only call emit().
+ */
+export class EventBus {
+  private listeners: EventListener[] = [];
+  private history: WorkspaceEvent[] = [];
+  private nextId = 1;
+
+  constructor(private historySize: number = 200) {}
+
+  /**
+   * Publish an event to every subscriber
+   */
+  emit(type: WorkspaceEventType, data: Record<string, unknown> = {}): WorkspaceEvent {
+    const event: WorkspaceEvent = {
+      id: this.nextId++,
+      type,
+      timestamp: new Date().toISOString(),
+      data
+    };
+
+    this.history.push(event);
+    if (this.history.length > this.historySize) {
+      this.history.shift();
+    }
+
+    // A failing subscriber must not prevent delivery to the others
+    for (const listener of [...this.listeners]) {
+      try {
+        listener(event);
+      } catch (error) {
+        console.error(`[Events] Listener failed for ${type}:`, error);
+      }
+    }
+    return event;
+  }
+
+  /**
+   * Subscribe to all events; returns a function that unsubscribes
+   */
+  subscribe(listener: EventListener): () => void {
+    this.listeners.push(listener);
+    return () => {
+      this.listeners = this.listeners.filter(l => l !== listener);
+    };
+  }
+
+  /**
+   * Get recent events with an id greater than the given one (oldest first),
+   * so reconnecting consumers can catch up
+   */
+  getEventsSince(id: number): WorkspaceEvent[] {
+    return this.history.filter(event => event.id > id);
+  }
+}
@@ -0,0 +1,52 @@
+import { Request, Response } from 'express';
+import { EventBus, WorkspaceEvent, WorkspaceEventType } from './bus.js';
+
+const HEARTBEAT_INTERVAL_MS = 25000;
+
+/**
+ * Format an event in the text/event-stream wire format
+ */
+export function formatServerSentEvent(event: WorkspaceEvent): string {
+  return `id: ${event.id}\nevent: ${event.type}\ndata: ${JSON.stringify(event)}\n\n`;
+}
+
+/**
+ * Turn a response into a server-sent event stream of bus events. Events missed
+ * since the Last-Event-ID header are replayed from the bus history first.
+ * The stream stays open until the client disconnects.
+ */
+export function openEventStream(bus: EventBus, req: Request, res: Response, types?: WorkspaceEventType[]): void {
+  const wanted = (event: WorkspaceEvent) => !types || types.includes(event.type);
+
+  res.status(200);
+  res.setHeader('Content-Type', 'text/event-stream');
+  res.setHeader('Cache-Control', 'no-cache');
+  res.setHeader('Connection', 'keep-alive');
+  // Stop reverse proxies from buffering the stream
+  res.setHeader('X-Accel-Buffering', 'no');
+  res.flushHeaders();
+  res.write('retry: 3000\n\n');
+
+  const lastEventId = parseInt(req.get('Last-Event-ID') ?? '', 10);
+  if (!Number.isNaN(lastEventId)) {
+    for (const event of bus.getEventsSince(lastEventId)) {
+      if (wanted(event)) {
+        res.write(formatServerSentEvent(event));
+      }
+    }
+  }
+
+  const unsubscribe = bus.subscribe((event) => {
+    if (wanted(event)) {
+      res.write(formatServerSentEvent(event));
+    }
+  });
+
+  // Comment lines keep idle connections from being closed by proxies
+  const heartbeat = setInterval(() => res.write(': ping\n\n'), HEARTBEAT_INTERVAL_MS);
+
+  req.on('close', () => {
+    clearInterval(heartbeat);
+    unsubscribe();
+  });
+}
@@ -0,0 +1,129 @@
+import { createHmac, randomUUID } from 'crypto';
+import { EventBus, WorkspaceEvent, WorkspaceEventType } from './bus.js';
+
+export interface WebhookConfig {
+  url: string;
+  // Used to sign deliveries; receivers should reject unsigned or mismatched requests
+  secret?: string;
+  // Event types to deliver; all events when omitted
+  events?: WorkspaceEventType[];
+  // Total delivery attempts before giving up (default 5)
+  maxAttempts?: number;
+}
+
+export interface WebhookDispatcherOptions {
+  fetch?: typeof fetch;
+  // Delay before the first retry; doubles with every further attempt
+  retryDelayMs?: number;
+  timeoutMs?: number;
+}
+
+const DEFAULT_MAX_ATTEMPTS = 5;
+const DEFAULT_RETRY_DELAY_MS = 1000;
+const DEFAULT_TIMEOUT_MS = 10000;
+
+/**
+ * Compute the X-Oneline-Signature value for a delivery. The timestamp is part
+ * of the signed content so a captured request can't be replayed later.
+ */
+export function signPayload(secret: string, timestamp: string, body: string): string {
+  return 'sha256=' + createHmac('sha256', secret).update(`${timestamp}.${body}`).digest('hex');
+}
+
+/**
+ * WebhookDispatcher POSTs workspace events to the configured URLs, retrying
+ * with exponential backoff on network errors, timeouts, 429 and 5xx responses.
+ */
+export class WebhookDispatcher {
+  private pending: Set<Promise<boolean>> = new Set();
+  private fetchImpl: typeof fetch;
+  private retryDelayMs: number;
+  private timeoutMs: number;
+
+  constructor(private webhooks: WebhookConfig[], options: WebhookDispatcherOptions = {}) {
+    this.fetchImpl = options.fetch ?? fetch;
+    this.retryDelayMs = options.retryDelayMs ?? DEFAULT_RETRY_DELAY_MS;
+    this.timeoutMs = options.timeoutMs ?? DEFAULT_TIMEOUT_MS;
+  }
+
+  /**
+   * Deliver every matching event published on the bus; returns an unsubscribe function
+   */
+  attach(bus: EventBus): () => void {
+    return bus.subscribe((event) => this.dispatch(event));
+  }
+
+  /**
+   * Queue deliveries of an event to every webhook subscribed to its type
+   */
+  dispatch(event: WorkspaceEvent): void {
+    for (const webhook of this.webhooks) {
+      if (webhook.events && !webhook.events.includes(event.type)) {
+        continue;
+      }
+      const delivery = this.deliver(webhook, event);
+      this.pending.add(delivery);
+      delivery.finally(() => this.pending.delete(delivery));
+    }
+  }
+
+  /**
+   * Wait for all in-flight deliveries, including their retries
+   */
+  async flush(): Promise<void> {
+    while (this.pending.size > 0) {
+      await Promise.all(this.pending);
+    }
+  }
+
+  /**
+   * Deliver one event to one webhook. Resolves to whether it was accepted (2xx).
+   */
+  async deliver(webhook: WebhookConfig, event: WorkspaceEvent): Promise<boolean> {
+    const body = JSON.stringify(event);
+    const deliveryId = randomUUID();
+    const maxAttempts = webhook.maxAttempts ?? DEFAULT_MAX_ATTEMPTS;
+
+    for (let attempt = 1; attempt <= maxAttempts; attempt++) {
+      const timestamp = Math.floor(Date.now() / 1000).toString();
+      const headers: Record<string, string> = {
+        'Content-Type': 'application/json',
+        'User-Agent': 'oneline-editor-webhooks',
+        'X-Oneline-Event': event.type,
+        'X-Oneline-Delivery': deliveryId,
+        'X-Oneline-Timestamp': timestamp
+      };
+      if (webhook.secret) {
+        headers['X-Oneline-Signature'] = signPayload(webhook.secret, timestamp, body);
+      }
+
+      let retryable = true;
+      let reason: string;
+      try {
+        const response = await this.fetchImpl(webhook.url, {
+          method: 'POST',
+          headers,
+          body,
+          signal: AbortSignal.timeout(this.timeoutMs)
+        });
+        if (response.ok) {
+          return true;
+        }
+        reason = `HTTP ${response.status}`;
+        retryable = response.status === 408 || response.status === 429 || response.status >= 500;
+      } catch (error) {
+        reason = (error as Error).message;
+      }
+
+      if (!retryable || attempt === maxAttempts) {
+        console.error(`[Webhooks] Giving up on ${event.type} delivery to ${webhook.url} after ${attempt} attempt(s): ${reason}`);
+        return false;
+      }
+
+      const delay = this.retryDelayMs * 2 ** (attempt - 1);
+      console.warn(`[Webhooks] ${event.type} delivery to ${webhook.url} failed (${reason}), retrying in ${delay}ms`);
+      await new Promise(resolve => setTimeout(resolve, delay));
+    }
+    return false;
+  }
+}
@@ -1,5 +1,9 @@
 import * as fs from 'fs/promises';
 import * as path from 'path';
+import { pathToFileURL } from 'url';
+import { getLanguageIdForPath } from '../lsp/languages.js';
+import { UriMapper } from './uris.js';
+import { isStatePath, STATE_DIR_NAME } from '../workspace/state.js';
 
 export interface FileEntry {
   uri: string;
@@ -22,8 +26,11 @@ export interface FileTreeNode {
 export class RealFileSystem {
   private fileVersions: Map<string, number> = new Map();
   private fileLanguages: Map<string, string> = new Map();
+  private uris: UriMapper;
 
-  constructor(private workspaceRoot: string) {}
+  constructor(private workspaceRoot: string) {
+    this.uris = new UriMapper(workspaceRoot);
+  }
 
   /**
    * Create a new file in the real file system
@@ -80,6 +87,13 @@ export class RealFileSystem {
     }
   }
 
+  /**
+   * Language a file was last opened in, or undefined if it isn't open
+   */
+  getOpenLanguageId(uri: string): string | undefined {
+    return this.fileLanguages.get(uri);
+  }
+
   /**
    * Delete a file from the file system
    */
@@ -99,15 +113,7 @@ export class RealFileSystem {
    * Create a new directory in the real file system
    */
   async createDirectory(dirPath: string): Promise<void> {
-    const fullPath = path.join(this.workspaceRoot, dirPath.replace(/^\//, ''));
-    
-    // Security check: ensure the resolved path is within workspace
-    const resolvedPath = path.resolve(fullPath);
-    const resolvedWorkspace = path.resolve(this.workspaceRoot);
-    if (!resolvedPath.startsWith(resolvedWorkspace)) {
-      throw new Error(`Access denied: path outside workspace`);
-    }
-    
+    const resolvedPath = this.resolveWorkspacePath(dirPath);
     await fs.mkdir(resolvedPath, { recursive: true });
   }
 
@@ -115,14 +121,7 @@ export class RealFileSystem {
    * Delete a file or directory (recursively) from the file system
    */
   async deletePath(targetPath: string): Promise<void> {
-    const fullPath = path.join(this.workspaceRoot, targetPath.replace(/^\//, ''));
-    
-    // Security check: ensure the resolved path is within workspace
-    const resolvedPath = path.resolve(fullPath);
-    const resolvedWorkspace = path.resolve(this.workspaceRoot);
-    if (!resolvedPath.startsWith(resolvedWorkspace)) {
-      throw new Error(`Access denied: path outside workspace`);
-    }
+    const resolvedPath = this.resolveWorkspacePath(targetPath);
     
     try {
       const stats = await fs.stat(resolvedPath);
@@ -144,20 +143,11 @@ export class RealFileSystem {
    * Rename a file or directory in the file system
    */
   async renamePath(oldPath: string, newPath: string): Promise<void> {
-    const oldFullPath = path.join(this.workspaceRoot, oldPath.replace(/^\//, ''));
-    const newFullPath = path.join(this.workspaceRoot, newPath.replace(/^\//, ''));
-    
-    // Security check: ensure both paths are within workspace
-    const resolvedOldPath = path.resolve(oldFullPath);
-    const resolvedNewPath = path.resolve(newFullPath);
-    const resolvedWorkspace = path.resolve(this.workspaceRoot);
-    
-    if (!resolvedOldPath.startsWith(resolvedWorkspace) || !resolvedNewPath.startsWith(resolvedWorkspace)) {
-      throw new Error(`Access denied: path outside workspace`);
-    }
+    const resolvedOldPath = this.resolveWorkspacePath(oldPath);
+    const resolvedNewPath = this.resolveWorkspacePath(newPath);
     
     // Ensure target directory exists
-    const newDir = path.dirname(newFullPath);
+    const newDir = path.dirname(resolvedNewPath);
     await fs.mkdir(newDir, { recursive: true });
     
     // Rename the file/directory
@@ -248,14 +238,7 @@ export class RealFileSystem {
    * Read file content by path (not URI)
    */
   async readFileContent(filePath: string): Promise<string> {
-    const fullPath = path.join(this.workspaceRoot, filePath.replace(/^\//, ''));
-    
-    // Security check: ensure the resolved path is within workspace
-    const resolvedPath = path.resolve(fullPath);
-    const resolvedWorkspace = path.resolve(this.workspaceRoot);
-    if (!resolvedPath.startsWith(resolvedWorkspace)) {
-      throw new Error(`Access denied: path outside workspace`);
-    }
+    const resolvedPath = this.resolveWorkspacePath(filePath);
     
     try {
       return await fs.readFile(resolvedPath, 'utf-8');
@@ -264,6 +247,21 @@ export class RealFileSystem {
     }
   }
 
+  /**
+   * Get the size of a file in bytes, or undefined if it doesn't exist
+   */
+  async getFileSize(filePath: string): Promise<number | undefined> {
+    try {
+      const stats = await fs.stat(this.resolveWorkspacePath(filePath));
+      return stats.size;
+    } catch (error) {
+      if ((error as Error).message.startsWith('Access denied')) {
+        throw error;
+      }
+      return undefined;
+    }
+  }
+
   /**
    * Check if a file exists
    */
@@ -277,56 +275,48 @@ export class RealFileSystem {
     }
   }
 
+  /**
+   * Resolve a workspace-relative path (e.g. /src/main.go) to an absolute path,
+   * throwing if it would escape the workspace root or reach the editor's
+   * state directory
+   */
+  resolveWorkspacePath(relativePath: string): string {
+    const resolvedWorkspace = path.resolve(this.workspaceRoot);
+    const resolvedPath = path.resolve(resolvedWorkspace, relativePath.replace(/^[/\\]+/, ''));
+    const relative = path.relative(resolvedWorkspace, resolvedPath);
+    if (relative.startsWith('..') || path.isAbsolute(relative)) {
+      throw new Error(`Access denied: path outside workspace`);
+    }
+    if (isStatePath(relative)) {
+      throw new Error(`Access denied: ${STATE_DIR_NAME} holds editor state`);
+    }
+    return resolvedPath;
+  }
+
   /**
    * Convert URI to real file path
    * This is the key method that maps URIs to the workspace root
    */
   uriToPath(uri: string): string {
-    const rawPath = this.extractPathFromUri(uri);
-    const normalized = this.normalizePath(rawPath);
-    return path.join(this.workspaceRoot, normalized);
+    if (isStatePath(this.uris.toWorkspacePath(uri))) {
+      throw new Error(`Access denied: ${STATE_DIR_NAME} holds editor state`);
+    }
+    return this.uris.toDiskPath(uri);
   }
 
   /**
-   * Convert file path to URI
+   * Convert a file path inside the workspace to its browser URI
+   * (workspace:///...)
    */
   pathToUri(filePath: string): string {
-    // If the path is within workspace root, make it relative
-    if (filePath.startsWith(this.workspaceRoot)) {
-      const relativePath = path.relative(this.workspaceRoot, filePath);
-      return `file:///${relativePath.replace(/\\/g, '/')}`;
-    }
-    return `file:///${filePath.replace(/\\/g, '/')}`;
-  }
-
-  private extractPathFromUri(uri: string): string {
-    try {
-      return new URL(uri).pathname;
-    } catch {
-      return uri;
-    }
-  }
-
-  private normalizePath(rawPath: string): string {
-    // Strip leading slashes so path.join doesn't escape the workspace root
-    const withoutLeadingSlash = rawPath.replace(/^[/\\]+/, '');
-    // Normalize to collapse any .. segments
-    return path.normalize(withoutLeadingSlash);
+    return this.uris.toClientUri(pathToFileURL(filePath).href);
   }
 
   /**
    * Infer language ID from file extension
    */
   private inferLanguageId(uri: string): string {
-    const ext = path.extname(uri).toLowerCase();
-    const languageMap: Record<string, string> = {
-      '.go': 'go',
-      '.ts': 'typescript',
-      '.tsx': 'typescript',
-      '.js': 'javascript',
-      '.jsx': 'javascript',
-    };
-    return languageMap[ext] || 'plaintext';
+    return getLanguageIdForPath(uri);
   }
 
   /**
@@ -0,0 +1,132 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import { getLanguageExtension } from '../lsp/languages.js';
+import { STATE_DIR_NAME } from '../workspace/state.js';
+
+// Scheme of in-memory buffers not saved into the workspace yet, e.g.
+// untitled:Untitled-1
+export const UNTITLED_URI_SCHEME = 'untitled';
+
+export interface ScratchDocument {
+  // Connection (browser tab) the buffer belongs to
+  client: string;
+  uri: string;
+  languageId: string;
+  // Temporary file language servers know the buffer by
+  filePath: string;
+}
+
+/**
+ * Backs untitled buffers with temporary files under
+ * <workspaceRoot>/.oneline-editor/scratch/, so language servers can work on
+ * them like on any other file. Every tab numbers its buffers from
+ * Untitled-1, so buffers are kept per client (connection), and each gets its
+ * own folder so buffers don't see each other (e.g. two Go buffers both
+ * declaring main). The file extension follows the buffer's language.
+ */
+export class ScratchDocuments {
+  private documents: Map<string, ScratchDocument> = new Map();
+
+  constructor(
+    private workspaceRoot: string,
+    private getExtension: (languageId: string) => string | undefined = getLanguageExtension
+  ) {}
+
+  isScratchUri(uri: string): boolean {
+    return uri.startsWith(`${UNTITLED_URI_SCHEME}:`);
+  }
+
+  /**
+   * Get the absolute path of the scratch directory
+   */
+  getDir(): string {
+    return path.join(path.resolve(this.workspaceRoot), STATE_DIR_NAME, 'scratch');
+  }
+
+  get(client: string, uri: string): ScratchDocument | undefined {
+    return this.documents.get(documentKey(client, uri));
+  }
+
+  /**
+   * Write an untitled buffer to its temporary file. Reopening it in another
+   * language moves it to a file with that language's extension.
+   */
+  async open(client: string, uri: string, languageId: string, text: string): Promise<ScratchDocument> {
+    const previous = this.get(client, uri);
+    if (previous && previous.languageId !== languageId) {
+      await this.close(client, uri);
+    }
+
+    const name = toFileName(uri.slice(UNTITLED_URI_SCHEME.length + 1));
+    const filePath = path.join(this.getDir(), toFileName(client), name, name + (this.getExtension(languageId) ?? '.txt'));
+    await fs.mkdir(path.dirname(filePath), { recursive: true });
+    await fs.writeFile(filePath, text, 'utf-8');
+
+    const document = { client, uri, languageId, filePath };
+    this.documents.set(documentKey(client, uri), document);
+    return document;
+  }
+
+  async update(client: string, uri: string, text: string): Promise<void> {
+    const document = this.get(client, uri);
+    if (!document) {
+      throw new Error(`Untitled document is not open: ${uri}`);
+    }
+    await fs.writeFile(document.filePath, text, 'utf-8');
+  }
+
+  /**
+   * Forget an untitled buffer and remove its temporary file
+   */
+  async close(client: string, uri: string): Promise<void> {
+    const document = this.get(client, uri);
+    if (!document) return;
+    this.documents.delete(documentKey(client, uri));
+    await fs.rm(path.dirname(document.filePath), { recursive: true, force: true });
+  }
+
+  /**
+   * Remove temporary files left over from a previous run
+   */
+  async clear(): Promise<void> {
+    this.documents.clear();
+    await fs.rm(this.getDir(), { recursive: true, force: true });
+  }
+
+  /**
+   * file:// URI of an open untitled buffer's temporary file
+   */
+  toServerUri(client: string, uri: string): string | undefined {
+    const document = this.get(client, uri);
+    return document ? pathToFileURL(document.filePath).href : undefined;
+  }
+
+  /**
+   * The untitled URI a temporary file (path or file:// URI) belongs to
+   */
+  toClientUri(fileUriOrPath: string): string | undefined {
+    let filePath = fileUriOrPath;
+    if (fileUriOrPath.startsWith('file:')) {
+      try {
+        filePath = fileURLToPath(fileUriOrPath);
+      } catch {
+        return undefined;
+      }
+    }
+    for (const document of this.documents.values()) {
+      if (document.filePath === filePath) {
+        return document.uri;
+      }
+    }
+    return undefined;
+  }
+}
+
+function documentKey(client: string, uri: string): string {
+  return `${client}\n${uri}`;
+}
+
+function toFileName(name: string): string {
+  return name.replace(/[^\w.-]+/g, '_').replace(/^\.+/, '') || 'untitled';
+}
@@ -0,0 +1,151 @@
+import * as path from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import type { ScratchDocuments } from './scratch.js';
+import type { VirtualDocumentProvider } from './virtual.js';
+
+// Scheme of workspace documents in the browser, e.g. workspace:///src/main.go
+export const WORKSPACE_URI_SCHEME = 'workspace';
+
+// Fields that hold document URIs in LSP messages
+const URI_FIELDS = new Set(['uri', 'targetUri', 'oldUri', 'newUri', 'rootUri', 'scopeUri']);
+
+/**
+ * Percent-encode a path segment the way Monaco's URIs do, so URIs built
+ * here compare equal to the browser's model URIs
+ */
+export function encodeUriSegment(segment: string): string {
+  return encodeURIComponent(segment).replace(/[!'()*]/g, char => `%${char.charCodeAt(0).toString(16).toUpperCase()}`);
+}
+
+/**
+ * Browser URI of a workspace path, e.g. /src/main.go -> workspace:///src/main.go
+ */
+export function workspacePathToUri(workspacePath: string): string {
+  const segments = workspacePath.replace(/\\/g, '/').replace(/^\/+/, '').split('/');
+  return `${WORKSPACE_URI_SCHEME}:///${segments.map(encodeUriSegment).join('/')}`;
+}
+
+/**
+ * Translates document URIs between the browser and the language servers.
+ *
+ * The browser knows workspace files as workspace:///<workspace path> and
+ * read-only files outside the workspace as deps:///<root>/<path>; language
+ * servers only see file:// URIs of the real files on disk. Temporary files of
+ * untitled buffers map back to their untitled: URI; the other way round
+ * depends on the tab, so LSPProxy does it with ScratchDocuments. file:// URIs
+ * from the browser are still accepted as workspace paths for older clients.
+ */
+export class UriMapper {
+  private workspaceRoot: string;
+
+  constructor(
+    workspaceRoot: string,
+    private virtualDocuments?: VirtualDocumentProvider,
+    private scratch?: ScratchDocuments
+  ) {
+    this.workspaceRoot = path.resolve(workspaceRoot);
+  }
+
+  isVirtualUri(uri: string): boolean {
+    return this.virtualDocuments?.isVirtualUri(uri) ?? false;
+  }
+
+  /**
+   * Workspace path (e.g. /src/main.go) of a browser URI
+   */
+  toWorkspacePath(clientUri: string): string {
+    let rawPath: string;
+    try {
+      rawPath = decodeURIComponent(new URL(clientUri).pathname);
+    } catch {
+      rawPath = clientUri;
+    }
+    return '/' + path.posix.normalize(rawPath.replace(/\\/g, '/').replace(/^\/+/, '')).replace(/^(\.\.\/?)+/, '').replace(/^\.$/, '');
+  }
+
+  /**
+   * Absolute path on disk of a browser URI for a workspace document
+   */
+  toDiskPath(clientUri: string): string {
+    return path.join(this.workspaceRoot, this.toWorkspacePath(clientUri));
+  }
+
+  /**
+   * URI a language server knows a browser document by
+   */
+  toServerUri(clientUri: string): string {
+    if (this.virtualDocuments?.isVirtualUri(clientUri)) {
+      return this.virtualDocuments.toRealUri(clientUri);
+    }
+    if (clientUri.startsWith(`${WORKSPACE_URI_SCHEME}:`) || clientUri.startsWith('file:')) {
+      return pathToFileURL(this.toDiskPath(clientUri)).href;
+    }
+    return clientUri;
+  }
+
+  /**
+   * URI the browser knows a language server's file:// URI by. Files outside
+   * the workspace and the read-only roots keep their file:// URI.
+   */
+  toClientUri(serverUri: string): string {
+    if (!serverUri.startsWith('file:')) {
+      return serverUri;
+    }
+    let filePath: string;
+    try {
+      filePath = fileURLToPath(serverUri);
+    } catch {
+      return serverUri;
+    }
+    // Untitled buffers' temporary files live inside the workspace
+    const scratchUri = this.scratch?.toClientUri(filePath);
+    if (scratchUri) {
+      return scratchUri;
+    }
+    const relative = path.relative(this.workspaceRoot, filePath);
+    if (!relative.startsWith('..') && !path.isAbsolute(relative)) {
+      return workspacePathToUri(relative);
+    }
+    return this.virtualDocuments?.toVirtualUri(filePath) ?? serverUri;
+  }
+
+  /**
+   * Map every URI field of a message going to a language server
+   */
+  toServer<T>(value: T): T {
+    return this.mapUris(value, uri => this.toServerUri(uri));
+  }
+
+  /**
+   * Map every URI field of a message coming from a language server
+   */
+  toClient<T>(value: T): T {
+    return this.mapUris(value, uri => this.toClientUri(uri));
+  }
+
+  /**
+   * Copy a value with its URI fields mapped, including the keys of a
+   * WorkspaceEdit's `changes`
+   */
+  private mapUris<T>(value: T, map: (uri: string) => string): T {
+    if (Array.isArray(value)) {
+      return value.map(item => this.mapUris(item, map)) as T;
+    }
+    if (!value || typeof value !== 'object') {
+      return value;
+    }
+    const mapped: Record<string, unknown> = {};
+    for (const [key, field] of Object.entries(value)) {
+      if (URI_FIELDS.has(key) && typeof field === 'string') {
+        mapped[key] = map(field);
+      } else if (key === 'changes' && field && typeof field === 'object' && !Array.isArray(field)) {
+        mapped[key] = Object.fromEntries(
+          Object.entries(field).map(([uri, edits]) => [map(uri), this.mapUris(edits, map)])
+        );
+      } else {
+        mapped[key] = this.mapUris(field, map);
+      }
+    }
+    return mapped as T;
+  }
+}
@@ -0,0 +1,166 @@
+import { execFile } from 'child_process';
+import * as fs from 'fs/promises';
+import { createRequire } from 'module';
+import * as os from 'os';
+import * as path from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import { encodeUriSegment } from './uris.js';
+
+// Scheme of read-only documents outside the workspace, e.g.
+// deps:///goroot/src/fmt/print.go
+export const VIRTUAL_DOCUMENT_SCHEME = 'deps';
+
+export interface VirtualDocument {
+  uri: string;
+  // Root name and path below it, e.g. goroot/src/fmt/print.go
+  name: string;
+  content: string;
+  size: number;
+}
+
+/**
+ * Find the directories definitions outside the workspace usually point
+ * into: the Go standard library, the Go module cache and the TypeScript
+ * standard library declarations. Missing ones are left out.
+ */
+export async function detectVirtualDocumentRoots(
+  env: NodeJS.ProcessEnv = process.env
+): Promise<Record<string, string>> {
+  const goEnv = await new Promise<Record<string, string>>((resolve) => {
+    execFile('go', ['env', '-json', 'GOROOT', 'GOMODCACHE'], { timeout: 10000 }, (error, stdout) => {
+      try {
+        resolve(error ? {} : JSON.parse(stdout));
+      } catch {
+        resolve({});
+      }
+    });
+  });
+
+  const candidates: Record<string, string | undefined> = {
+    goroot: goEnv.GOROOT || env.GOROOT,
+    gomodcache: goEnv.GOMODCACHE || env.GOMODCACHE
+      || path.join((env.GOPATH || path.join(os.homedir(), 'go')).split(path.delimiter)[0], 'pkg', 'mod')
+  };
+  try {
+    const require = createRequire(import.meta.url);
+    candidates.typescript = path.dirname(require.resolve('typescript/package.json'));
+  } catch {
+    // TypeScript isn't installed next to the server
+  }
+
+  const roots: Record<string, string> = {};
+  for (const [name, dir] of Object.entries(candidates)) {
+    if (!dir) continue;
+    try {
+      // Language servers report resolved paths, so compare against those
+      roots[name] = await fs.realpath(dir);
+    } catch {
+      // Not present on this machine
+    }
+  }
+  return roots;
+}
+
+/**
+ * Serves files from an allow-list of directories outside the workspace as
+ * read-only documents under deps:///<root name>/<path> URIs
+ */
+export class VirtualDocumentProvider {
+  constructor(
+    private roots: Record<string, string>,
+    private maxFileSizeBytes: number = 5 * 1024 * 1024
+  ) {}
+
+  /**
+   * Names and directories of the allowed roots
+   */
+  getRoots(): Record<string, string> {
+    return { ...this.roots };
+  }
+
+  isVirtualUri(uri: string): boolean {
+    return uri.startsWith(`${VIRTUAL_DOCUMENT_SCHEME}:`);
+  }
+
+  /**
+   * The virtual URI of a real file URI or absolute path, or undefined if it
+   * isn't inside one of the roots
+   */
+  toVirtualUri(fileUriOrPath: string): string | undefined {
+    let filePath = fileUriOrPath;
+    if (fileUriOrPath.startsWith('file:')) {
+      try {
+        filePath = fileURLToPath(fileUriOrPath);
+      } catch {
+        return undefined;
+      }
+    }
+    for (const [name, root] of Object.entries(this.roots)) {
+      const relative = path.relative(root, filePath);
+      if (relative && !relative.startsWith('..') && !path.isAbsolute(relative)) {
+        return `${VIRTUAL_DOCUMENT_SCHEME}:///${name}/${relative.split(path.sep).map(encodeUriSegment).join('/')}`;
+      }
+    }
+    return undefined;
+  }
+
+  /**
+   * Absolute path of a virtual URI, throwing if the root isn't allowed or
+   * the path would escape it
+   */
+  toRealPath(uri: string): string {
+    if (!this.isVirtualUri(uri)) {
+      throw new Error(`Not a ${VIRTUAL_DOCUMENT_SCHEME}: URI: ${uri}`);
+    }
+    const segments = uri.slice(VIRTUAL_DOCUMENT_SCHEME.length + 1).replace(/^\/+/, '').split('/');
+    const root = Object.prototype.hasOwnProperty.call(this.roots, segments[0])
+      ? this.roots[segments[0]]
+      : undefined;
+    if (!root) {
+      throw new Error(`Access denied: unknown document root: ${segments[0]}`);
+    }
+    let relativePath: string;
+    try {
+      relativePath = segments.slice(1).map(decodeURIComponent).join('/');
+    } catch {
+      throw new Error(`Access denied: malformed URI: ${uri}`);
+    }
+    const resolved = path.resolve(root, relativePath);
+    const relative = path.relative(root, resolved);
+    if (!relative || relative.startsWith('..') || path.isAbsolute(relative)) {
+      throw new Error('Access denied: path outside document root');
+    }
+    return resolved;
+  }
+
+  /**
+   * File URI the language servers know a virtual document by
+   */
+  toRealUri(uri: string): string {
+    return pathToFileURL(this.toRealPath(uri)).href;
+  }
+
+  /**
+   * Read a virtual document. Symlinks leading out of the root are refused.
+   */
+  async read(uri: string): Promise<VirtualDocument> {
+    const filePath = this.toRealPath(uri);
+    const realPath = await fs.realpath(filePath);
+    if (!this.toVirtualUri(realPath)) {
+      throw new Error('Access denied: path outside document root');
+    }
+    const stats = await fs.stat(realPath);
+    if (!stats.isFile()) {
+      throw new Error(`Failed to read file: not a file: ${uri}`);
+    }
+    if (stats.size > this.maxFileSizeBytes) {
+      throw new Error(`File is too large to open (${stats.size} bytes, limit ${this.maxFileSizeBytes})`);
+    }
+    return {
+      uri,
+      name: uri.slice(VIRTUAL_DOCUMENT_SCHEME.length + 1).replace(/^\/+/, ''),
+      content: await fs.readFile(realPath, 'utf-8'),
+      size: stats.size
+    };
+  }
+}
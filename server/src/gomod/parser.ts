@@ -0,0 +1,204 @@
+export interface GoRequirement {
+  path: string;
+  version: string;
+  indirect: boolean;
+  // 1-based line of the requirement in go.mod
+  line: number;
+  column: number;
+}
+
+export interface GoReplacement {
+  path: string;
+  version?: string;
+  // Module path and version, or a local directory
+  target: string;
+  line: number;
+}
+
+export interface GoModFile {
+  module?: string;
+  goVersion?: string;
+  toolchain?: string;
+  requires: GoRequirement[];
+  replaces: GoReplacement[];
+  excludes: { path: string; version: string; line: number }[];
+}
+
+const unquote = (token: string) => token.replace(/^["`](.*)["`]$/, '$1');
+
+/**
+ * Parse the directives of a go.mod file that matter for dependency
+ * management. Unknown directives are ignored rather than rejected, so a
+ * newer go.mod still parses.
+ */
+export function parseGoMod(content: string): GoModFile {
+  const result: GoModFile = { requires: [], replaces: [], excludes: [] };
+  let block: string | undefined;
+
+  content.split(/\r?\n/).forEach((rawLine, index) => {
+    const line = index + 1;
+    const commentIndex = rawLine.indexOf('//');
+    const comment = commentIndex >= 0 ? rawLine.slice(commentIndex + 2).trim() : '';
+    const code = (commentIndex >= 0 ? rawLine.slice(0, commentIndex) : rawLine).trim();
+    if (!code) {
+      return;
+    }
+
+    let verb: string | undefined;
+    let rest: string;
+    if (block) {
+      if (code === ')') {
+        block = undefined;
+        return;
+      }
+      verb = block;
+      rest = code;
+    } else {
+      const match = /^(\w+)\s*(.*)$/.exec(code);
+      if (!match) {
+        return;
+      }
+      verb = match[1];
+      rest = match[2].trim();
+      if (rest === '(') {
+        block = verb;
+        return;
+      }
+    }
+
+    const tokens = rest.split(/\s+/).filter(Boolean).map(unquote);
+    const column = rawLine.indexOf(rest.split(/\s+/)[0] ?? '') + 1;
+    switch (verb) {
+      case 'module':
+        result.module = tokens[0];
+        break;
+      case 'go':
+        result.goVersion = tokens[0];
+        break;
+      case 'toolchain':
+        result.toolchain = tokens[0];
+        break;
+      case 'require':
+        if (tokens.length >= 2) {
+          result.requires.push({
+            path: tokens[0],
+            version: tokens[1],
+            indirect: /^indirect\b/.test(comment) || /;\s*indirect\b/.test(comment),
+            line,
+            column
+          });
+        }
+        break;
+      case 'exclude':
+        if (tokens.length >= 2) {
+          result.excludes.push({ path: tokens[0], version: tokens[1], line });
+        }
+        break;
+      case 'replace': {
+        const arrow = tokens.indexOf('=>');
+        if (arrow === 1 || arrow === 2) {
+          result.replaces.push({
+            path: tokens[0],
+            version: arrow === 2 ? tokens[1] : undefined,
+            target: tokens.slice(arrow + 1).join(' '),
+            line
+          });
+        }
+        break;
+      }
+    }
+  });
+
+  return result;
+}
+
+/**
+ * Parse go.sum into the set of "module@version" entries it has a checksum
+ * for (ignoring the separate /go.mod hashes)
+ */
+export function parseGoSum(content: string): Set<string> {
+  const entries = new Set<string>();
+  for (const line of content.split(/\r?\n/)) {
+    const [modulePath, version] = line.trim().split(/\s+/);
+    if (modulePath && version && !version.endsWith('/go.mod')) {
+      entries.add(`${modulePath}@${version}`);
+    }
+  }
+  return entries;
+}
+
+interface ParsedVersion {
+  numbers: number[];
+  prerelease: string[];
+}
+
+function parseVersion(version: string): ParsedVersion | undefined {
+  const match = /^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$/.exec(version);
+  if (!match) {
+    return undefined;
+  }
+  return {
+    numbers: [Number(match[1]), Number(match[2] ?? 0), Number(match[3] ?? 0)],
+    prerelease: match[4] ? match[4].split('.') : []
+  };
+}
+
+/**
+ * Whether a version is a pre-release, which includes pseudo-versions
+ * such as v0.0.0-20240101120000-abcdef123456
+ */
+export function isPrerelease(version: string): boolean {
+  return (parseVersion(version)?.prerelease.length ?? 0) > 0;
+}
+
+/**
+ * Compare two module versions by semantic versioning precedence. Build
+ * metadata such as +incompatible is ignored; the "v" prefix is optional so
+ * OSV versions ("1.2.3") compare with go.mod versions ("v1.2.3").
+ */
+export function compareGoVersions(a: string, b: string): number {
+  const left = parseVersion(a);
+  const right = parseVersion(b);
+  if (!left || !right) {
+    return left ? 1 : right ? -1 : a.localeCompare(b);
+  }
+
+  for (let i = 0; i < 3; i++) {
+    if (left.numbers[i] !== right.numbers[i]) {
+      return left.numbers[i] - right.numbers[i];
+    }
+  }
+
+  // A release sorts after all of its pre-releases
+  if (left.prerelease.length === 0 || right.prerelease.length === 0) {
+    return right.prerelease.length - left.prerelease.length;
+  }
+  const length = Math.max(left.prerelease.length, right.prerelease.length);
+  for (let i = 0; i < length; i++) {
+    const x = left.prerelease[i];
+    const y = right.prerelease[i];
+    if (x === undefined || y === undefined) {
+      return x === undefined ? -1 : 1;
+    }
+    const xNumeric = /^\d+$/.test(x);
+    const yNumeric = /^\d+$/.test(y);
+    if (xNumeric && yNumeric) {
+      if (Number(x) !== Number(y)) {
+        return Number(x) - Number(y);
+      }
+    } else if (xNumeric !== yNumeric) {
+      return xNumeric ? -1 : 1;
+    } else if (x !== y) {
+      return x < y ? -1 : 1;
+    }
+  }
+  return 0;
+}
+
+/**
+ * Escape a module path the way module proxies and the module cache do:
+ * upper-case letters become "!" followed by the lower-case letter
+ */
+export function escapeModulePath(modulePath: string): string {
+  return modulePath.replace(/[A-Z]/g, letter => `!${letter.toLowerCase()}`);
+}
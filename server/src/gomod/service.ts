@@ -0,0 +1,277 @@
+import * as fs from 'fs/promises';
+import * as os from 'os';
+import * as path from 'path';
+import { fileURLToPath } from 'url';
+import { TaskInfo, TaskManager } from '../tasks/manager.js';
+import { GoRequirement, compareGoVersions, escapeModulePath, isPrerelease, parseGoMod, parseGoSum } from './parser.js';
+import { GoVulnDatabase, GoVulnerability } from './vulndb.js';
+
+export type GoModuleCommand = 'update' | 'tidy' | 'vendor';
+
+export interface GoModulesOptions {
+  // GOPROXY for module commands; defaults to the local module cache
+  proxy?: string;
+  // OSV vulnerability database file
+  vulnDb?: string;
+}
+
+export interface GoDependency {
+  path: string;
+  version: string;
+  indirect: boolean;
+  line: number;
+  // Whether go.sum has a checksum for this version
+  checksum: boolean;
+  // Newest version available from the local proxy, when newer than `version`
+  latest?: string;
+  replace?: string;
+  vulnerabilities: GoVulnerability[];
+}
+
+export interface GoModuleDiagnostic {
+  line: number;
+  column: number;
+  endColumn: number;
+  severity: 'warning' | 'info';
+  message: string;
+  code: string;
+}
+
+export interface GoModule {
+  // Workspace path of the module directory, e.g. / or /services/api
+  path: string;
+  module: string;
+  goVersion?: string;
+  dependencies: GoDependency[];
+  diagnostics: GoModuleDiagnostic[];
+}
+
+// Directories that never contain modules worth listing
+const IGNORED_DIRECTORIES = new Set(['node_modules', 'vendor', 'testdata', 'dist', 'build']);
+
+const COMMAND_ARGS: Record<GoModuleCommand, string[]> = {
+  update: ['get', '-u', './...'],
+  tidy: ['mod', 'tidy'],
+  vendor: ['mod', 'vendor']
+};
+
+/**
+ * Default module proxy: the local module cache, which the go command can
+ * serve from directly, so module commands work without network access
+ */
+export function getDefaultGoProxy(env: NodeJS.ProcessEnv = process.env): string {
+  const modCache = env.GOMODCACHE
+    || path.join((env.GOPATH || path.join(os.homedir(), 'go')).split(path.delimiter)[0], 'pkg', 'mod');
+  return `file://${path.join(modCache, 'cache', 'download')}`;
+}
+
+/**
+ * GoModuleService reads go.mod/go.sum files of the workspace, reports
+ * outdated and vulnerable dependencies and runs module commands. Lookups
+ * only use local data: a file:// module proxy and an offline vulnerability
+ * database.
+ */
+export class GoModuleService {
+  private vulnDb?: GoVulnDatabase;
+  private vulnDbLoaded?: Promise<void>;
+  private proxy: string;
+
+  constructor(
+    private workspaceRoot: string,
+    private taskManager: TaskManager,
+    private options: GoModulesOptions = {}
+  ) {
+    this.proxy = options.proxy || getDefaultGoProxy();
+  }
+
+  /**
+   * List every Go module in the workspace
+   */
+  async listModules(): Promise<GoModule[]> {
+    const directories: string[] = [];
+    await this.findModuleDirectories(path.resolve(this.workspaceRoot), directories);
+
+    const modules: GoModule[] = [];
+    for (const directory of directories.sort()) {
+      try {
+        modules.push(await this.readModule(directory));
+      } catch (error) {
+        console.warn(`[Go Modules] Skipping ${directory}: ${(error as Error).message}`);
+      }
+    }
+    return modules;
+  }
+
+  /**
+   * Read one module by the workspace path of its directory
+   */
+  async getModule(modulePath: string): Promise<GoModule> {
+    return this.readModule(this.resolveModuleDir(modulePath));
+  }
+
+  /**
+   * Run a module command in a module directory and wait for it to finish
+   */
+  async runCommand(
+    modulePath: string,
+    command: GoModuleCommand,
+    dependency?: string
+  ): Promise<{ task: TaskInfo; output: string }> {
+    const cwd = this.resolveModuleDir(modulePath);
+    await fs.access(path.join(cwd, 'go.mod'));
+
+    const args = command === 'update' && dependency ? ['get', '-u', dependency] : COMMAND_ARGS[command];
+    const env: Record<string, string> = { GOPROXY: this.proxy, GOFLAGS: '-mod=mod' };
+    if (this.proxy.startsWith('file://')) {
+      // A local proxy can't be checked against the public checksum database;
+      // existing go.sum entries are still verified
+      env.GOSUMDB = 'off';
+    }
+
+    const started = this.taskManager.run({ label: `go ${args.join(' ')}`, command: 'go', args, cwd, env });
+    const task = await this.taskManager.wait(started.id);
+    return { task, output: this.taskManager.getOutput(started.id) || '' };
+  }
+
+  private async findModuleDirectories(dir: string, result: string[]): Promise<void> {
+    let entries;
+    try {
+      entries = await fs.readdir(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+    if (entries.some(entry => entry.isFile() && entry.name === 'go.mod')) {
+      result.push(dir);
+    }
+    for (const entry of entries) {
+      if (entry.isDirectory() && !entry.name.startsWith('.') && !IGNORED_DIRECTORIES.has(entry.name)) {
+        await this.findModuleDirectories(path.join(dir, entry.name), result);
+      }
+    }
+  }
+
+  private async readModule(dir: string): Promise<GoModule> {
+    const goMod = parseGoMod(await fs.readFile(path.join(dir, 'go.mod'), 'utf-8'));
+    let sums = new Set<string>();
+    try {
+      sums = parseGoSum(await fs.readFile(path.join(dir, 'go.sum'), 'utf-8'));
+    } catch {
+      // A module without dependencies has no go.sum
+    }
+    const vulnDb = await this.loadVulnDb();
+
+    const dependencies: GoDependency[] = [];
+    for (const requirement of goMod.requires) {
+      const replacement = goMod.replaces.find(r => r.path === requirement.path && (!r.version || r.version === requirement.version));
+      const latest = await this.findLatestVersion(requirement.path);
+      dependencies.push({
+        path: requirement.path,
+        version: requirement.version,
+        indirect: requirement.indirect,
+        line: requirement.line,
+        checksum: sums.has(`${requirement.path}@${requirement.version}`),
+        latest: latest && compareGoVersions(latest, requirement.version) > 0 ? latest : undefined,
+        replace: replacement?.target,
+        vulnerabilities: vulnDb?.check(requirement.path, requirement.version) ?? []
+      });
+    }
+
+    const relative = path.relative(path.resolve(this.workspaceRoot), dir).split(path.sep).join('/');
+    return {
+      path: '/' + relative,
+      module: goMod.module ?? '',
+      goVersion: goMod.goVersion,
+      dependencies,
+      diagnostics: this.getDiagnostics(goMod.requires, dependencies)
+    };
+  }
+
+  private getDiagnostics(requires: GoRequirement[], dependencies: GoDependency[]): GoModuleDiagnostic[] {
+    const diagnostics: GoModuleDiagnostic[] = [];
+    dependencies.forEach((dependency, index) => {
+      const { column } = requires[index];
+      const endColumn = column + dependency.path.length;
+      for (const vulnerability of dependency.vulnerabilities) {
+        diagnostics.push({
+          line: dependency.line,
+          column,
+          endColumn,
+          severity: 'warning',
+          code: vulnerability.id,
+          message: `${dependency.path}@${dependency.version} is affected by ${vulnerability.id}`
+            + (vulnerability.summary ? `: ${vulnerability.summary}` : '')
+            + (vulnerability.fixed ? ` (fixed in ${vulnerability.fixed.replace(/^v?/, 'v')})` : ' (no fix available)')
+        });
+      }
+      if (dependency.latest) {
+        diagnostics.push({
+          line: dependency.line,
+          column,
+          endColumn,
+          severity: 'info',
+          code: 'outdated',
+          message: `${dependency.path} can be upgraded to ${dependency.latest}`
+        });
+      }
+    });
+    return diagnostics;
+  }
+
+  /**
+   * Newest version of a module known to a file:// proxy. Pre-releases only
+   * count when no release exists.
+   */
+  private async findLatestVersion(modulePath: string): Promise<string | undefined> {
+    if (!this.proxy.startsWith('file://')) {
+      return undefined;
+    }
+    const versionDir = path.join(fileURLToPath(this.proxy), escapeModulePath(modulePath), '@v');
+
+    const versions = new Set<string>();
+    try {
+      const list = await fs.readFile(path.join(versionDir, 'list'), 'utf-8');
+      list.split(/\r?\n/).map(v => v.trim()).filter(Boolean).forEach(v => versions.add(v));
+    } catch {
+      // The module cache only writes a list when a query needed one
+    }
+    try {
+      for (const name of await fs.readdir(versionDir)) {
+        if (name.endsWith('.info')) {
+          versions.add(name.slice(0, -'.info'.length));
+        }
+      }
+    } catch {
+      return undefined;
+    }
+
+    const sorted = Array.from(versions).sort(compareGoVersions);
+    const releases = sorted.filter(version => !isPrerelease(version));
+    return (releases.length > 0 ? releases : sorted).pop();
+  }
+
+  private loadVulnDb(): Promise<GoVulnDatabase | undefined> {
+    if (!this.options.vulnDb) {
+      return Promise.resolve(undefined);
+    }
+    if (!this.vulnDbLoaded) {
+      this.vulnDbLoaded = GoVulnDatabase.load(this.options.vulnDb)
+        .then((db) => {
+          this.vulnDb = db;
+          console.log(`[Go Modules] Loaded ${db.size} vulnerabilities from ${this.options.vulnDb}`);
+        })
+        .catch((error) => {
+          console.error(`[Go Modules] Failed to load vulnerability database:`, error);
+        });
+    }
+    return this.vulnDbLoaded.then(() => this.vulnDb);
+  }
+
+  private resolveModuleDir(modulePath: string): string {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(root, modulePath.replace(/^[/\\]+/, ''));
+    if (resolved !== root && !resolved.startsWith(root + path.sep)) {
+      throw new Error('Access denied: path outside workspace');
+    }
+    return resolved;
+  }
+}
@@ -0,0 +1,126 @@
+import * as fs from 'fs/promises';
+import { compareGoVersions } from './parser.js';
+
+export interface GoVulnerability {
+  id: string;
+  summary?: string;
+  aliases: string[];
+  // First version without the vulnerability, if one has been released
+  fixed?: string;
+}
+
+// The subset of the OSV format (https://ossf.github.io/osv-schema/) used here
+interface OsvEntry {
+  id: string;
+  summary?: string;
+  details?: string;
+  aliases?: string[];
+  withdrawn?: string;
+  affected?: {
+    package?: { name?: string; ecosystem?: string };
+    ranges?: {
+      type?: string;
+      events?: { introduced?: string; fixed?: string; last_affected?: string }[];
+    }[];
+  }[];
+}
+
+/**
+ * Offline Go vulnerability database: a JSON file holding an array of OSV
+ * entries, e.g. downloaded from https://vuln.go.dev ahead of time.
+ */
+export class GoVulnDatabase {
+  private entriesByModule = new Map<string, OsvEntry[]>();
+
+  constructor(entries: OsvEntry[] = []) {
+    for (const entry of entries) {
+      if (!entry?.id || entry.withdrawn) {
+        continue;
+      }
+      const modules = new Set(
+        (entry.affected ?? [])
+          .filter(affected => (affected.package?.ecosystem ?? 'Go') === 'Go' && affected.package?.name)
+          .map(affected => affected.package!.name!)
+      );
+      for (const modulePath of modules) {
+        const list = this.entriesByModule.get(modulePath) ?? [];
+        list.push(entry);
+        this.entriesByModule.set(modulePath, list);
+      }
+    }
+  }
+
+  /**
+   * Load a database file. Accepts either an array of entries or an object
+   * with an "entries" array.
+   */
+  static async load(filePath: string): Promise<GoVulnDatabase> {
+    const parsed = JSON.parse(await fs.readFile(filePath, 'utf-8'));
+    const entries = Array.isArray(parsed) ? parsed : parsed?.entries;
+    if (!Array.isArray(entries)) {
+      throw new Error(`${filePath} must contain an array of OSV entries`);
+    }
+    return new GoVulnDatabase(entries);
+  }
+
+  get size(): number {
+    return new Set(Array.from(this.entriesByModule.values()).flat().map(entry => entry.id)).size;
+  }
+
+  /**
+   * Vulnerabilities affecting a version of a module
+   */
+  check(modulePath: string, version: string): GoVulnerability[] {
+    const results: GoVulnerability[] = [];
+    for (const entry of this.entriesByModule.get(modulePath) ?? []) {
+      for (const affected of entry.affected ?? []) {
+        if (affected.package?.name !== modulePath) {
+          continue;
+        }
+        const range = (affected.ranges ?? []).find(r => r.type === 'SEMVER' && isAffected(version, r.events ?? []));
+        if (range) {
+          results.push({
+            id: entry.id,
+            summary: entry.summary ?? entry.details?.split('\n')[0],
+            aliases: entry.aliases ?? [],
+            fixed: range.events?.map(event => event.fixed).find(
+              fixed => fixed !== undefined && compareGoVersions(fixed, version) > 0
+            )
+          });
+          break;
+        }
+      }
+    }
+    return results;
+  }
+}
+
+/**
+ * Evaluate an OSV SEMVER range: events are applied in version order, with
+ * "introduced" opening and "fixed"/"last_affected" closing an affected span
+ */
+function isAffected(
+  version: string,
+  events: { introduced?: string; fixed?: string; last_affected?: string }[]
+): boolean {
+  const eventVersion = (event: (typeof events)[number]) => event.introduced ?? event.fixed ?? event.last_affected ?? '0';
+  const sorted = [...events].sort((a, b) => compareGoVersions(eventVersion(a), eventVersion(b)));
+
+  let affected = false;
+  for (const event of sorted) {
+    if (event.introduced !== undefined) {
+      if (event.introduced === '0' || compareGoVersions(version, event.introduced) >= 0) {
+        affected = true;
+      }
+    } else if (event.fixed !== undefined) {
+      if (compareGoVersions(version, event.fixed) >= 0) {
+        affected = false;
+      }
+    } else if (event.last_affected !== undefined) {
+      if (compareGoVersions(version, event.last_affected) > 0) {
+        affected = false;
+      }
+    }
+  }
+  return affected;
+}
@@ -0,0 +1,166 @@
+export type LinterFormat = 'golangci-lint' | 'eslint';
+
+/**
+ * A text edit; lines and columns are 1-based and the end is exclusive
+ */
+export interface LintEdit {
+  startLine: number;
+  startColumn: number;
+  endLine: number;
+  endColumn: number;
+  text: string;
+}
+
+export interface LintFix {
+  title: string;
+  edits: LintEdit[];
+}
+
+/**
+ * A problem reported by a linter. `file` is the name as reported (absolute
+ * or relative to where the linter ran); the service maps it to a
+ * workspace path.
+ */
+export interface LintProblem {
+  file: string;
+  line: number;
+  column: number;
+  // Unset when the linter reports a position only
+  endLine?: number;
+  endColumn?: number;
+  severity: 'error' | 'warning' | 'info';
+  message: string;
+  code?: string;
+  fixes: LintFix[];
+}
+
+/**
+ * Convert an offset into a 1-based line and column
+ */
+function offsetToPosition(text: string, offset: number): { line: number; column: number } {
+  let line = 1;
+  let lineStart = 0;
+  for (let i = 0; i < offset && i < text.length; i++) {
+    if (text[i] === '\n') {
+      line++;
+      lineStart = i + 1;
+    }
+  }
+  return { line, column: Math.min(offset, text.length) - lineStart + 1 };
+}
+
+function rangeFix(title: string, text: string, fix: any): LintFix | undefined {
+  if (!Array.isArray(fix?.range) || typeof fix.text !== 'string') {
+    return undefined;
+  }
+  const start = offsetToPosition(text, fix.range[0]);
+  const end = offsetToPosition(text, fix.range[1]);
+  return {
+    title,
+    edits: [{ startLine: start.line, startColumn: start.column, endLine: end.line, endColumn: end.column, text: fix.text }]
+  };
+}
+
+/**
+ * Parse `eslint --format json` output. Fixes are character ranges into the
+ * linted source, so `readSource` supplies the text they refer to.
+ */
+export async function parseEslintOutput(
+  output: string,
+  readSource: (file: string) => Promise<string | undefined>
+): Promise<LintProblem[]> {
+  const results = JSON.parse(output);
+  if (!Array.isArray(results)) {
+    throw new Error('Expected an array of results');
+  }
+  const problems: LintProblem[] = [];
+  for (const result of results) {
+    if (typeof result?.filePath !== 'string' || !Array.isArray(result.messages) || result.messages.length === 0) {
+      continue;
+    }
+    const needsSource = result.messages.some((m: any) => m.fix || m.suggestions?.length);
+    const source = needsSource ? (typeof result.source === 'string' ? result.source : await readSource(result.filePath)) : undefined;
+
+    for (const message of result.messages) {
+      const line = Math.max(1, message.line ?? 1);
+      const column = Math.max(1, message.column ?? 1);
+      const fixes: LintFix[] = [];
+      if (source !== undefined) {
+        const rule = message.ruleId ? ` (${message.ruleId})` : '';
+        const fix = message.fix && rangeFix(`Fix: ${message.message}${rule}`, source, message.fix);
+        if (fix) {
+          fixes.push(fix);
+        }
+        for (const suggestion of message.suggestions ?? []) {
+          const suggested = rangeFix(suggestion.desc ?? 'Apply suggestion', source, suggestion.fix);
+          if (suggested) {
+            fixes.push(suggested);
+          }
+        }
+      }
+      problems.push({
+        file: result.filePath,
+        line,
+        column,
+        endLine: message.endLine,
+        endColumn: message.endColumn,
+        severity: message.severity === 2 ? 'error' : 'warning',
+        message: message.message,
+        code: message.ruleId ?? undefined,
+        fixes
+      });
+    }
+  }
+  return problems;
+}
+
+/**
+ * Parse golangci-lint JSON output ({ "Issues": [...] }), including the
+ * suggested replacements some linters attach to an issue
+ */
+export function parseGolangciLintOutput(output: string): LintProblem[] {
+  // Some versions print a summary after the JSON line
+  const json = output.split('\n').find(line => line.trimStart().startsWith('{')) ?? output;
+  const report = JSON.parse(json);
+  const issues = Array.isArray(report?.Issues) ? report.Issues : [];
+  const problems: LintProblem[] = [];
+  for (const issue of issues) {
+    const pos = issue?.Pos;
+    if (typeof pos?.Filename !== 'string' || typeof pos.Line !== 'number') {
+      continue;
+    }
+    const line = Math.max(1, pos.Line);
+    const column = Math.max(1, pos.Column ?? 1);
+    const from = issue.LineRange?.From ?? line;
+    const to = issue.LineRange?.To ?? from;
+    const replacement = issue.Replacement;
+    const title = `Fix: ${issue.Text} (${issue.FromLinter})`;
+    const fixes: LintFix[] = [];
+
+    if (replacement?.Inline) {
+      const { StartCol, Length, NewString } = replacement.Inline;
+      fixes.push({
+        title,
+        edits: [{ startLine: line, startColumn: StartCol + 1, endLine: line, endColumn: StartCol + Length + 1, text: NewString ?? '' }]
+      });
+    } else if (replacement?.NeedOnlyDelete) {
+      fixes.push({ title, edits: [{ startLine: from, startColumn: 1, endLine: to + 1, endColumn: 1, text: '' }] });
+    } else if (Array.isArray(replacement?.NewLines)) {
+      fixes.push({
+        title,
+        edits: [{ startLine: from, startColumn: 1, endLine: to + 1, endColumn: 1, text: replacement.NewLines.map((l: string) => `${l}\n`).join('') }]
+      });
+    }
+
+    problems.push({
+      file: pos.Filename,
+      line,
+      column,
+      severity: issue.Severity === 'error' ? 'error' : issue.Severity === 'info' ? 'info' : 'warning',
+      message: issue.Text,
+      code: issue.FromLinter,
+      fixes
+    });
+  }
+  return problems;
+}
@@ -0,0 +1,334 @@
+import { execFile } from 'child_process';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { LintFix, LintProblem, LinterFormat, parseEslintOutput, parseGolangciLintOutput } from './parsers.js';
+
+export interface LinterConfig {
+  // Unique name, shown as the source of its diagnostics
+  name: string;
+  format: LinterFormat;
+  // Defaults depend on the format; ${file} and ${dir} in args are replaced
+  // by the linted file and its directory, relative to where the linter runs
+  command?: string;
+  args?: string[];
+  fileExtensions?: string[];
+  // Lint a file whenever it is saved (default true)
+  runOnSave?: boolean;
+}
+
+export interface LintDiagnostic {
+  path: string;
+  line: number;
+  column: number;
+  endLine: number;
+  endColumn: number;
+  severity: 'error' | 'warning' | 'info';
+  message: string;
+  code?: string;
+  // Name of the linter
+  source: string;
+  fixes: LintFix[];
+}
+
+export interface LintResult {
+  path: string;
+  // Linters that ran for the file
+  linters: string[];
+  diagnostics: LintDiagnostic[];
+  // Linters that could not run or whose output couldn't be read
+  errors: string[];
+}
+
+interface LinterPreset {
+  command: string;
+  args: string[];
+  fileExtensions: string[];
+  // The linter runs in the nearest directory containing one of these
+  rootMarkers: string[];
+}
+
+const PRESETS: Record<LinterFormat, LinterPreset> = {
+  'golangci-lint': {
+    command: 'golangci-lint',
+    args: ['run', '--output.json.path=stdout', '--show-stats=false', '--issues-exit-code=0', '${dir}'],
+    fileExtensions: ['.go'],
+    rootMarkers: ['go.mod']
+  },
+  eslint: {
+    command: 'eslint',
+    args: ['--format', 'json', '${file}'],
+    fileExtensions: ['.js', '.jsx', '.mjs', '.cjs', '.ts', '.tsx', '.mts', '.cts'],
+    rootMarkers: ['package.json']
+  }
+};
+
+// Linters read whole packages; give them time but not forever
+const LINT_TIMEOUT_MS = 120_000;
+const MAX_OUTPUT_BYTES = 32 * 1024 * 1024;
+
+type DiagnosticsListener = (filePath: string, diagnostics: LintDiagnostic[]) => void;
+
+/**
+ * LinterService runs external linters such as golangci-lint and eslint on
+ * workspace files and keeps their latest diagnostics, separately from the
+ * language servers'
+ */
+export class LinterService {
+  // Diagnostics per linter, then per workspace path
+  private results: Map<string, Map<string, LintDiagnostic[]>> = new Map();
+  private listeners: DiagnosticsListener[] = [];
+  // Runs in progress per linter and directory; saves during a run queue one rerun
+  private running: Map<string, { pending: boolean; result: Promise<LintResult> }> = new Map();
+
+  constructor(
+    private workspaceRoot: string,
+    private linters: LinterConfig[] = []
+  ) {}
+
+  /**
+   * Names of the configured linters
+   */
+  getLinters(): string[] {
+    return this.linters.map(linter => linter.name);
+  }
+
+  /**
+   * Latest diagnostics of every file with problems
+   */
+  listDiagnostics(): { path: string; diagnostics: LintDiagnostic[] }[] {
+    const paths = new Set<string>();
+    this.results.forEach(byPath => byPath.forEach((_, filePath) => paths.add(filePath)));
+    return Array.from(paths)
+      .sort()
+      .map(filePath => ({ path: filePath, diagnostics: this.getDiagnostics(filePath) }))
+      .filter(entry => entry.diagnostics.length > 0);
+  }
+
+  getDiagnostics(filePath: string): LintDiagnostic[] {
+    const diagnostics: LintDiagnostic[] = [];
+    this.results.forEach(byPath => diagnostics.push(...(byPath.get(filePath) ?? [])));
+    return diagnostics.sort((a, b) => a.line - b.line || a.column - b.column);
+  }
+
+  /**
+   * Lint a file with every linter configured for its extension
+   */
+  async lint(filePath: string, options: { onSave?: boolean } = {}): Promise<LintResult> {
+    const absolute = this.resolveWorkspacePath(filePath);
+    const workspacePath = this.toWorkspacePath(absolute)!;
+    const linters = this.linters.filter(linter =>
+      this.getFileExtensions(linter).includes(path.extname(absolute)) &&
+      (!options.onSave || linter.runOnSave !== false)
+    );
+
+    const errors: string[] = [];
+    for (const linter of linters) {
+      try {
+        await this.runQueued(linter, absolute);
+      } catch (error) {
+        errors.push(`${linter.name}: ${(error as Error).message}`);
+      }
+    }
+    return { path: workspacePath, linters: linters.map(l => l.name), diagnostics: this.getDiagnostics(workspacePath), errors };
+  }
+
+  /**
+   * Register a listener for changed diagnostics of a file
+   */
+  onDiagnostics(listener: DiagnosticsListener): void {
+    this.listeners.push(listener);
+  }
+
+  private async runQueued(linter: LinterConfig, file: string): Promise<LintResult> {
+    const key = `${linter.name}\0${this.usesDirectory(linter) ? path.dirname(file) : file}`;
+    const current = this.running.get(key);
+    if (current) {
+      // The file changed since this run started; lint once more afterwards
+      current.pending = true;
+      return current.result;
+    }
+
+    let settle!: { resolve: (result: LintResult) => void; reject: (error: unknown) => void };
+    const entry = {
+      pending: false,
+      result: new Promise<LintResult>((resolve, reject) => {
+        settle = { resolve, reject };
+      })
+    };
+    this.running.set(key, entry);
+    try {
+      let result: LintResult;
+      do {
+        entry.pending = false;
+        result = await this.run(linter, file);
+      } while (entry.pending);
+      settle.resolve(result);
+    } catch (error) {
+      settle.reject(error);
+    } finally {
+      this.running.delete(key);
+    }
+    return entry.result;
+  }
+
+  private async run(linter: LinterConfig, file: string): Promise<LintResult> {
+    const preset = PRESETS[linter.format];
+    const cwd = await this.findRoot(path.dirname(file), preset.rootMarkers);
+    const relativeFile = path.relative(cwd, file);
+    const relativeDir = path.relative(cwd, path.dirname(file));
+    const args = (linter.args ?? preset.args).map(arg => arg
+      .replace(/\$\{file\}/g, relativeFile)
+      .replace(/\$\{dir\}/g, relativeDir ? `./${relativeDir.split(path.sep).join('/')}` : '.'));
+    const command = await this.resolveCommand(linter.command ?? preset.command, cwd);
+
+    const stdout = await new Promise<string>((resolve, reject) => {
+      execFile(command, args, { cwd, timeout: LINT_TIMEOUT_MS, maxBuffer: MAX_OUTPUT_BYTES }, (error, out, stderr) => {
+        // Linters exit non-zero when they find problems; only a missing or
+        // crashed linter leaves stdout empty
+        if (error && !out.trim()) {
+          const code = (error as NodeJS.ErrnoException).code;
+          reject(new Error(code === 'ENOENT' ? `command not found: ${command}` : (stderr.trim() || error.message).split('\n')[0]));
+          return;
+        }
+        resolve(out);
+      });
+    });
+
+    let problems: LintProblem[];
+    try {
+      problems = linter.format === 'eslint'
+        ? await parseEslintOutput(stdout, name => fs.readFile(path.resolve(cwd, name), 'utf-8').catch(() => undefined))
+        : parseGolangciLintOutput(stdout);
+    } catch (error) {
+      throw new Error(`unreadable output: ${(error as Error).message}`);
+    }
+
+    const filePath = this.toWorkspacePath(file)!;
+    const byPath = new Map<string, LintDiagnostic[]>();
+    for (const problem of problems) {
+      const problemPath = this.toWorkspacePath(path.resolve(cwd, problem.file));
+      if (!problemPath) {
+        continue;
+      }
+      const diagnostics = byPath.get(problemPath) ?? [];
+      diagnostics.push(await this.toDiagnostic(linter, problemPath, problem));
+      byPath.set(problemPath, diagnostics);
+    }
+
+    // Replace what this linter reported for the files it just looked at
+    const inScope = this.usesDirectory(linter)
+      ? (p: string) => path.posix.dirname(p) === path.posix.dirname(filePath)
+      : (p: string) => p === filePath;
+    const previous = this.results.get(linter.name) ?? new Map<string, LintDiagnostic[]>();
+    const changed = new Set<string>([filePath, ...byPath.keys()]);
+    for (const previousPath of previous.keys()) {
+      if (inScope(previousPath)) {
+        changed.add(previousPath);
+        previous.delete(previousPath);
+      }
+    }
+    byPath.forEach((diagnostics, problemPath) => previous.set(problemPath, diagnostics));
+    this.results.set(linter.name, previous);
+
+    for (const changedPath of changed) {
+      const diagnostics = this.getDiagnostics(changedPath);
+      this.listeners.forEach(listener => listener(changedPath, diagnostics));
+    }
+    console.log(`[Linters] ${linter.name} reported ${problems.length} problem(s) for ${filePath}`);
+    return { path: filePath, linters: [linter.name], diagnostics: this.getDiagnostics(filePath), errors: [] };
+  }
+
+  /**
+   * Fill in the end of problems reported as a position only, underlining
+   * the rest of the line
+   */
+  private async toDiagnostic(linter: LinterConfig, filePath: string, problem: LintProblem): Promise<LintDiagnostic> {
+    let { endLine, endColumn } = problem;
+    if (endLine === undefined || endColumn === undefined) {
+      const content = await fs.readFile(this.resolveWorkspacePath(filePath), 'utf-8').catch(() => '');
+      const lineText = content.split('\n')[problem.line - 1] ?? '';
+      endLine = problem.line;
+      endColumn = Math.max(problem.column + 1, lineText.replace(/\r$/, '').length + 1);
+    }
+    return {
+      path: filePath,
+      line: problem.line,
+      column: problem.column,
+      endLine,
+      endColumn,
+      severity: problem.severity,
+      message: problem.message,
+      code: problem.code,
+      source: linter.name,
+      fixes: problem.fixes
+    };
+  }
+
+  private usesDirectory(linter: LinterConfig): boolean {
+    return (linter.args ?? PRESETS[linter.format].args).some(arg => arg.includes('${dir}'));
+  }
+
+  private getFileExtensions(linter: LinterConfig): string[] {
+    return linter.fileExtensions ?? PRESETS[linter.format].fileExtensions;
+  }
+
+  /**
+   * Nearest directory with one of the markers, up to the workspace root
+   */
+  private async findRoot(dir: string, markers: string[]): Promise<string> {
+    const root = path.resolve(this.workspaceRoot);
+    for (let current = dir; ; current = path.dirname(current)) {
+      for (const marker of markers) {
+        try {
+          await fs.access(path.join(current, marker));
+          return current;
+        } catch {
+          // Keep looking
+        }
+      }
+      if (current === root || path.dirname(current) === current) {
+        return root;
+      }
+    }
+  }
+
+  /**
+   * Prefer a project-local binary (node_modules/.bin) over one on the PATH
+   */
+  private async resolveCommand(command: string, cwd: string): Promise<string> {
+    if (command.includes('/') || command.includes(path.sep)) {
+      return command;
+    }
+    const root = path.resolve(this.workspaceRoot);
+    for (let current = cwd; ; current = path.dirname(current)) {
+      const local = path.join(current, 'node_modules', '.bin', command);
+      try {
+        await fs.access(local);
+        return local;
+      } catch {
+        // Not installed here
+      }
+      if (current === root || path.dirname(current) === current) {
+        return command;
+      }
+    }
+  }
+
+  private toWorkspacePath(absolute: string): string | undefined {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(absolute);
+    if (!resolved.startsWith(root + path.sep)) {
+      return undefined;
+    }
+    return '/' + path.relative(root, resolved).split(path.sep).join('/');
+  }
+
+  private resolveWorkspacePath(workspacePath: string): string {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(root, workspacePath.replace(/^[/\\]+/, ''));
+    if (!resolved.startsWith(root + path.sep)) {
+      throw new Error('Access denied: path outside workspace');
+    }
+    return resolved;
+  }
+}
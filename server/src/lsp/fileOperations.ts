@@ -0,0 +1,397 @@
+import * as fs from 'fs/promises';
+import { UriMapper, WORKSPACE_URI_SCHEME, workspacePathToUri } from '../fs/uris.js';
+
+export type FileOperationKind = 'create' | 'rename' | 'delete';
+
+export interface FileOperationFilter {
+  scheme?: string;
+  pattern: {
+    glob: string;
+    matches?: 'file' | 'folder';
+    options?: { ignoreCase?: boolean };
+  };
+}
+
+export interface TextEdit {
+  range: {
+    start: { line: number; character: number };
+    end: { line: number; character: number };
+  };
+  newText: string;
+}
+
+export interface WorkspaceEdit {
+  changes?: Record<string, TextEdit[]>;
+  documentChanges?: Array<
+    | { textDocument: { uri: string }; edits: TextEdit[] }
+    | { kind: string }
+  >;
+}
+
+/**
+ * A running language server, as far as file operations are concerned
+ */
+export interface FileOperationServer {
+  languageId: string;
+  capabilities: Record<string, any>;
+  request(method: string, params: unknown): Promise<unknown>;
+  notify(method: string, params: unknown): Promise<void>;
+}
+
+export interface FileOperationServerSource {
+  getFileOperationServers(): FileOperationServer[];
+}
+
+/**
+ * Which clients have a workspace file open, so edits to it go to their
+ * editors instead of the file on disk
+ */
+export interface OpenDocumentSource {
+  getClientsWithOpenDocument(workspacePath: string): string[];
+  // Follow a rename, so the clients' open documents are known by their new path
+  renameOpenDocuments(oldPath: string, newPath: string): void;
+}
+
+/**
+ * Edits a server made to a file open in a client's editor
+ */
+export interface OpenDocumentEdit {
+  path: string;
+  edits: TextEdit[];
+}
+
+/**
+ * A file a server edited ahead of an operation; files open in an editor are
+ * edited there, so only the others are written to disk
+ */
+export interface EditedFile {
+  path: string;
+  written: boolean;
+}
+
+export interface RenamedPath {
+  oldPath: string;
+  newPath: string;
+}
+
+const METHOD_NAMES: Record<FileOperationKind, string> = {
+  create: 'CreateFiles',
+  rename: 'RenameFiles',
+  delete: 'DeleteFiles'
+};
+
+// Servers that take longer than this to answer a will* request are skipped,
+// so a slow server can't hold up the operation
+const WILL_REQUEST_TIMEOUT_MS = 5000;
+
+/**
+ * Translate an LSP glob pattern (`**`, `*`, `?`, `{a,b}`, `[a-z]`) into a
+ * regular expression matched against the whole path
+ */
+export function globToRegExp(glob: string, ignoreCase = false): RegExp {
+  let source = '';
+  let inGroup = false;
+  for (let i = 0; i < glob.length; i++) {
+    const char = glob[i];
+    if (char === '*') {
+      if (glob[i + 1] === '*') {
+        // `**/` also matches no folder at all
+        if (glob[i + 2] === '/') {
+          source += '(?:.*/)?';
+          i += 2;
+        } else {
+          source += '.*';
+          i += 1;
+        }
+      } else {
+        source += '[^/]*';
+      }
+    } else if (char === '?') {
+      source += '[^/]';
+    } else if (char === '{') {
+      inGroup = true;
+      source += '(?:';
+    } else if (char === '}' && inGroup) {
+      inGroup = false;
+      source += ')';
+    } else if (char === ',' && inGroup) {
+      source += '|';
+    } else if (char === '[') {
+      const end = glob.indexOf(']', i + 1);
+      if (end === -1) {
+        source += '\\[';
+      } else {
+        const body = glob.slice(i + 1, end).replace(/^!/, '^').replace(/\\/g, '\\\\');
+        source += `[${body}]`;
+        i = end;
+      }
+    } else {
+      source += char.replace(/[.+^$()|\\\]]/g, '\\$&');
+    }
+  }
+  return new RegExp(`^${source}$`, ignoreCase ? 'i' : '');
+}
+
+/**
+ * Whether a file URI passes a server's file operation filters
+ */
+export function matchesFileOperationFilters(
+  filters: FileOperationFilter[],
+  uri: string,
+  isFolder: boolean
+): boolean {
+  const scheme = uri.slice(0, uri.indexOf(':'));
+  // Globs match the path itself, not its percent-encoded form
+  const filePath = decodeURIComponent(uri.replace(/^file:\/\//, ''));
+  return filters.some(filter => {
+    if (filter.scheme && filter.scheme !== scheme) {
+      return false;
+    }
+    if (filter.pattern.matches === 'file' && isFolder) return false;
+    if (filter.pattern.matches === 'folder' && !isFolder) return false;
+    return globToRegExp(filter.pattern.glob, filter.pattern.options?.ignoreCase).test(filePath);
+  });
+}
+
+/**
+ * Apply LSP text edits to a document's content
+ */
+export function applyTextEdits(content: string, edits: TextEdit[]): string {
+  const lineStarts = [0];
+  for (let i = 0; i < content.length; i++) {
+    if (content[i] === '\n') lineStarts.push(i + 1);
+  }
+  const offsetAt = ({ line, character }: { line: number; character: number }) => {
+    if (line >= lineStarts.length) return content.length;
+    const lineEnd = line + 1 < lineStarts.length ? lineStarts[line + 1] - 1 : content.length;
+    return Math.min(lineStarts[line] + character, lineEnd);
+  };
+
+  // Apply from the end so earlier offsets stay valid
+  const sorted = edits
+    .map((edit, index) => ({ start: offsetAt(edit.range.start), end: offsetAt(edit.range.end), text: edit.newText, index }))
+    .sort((a, b) => b.start - a.start || b.index - a.index);
+  let result = content;
+  for (const edit of sorted) {
+    result = result.slice(0, edit.start) + edit.text + result.slice(edit.end);
+  }
+  return result;
+}
+
+/**
+ * Sends workspace/will* requests and did* notifications to the language
+ * servers interested in files being created, renamed or deleted, and applies
+ * the edits they return (e.g. updated imports) before the operation happens
+ */
+export class FileOperations {
+  private editListeners: Array<(paths: string[]) => void> = [];
+  private openEditListeners: Array<(clientId: string, edit: OpenDocumentEdit) => void> = [];
+
+  constructor(
+    private uris: UriMapper,
+    private servers: FileOperationServerSource,
+    private documents?: OpenDocumentSource
+  ) {}
+
+  /**
+   * Register a listener for files changed on disk by edits servers returned
+   */
+  onDidEditFiles(listener: (paths: string[]) => void): void {
+    this.editListeners.push(listener);
+  }
+
+  /**
+   * Register a listener for edits to files open in a client's editor; the
+   * client applies them to its buffer, which may have unsaved changes
+   */
+  onDidEditOpenDocument(listener: (clientId: string, edit: OpenDocumentEdit) => void): void {
+    this.openEditListeners.push(listener);
+  }
+
+  async willCreate(paths: string[]): Promise<EditedFile[]> {
+    return this.will('create', paths.map(p => ({ path: p })));
+  }
+
+  async didCreate(paths: string[]): Promise<void> {
+    await this.did('create', paths.map(p => ({ path: p })));
+  }
+
+  async willRename(renames: RenamedPath[]): Promise<EditedFile[]> {
+    return this.will('rename', renames.map(r => ({ path: r.oldPath, newPath: r.newPath })));
+  }
+
+  async didRename(renames: RenamedPath[]): Promise<void> {
+    // Before anything else, so edits to open documents sent ahead of the
+    // rename can't recreate the old paths
+    renames.forEach(r => this.documents?.renameOpenDocuments(r.oldPath, r.newPath));
+    // The paths have moved by now, so folders are recognised at their new location
+    await this.did('rename', renames.map(r => ({ path: r.oldPath, newPath: r.newPath })));
+  }
+
+  async willDelete(paths: string[]): Promise<EditedFile[]> {
+    return this.will('delete', paths.map(p => ({ path: p })));
+  }
+
+  async didDelete(paths: string[], folders: string[] = []): Promise<void> {
+    await this.did('delete', paths.map(p => ({ path: p })), new Set(folders));
+  }
+
+  /**
+   * Ask interested servers for edits to make before the operation and apply
+   * them, to the editors that have a file open or else to disk. Returns the
+   * files that changed.
+   */
+  private async will(
+    kind: FileOperationKind,
+    files: Array<{ path: string; newPath?: string }>
+  ): Promise<EditedFile[]> {
+    const method = `workspace/will${METHOD_NAMES[kind]}`;
+    const edits: WorkspaceEdit[] = [];
+    for (const server of this.servers.getFileOperationServers()) {
+      const params = await this.paramsFor(server, kind, 'will', files);
+      if (!params) continue;
+      let timer: NodeJS.Timeout | undefined;
+      try {
+        const edit = await Promise.race([
+          server.request(method, params),
+          new Promise<undefined>(resolve => { timer = setTimeout(resolve, WILL_REQUEST_TIMEOUT_MS); })
+        ]);
+        if (edit) edits.push(edit as WorkspaceEdit);
+      } catch (error) {
+        console.error(`[File Operations] ${method} failed for ${server.languageId}:`, error);
+      } finally {
+        clearTimeout(timer);
+      }
+    }
+
+    const edited = new Set<string>();
+    const written = new Set<string>();
+    for (const edit of edits) {
+      const result = await this.applyWorkspaceEdit(edit);
+      result.edited.forEach(p => edited.add(p));
+      result.written.forEach(p => written.add(p));
+    }
+    if (written.size > 0) {
+      const paths = Array.from(written);
+      this.editListeners.forEach(listener => listener(paths));
+    }
+    return Array.from(edited, p => ({ path: p, written: written.has(p) }));
+  }
+
+  private async did(
+    kind: FileOperationKind,
+    files: Array<{ path: string; newPath?: string }>,
+    folders?: Set<string>
+  ): Promise<void> {
+    const method = `workspace/did${METHOD_NAMES[kind]}`;
+    for (const server of this.servers.getFileOperationServers()) {
+      const params = await this.paramsFor(server, kind, 'did', files, folders);
+      if (!params) continue;
+      try {
+        await server.notify(method, params);
+      } catch (error) {
+        console.error(`[File Operations] ${method} failed for ${server.languageId}:`, error);
+      }
+    }
+  }
+
+  /**
+   * Build the request params holding the files a server registered interest
+   * in, or undefined if there are none
+   */
+  private async paramsFor(
+    server: FileOperationServer,
+    kind: FileOperationKind,
+    stage: 'will' | 'did',
+    files: Array<{ path: string; newPath?: string }>,
+    folders?: Set<string>
+  ): Promise<{ files: Array<Record<string, string>> } | undefined> {
+    const capability = `${stage}${kind[0].toUpperCase()}${kind.slice(1)}`;
+    const filters: FileOperationFilter[] | undefined =
+      server.capabilities.workspace?.fileOperations?.[capability]?.filters;
+    if (!filters || filters.length === 0) {
+      return undefined;
+    }
+
+    const matched: Array<Record<string, string>> = [];
+    for (const file of files) {
+      const uri = this.toUri(file.path);
+      // Before a create, and after a delete, there is nothing on disk to stat
+      const existing = kind === 'rename' && stage === 'did' ? file.newPath! : file.path;
+      const isFolder = folders ? folders.has(file.path) : await this.isFolder(existing);
+      if (!matchesFileOperationFilters(filters, uri, isFolder)) {
+        continue;
+      }
+      matched.push(file.newPath !== undefined
+        ? { oldUri: uri, newUri: this.toUri(file.newPath) }
+        : { uri });
+    }
+    return matched.length > 0 ? { files: matched } : undefined;
+  }
+
+  /**
+   * Apply the text edits of a workspace edit. Servers compute edits against
+   * the buffers clients have open, so those go to the clients; files nobody
+   * has open are edited on disk. Resource operations are not supported and
+   * are skipped.
+   */
+  private async applyWorkspaceEdit(edit: WorkspaceEdit): Promise<{ edited: string[]; written: string[] }> {
+    const editsByUri = new Map<string, TextEdit[]>();
+    for (const [uri, edits] of Object.entries(edit.changes ?? {})) {
+      editsByUri.set(uri, [...(editsByUri.get(uri) ?? []), ...edits]);
+    }
+    for (const change of edit.documentChanges ?? []) {
+      if ('textDocument' in change) {
+        const uri = change.textDocument.uri;
+        editsByUri.set(uri, [...(editsByUri.get(uri) ?? []), ...change.edits]);
+      } else {
+        console.warn(`[File Operations] Skipping unsupported ${change.kind} operation in workspace edit`);
+      }
+    }
+
+    const edited: string[] = [];
+    const written: string[] = [];
+    for (const [uri, edits] of editsByUri) {
+      // Only workspace files are edited, never dependencies or untitled buffers
+      const clientUri = this.uris.toClientUri(uri);
+      if (!clientUri.startsWith(`${WORKSPACE_URI_SCHEME}:`) || edits.length === 0) {
+        continue;
+      }
+      const workspacePath = this.uris.toWorkspacePath(clientUri);
+      const clients = this.documents?.getClientsWithOpenDocument(workspacePath) ?? [];
+      if (clients.length > 0) {
+        for (const clientId of clients) {
+          this.openEditListeners.forEach(listener => listener(clientId, { path: workspacePath, edits }));
+        }
+        edited.push(workspacePath);
+        continue;
+      }
+      const filePath = this.uris.toDiskPath(clientUri);
+      try {
+        const content = await fs.readFile(filePath, 'utf-8');
+        await fs.writeFile(filePath, applyTextEdits(content, edits), 'utf-8');
+        edited.push(workspacePath);
+        written.push(workspacePath);
+      } catch (error) {
+        console.error(`[File Operations] Failed to apply edits to ${uri}:`, error);
+      }
+    }
+    return { edited, written };
+  }
+
+  /**
+   * Whether a workspace path is a folder; deletes need to know before the
+   * folder is gone
+   */
+  async isFolder(workspacePath: string): Promise<boolean> {
+    try {
+      return (await fs.stat(this.uris.toDiskPath(workspacePathToUri(workspacePath)))).isDirectory();
+    } catch {
+      return false;
+    }
+  }
+
+  private toUri(workspacePath: string): string {
+    return this.uris.toServerUri(workspacePathToUri(workspacePath));
+  }
+}
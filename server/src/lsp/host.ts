@@ -1,14 +1,23 @@
 import { IHost, IWindow, IWorkspace, IConfiguration } from '@lewin671/lsp-client';
-import { MessageType, MessageActionItem, Diagnostic } from 'vscode-languageserver-protocol';
+import {
+  MessageType,
+  MessageActionItem,
+  Diagnostic,
+  ShowDocumentParams,
+  ShowDocumentResult
+} from 'vscode-languageserver-protocol';
 import { WebSocket } from 'ws';
 
+export type DiagnosticsListener = (uri: string, diagnostics: Diagnostic[]) => void;
+
 /**
  * Server Window implementation that forwards messages through WebSocket
  */
 export class ServerWindow implements IWindow {
   constructor(
     private wsConnection?: WebSocket,
-    private mapUri?: (uri: string) => string
+    private mapUri?: (uri: string) => string,
+    private onDiagnostics?: DiagnosticsListener
   ) {}
 
   /**
@@ -52,6 +61,27 @@ export class ServerWindow implements IWindow {
     return undefined;
   }
 
+  /**
+   * Ask the browser to open a document or an external URL. Files the
+   * browser can't open (outside the workspace and the read-only roots) fail.
+   */
+  showDocument(params: ShowDocumentParams): ShowDocumentResult {
+    const uri = params.external || !this.mapUri ? params.uri : this.mapUri(params.uri);
+    if (!params.external && uri.startsWith('file:')) {
+      console.log(`[Window] Can't show document outside the workspace: ${params.uri}`);
+      return { success: false };
+    }
+    if (!this.wsConnection || this.wsConnection.readyState !== WebSocket.OPEN) {
+      return { success: false };
+    }
+    this.wsConnection.send(JSON.stringify({
+      jsonrpc: '2.0',
+      method: 'window/showDocument',
+      params: { ...params, uri }
+    }));
+    return { success: true };
+  }
+
   logMessage(type: MessageType, message: string): void {
     const level = type === MessageType.Error ? 'ERROR' :
                   type === MessageType.Warning ? 'WARNING' :
@@ -61,6 +91,7 @@ export class ServerWindow implements IWindow {
 
   publishDiagnostics(uri: string, diagnostics: Diagnostic[]): void {
     const targetUri = this.mapUri ? this.mapUri(uri) : uri;
+    this.onDiagnostics?.(targetUri, diagnostics);
     if (this.wsConnection && this.wsConnection.readyState === WebSocket.OPEN) {
       this.wsConnection.send(JSON.stringify({
         jsonrpc: '2.0',
@@ -77,7 +108,10 @@ export class ServerWindow implements IWindow {
  * Console-only Window implementation for when no WebSocket is available
  */
 export class ConsoleWindow implements IWindow {
-  constructor(private mapUri?: (uri: string) => string) {}
+  constructor(
+    private mapUri?: (uri: string) => string,
+    private onDiagnostics?: DiagnosticsListener
+  ) {}
 
   showMessage(type: MessageType, message: string): void {
     const typeStr = type === MessageType.Error ? 'ERROR' :
@@ -98,6 +132,11 @@ export class ConsoleWindow implements IWindow {
     return undefined;
   }
 
+  showDocument(params: ShowDocumentParams): ShowDocumentResult {
+    console.log(`[ShowDocument] ${params.uri}`);
+    return { success: false };
+  }
+
   logMessage(type: MessageType, message: string): void {
     const level = type === MessageType.Error ? 'ERROR' :
                   type === MessageType.Warning ? 'WARNING' :
@@ -107,6 +146,7 @@ export class ConsoleWindow implements IWindow {
 
   publishDiagnostics(uri: string, diagnostics: Diagnostic[]): void {
     const targetUri = this.mapUri ? this.mapUri(uri) : uri;
+    this.onDiagnostics?.(targetUri, diagnostics);
     console.log(`[Diagnostics] ${targetUri}: ${diagnostics.length} issue(s)`);
     diagnostics.forEach(d => {
       const severity = d.severity === 1 ? 'Error' :
@@ -133,8 +173,18 @@ export class ServerConfiguration implements IConfiguration {
     this.config = config || {};
   }
 
+  /**
+   * Look up a section such as "python.analysis" in the nested settings
+   */
   get(section: string): any {
-    return this.config[section] || {};
+    if (section in this.config) {
+      return this.config[section] ?? {};
+    }
+    const value = section.split('.').reduce<any>(
+      (current, key) => (current && typeof current === 'object' ? current[key] : undefined),
+      this.config
+    );
+    return value ?? {};
   }
 
   set(section: string, value: any): void {
@@ -146,7 +196,7 @@ export class ServerConfiguration implements IConfiguration {
  * Server Host implementation
  */
 export class ServerHost implements IHost {
-  window: IWindow;
+  window: ServerWindow | ConsoleWindow;
   workspace: IWorkspace;
   configuration: IConfiguration;
 
@@ -154,9 +204,12 @@ export class ServerHost implements IHost {
     workspaceRoot: string,
     wsConnection?: WebSocket,
     config?: Record<string, any>,
-    mapUri?: (uri: string) => string
+    mapUri?: (uri: string) => string,
+    onDiagnostics?: DiagnosticsListener
   ) {
-    this.window = wsConnection ? new ServerWindow(wsConnection, mapUri) : new ConsoleWindow(mapUri);
+    this.window = wsConnection
+      ? new ServerWindow(wsConnection, mapUri, onDiagnostics)
+      : new ConsoleWindow(mapUri, onDiagnostics);
     this.workspace = new ServerWorkspace(`file://${workspaceRoot}`);
     this.configuration = new ServerConfiguration(config);
   }
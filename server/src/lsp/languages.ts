@@ -0,0 +1,144 @@
+import * as path from 'path';
+import type { LanguageServerConfig } from './manager.js';
+
+/**
+ * File extensions and well-known file names of each language the editor
+ * knows about, whether or not a language server is configured for it. Kept
+ * in step with LANGUAGES in web/lib/languages.ts.
+ */
+const LANGUAGE_FILES: Record<string, { extensions: string[]; fileNames?: string[] }> = {
+  go: { extensions: ['.go'] },
+  typescript: { extensions: ['.ts', '.tsx', '.mts', '.cts'] },
+  javascript: { extensions: ['.js', '.jsx', '.mjs', '.cjs'] },
+  python: { extensions: ['.py', '.pyi'] },
+  rust: { extensions: ['.rs'] },
+  json: { extensions: ['.json', '.jsonc'], fileNames: ['.babelrc', '.eslintrc', '.prettierrc'] },
+  yaml: { extensions: ['.yaml', '.yml'] },
+  html: { extensions: ['.html', '.htm'] },
+  css: { extensions: ['.css'] },
+  scss: { extensions: ['.scss'] },
+  less: { extensions: ['.less'] },
+  markdown: { extensions: ['.md', '.markdown'] },
+  shell: { extensions: ['.sh', '.bash'] },
+  dockerfile: { extensions: ['.dockerfile'], fileNames: ['dockerfile'] },
+  http: { extensions: ['.http', '.rest'] }
+};
+
+/**
+ * Usual file extension of a language, e.g. '.go', or undefined if unknown
+ */
+export function getLanguageExtension(languageId: string): string | undefined {
+  return Object.prototype.hasOwnProperty.call(LANGUAGE_FILES, languageId)
+    ? LANGUAGE_FILES[languageId].extensions[0]
+    : undefined;
+}
+
+/**
+ * Infer a language ID from a path or URI, 'plaintext' if unknown
+ */
+export function getLanguageIdForPath(filePath: string): string {
+  const name = path.basename(filePath).toLowerCase();
+  const ext = path.extname(name);
+  for (const [languageId, files] of Object.entries(LANGUAGE_FILES)) {
+    if (files.fileNames?.includes(name) || (ext && files.extensions.includes(ext))) {
+      return languageId;
+    }
+  }
+  return 'plaintext';
+}
+
+/**
+ * Built-in language server configurations. Commands can be overridden with
+ * the environment variables below or replaced in the config file.
+ */
+export function getDefaultLanguageServers(env: NodeJS.ProcessEnv = process.env): LanguageServerConfig[] {
+  const typescriptServer = env.TS_SERVER_PATH || 'typescript-language-server';
+  const cssServer = env.CSS_SERVER_PATH || 'vscode-css-language-server';
+  const cssSettings = { validate: true };
+
+  return [
+    {
+      languageId: 'go',
+      command: env.GOPLS_PATH || 'gopls',
+      args: [],
+      fileExtensions: LANGUAGE_FILES.go.extensions
+    },
+    {
+      languageId: 'typescript',
+      command: typescriptServer,
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.typescript.extensions
+    },
+    {
+      languageId: 'javascript',
+      command: typescriptServer,
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.javascript.extensions
+    },
+    {
+      // pylsp works too: { "languageId": "python", "command": "pylsp", "args": [] }
+      languageId: 'python',
+      command: env.PYRIGHT_PATH || 'pyright-langserver',
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.python.extensions,
+      settings: {
+        python: { analysis: { autoSearchPaths: true, useLibraryCodeForTypes: true, diagnosticMode: 'openFilesOnly' } }
+      }
+    },
+    {
+      languageId: 'rust',
+      command: env.RUST_ANALYZER_PATH || 'rust-analyzer',
+      args: [],
+      fileExtensions: LANGUAGE_FILES.rust.extensions,
+      initializationOptions: { checkOnSave: true, cargo: { buildScripts: { enable: true } } }
+    },
+    {
+      languageId: 'json',
+      command: env.JSON_SERVER_PATH || 'vscode-json-language-server',
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.json.extensions,
+      initializationOptions: { provideFormatter: true },
+      // The browser validates JSON against the schema registry itself, so
+      // the server is only used for formatting
+      settings: { json: { validate: { enable: false } } }
+    },
+    {
+      languageId: 'yaml',
+      command: env.YAML_SERVER_PATH || 'yaml-language-server',
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.yaml.extensions,
+      settings: { yaml: { validate: true, hover: true, completion: true, schemaStore: { enable: false } } }
+    },
+    {
+      languageId: 'html',
+      command: env.HTML_SERVER_PATH || 'vscode-html-language-server',
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.html.extensions,
+      initializationOptions: { provideFormatter: true, embeddedLanguages: { css: true, javascript: true } }
+    },
+    {
+      languageId: 'css',
+      command: cssServer,
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.css.extensions,
+      initializationOptions: { provideFormatter: true },
+      settings: { css: cssSettings }
+    },
+    {
+      languageId: 'scss',
+      command: cssServer,
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.scss.extensions,
+      initializationOptions: { provideFormatter: true },
+      settings: { scss: cssSettings }
+    },
+    {
+      languageId: 'less',
+      command: cssServer,
+      args: ['--stdio'],
+      fileExtensions: LANGUAGE_FILES.less.extensions,
+      initializationOptions: { provideFormatter: true },
+      settings: { less: cssSettings }
+    }
+  ];
+}
@@ -1,5 +1,8 @@
 import { LanguageClient, StdioTransport } from '@lewin671/lsp-client';
-import { ServerHost, ServerWindow } from './host.js';
+import { FileOperationServer } from './fileOperations.js';
+import { DiagnosticsListener, ServerHost, ServerWindow } from './host.js';
+import { getDefaultLanguageServers, getLanguageExtension } from './languages.js';
+import { ConfiguredTransport } from './transport.js';
 import { WebSocket } from 'ws';
 
 export interface LanguageServerConfig {
@@ -7,15 +10,28 @@ export interface LanguageServerConfig {
   command: string;
   args: string[];
   fileExtensions: string[];
+  // Sent as initializationOptions in the initialize request
+  initializationOptions?: Record<string, unknown>;
+  // Answers workspace/configuration and is pushed after initialization
+  settings?: Record<string, unknown>;
 }
 
 interface ClientInfo {
   client: LanguageClient;
   host: ServerHost;
+  transport: ConfiguredTransport;
   lastUsed: number;
   idleTimer?: NodeJS.Timeout;
 }
 
+/**
+ * Adjusts a language server's settings just before it starts, e.g. to add
+ * workspace-specific schema associations
+ */
+export type SettingsResolver = (
+  config: LanguageServerConfig
+) => Promise<Record<string, unknown> | undefined>;
+
 interface ClientOptions {
   wsConnection?: WebSocket;
   mapUri?: (uri: string) => string;
@@ -26,6 +42,9 @@ export class LanguageServerManager {
   private startingClients: Map<string, Promise<LanguageClient>> = new Map();
   private configs: LanguageServerConfig[];
   private idleTimeout: number = 5 * 60 * 1000; // 5 minutes
+  private diagnosticsListeners: DiagnosticsListener[] = [];
+  private settingsResolver?: SettingsResolver;
+  private mapUri?: (uri: string) => string;
 
   constructor(
     private workspaceRoot: string,
@@ -38,26 +57,7 @@ export class LanguageServerManager {
    * Get default language server configurations
    */
   private getDefaultConfigs(): LanguageServerConfig[] {
-    return [
-      {
-        languageId: 'go',
-        command: process.env.GOPLS_PATH || 'gopls',
-        args: [],
-        fileExtensions: ['.go']
-      },
-      {
-        languageId: 'typescript',
-        command: process.env.TS_SERVER_PATH || 'typescript-language-server',
-        args: ['--stdio'],
-        fileExtensions: ['.ts', '.tsx']
-      },
-      {
-        languageId: 'javascript',
-        command: process.env.TS_SERVER_PATH || 'typescript-language-server',
-        args: ['--stdio'],
-        fileExtensions: ['.js', '.jsx']
-      }
-    ];
+    return getDefaultLanguageServers();
   }
 
   /**
@@ -109,8 +109,21 @@ export class LanguageServerManager {
 
       while (retryCount <= maxRetries) {
         try {
-          const transport = new StdioTransport(config.command, config.args);
-          const host = new ServerHost(this.workspaceRoot, options?.wsConnection, undefined, options?.mapUri);
+          const settings = this.settingsResolver
+            ? await this.settingsResolver(config)
+            : config.settings;
+          const transport = new ConfiguredTransport(
+            new StdioTransport(config.command, config.args),
+            { initializationOptions: config.initializationOptions, settings }
+          );
+          const host = new ServerHost(
+            this.workspaceRoot,
+            options?.wsConnection,
+            settings,
+            options?.mapUri ?? this.mapUri,
+            (uri, diagnostics) => this.diagnosticsListeners.forEach(listener => listener(uri, diagnostics))
+          );
+          transport.onRequest('window/showDocument', params => host.window.showDocument(params));
 
           const client = new LanguageClient(
             host,
@@ -134,8 +147,20 @@ export class LanguageServerManager {
                 formatting: { dynamicRegistration: true },
                 publishDiagnostics: { relatedInformation: true }
               },
+              window: {
+                showDocument: { support: true }
+              },
               workspace: {
-                workspaceFolders: true
+                workspaceFolders: true,
+                fileOperations: {
+                  dynamicRegistration: false,
+                  willCreate: true,
+                  didCreate: true,
+                  willRename: true,
+                  didRename: true,
+                  willDelete: true,
+                  didDelete: true
+                }
               }
             }
           );
@@ -147,6 +172,7 @@ export class LanguageServerManager {
           const info: ClientInfo = {
             client,
             host,
+            transport,
             lastUsed: Date.now(),
             idleTimer: setTimeout(() => {
               this.stopClient(languageId);
@@ -228,6 +254,22 @@ export class LanguageServerManager {
     return config?.languageId;
   }
 
+  /**
+   * File extension for documents of a language, preferring the configured
+   * language server's, e.g. for untitled buffers
+   */
+  getFileExtension(languageId: string): string | undefined {
+    const config = this.configs.find(c => c.languageId === languageId);
+    return config?.fileExtensions[0] ?? getLanguageExtension(languageId);
+  }
+
+  /**
+   * Whether a language server is configured for a language
+   */
+  hasLanguageServer(languageId: string): boolean {
+    return this.configs.some(c => c.languageId === languageId);
+  }
+
   /**
    * Get language ID from URI
    */
@@ -254,6 +296,68 @@ export class LanguageServerManager {
     return Array.from(this.clients.keys());
   }
 
+  /**
+   * Running language servers with the capabilities they announced, for
+   * workspace-wide requests such as file operations
+   */
+  getFileOperationServers(): FileOperationServer[] {
+    return Array.from(this.clients.entries()).map(([languageId, info]) => ({
+      languageId,
+      capabilities: info.transport.getServerCapabilities() ?? {},
+      request: (method, params) => info.client.sendRequest(method, params),
+      notify: (method, params) => info.transport.notify(method, params)
+    }));
+  }
+
+  /**
+   * Get the configured language servers
+   */
+  getConfigs(): LanguageServerConfig[] {
+    return [...this.configs];
+  }
+
+  /**
+   * Add a language server configuration at runtime (e.g. from a plugin)
+   */
+  addConfig(config: LanguageServerConfig): void {
+    if (this.configs.some(c => c.languageId === config.languageId)) {
+      throw new Error(`Language server already configured for ${config.languageId}`);
+    }
+    this.configs.push(config);
+  }
+
+  /**
+   * Remove a language server configuration, stopping its server if running
+   */
+  async removeConfig(languageId: string): Promise<void> {
+    this.configs = this.configs.filter(c => c.languageId !== languageId);
+    await this.stopClient(languageId);
+  }
+
+  /**
+   * Register a listener for diagnostics published by any language server.
+   * URIs are already mapped back to the client's URI space.
+   */
+  onDiagnostics(listener: DiagnosticsListener): void {
+    this.diagnosticsListeners.push(listener);
+  }
+
+  /**
+   * Set a hook that computes the settings sent to each language server
+   * when it starts. Servers that are already running are not affected.
+   */
+  setSettingsResolver(resolver: SettingsResolver): void {
+    this.settingsResolver = resolver;
+  }
+
+  /**
+   * Set how file:// URIs published by language servers (e.g. with
+   * diagnostics) are translated for the browser
+   */
+  setUriMapper(mapUri: (uri: string) => string): void {
+    this.mapUri = mapUri;
+  }
+
   /**
    * Set idle timeout (in milliseconds)
    */
@@ -1,4 +1,6 @@
 import { RealFileSystem } from '../fs/real.js';
+import { ScratchDocuments, UNTITLED_URI_SCHEME } from '../fs/scratch.js';
+import { UriMapper } from '../fs/uris.js';
 import { LanguageServerManager } from './manager.js';
 import { WebSocket } from 'ws';
 import {
@@ -13,6 +15,7 @@ import {
   DocumentFormattingParams
 } from 'vscode-languageserver-protocol';
 import { TextEdit } from 'vscode-languageserver-types';
+import type { LspMiddleware } from '../plugins/types.js';
 
 export interface LSPMessage {
   jsonrpc: '2.0';
@@ -30,13 +33,49 @@ export interface LSPMessage {
 export class LSPProxy {
   // Serialize per-document operations to keep order (didChange before completion, etc.)
   private uriLocks: Map<string, Promise<any>> = new Map();
+  // Workspace paths of the documents the client has open
+  private openDocuments = new Set<string>();
+  // Open documents whose file was renamed, until the client reopens them
+  // under the new path
+  private movedDocuments = new Set<string>();
 
   constructor(
     private fileSystem: RealFileSystem,
     private lsManager: LanguageServerManager,
-    private wsConnection: WebSocket
+    private wsConnection: WebSocket,
+    // Shared, live list: middleware registered later applies to existing proxies
+    private middleware: LspMiddleware[] = [],
+    private clientId: string = '',
+    // Translates between browser URIs and the language servers' file:// URIs
+    private uris: UriMapper = new UriMapper(fileSystem.getWorkspaceRoot()),
+    // Temporary files behind untitled buffers; they're refused without it
+    private scratch?: ScratchDocuments,
+    // Browser tab the connection belongs to, which untitled buffers are kept per
+    private client: string = clientId
   ) {}
 
+  /**
+   * Whether the client has a workspace file open in an editor
+   */
+  hasOpenDocument(workspacePath: string): boolean {
+    return this.openDocuments.has(workspacePath);
+  }
+
+  /**
+   * Move the open documents under a renamed path to the new path. Changes
+   * the client still sends for the old path (e.g. edits a language server
+   * made ahead of the rename) are dropped rather than recreating the old
+   * file; the client reopens the document under the new path with its text.
+   */
+  renameDocuments(oldPath: string, newPath: string): void {
+    for (const workspacePath of Array.from(this.openDocuments)) {
+      if (workspacePath !== oldPath && !workspacePath.startsWith(`${oldPath}/`)) continue;
+      this.openDocuments.delete(workspacePath);
+      this.openDocuments.add(newPath + workspacePath.slice(oldPath.length));
+      this.movedDocuments.add(workspacePath);
+    }
+  }
+
   /**
    * Run a task serialized per URI to guarantee ordering of LSP messages
    */
@@ -58,9 +97,40 @@ export class LSPProxy {
   }
 
   /**
-   * Handle incoming LSP request/notification
+   * Handle incoming LSP request/notification, running it through the
+   * registered middleware on the way in and its response on the way out
    */
   async handleMessage(message: LSPMessage): Promise<LSPMessage | void> {
+    const context = { clientId: this.clientId };
+    let request: LSPMessage = message;
+    for (const middleware of this.middleware) {
+      if (middleware.incoming) {
+        const next = await middleware.incoming(request, context);
+        if (next === null) {
+          // Requests still need an answer so the client doesn't wait forever
+          return message.id !== undefined
+            ? this.createErrorResponse(message.id, -32800, 'Request cancelled by server middleware')
+            : undefined;
+        }
+        request = next;
+      }
+    }
+
+    let response = await this.dispatch(request);
+    if (response) {
+      for (const middleware of this.middleware) {
+        if (middleware.outgoing) {
+          response = await middleware.outgoing(response, request, context);
+        }
+      }
+    }
+    return response;
+  }
+
+  /**
+   * Handle a request/notification after middleware has run
+   */
+  private async dispatch(message: LSPMessage): Promise<LSPMessage | void> {
     const { method, params, id } = message;
 
     if (!method) {
@@ -68,6 +138,14 @@ export class LSPProxy {
     }
 
     try {
+      const uri = params?.textDocument?.uri;
+      if (typeof uri === 'string' && this.uris.isVirtualUri(uri)) {
+        return await this.dispatchVirtual(method, params, id);
+      }
+      if (typeof uri === 'string' && uri.startsWith(`${UNTITLED_URI_SCHEME}:`)) {
+        return await this.dispatchScratch(method, params, id);
+      }
+
       switch (method) {
         case 'textDocument/didOpen':
           await this.handleDidOpen(params as DidOpenTextDocumentParams);
@@ -87,23 +165,23 @@ export class LSPProxy {
 
         case 'textDocument/completion':
           const completionResult = await this.handleCompletion(params as CompletionParams);
-          return this.createSuccessResponse(id, completionResult);
+          return this.createSuccessResponse(id, this.uris.toClient(completionResult));
 
         case 'textDocument/hover':
           const hoverResult = await this.handleHover(params as HoverParams);
-          return this.createSuccessResponse(id, hoverResult);
+          return this.createSuccessResponse(id, this.uris.toClient(hoverResult));
 
         case 'textDocument/definition':
           const definitionResult = await this.handleDefinition(params as DefinitionParams);
-          return this.createSuccessResponse(id, definitionResult);
+          return this.createSuccessResponse(id, this.uris.toClient(definitionResult));
 
         case 'textDocument/references':
           const referencesResult = await this.handleReferences(params as ReferenceParams);
-          return this.createSuccessResponse(id, referencesResult);
+          return this.createSuccessResponse(id, this.uris.toClient(referencesResult));
 
         case 'textDocument/formatting':
           const formattingResult = await this.handleFormatting(params as DocumentFormattingParams);
-          return this.createSuccessResponse(id, formattingResult);
+          return this.createSuccessResponse(id, this.uris.toClient(formattingResult));
 
         default:
           return this.createErrorResponse(id, -32601, `Method not found: ${method}`);
@@ -118,6 +196,152 @@ export class LSPProxy {
     }
   }
 
+  /**
+   * Handle a request/notification for a read-only document outside the
+   * workspace (deps: URI). Nothing is written to disk and only the
+   * navigation requests are served.
+   */
+  private async dispatchVirtual(method: string, params: any, id: LSPMessage['id']): Promise<LSPMessage | void> {
+    const uri: string = params.textDocument.uri;
+    const realUri = this.uris.toServerUri(uri);
+    const languageId: string | undefined = params.textDocument.languageId ?? this.lsManager.getLanguageIdFromUri(uri);
+    if (!languageId) {
+      throw new Error(`No language server for ${uri}`);
+    }
+    const getClient = () => this.lsManager.getOrCreateClient(languageId, {
+      wsConnection: this.wsConnection
+    });
+
+    switch (method) {
+      case 'textDocument/didOpen':
+        console.log(`[LSP Proxy] didOpen (read-only): ${uri}`);
+        (await getClient()).didOpen({
+          textDocument: { uri: realUri, languageId, version: params.textDocument.version, text: params.textDocument.text }
+        });
+        return;
+
+      case 'textDocument/didClose':
+        if (this.lsManager.isClientRunning(languageId)) {
+          (await getClient()).didClose({ textDocument: { uri: realUri } });
+        }
+        return;
+
+      case 'textDocument/didChange':
+      case 'textDocument/didSave':
+        // Read-only: edits are never written back
+        return;
+
+      case 'textDocument/hover':
+        return this.createSuccessResponse(id, this.uris.toClient(await (await getClient()).sendRequest('textDocument/hover', {
+          textDocument: { uri: realUri },
+          position: params.position
+        })));
+
+      case 'textDocument/definition':
+      case 'textDocument/references': {
+        const result = await (await getClient()).sendRequest(method, this.uris.toServer(params));
+        return this.createSuccessResponse(id, this.uris.toClient(result));
+      }
+
+      case 'textDocument/completion':
+        return this.createSuccessResponse(id, null);
+
+      case 'textDocument/formatting':
+        return this.createSuccessResponse(id, []);
+
+      default:
+        return this.createErrorResponse(id, -32601, `Method not found: ${method}`);
+    }
+  }
+
+  /**
+   * Handle a request/notification for an untitled buffer, backed by a
+   * temporary file while it is open. Reopening it with another languageId
+   * moves it to that language's server.
+   */
+  private async dispatchScratch(method: string, params: any, id: LSPMessage['id']): Promise<LSPMessage | void> {
+    const scratch = this.scratch;
+    const uri: string = params.textDocument.uri;
+    if (!scratch) {
+      throw new Error(`Untitled documents are not supported: ${uri}`);
+    }
+    const getClient = (languageId: string) => this.lsManager.getOrCreateClient(languageId, {
+      wsConnection: this.wsConnection
+    });
+
+    return this.withUriLock(uri, async () => {
+      const document = scratch.get(this.client, uri);
+
+      switch (method) {
+        case 'textDocument/didOpen': {
+          const { languageId, version, text } = params.textDocument;
+          console.log(`[LSP Proxy] didOpen (untitled): ${uri}, language: ${languageId}`);
+          if (document && this.lsManager.isClientRunning(document.languageId)) {
+            (await getClient(document.languageId)).didClose({
+              textDocument: { uri: scratch.toServerUri(this.client, uri)! }
+            });
+          }
+          const opened = await scratch.open(this.client, uri, languageId, text);
+          if (this.lsManager.hasLanguageServer(languageId)) {
+            (await getClient(languageId)).didOpen({
+              textDocument: { uri: scratch.toServerUri(this.client, opened.uri)!, languageId, version, text }
+            });
+          }
+          return;
+        }
+
+        case 'textDocument/didChange': {
+          const changes = params.contentChanges as DidChangeTextDocumentParams['contentChanges'];
+          const lastChange = changes[changes.length - 1];
+          if (!document || !lastChange || !('text' in lastChange) || 'range' in lastChange) {
+            return;
+          }
+          await scratch.update(this.client, uri, lastChange.text);
+          if (this.lsManager.hasLanguageServer(document.languageId)) {
+            (await getClient(document.languageId)).didChange({
+              textDocument: { uri: scratch.toServerUri(this.client, uri)!, version: params.textDocument.version },
+              contentChanges: [{ text: lastChange.text }]
+            });
+          }
+          return;
+        }
+
+        case 'textDocument/didClose':
+          if (document && this.lsManager.isClientRunning(document.languageId)) {
+            (await getClient(document.languageId)).didClose({
+              textDocument: { uri: scratch.toServerUri(this.client, uri)! }
+            });
+          }
+          await scratch.close(this.client, uri);
+          return;
+
+        case 'textDocument/didSave':
+          // Untitled buffers are saved with Save As, which creates a workspace file
+          return;
+
+        case 'textDocument/completion':
+        case 'textDocument/hover':
+        case 'textDocument/definition':
+        case 'textDocument/references':
+        case 'textDocument/formatting': {
+          if (!document) {
+            throw new Error(`Untitled document is not open: ${uri}`);
+          }
+          if (!this.lsManager.hasLanguageServer(document.languageId)) {
+            return this.createSuccessResponse(id, method === 'textDocument/formatting' ? [] : null);
+          }
+          const serverParams = this.uris.toServer(params);
+          serverParams.textDocument = { ...serverParams.textDocument, uri: scratch.toServerUri(this.client, uri) };
+          const result = await (await getClient(document.languageId)).sendRequest(method, serverParams);
+          return this.createSuccessResponse(id, this.uris.toClient(result));
+        }
+
+        default:
+          return this.createErrorResponse(id, -32601, `Method not found: ${method}`);
+      }
+    });
+  }
+
   /**
    * Handle textDocument/didOpen
    */
@@ -127,6 +351,19 @@ export class LSPProxy {
     console.log(`[LSP Proxy] didOpen: ${textDocument.uri}, language: ${textDocument.languageId}`);
 
     await this.withUriLock(textDocument.uri, async () => {
+      const realUri = this.uris.toServerUri(textDocument.uri);
+
+      // Reopened in another language (e.g. picked in the status bar): the
+      // previous language's server lets go of it first
+      const previousLanguageId = this.fileSystem.getOpenLanguageId(textDocument.uri);
+      if (
+        previousLanguageId &&
+        previousLanguageId !== textDocument.languageId &&
+        this.lsManager.isClientRunning(previousLanguageId)
+      ) {
+        const previousClient = await this.lsManager.getOrCreateClient(previousLanguageId);
+        previousClient.didClose({ textDocument: { uri: realUri } });
+      }
 
       // Save to real file system
       await this.fileSystem.createFile(
@@ -134,17 +371,23 @@ export class LSPProxy {
         textDocument.text,
         textDocument.languageId
       );
+      this.openDocuments.add(this.uris.toWorkspacePath(textDocument.uri));
+      this.movedDocuments.delete(this.uris.toWorkspacePath(textDocument.uri));
 
       const filePath = this.fileSystem.uriToPath(textDocument.uri);
       console.log(`[LSP Proxy] File created at: ${filePath}`);
 
+      // e.g. switched to Plain Text
+      if (!this.lsManager.hasLanguageServer(textDocument.languageId)) {
+        return;
+      }
+
       // Get or create Language Server client
       const client = await this.lsManager.getOrCreateClient(textDocument.languageId, {
         wsConnection: this.wsConnection
       });
 
       // Forward to Language Server with the real file URI
-      const realUri = `file://${filePath}`;
       console.log(`[LSP Proxy] Forwarding didOpen to LS with URI: ${realUri}`);
       
       client.didOpen({
@@ -165,6 +408,10 @@ export class LSPProxy {
     const { textDocument, contentChanges } = params;
 
     console.log(`[LSP Proxy] didChange: ${textDocument.uri}`);
+    if (this.movedDocuments.has(this.uris.toWorkspacePath(textDocument.uri))) {
+      console.log(`[LSP Proxy] Ignoring didChange for renamed document: ${textDocument.uri}`);
+      return;
+    }
 
     await this.withUriLock(textDocument.uri, async () => {
       // Update real file system
@@ -190,8 +437,7 @@ export class LSPProxy {
       });
 
       // Forward to Language Server with real file URI
-      const filePath = this.fileSystem.uriToPath(textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(textDocument.uri);
       
       // Always send full content to ensure LSP has accurate file state
       // This is more reliable than forwarding incremental changes
@@ -214,11 +460,22 @@ export class LSPProxy {
     const { textDocument } = params;
 
     console.log(`[LSP Proxy] didClose: ${textDocument.uri}`);
+    this.openDocuments.delete(this.uris.toWorkspacePath(textDocument.uri));
+    this.movedDocuments.delete(this.uris.toWorkspacePath(textDocument.uri));
 
     await this.withUriLock(textDocument.uri, async () => {
       // Get file info before deleting
       const file = await this.fileSystem.getFile(textDocument.uri);
       if (!file) {
+        // Renamed or deleted: the server still has the document open under
+        // its old URI, so close it there (if the server is still running)
+        const languageId = this.lsManager.getLanguageIdFromUri(textDocument.uri);
+        if (languageId && this.lsManager.isClientRunning(languageId)) {
+          const client = await this.lsManager.getOrCreateClient(languageId);
+          client.didClose({
+            textDocument: { uri: this.uris.toServerUri(textDocument.uri) }
+          });
+        }
         return;
       }
 
@@ -226,8 +483,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(textDocument.uri);
       
       client.didClose({
         textDocument: { uri: realUri }
@@ -256,8 +512,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(textDocument.uri);
       
       client.didSave({
         textDocument: { uri: realUri },
@@ -289,8 +544,7 @@ export class LSPProxy {
       });
       
       // Get real file path
-      const filePath = this.fileSystem.uriToPath(params.textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(params.textDocument.uri);
       
       console.log(`[LSP Proxy] Sending completion request to language server with URI: ${realUri}`);
       const result = await client.sendRequest('textDocument/completion', {
@@ -319,8 +573,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(params.textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(params.textDocument.uri);
       
       return client.sendRequest('textDocument/hover', {
         textDocument: { uri: realUri },
@@ -344,8 +597,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(params.textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(params.textDocument.uri);
       
       return client.sendRequest('textDocument/definition', {
         textDocument: { uri: realUri },
@@ -369,8 +621,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(params.textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(params.textDocument.uri);
       
       return client.sendRequest('textDocument/references', {
         textDocument: { uri: realUri },
@@ -395,8 +646,7 @@ export class LSPProxy {
       const client = await this.lsManager.getOrCreateClient(file.languageId, {
         wsConnection: this.wsConnection
       });
-      const filePath = this.fileSystem.uriToPath(params.textDocument.uri);
-      const realUri = `file://${filePath}`;
+      const realUri = this.uris.toServerUri(params.textDocument.uri);
 
       return client.sendRequest('textDocument/formatting', {
         textDocument: { uri: realUri },
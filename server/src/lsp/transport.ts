@@ -0,0 +1,140 @@
+import { ITransport } from '@lewin671/lsp-client';
+import { Message, MessageReader, MessageWriter } from 'vscode-jsonrpc';
+
+export interface LanguageServerOptions {
+  // Merged into the params of the initialize request
+  initializationOptions?: Record<string, unknown>;
+  // Pushed with workspace/didChangeConfiguration once the server is initialized
+  settings?: Record<string, unknown>;
+}
+
+/**
+ * Answers a request sent by the language server to the client
+ */
+export type ServerRequestHandler = (params: any) => unknown | Promise<unknown>;
+
+/**
+ * Wraps a language server transport to add per-language initialization
+ * options and settings to the handshake sent by the LSP client, and to
+ * answer server requests the LSP client doesn't handle itself
+ */
+export class ConfiguredTransport implements ITransport {
+  private writer?: MessageWriter;
+  private initializeId?: number | string;
+  private capabilities?: Record<string, any>;
+  private requestHandlers = new Map<string, ServerRequestHandler>();
+
+  constructor(
+    private inner: ITransport,
+    private options: LanguageServerOptions
+  ) {}
+
+  async connect(): Promise<{ reader: MessageReader; writer: MessageWriter }> {
+    const { reader, writer } = await this.inner.connect();
+    this.writer = writer;
+    return { reader: this.wrapReader(reader), writer: this.wrapWriter(writer) };
+  }
+
+  /**
+   * Capabilities the server answered the initialize request with
+   */
+  getServerCapabilities(): Record<string, any> | undefined {
+    return this.capabilities;
+  }
+
+  /**
+   * Send a notification the LSP client has no method for
+   */
+  async notify(method: string, params: unknown): Promise<void> {
+    if (!this.writer) {
+      throw new Error('Language server is not connected');
+    }
+    await this.writer.write({ jsonrpc: '2.0', method, params } as Message);
+  }
+
+  /**
+   * Answer requests for a method from the language server here instead of
+   * passing them on to the LSP client
+   */
+  onRequest(method: string, handler: ServerRequestHandler): void {
+    this.requestHandlers.set(method, handler);
+  }
+
+  dispose(): void {
+    (this.inner as { dispose?: () => void }).dispose?.();
+  }
+
+  private wrapReader(reader: MessageReader): MessageReader {
+    return {
+      listen: (callback) => reader.listen((message: Message) => {
+        const msg = message as Message & { id?: number | string; method?: string; params?: any; result?: any };
+        if (this.initializeId !== undefined && msg.id === this.initializeId) {
+          this.capabilities = msg.result?.capabilities;
+        }
+        const handler = msg.method !== undefined ? this.requestHandlers.get(msg.method) : undefined;
+        if (handler && msg.id !== undefined) {
+          void this.answer(msg.id, msg.method!, () => handler(msg.params));
+          return;
+        }
+        callback(message);
+      }),
+      onError: (listener) => reader.onError(listener),
+      onClose: (listener) => reader.onClose(listener),
+      onPartialMessage: (listener) => reader.onPartialMessage(listener),
+      dispose: () => reader.dispose()
+    };
+  }
+
+  private async answer(id: number | string, method: string, handle: () => unknown): Promise<void> {
+    let response: Message;
+    try {
+      response = { jsonrpc: '2.0', id, result: (await handle()) ?? null } as Message;
+    } catch (error) {
+      console.error(`[LSP Transport] Failed to handle ${method}:`, error);
+      response = {
+        jsonrpc: '2.0',
+        id,
+        error: { code: -32603, message: error instanceof Error ? error.message : String(error) }
+      } as Message;
+    }
+    try {
+      await this.writer?.write(response);
+    } catch (error) {
+      console.error(`[LSP Transport] Failed to answer ${method}:`, error);
+    }
+  }
+
+  private wrapWriter(writer: MessageWriter): MessageWriter {
+    const { initializationOptions, settings } = this.options;
+
+    return {
+      write: async (message: Message) => {
+        const msg = message as Message & { method?: string; id?: number | string; params?: any };
+        if (msg.method === 'initialize' && msg.id !== undefined) {
+          this.initializeId = msg.id;
+        }
+        if (msg.method === 'initialize' && msg.id !== undefined && initializationOptions) {
+          message = {
+            ...msg,
+            params: {
+              ...msg.params,
+              initializationOptions: { ...msg.params?.initializationOptions, ...initializationOptions }
+            }
+          } as Message;
+        }
+        await writer.write(message);
+        if (msg.method === 'initialized' && settings) {
+          await writer.write({
+            jsonrpc: '2.0',
+            method: 'workspace/didChangeConfiguration',
+            params: { settings }
+          } as Message);
+        }
+      },
+      onError: (listener) => writer.onError(listener),
+      onClose: (listener) => writer.onClose(listener),
+      end: () => writer.end(),
+      dispose: () => writer.dispose()
+    };
+  }
+}
@@ -0,0 +1,121 @@
+export type PackageManager = 'npm' | 'yarn' | 'pnpm';
+
+export const LOCKFILES: Record<PackageManager, string> = {
+  npm: 'package-lock.json',
+  yarn: 'yarn.lock',
+  pnpm: 'pnpm-lock.yaml'
+};
+
+/**
+ * Versions recorded in package-lock.json keyed by install location, e.g.
+ * "node_modules/react" or "packages/app/node_modules/react" for a nested
+ * workspace package (lockfileVersion 1, 2 and 3)
+ */
+export function parsePackageLock(content: string): Map<string, string> {
+  const lock = JSON.parse(content);
+  const versions = new Map<string, string>();
+  if (lock.packages && typeof lock.packages === 'object') {
+    for (const [key, entry] of Object.entries<any>(lock.packages)) {
+      if (key.includes('node_modules/') && typeof entry?.version === 'string') {
+        versions.set(key, entry.version);
+      }
+    }
+  } else if (lock.dependencies && typeof lock.dependencies === 'object') {
+    for (const [name, entry] of Object.entries<any>(lock.dependencies)) {
+      if (typeof entry?.version === 'string') {
+        versions.set(`node_modules/${name}`, entry.version);
+      }
+    }
+  }
+  return versions;
+}
+
+/**
+ * Resolved versions in a yarn.lock, keyed by "name@range" as written in
+ * package.json (classic yarn.lock and Berry's YAML-like format)
+ */
+export function parseYarnLock(content: string): Map<string, string> {
+  const versions = new Map<string, string>();
+  let specifiers: string[] = [];
+
+  for (const line of content.split(/\r?\n/)) {
+    if (!line.trim() || line.trim().startsWith('#')) {
+      continue;
+    }
+    if (!/^\s/.test(line)) {
+      specifiers = line
+        .replace(/:\s*$/, '')
+        .split(',')
+        .map(spec => spec.trim().replace(/^"(.*)"$/, '$1').replace(/@npm:/, '@'));
+      continue;
+    }
+    const match = /^\s+version:?\s+"?([^"\s]+)"?/.exec(line);
+    if (match) {
+      specifiers.forEach(spec => versions.set(spec, match[1]));
+      specifiers = [];
+    }
+  }
+  return versions;
+}
+
+/**
+ * Versions of the root project's direct dependencies in a pnpm-lock.yaml.
+ * Only the handful of lines needed are read, so no YAML parser is required.
+ */
+export function parsePnpmLock(content: string): Map<string, string> {
+  const versions = new Map<string, string>();
+  const sections = ['dependencies:', 'devDependencies:', 'optionalDependencies:'];
+  // Lockfile v6+ nests the root project under "importers: .:", v5 has it at the top level
+  const hasImporters = /^importers:/m.test(content);
+  const sectionIndent = hasImporters ? 4 : 0;
+  let inImporters = false;
+  let inRoot = !hasImporters;
+  let inSection = false;
+  let current: string | undefined;
+
+  for (const line of content.split(/\r?\n/)) {
+    if (!line.trim() || line.trim().startsWith('#')) {
+      continue;
+    }
+    const indent = line.length - line.trimStart().length;
+    const text = line.trim();
+
+    if (hasImporters && indent === 0) {
+      inImporters = text === 'importers:';
+      inRoot = false;
+      continue;
+    }
+    if (hasImporters && indent === 2) {
+      inRoot = inImporters && /^['"]?\.['"]?:$/.test(text);
+      inSection = false;
+      continue;
+    }
+    if (!inRoot) {
+      continue;
+    }
+    if (indent === sectionIndent) {
+      inSection = sections.includes(text);
+      current = undefined;
+      continue;
+    }
+    if (!inSection || indent < sectionIndent) {
+      continue;
+    }
+
+    // Either "name: 1.2.3" (v5) or "name:" followed by "version: 1.2.3" (v6+)
+    const entry = /^['"]?((?:@[^/\s]+\/)?[^:'"\s]+)['"]?:\s*(.*)$/.exec(text);
+    if (!entry) {
+      continue;
+    }
+    const value = entry[2].replace(/^['"]|['"]$/g, '').split('(')[0];
+    if (indent === sectionIndent + 2) {
+      current = entry[1];
+      if (value) {
+        versions.set(current, value);
+      }
+    } else if (entry[1] === 'version' && current) {
+      versions.set(current, value);
+    }
+  }
+  return versions;
+}
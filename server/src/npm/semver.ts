@@ -0,0 +1,162 @@
+type Version = [number, number, number, string[]];
+
+function parse(version: string): Version | undefined {
+  const match = /^\s*[v=]?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?\s*$/.exec(version);
+  if (!match) {
+    return undefined;
+  }
+  return [Number(match[1]), Number(match[2]), Number(match[3]), match[4] ? match[4].split('.') : []];
+}
+
+/**
+ * Compare two semantic versions; unparseable versions sort first
+ */
+export function compareVersions(a: string, b: string): number {
+  const left = parse(a);
+  const right = parse(b);
+  if (!left || !right) {
+    return left ? 1 : right ? -1 : 0;
+  }
+  for (let i = 0; i < 3; i++) {
+    if (left[i] !== right[i]) {
+      return (left[i] as number) - (right[i] as number);
+    }
+  }
+  const [x, y] = [left[3], right[3]];
+  if (x.length === 0 || y.length === 0) {
+    return y.length - x.length;
+  }
+  for (let i = 0; i < Math.max(x.length, y.length); i++) {
+    if (x[i] === undefined || y[i] === undefined) {
+      return x[i] === undefined ? -1 : 1;
+    }
+    if (x[i] !== y[i]) {
+      const numeric = /^\d+$/.test(x[i]) && /^\d+$/.test(y[i]);
+      return numeric ? Number(x[i]) - Number(y[i]) : x[i] < y[i] ? -1 : 1;
+    }
+  }
+  return 0;
+}
+
+interface Comparator {
+  operator: '<' | '<=' | '>' | '>=' | '=';
+  version: string;
+}
+
+/**
+ * Expand a partial version such as "1", "1.2" or "1.x" into the comparators
+ * it stands for, applying the given operator
+ */
+function expandPartial(operator: string, partial: string): Comparator[] | undefined {
+  const match = /^[v=]?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$/.exec(partial);
+  if (!match) {
+    return undefined;
+  }
+  const isWild = (part?: string) => part === undefined || /^[xX*]$/.test(part);
+  const [major, minor, patch] = [match[1], match[2], match[3]];
+  const prerelease = match[4] ?? '';
+
+  if (isWild(major)) {
+    return operator === '<' || operator === '>' ? [{ operator: '<', version: '0.0.0-0' }] : [];
+  }
+  if (!isWild(minor) && !isWild(patch)) {
+    const version = `${major}.${minor}.${patch}${prerelease}`;
+    switch (operator) {
+      case '^': {
+        const upper = Number(major) > 0 ? `${Number(major) + 1}.0.0-0`
+          : Number(minor) > 0 ? `0.${Number(minor) + 1}.0-0`
+          : `0.0.${Number(patch) + 1}-0`;
+        return [{ operator: '>=', version }, { operator: '<', version: upper }];
+      }
+      case '~':
+        return [{ operator: '>=', version }, { operator: '<', version: `${major}.${Number(minor) + 1}.0-0` }];
+      case '':
+        return [{ operator: '=', version }];
+      default:
+        return [{ operator: operator as Comparator['operator'], version }];
+    }
+  }
+
+  // Partial versions cover a whole major or minor line
+  const lower = `${major}.${isWild(minor) ? 0 : minor}.0`;
+  const upper = isWild(minor) || (operator === '^' && Number(major) > 0)
+    ? `${Number(major) + 1}.0.0-0`
+    : `${major}.${Number(minor) + 1}.0-0`;
+  switch (operator) {
+    case '>':
+      return [{ operator: '>=', version: upper }];
+    case '<=':
+      return [{ operator: '<', version: upper }];
+    case '<':
+    case '>=':
+      return [{ operator, version: lower }];
+    default:
+      return [{ operator: '>=', version: lower }, { operator: '<', version: upper }];
+  }
+}
+
+function parseComparatorSet(set: string): Comparator[] | undefined {
+  const hyphen = /^\s*(\S+)\s+-\s+(\S+)\s*$/.exec(set);
+  if (hyphen) {
+    const lower = expandPartial('>=', hyphen[1]);
+    const upper = expandPartial('<=', hyphen[2]);
+    return lower && upper ? [...lower, ...upper] : undefined;
+  }
+
+  const comparators: Comparator[] = [];
+  const tokens = set.replace(/(>=|<=|>|<|=|\^|~>?)\s+/g, '$1').trim().split(/\s+/).filter(Boolean);
+  for (const token of tokens) {
+    const match = /^(>=|<=|>|<|=|\^|~>?)?(.+)$/.exec(token);
+    const expanded = match && expandPartial((match[1] ?? '').replace('~>', '~').replace(/^=$/, ''), match[2]);
+    if (!expanded) {
+      return undefined;
+    }
+    comparators.push(...expanded);
+  }
+  return comparators;
+}
+
+/**
+ * Whether a version satisfies an npm version range. Returns undefined for
+ * ranges that aren't plain semver, such as tags, URLs, git and file specs.
+ * Pre-releases only match comparators on the same major.minor.patch, as in npm.
+ */
+export function satisfies(version: string, range: string): boolean | undefined {
+  const parsed = parse(version);
+  if (!parsed) {
+    return undefined;
+  }
+  const normalized = range.trim() === '' || range.trim() === 'latest' ? '*' : range;
+  if (/[:/#]/.test(normalized) && !normalized.startsWith('npm:')) {
+    return undefined;
+  }
+  const spec = normalized.startsWith('npm:') ? normalized.slice(normalized.lastIndexOf('@') + 1) : normalized;
+
+  let recognised = false;
+  for (const set of spec.split('||')) {
+    const comparators = parseComparatorSet(set);
+    if (!comparators) {
+      continue;
+    }
+    recognised = true;
+    const matches = comparators.every(({ operator, version: bound }) => {
+      const order = compareVersions(version, bound);
+      switch (operator) {
+        case '<': return order < 0;
+        case '<=': return order <= 0;
+        case '>': return order > 0;
+        case '>=': return order >= 0;
+        default: return order === 0;
+      }
+    });
+    const prereleaseAllowed = parsed[3].length === 0 || comparators.some(({ version: bound }) => {
+      const b = parse(bound);
+      return b !== undefined && b[3].length > 0 && b[0] === parsed[0] && b[1] === parsed[1] && b[2] === parsed[2]
+        && !bound.endsWith('-0');
+    });
+    if (matches && prereleaseAllowed) {
+      return true;
+    }
+  }
+  return recognised ? false : undefined;
+}
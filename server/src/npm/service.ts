@@ -0,0 +1,267 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { TaskInfo, TaskManager } from '../tasks/manager.js';
+import { LOCKFILES, PackageManager, parsePackageLock, parsePnpmLock, parseYarnLock } from './lockfiles.js';
+import { satisfies } from './semver.js';
+
+export type DependencyType = 'dependencies' | 'devDependencies' | 'optionalDependencies' | 'peerDependencies';
+
+export type NpmPackageCommand = 'install' | 'update';
+
+export interface NpmOptions {
+  // Registry mirror used by install and update, e.g. http://localhost:4873
+  registry?: string;
+}
+
+export interface NpmDependency {
+  name: string;
+  type: DependencyType;
+  // Range declared in package.json
+  declared: string;
+  // Version in node_modules, if installed
+  installed?: string;
+  // Version pinned by the lockfile
+  locked?: string;
+  // 'mismatch' when the installed version doesn't satisfy the declared range
+  status: 'ok' | 'missing' | 'mismatch' | 'unknown';
+}
+
+export interface NpmPackage {
+  // Workspace path of the package directory, e.g. / or /web
+  path: string;
+  name?: string;
+  version?: string;
+  packageManager: PackageManager;
+  lockfile?: string;
+  scripts: Record<string, string>;
+  dependencies: NpmDependency[];
+}
+
+const DEPENDENCY_TYPES: DependencyType[] = ['dependencies', 'devDependencies', 'optionalDependencies', 'peerDependencies'];
+
+// Directories that never contain packages worth listing
+const IGNORED_DIRECTORIES = new Set(['node_modules', 'vendor', 'dist', 'build', 'coverage']);
+
+const PACKAGE_MANAGER_ARGS: Record<PackageManager, Record<NpmPackageCommand, string[]>> = {
+  npm: { install: ['install'], update: ['update'] },
+  yarn: { install: ['install'], update: ['upgrade'] },
+  pnpm: { install: ['install'], update: ['update'] }
+};
+
+/**
+ * NpmService finds package.json files in the workspace, compares declared
+ * dependency ranges with node_modules and the lockfile, and runs scripts
+ * and installs as tasks.
+ */
+export class NpmService {
+  constructor(
+    private workspaceRoot: string,
+    private taskManager: TaskManager,
+    private options: NpmOptions = {}
+  ) {}
+
+  /**
+   * List every package in the workspace
+   */
+  async listPackages(): Promise<NpmPackage[]> {
+    const directories: string[] = [];
+    await this.findPackageDirectories(path.resolve(this.workspaceRoot), directories);
+
+    const packages: NpmPackage[] = [];
+    for (const directory of directories.sort()) {
+      try {
+        packages.push(await this.readPackage(directory));
+      } catch (error) {
+        console.warn(`[npm] Skipping ${directory}: ${(error as Error).message}`);
+      }
+    }
+    return packages;
+  }
+
+  /**
+   * Read one package by the workspace path of its directory
+   */
+  async getPackage(packagePath: string): Promise<NpmPackage> {
+    return this.readPackage(this.resolvePackageDir(packagePath));
+  }
+
+  /**
+   * Start a package.json script. Returns as soon as the task has started;
+   * output is reported through the task manager.
+   */
+  async runScript(packagePath: string, script: string): Promise<TaskInfo> {
+    const pkg = await this.getPackage(packagePath);
+    if (!(script in pkg.scripts)) {
+      throw new Error(`Unknown script: ${script}`);
+    }
+    return this.taskManager.run({
+      label: `${pkg.packageManager} run ${script}${pkg.path === '/' ? '' : ` (${pkg.path})`}`,
+      command: pkg.packageManager,
+      args: ['run', script],
+      cwd: this.resolvePackageDir(packagePath)
+    });
+  }
+
+  /**
+   * Start an install, or an update of all or one dependency, with the
+   * package manager the lockfile belongs to
+   */
+  async runCommand(packagePath: string, command: NpmPackageCommand, dependency?: string): Promise<TaskInfo> {
+    const pkg = await this.getPackage(packagePath);
+    if (dependency && !pkg.dependencies.some(d => d.name === dependency)) {
+      throw new Error(`Unknown dependency: ${dependency}`);
+    }
+    const args = [...PACKAGE_MANAGER_ARGS[pkg.packageManager][command], ...(dependency ? [dependency] : [])];
+    const env: Record<string, string> = {};
+    if (this.options.registry) {
+      // Understood by npm, pnpm and yarn classic
+      env.npm_config_registry = this.options.registry;
+      env.YARN_NPM_REGISTRY_SERVER = this.options.registry;
+    }
+    return this.taskManager.run({
+      label: `${pkg.packageManager} ${args.join(' ')}${pkg.path === '/' ? '' : ` (${pkg.path})`}`,
+      command: pkg.packageManager,
+      args,
+      cwd: this.resolvePackageDir(packagePath),
+      env
+    });
+  }
+
+  private async findPackageDirectories(dir: string, result: string[]): Promise<void> {
+    let entries;
+    try {
+      entries = await fs.readdir(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+    if (entries.some(entry => entry.isFile() && entry.name === 'package.json')) {
+      result.push(dir);
+    }
+    for (const entry of entries) {
+      if (entry.isDirectory() && !entry.name.startsWith('.') && !IGNORED_DIRECTORIES.has(entry.name)) {
+        await this.findPackageDirectories(path.join(dir, entry.name), result);
+      }
+    }
+  }
+
+  private async readPackage(dir: string): Promise<NpmPackage> {
+    const manifest = JSON.parse(await fs.readFile(path.join(dir, 'package.json'), 'utf-8'));
+    const { packageManager, lockfile, locked } = await this.readLockfile(dir);
+
+    const dependencies: NpmDependency[] = [];
+    for (const type of DEPENDENCY_TYPES) {
+      const declaredDeps = manifest[type];
+      if (!declaredDeps || typeof declaredDeps !== 'object') {
+        continue;
+      }
+      for (const [name, declared] of Object.entries<string>(declaredDeps)) {
+        if (dependencies.some(d => d.name === name)) {
+          // Already listed, e.g. a peer dependency that is also a dev dependency
+          continue;
+        }
+        const installed = await this.readInstalledVersion(dir, name);
+        const matches = installed ? satisfies(installed, String(declared)) : undefined;
+        dependencies.push({
+          name,
+          type,
+          declared: String(declared),
+          installed,
+          locked: locked(name, String(declared)),
+          status: !installed
+            ? (type === 'peerDependencies' || type === 'optionalDependencies' ? 'unknown' : 'missing')
+            : matches === undefined ? 'unknown' : matches ? 'ok' : 'mismatch'
+        });
+      }
+    }
+
+    const relative = path.relative(path.resolve(this.workspaceRoot), dir).split(path.sep).join('/');
+    return {
+      path: '/' + relative,
+      name: typeof manifest.name === 'string' ? manifest.name : undefined,
+      version: typeof manifest.version === 'string' ? manifest.version : undefined,
+      packageManager,
+      lockfile,
+      scripts: manifest.scripts && typeof manifest.scripts === 'object' ? { ...manifest.scripts } : {},
+      dependencies
+    };
+  }
+
+  /**
+   * Find the lockfile of a package, looking in parent directories up to the
+   * workspace root for workspaces/monorepos
+   */
+  private async readLockfile(dir: string): Promise<{
+    packageManager: PackageManager;
+    lockfile?: string;
+    locked: (name: string, range: string) => string | undefined;
+  }> {
+    const root = path.resolve(this.workspaceRoot);
+    for (let current = dir; ; current = path.dirname(current)) {
+      for (const [manager, file] of Object.entries(LOCKFILES) as [PackageManager, string][]) {
+        let content: string;
+        try {
+          content = await fs.readFile(path.join(current, file), 'utf-8');
+        } catch {
+          continue;
+        }
+        const lockfile = '/' + path.relative(root, path.join(current, file)).split(path.sep).join('/');
+        try {
+          if (manager === 'yarn') {
+            const versions = parseYarnLock(content);
+            return { packageManager: manager, lockfile, locked: (name, range) => versions.get(`${name}@${range}`) };
+          }
+          if (manager === 'npm') {
+            // Nested workspace packages may have their own copy; otherwise
+            // the dependency is hoisted to the lockfile's directory
+            const versions = parsePackageLock(content);
+            const prefix = path.relative(current, dir).split(path.sep).join('/');
+            return {
+              packageManager: manager,
+              lockfile,
+              locked: name => (prefix && versions.get(`${prefix}/node_modules/${name}`)) || versions.get(`node_modules/${name}`)
+            };
+          }
+          // Only the root project of a pnpm lockfile is read
+          const versions = parsePnpmLock(content);
+          return { packageManager: manager, lockfile, locked: name => (current === dir ? versions.get(name) : undefined) };
+        } catch (error) {
+          console.warn(`[npm] Failed to parse ${lockfile}: ${(error as Error).message}`);
+          return { packageManager: manager, lockfile, locked: () => undefined };
+        }
+      }
+      if (current === root || path.dirname(current) === current) {
+        return { packageManager: 'npm', locked: () => undefined };
+      }
+    }
+  }
+
+  /**
+   * Version of a dependency resolved the way Node does: the nearest
+   * node_modules up to the workspace root
+   */
+  private async readInstalledVersion(dir: string, name: string): Promise<string | undefined> {
+    const root = path.resolve(this.workspaceRoot);
+    for (let current = dir; ; current = path.dirname(current)) {
+      try {
+        const manifest = JSON.parse(await fs.readFile(path.join(current, 'node_modules', name, 'package.json'), 'utf-8'));
+        if (typeof manifest.version === 'string') {
+          return manifest.version;
+        }
+      } catch {
+        // Not installed here
+      }
+      if (current === root || path.dirname(current) === current) {
+        return undefined;
+      }
+    }
+  }
+
+  private resolvePackageDir(packagePath: string): string {
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(root, packagePath.replace(/^[/\\]+/, ''));
+    if (resolved !== root && !resolved.startsWith(root + path.sep)) {
+      throw new Error('Access denied: path outside workspace');
+    }
+    return resolved;
+  }
+}
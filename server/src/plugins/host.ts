@@ -0,0 +1,461 @@
+import express, { Request, Response, Router } from 'express';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import { LSPWebSocketServer } from '../transport/websocket.js';
+import { LanguageServerManager } from '../lsp/manager.js';
+import { TaskManager } from '../tasks/manager.js';
+import { EventBus } from '../events/bus.js';
+import { ANONYMOUS_USER } from '../auth/tokens.js';
+import { sendApiError } from '../api/router.js';
+import { workspacePathToUri } from '../fs/uris.js';
+import {
+  LspMiddleware,
+  PluginContext,
+  PluginHttpMethod,
+  PluginInfo,
+  PluginLogger,
+  ServerPlugin
+} from './types.js';
+
+export interface PluginHostDependencies {
+  workspaceRoot: string;
+  wsServer: Pick<LSPWebSocketServer, 'onMethod' | 'offMethod' | 'hasMethod' | 'sendToClient' | 'sendError' | 'broadcast' | 'getClientUser'>;
+  lsManager: Pick<LanguageServerManager, 'addConfig' | 'removeConfig'>;
+  taskManager: Pick<TaskManager, 'run' | 'wait' | 'kill'>;
+  events: EventBus;
+  // plugins.options from the config file, keyed by plugin name
+  options?: Record<string, Record<string, unknown>>;
+}
+
+interface LoadedPlugin {
+  plugin?: ServerPlugin;
+  info: PluginInfo;
+  router: Router;
+  // Undo functions for everything registered during the current activation
+  disposers: Array<() => void | Promise<void>>;
+}
+
+const ACTIVATION_TIMEOUT_MS = 10000;
+const PLUGIN_NAME_PATTERN = /^[a-z0-9][a-z0-9._-]*$/i;
+const PLUGIN_EXTENSIONS = ['.js', '.mjs', '.cjs'];
+
+/**
+ * PluginHost loads server plugins, hands each one a PluginContext and keeps
+ * track of what it registered, so a plugin that fails to activate (or is
+ * deactivated) leaves nothing behind. Errors thrown by plugin handlers are
+ * caught and reported instead of taking the server down.
+ */
+export class PluginHost {
+  // Mounted at /api/plugins; each plugin's routes live under /<name>
+  readonly router: Router = express.Router();
+  private plugins: Map<string, LoadedPlugin> = new Map();
+  private lspMiddleware: LspMiddleware[] = [];
+
+  constructor(private deps: PluginHostDependencies) {}
+
+  /**
+   * Import every plugin in a directory: *.js / *.mjs files, or folders with a
+   * package.json "main" or an index.js. Plugins are not activated yet.
+   */
+  async loadFromDirectory(dir: string): Promise<void> {
+    let entries;
+    try {
+      entries = await fs.readdir(dir, { withFileTypes: true });
+    } catch (error) {
+      console.error(`[Plugins] Cannot read plugins directory ${dir}:`, (error as Error).message);
+      return;
+    }
+
+    for (const entry of entries.sort((a, b) => a.name.localeCompare(b.name))) {
+      if (entry.name.startsWith('.') || entry.name === 'node_modules') {
+        continue;
+      }
+      const source = path.join(dir, entry.name);
+      let entryFile: string | undefined;
+      if (entry.isDirectory()) {
+        entryFile = await this.resolveDirectoryEntry(source);
+      } else if (PLUGIN_EXTENSIONS.includes(path.extname(entry.name))) {
+        entryFile = source;
+      }
+      if (!entryFile) {
+        continue;
+      }
+
+      const fallbackName = path.basename(entry.name, path.extname(entry.name));
+      try {
+        const module = await import(pathToFileURL(entryFile).href);
+        this.add(module.default ?? module.plugin, source);
+      } catch (error) {
+        this.addFailed(fallbackName, source, `Failed to load: ${(error as Error).message}`);
+      }
+    }
+  }
+
+  /**
+   * Register a plugin object. Invalid or duplicate plugins are recorded as failed.
+   */
+  add(plugin: ServerPlugin, source: string): void {
+    const fallbackName = path.basename(source, path.extname(source));
+    if (!plugin || typeof plugin !== 'object' || typeof plugin.activate !== 'function') {
+      this.addFailed(fallbackName, source, 'Plugin must export an object with an activate() function');
+      return;
+    }
+    if (typeof plugin.name !== 'string' || !PLUGIN_NAME_PATTERN.test(plugin.name)) {
+      this.addFailed(fallbackName, source, `Invalid plugin name: ${JSON.stringify(plugin.name)}`);
+      return;
+    }
+    if (this.plugins.has(plugin.name)) {
+      console.error(`[Plugins] Ignoring ${source}: a plugin named ${plugin.name} is already loaded`);
+      return;
+    }
+
+    const record: LoadedPlugin = {
+      plugin,
+      info: {
+        name: plugin.name,
+        version: plugin.version,
+        source,
+        status: 'inactive',
+        errorCount: 0,
+        routes: [],
+        methods: [],
+        languageServers: []
+      },
+      router: express.Router(),
+      disposers: []
+    };
+    this.plugins.set(plugin.name, record);
+
+    // Only active plugins receive requests
+    this.router.use(`/${plugin.name}`, (req, res, next) => {
+      if (record.info.status === 'active') {
+        record.router(req, res, next);
+      } else {
+        next();
+      }
+    });
+  }
+
+  /**
+   * Activate every loaded plugin that isn't active yet
+   */
+  async activateAll(): Promise<void> {
+    for (const record of this.plugins.values()) {
+      if (record.plugin && record.info.status === 'inactive') {
+        await this.activate(record);
+      }
+    }
+  }
+
+  /**
+   * Deactivate all plugins in reverse activation order
+   */
+  async deactivateAll(): Promise<void> {
+    for (const record of Array.from(this.plugins.values()).reverse()) {
+      if (record.info.status !== 'active') {
+        continue;
+      }
+      try {
+        await record.plugin?.deactivate?.();
+      } catch (error) {
+        this.recordError(record, error);
+      }
+      await this.dispose(record);
+      record.info.status = 'inactive';
+      console.log(`[Plugins] Deactivated ${record.info.name}`);
+    }
+  }
+
+  /**
+   * Describe all known plugins
+   */
+  list(): PluginInfo[] {
+    return Array.from(this.plugins.values()).map(record => ({
+      ...record.info,
+      routes: [...record.info.routes],
+      methods: [...record.info.methods],
+      languageServers: [...record.info.languageServers]
+    }));
+  }
+
+  /**
+   * The live list of LSP middleware, shared with every LSPProxy
+   */
+  getLspMiddleware(): LspMiddleware[] {
+    return this.lspMiddleware;
+  }
+
+  private async activate(record: LoadedPlugin): Promise<void> {
+    const plugin = record.plugin!;
+    const context = this.createContext(record);
+    let timer: NodeJS.Timeout | undefined;
+
+    try {
+      await Promise.race([
+        Promise.resolve().then(() => plugin.activate(context)),
+        new Promise((_, reject) => {
+          timer = setTimeout(() => reject(new Error(`activate() did not finish within ${ACTIVATION_TIMEOUT_MS}ms`)), ACTIVATION_TIMEOUT_MS);
+        })
+      ]);
+      record.info.status = 'active';
+      console.log(`[Plugins] Activated ${plugin.name}${plugin.version ? ` ${plugin.version}` : ''}`);
+    } catch (error) {
+      record.info.status = 'failed';
+      record.info.error = (error as Error)?.message ?? String(error);
+      console.error(`[Plugins] Failed to activate ${plugin.name}:`, error);
+      await this.dispose(record);
+    } finally {
+      clearTimeout(timer);
+    }
+  }
+
+  private createContext(record: LoadedPlugin): PluginContext {
+    const { name } = record.info;
+    const { workspaceRoot, wsServer, lsManager, taskManager, events } = this.deps;
+    const info = record.info;
+    const disposers = record.disposers;
+
+    // Registrations are only accepted until the plugin is disposed
+    const ensureOpen = () => {
+      if (record.disposers !== disposers) {
+        throw new Error(`Plugin ${name} is no longer active`);
+      }
+    };
+
+    const log: PluginLogger = {
+      info: (message, ...args) => console.log(`[Plugin:${name}] ${message}`, ...args),
+      warn: (message, ...args) => console.warn(`[Plugin:${name}] ${message}`, ...args),
+      error: (message, ...args) => console.error(`[Plugin:${name}] ${message}`, ...args)
+    };
+
+    return {
+      name,
+      workspaceRoot,
+      options: this.deps.options?.[name] ?? {},
+      log,
+
+      registerRoute: (method: PluginHttpMethod, routePath: string, handler) => {
+        ensureOpen();
+        if (!routePath.startsWith('/')) {
+          throw new Error(`Route path must start with /: ${routePath}`);
+        }
+        record.router[method](routePath, async (req: Request, res: Response) => {
+          try {
+            const result = await handler(req, res);
+            if (!res.headersSent) {
+              res.json(result ?? null);
+            }
+          } catch (error) {
+            this.recordError(record, error);
+            sendApiError(res, error, `plugin ${name}`);
+          }
+        });
+        info.routes.push(`${method.toUpperCase()} /api/plugins/${name}${routePath}`);
+      },
+
+      registerMethod: (method, handler) => {
+        ensureOpen();
+        if (wsServer.hasMethod(method)) {
+          throw new Error(`JSON-RPC method already registered: ${method}`);
+        }
+        wsServer.onMethod(method, async (clientId, message) => {
+          const isRequest = message.id !== undefined && message.id !== null;
+          try {
+            const result = await handler(message.params, {
+              clientId,
+              user: wsServer.getClientUser(clientId) ?? ANONYMOUS_USER
+            });
+            if (isRequest) {
+              wsServer.sendToClient(clientId, { jsonrpc: '2.0', id: message.id, result: result ?? null });
+            }
+          } catch (error) {
+            this.recordError(record, error);
+            if (isRequest) {
+              wsServer.sendError(clientId, -32603, `Plugin ${name} failed: ${(error as Error).message}`, message.id);
+            }
+          }
+        });
+        info.methods.push(method);
+        disposers.push(() => wsServer.offMethod(method));
+      },
+
+      registerLspMiddleware: (middleware) => {
+        ensureOpen();
+        const wrapped: LspMiddleware = {};
+        if (middleware.incoming) {
+          wrapped.incoming = async (message, ctx) => {
+            try {
+              return await middleware.incoming!(message, ctx);
+            } catch (error) {
+              this.recordError(record, error);
+              return message;
+            }
+          };
+        }
+        if (middleware.outgoing) {
+          wrapped.outgoing = async (response, request, ctx) => {
+            try {
+              return await middleware.outgoing!(response, request, ctx);
+            } catch (error) {
+              this.recordError(record, error);
+              return response;
+            }
+          };
+        }
+        this.lspMiddleware.push(wrapped);
+        disposers.push(() => {
+          const index = this.lspMiddleware.indexOf(wrapped);
+          if (index !== -1) {
+            this.lspMiddleware.splice(index, 1);
+          }
+        });
+      },
+
+      registerLanguageServer: (config) => {
+        ensureOpen();
+        if (!config || typeof config.languageId !== 'string' || typeof config.command !== 'string') {
+          throw new Error('Language server config needs a languageId and a command');
+        }
+        lsManager.addConfig({
+          languageId: config.languageId,
+          command: config.command,
+          args: config.args ?? [],
+          fileExtensions: config.fileExtensions ?? []
+        });
+        info.languageServers.push(config.languageId);
+        disposers.push(() => lsManager.removeConfig(config.languageId));
+      },
+
+      registerWebExtension: (file) => {
+        ensureOpen();
+        const bundlePath = file instanceof URL ? fileURLToPath(file) : path.resolve(file);
+        record.router.get('/extension.js', (req: Request, res: Response) => {
+          res.type('text/javascript');
+          res.setHeader('Cache-Control', 'no-cache');
+          res.sendFile(bundlePath, (error) => {
+            if (error && !res.headersSent) {
+              this.recordError(record, error);
+              sendApiError(res, new Error(`Web extension bundle not found: ${bundlePath}`));
+            }
+          });
+        });
+        info.webExtension = `/api/plugins/${name}/extension.js`;
+      },
+
+      notifyClient: (clientId, method, params) =>
+        wsServer.sendToClient(clientId, { jsonrpc: '2.0', method, params }),
+
+      broadcast: (method, params) => {
+        wsServer.broadcast({ jsonrpc: '2.0', method, params });
+      },
+
+      showDocument: (target, { clientId, selection, takeFocus } = {}) => {
+        const external = !target.startsWith('/');
+        const message = {
+          jsonrpc: '2.0' as const,
+          method: 'window/showDocument',
+          params: { uri: external ? target : workspacePathToUri(target), external, selection, takeFocus }
+        };
+        if (clientId) {
+          wsServer.sendToClient(clientId, message);
+        } else {
+          wsServer.broadcast(message);
+        }
+      },
+
+      onEvent: (listener) => {
+        ensureOpen();
+        const unsubscribe = events.subscribe((event) => {
+          try {
+            Promise.resolve(listener(event)).catch(error => this.recordError(record, error));
+          } catch (error) {
+            this.recordError(record, error);
+          }
+        });
+        disposers.push(unsubscribe);
+      },
+
+      runTask: (options) => {
+        ensureOpen();
+        const task = taskManager.run({
+          ...options,
+          label: options.label ?? `${name}: ${[options.command, ...(options.args ?? [])].join(' ')}`,
+          cwd: options.cwd ? path.resolve(workspaceRoot, options.cwd) : workspaceRoot
+        });
+        const kill = () => {
+          taskManager.kill(task.id);
+        };
+        disposers.push(kill);
+        // Only running tasks need killing on unload
+        const forget = () => {
+          const index = disposers.indexOf(kill);
+          if (index !== -1) disposers.splice(index, 1);
+        };
+        taskManager.wait(task.id).then(forget, forget);
+        return task;
+      },
+
+      waitForTask: (taskId) => taskManager.wait(taskId)
+    };
+  }
+
+  /**
+   * Undo a plugin's registrations, most recent first
+   */
+  private async dispose(record: LoadedPlugin): Promise<void> {
+    const disposers = record.disposers;
+    record.disposers = [];
+    for (const dispose of disposers.reverse()) {
+      try {
+        await dispose();
+      } catch (error) {
+        console.error(`[Plugins] Error cleaning up ${record.info.name}:`, error);
+      }
+    }
+    record.router = express.Router();
+    record.info.routes = [];
+    record.info.methods = [];
+    record.info.languageServers = [];
+    record.info.webExtension = undefined;
+  }
+
+  private recordError(record: LoadedPlugin, error: unknown): void {
+    record.info.errorCount++;
+    record.info.error = (error as Error)?.message ?? String(error);
+    console.error(`[Plugins] ${record.info.name} raised an error:`, error);
+  }
+
+  private addFailed(name: string, source: string, error: string): void {
+    console.error(`[Plugins] ${source}: ${error}`);
+    if (this.plugins.has(name)) {
+      return;
+    }
+    this.plugins.set(name, {
+      info: { name, source, status: 'failed', error, errorCount: 0, routes: [], methods: [], languageServers: [] },
+      router: express.Router(),
+      disposers: []
+    });
+  }
+
+  private async resolveDirectoryEntry(dir: string): Promise<string | undefined> {
+    try {
+      const pkg = JSON.parse(await fs.readFile(path.join(dir, 'package.json'), 'utf-8'));
+      if (typeof pkg.main === 'string') {
+        return path.join(dir, pkg.main);
+      }
+    } catch {
+      // No package.json; fall back to index files
+    }
+    for (const ext of PLUGIN_EXTENSIONS) {
+      const candidate = path.join(dir, `index${ext}`);
+      try {
+        await fs.access(candidate);
+        return candidate;
+      } catch {
+        // Try the next extension
+      }
+    }
+    return undefined;
+  }
+}
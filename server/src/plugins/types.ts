@@ -0,0 +1,112 @@
+import { Request, Response } from 'express';
+import { Range } from 'vscode-languageserver-protocol';
+import { LSPMessage } from '../lsp/proxy.js';
+import { LanguageServerConfig } from '../lsp/manager.js';
+import { TaskInfo, TaskOptions } from '../tasks/manager.js';
+import { WorkspaceEvent } from '../events/bus.js';
+
+/**
+ * A server plugin module's default export
+ */
+export interface ServerPlugin {
+  // Unique name; also the URL segment of the plugin's routes (/api/plugins/<name>)
+  name: string;
+  version?: string;
+  activate(context: PluginContext): void | Promise<void>;
+  // Called on shutdown. Registrations made through the context are removed automatically.
+  deactivate?(): void | Promise<void>;
+}
+
+export type PluginHttpMethod = 'get' | 'post' | 'put' | 'patch' | 'delete';
+
+/**
+ * Handles a plugin REST route. The returned value is sent as JSON unless the
+ * handler already wrote a response; thrown errors use the standard API error shape.
+ */
+export type PluginRouteHandler = (req: Request, res: Response) => unknown | Promise<unknown>;
+
+export interface PluginMethodContext {
+  clientId: string;
+  user: string;
+}
+
+/**
+ * Handles a JSON-RPC method sent over the LSP WebSocket. For requests the
+ * returned value becomes the result; for notifications it is ignored.
+ */
+export type PluginMethodHandler = (params: any, context: PluginMethodContext) => unknown | Promise<unknown>;
+
+export interface LspMiddlewareContext {
+  clientId: string;
+}
+
+/**
+ * Inspects or rewrites LSP traffic handled by LSPProxy. Hooks run in
+ * registration order; a hook that throws is skipped.
+ */
+export interface LspMiddleware {
+  // Client message before the proxy handles it; return null to drop it
+  incoming?(message: LSPMessage, context: LspMiddlewareContext): LSPMessage | null | Promise<LSPMessage | null>;
+  // Response to a client request before it is sent back
+  outgoing?(response: LSPMessage, request: LSPMessage, context: LspMiddlewareContext): LSPMessage | Promise<LSPMessage>;
+}
+
+export interface PluginLogger {
+  info(message: string, ...args: unknown[]): void;
+  warn(message: string, ...args: unknown[]): void;
+  error(message: string, ...args: unknown[]): void;
+}
+
+export interface PluginShowDocumentOptions {
+  // Only this client; every client if unset
+  clientId?: string;
+  // Zero-based range to select, as in LSP
+  selection?: Range;
+  takeFocus?: boolean;
+}
+
+/**
+ * Everything a plugin may touch. Each registration is tied to the plugin and
+ * undone when it is deactivated or fails to activate.
+ */
+export interface PluginContext {
+  name: string;
+  workspaceRoot: string;
+  // plugins.options[<name>] from the config file
+  options: Record<string, unknown>;
+  log: PluginLogger;
+  registerRoute(method: PluginHttpMethod, path: string, handler: PluginRouteHandler): void;
+  registerMethod(method: string, handler: PluginMethodHandler): void;
+  registerLspMiddleware(middleware: LspMiddleware): void;
+  registerLanguageServer(config: LanguageServerConfig): void;
+  // Serve a browser ES module that the web app loads as this plugin's extension
+  registerWebExtension(file: string | URL): void;
+  // Send a JSON-RPC notification to one client, or to every connected client
+  notifyClient(clientId: string, method: string, params?: unknown): boolean;
+  broadcast(method: string, params?: unknown): void;
+  // Open a workspace path (e.g. /docs/api.md) or an external URL in the browser of
+  // one client, or of every client. External URLs are confirmed by the user first.
+  showDocument(target: string, options?: PluginShowDocumentOptions): void;
+  onEvent(listener: (event: WorkspaceEvent) => void | Promise<void>): void;
+  // Run a command (cwd defaults to the workspace root); killed if the plugin is deactivated
+  runTask(options: Omit<TaskOptions, 'cwd'> & { cwd?: string }): TaskInfo;
+  waitForTask(taskId: string): Promise<TaskInfo>;
+}
+
+export type PluginStatus = 'active' | 'failed' | 'inactive';
+
+export interface PluginInfo {
+  name: string;
+  version?: string;
+  // File or directory the plugin was loaded from
+  source: string;
+  status: PluginStatus;
+  // Activation failure, or the most recent error raised by one of its handlers
+  error?: string;
+  errorCount: number;
+  routes: string[];
+  methods: string[];
+  languageServers: string[];
+  // URL of the plugin's web extension bundle, if it has one
+  webExtension?: string;
+}
@@ -0,0 +1,279 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { TaskManager } from '../tasks/manager.js';
+
+export interface ListeningPort {
+  port: number;
+  // Address to connect to, e.g. 127.0.0.1 or ::1
+  host: string;
+  pid: number;
+  // Name of the listening process, e.g. node or main
+  command: string;
+  // Task the process was started by, if any
+  taskId?: string;
+  taskLabel?: string;
+}
+
+export interface PortDetectorOptions {
+  // Ports never reported, e.g. the editor's own
+  ignore?: number[];
+  // How often to look for new ports
+  intervalMs?: number;
+  // Where the proc filesystem is mounted (for tests)
+  procRoot?: string;
+}
+
+export interface ProcSocket {
+  inode: string;
+  port: number;
+  address: string;
+}
+
+type ChangeListener = (ports: ListeningPort[]) => void;
+
+// Socket state of a listening TCP socket in /proc/net/tcp
+const TCP_LISTEN = '0A';
+const DEFAULT_INTERVAL_MS = 2000;
+
+/**
+ * Decode a /proc/net/tcp address: IPv4 is one little-endian word, IPv6 four
+ */
+function decodeAddress(hex: string): string {
+  const words = hex.match(/.{8}/g) ?? [];
+  const bytes = words.flatMap(word => (word.match(/../g) ?? []).reverse().map(byte => parseInt(byte, 16)));
+  if (bytes.length === 4) {
+    return bytes.join('.');
+  }
+  // IPv4-mapped IPv6 (::ffff:a.b.c.d)
+  if (bytes.slice(0, 10).every(byte => byte === 0) && bytes[10] === 0xff && bytes[11] === 0xff) {
+    return bytes.slice(12).join('.');
+  }
+  if (bytes.every(byte => byte === 0)) {
+    return '::';
+  }
+  if (bytes.slice(0, 15).every(byte => byte === 0) && bytes[15] === 1) {
+    return '::1';
+  }
+  const groups: string[] = [];
+  for (let i = 0; i < bytes.length; i += 2) {
+    groups.push(((bytes[i] << 8) | bytes[i + 1]).toString(16));
+  }
+  return groups.join(':');
+}
+
+/**
+ * Listening sockets in the content of /proc/net/tcp or /proc/net/tcp6
+ */
+export function parseProcNetTcp(content: string): ProcSocket[] {
+  const sockets: ProcSocket[] = [];
+  for (const line of content.split('\n').slice(1)) {
+    const fields = line.trim().split(/\s+/);
+    if (fields.length < 10 || fields[3] !== TCP_LISTEN) continue;
+    const [address, port] = fields[1].split(':');
+    sockets.push({ inode: fields[9], port: parseInt(port, 16), address: decodeAddress(address) });
+  }
+  return sockets;
+}
+
+/**
+ * Address to connect to for a socket bound to an address
+ */
+function connectHost(address: string): string {
+  if (address === '0.0.0.0') return '127.0.0.1';
+  if (address === '::') return '::1';
+  return address;
+}
+
+/**
+ * PortDetector finds TCP ports that processes started from the workspace
+ * listen on: tasks and their children, and any process whose working
+ * directory is inside the workspace. It reads /proc, so it only finds ports
+ * on Linux, and only of processes running as the server's user.
+ */
+export class PortDetector {
+  private ports: ListeningPort[] = [];
+  private signature = '[]';
+  private listeners: ChangeListener[] = [];
+  private timer?: NodeJS.Timeout;
+  private procRoot: string;
+
+  constructor(
+    private workspaceRoot: string,
+    private taskManager: TaskManager,
+    private options: PortDetectorOptions = {}
+  ) {
+    this.procRoot = options.procRoot ?? '/proc';
+  }
+
+  /**
+   * Look for ports now and then periodically
+   */
+  start(): void {
+    if (this.timer) return;
+    this.timer = setInterval(() => {
+      void this.refresh();
+    }, this.options.intervalMs ?? DEFAULT_INTERVAL_MS);
+    this.timer.unref();
+    void this.refresh();
+  }
+
+  stop(): void {
+    clearInterval(this.timer);
+    this.timer = undefined;
+  }
+
+  /**
+   * Ports found by the latest scan, lowest first
+   */
+  getPorts(): ListeningPort[] {
+    return this.ports.map(port => ({ ...port }));
+  }
+
+  getPort(port: number): ListeningPort | undefined {
+    return this.ports.find(candidate => candidate.port === port);
+  }
+
+  /**
+   * Register a listener called when ports are opened or closed
+   */
+  onChange(listener: ChangeListener): void {
+    this.listeners.push(listener);
+  }
+
+  /**
+   * Scan for ports now, notifying listeners if they changed
+   */
+  async refresh(): Promise<ListeningPort[]> {
+    let ports: ListeningPort[];
+    try {
+      ports = await this.scan();
+    } catch (error) {
+      console.error('[Ports] Failed to scan for listening ports:', error);
+      return this.getPorts();
+    }
+    const signature = JSON.stringify(ports);
+    if (signature !== this.signature) {
+      this.ports = ports;
+      this.signature = signature;
+      this.listeners.forEach(listener => listener(this.getPorts()));
+    }
+    return this.getPorts();
+  }
+
+  private async scan(): Promise<ListeningPort[]> {
+    const sockets = new Map<string, ProcSocket>();
+    for (const file of ['tcp', 'tcp6']) {
+      try {
+        const content = await fs.readFile(path.join(this.procRoot, 'net', file), 'utf-8');
+        parseProcNetTcp(content).forEach(socket => sockets.set(socket.inode, socket));
+      } catch {
+        // No /proc (not Linux) or no IPv6
+      }
+    }
+    if (sockets.size === 0) {
+      return [];
+    }
+
+    const processes = await this.readProcesses();
+    const owners = this.findTaskProcesses(processes);
+    const root = await fs.realpath(this.workspaceRoot).catch(() => path.resolve(this.workspaceRoot));
+    const ignore = new Set(this.options.ignore ?? []);
+    const found = new Map<number, ListeningPort>();
+
+    await Promise.all(Array.from(processes.keys()).map(async (pid) => {
+      if (pid === process.pid) return;
+      const task = owners.get(pid);
+      if (!task && !(await this.isInWorkspace(pid, root))) return;
+
+      for (const inode of await this.readSocketInodes(pid)) {
+        const socket = sockets.get(inode);
+        if (!socket || ignore.has(socket.port)) continue;
+        const existing = found.get(socket.port);
+        if (existing && (existing.taskId || !task)) continue;
+        found.set(socket.port, {
+          port: socket.port,
+          host: connectHost(socket.address),
+          pid,
+          command: processes.get(pid)!.command,
+          ...(task ? { taskId: task.id, taskLabel: task.label } : {})
+        });
+      }
+    }));
+
+    return Array.from(found.values()).sort((a, b) => a.port - b.port);
+  }
+
+  /**
+   * Parent and name of every process
+   */
+  private async readProcesses(): Promise<Map<number, { ppid: number; command: string }>> {
+    const processes = new Map<number, { ppid: number; command: string }>();
+    const entries = await fs.readdir(this.procRoot);
+    await Promise.all(entries.filter(entry => /^\d+$/.test(entry)).map(async (entry) => {
+      try {
+        // "<pid> (<comm>) <state> <ppid> ..."; comm may itself contain spaces and parentheses
+        const stat = await fs.readFile(path.join(this.procRoot, entry, 'stat'), 'utf-8');
+        const commEnd = stat.lastIndexOf(')');
+        const fields = stat.slice(commEnd + 2).split(' ');
+        processes.set(Number(entry), {
+          ppid: Number(fields[1]),
+          command: stat.slice(stat.indexOf('(') + 1, commEnd)
+        });
+      } catch {
+        // Exited while scanning
+      }
+    }));
+    return processes;
+  }
+
+  /**
+   * Map running tasks' processes and all their descendants to the task
+   */
+  private findTaskProcesses(processes: Map<number, { ppid: number }>): Map<number, { id: string; label: string }> {
+    const children = new Map<number, number[]>();
+    processes.forEach(({ ppid }, pid) => {
+      children.set(ppid, [...(children.get(ppid) ?? []), pid]);
+    });
+
+    const owners = new Map<number, { id: string; label: string }>();
+    for (const { task, pid } of this.taskManager.listProcesses()) {
+      const queue = [pid];
+      while (queue.length > 0) {
+        const current = queue.shift()!;
+        if (owners.has(current)) continue;
+        owners.set(current, { id: task.id, label: task.label });
+        queue.push(...(children.get(current) ?? []));
+      }
+    }
+    return owners;
+  }
+
+  private async isInWorkspace(pid: number, root: string): Promise<boolean> {
+    try {
+      const cwd = await fs.readlink(path.join(this.procRoot, String(pid), 'cwd'));
+      return cwd === root || cwd.startsWith(root + path.sep);
+    } catch {
+      return false;
+    }
+  }
+
+  private async readSocketInodes(pid: number): Promise<string[]> {
+    const fdDir = path.join(this.procRoot, String(pid), 'fd');
+    let fds: string[];
+    try {
+      fds = await fs.readdir(fdDir);
+    } catch {
+      return [];
+    }
+    const inodes: string[] = [];
+    await Promise.all(fds.map(async (fd) => {
+      try {
+        const match = /^socket:\[(\d+)\]$/.exec(await fs.readlink(path.join(fdDir, fd)));
+        if (match) inodes.push(match[1]);
+      } catch {
+        // Closed while scanning
+      }
+    }));
+    return inodes;
+  }
+}
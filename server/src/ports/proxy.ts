@@ -0,0 +1,225 @@
+import * as http from 'http';
+import * as net from 'net';
+import type { Duplex } from 'stream';
+import { Request, Response } from 'express';
+import { AuthConfig } from '../config/config.js';
+import { findUserForToken } from '../auth/tokens.js';
+import { ListeningPort, PortDetector } from './detector.js';
+
+export const PREVIEW_PATH = '/preview';
+
+// Cookie carrying the API token for requests the preview page makes itself,
+// which can't send an Authorization header
+const TOKEN_COOKIE = 'oneline_preview_token';
+
+const PREVIEW_URL = /^\/preview\/(\d{1,5})(\/[^?#]*)?(\?.*)?$/;
+const LOCAL_ORIGIN = /^https?:\/\/(?:localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1?\]):(\d+)(\/.*)?$/;
+
+/**
+ * Path a port is previewed under, e.g. /preview/3000/
+ */
+export function getPreviewPath(port: number): string {
+  return `${PREVIEW_PATH}/${port}/`;
+}
+
+export interface PreviewTarget {
+  port: number;
+  // Path and query on the previewed server, e.g. /assets/app.js?v=1
+  path: string;
+  // The request named no path after the port (/preview/3000)
+  bare: boolean;
+}
+
+/**
+ * Split /preview/<port>/<path> into the port and the path to forward
+ */
+export function parsePreviewUrl(url: string | undefined): PreviewTarget | undefined {
+  const match = PREVIEW_URL.exec(url ?? '');
+  if (!match) return undefined;
+  const port = Number(match[1]);
+  if (port < 1 || port > 65535) return undefined;
+  return { port, path: (match[2] || '/') + (match[3] ?? ''), bare: !match[2] };
+}
+
+/**
+ * Point a redirect the previewed server sends at itself back through the proxy
+ */
+export function rewriteLocation(location: string, port: number): string {
+  if (location.startsWith('/') && !location.startsWith('//')) {
+    return `${PREVIEW_PATH}/${port}${location}`;
+  }
+  const local = LOCAL_ORIGIN.exec(location);
+  if (local && Number(local[1]) === port) {
+    return `${PREVIEW_PATH}/${port}${local[2] ?? '/'}`;
+  }
+  return location;
+}
+
+function readCookie(header: string | undefined, name: string): string | undefined {
+  for (const part of (header ?? '').split(';')) {
+    const [key, ...value] = part.trim().split('=');
+    if (key === name) return decodeURIComponent(value.join('='));
+  }
+  return undefined;
+}
+
+/**
+ * Cookie header without the proxy's own cookie, or undefined if nothing is left
+ */
+function stripTokenCookie(header: string | undefined): string | undefined {
+  const rest = (header ?? '')
+    .split(';')
+    .map(part => part.trim())
+    .filter(part => part && !part.startsWith(`${TOKEN_COOKIE}=`));
+  return rest.length > 0 ? rest.join('; ') : undefined;
+}
+
+/**
+ * PreviewProxy forwards /preview/<port>/... (HTTP and WebSocket) to ports a
+ * workspace process listens on, so dev servers can be viewed through the
+ * editor's own port. Only ports the PortDetector found are reachable, so the
+ * proxy can't be used to reach other services on the machine. The prefix is
+ * stripped; X-Forwarded-Prefix tells the app where it is mounted.
+ */
+export class PreviewProxy {
+  constructor(private ports: PortDetector, private auth: AuthConfig) {}
+
+  /**
+   * Express handler for requests under /preview
+   */
+  handleRequest = async (req: Request, res: Response): Promise<void> => {
+    const target = parsePreviewUrl(req.originalUrl);
+    if (!target) {
+      res.status(404).type('text/plain').send('Not found');
+      return;
+    }
+
+    // A token in the URL (from the editor's preview pane) becomes a cookie,
+    // and is dropped from the URL the app sees
+    const url = new URL(req.originalUrl, 'http://localhost');
+    const queryToken = url.searchParams.get('token');
+    if (this.auth.mode === 'token' && queryToken && findUserForToken(this.auth, queryToken)) {
+      url.searchParams.delete('token');
+      res.cookie(TOKEN_COOKIE, queryToken, { path: `${PREVIEW_PATH}/`, httpOnly: true, sameSite: 'lax' });
+      res.redirect(302, url.pathname + (target.bare ? '/' : '') + url.search);
+      return;
+    }
+    if (!this.isAuthorized(req)) {
+      res.status(401).type('text/plain').send('Authentication required');
+      return;
+    }
+    if (target.bare) {
+      res.redirect(301, `${PREVIEW_PATH}/${target.port}/${url.search}`);
+      return;
+    }
+
+    const listening = await this.findPort(target.port);
+    if (!listening) {
+      res.status(404).type('text/plain').send(`Nothing started from the workspace is listening on port ${target.port}`);
+      return;
+    }
+
+    const upstream = http.request({
+      host: listening.host,
+      port: target.port,
+      method: req.method,
+      path: target.path,
+      headers: this.forwardHeaders(req, target.port)
+    }, (upstreamRes) => {
+      const headers = { ...upstreamRes.headers };
+      if (typeof headers.location === 'string') {
+        headers.location = rewriteLocation(headers.location, target.port);
+      }
+      // The preview pane shows the app in a frame of the editor
+      delete headers['x-frame-options'];
+      res.writeHead(upstreamRes.statusCode ?? 502, headers);
+      upstreamRes.pipe(res);
+    });
+    upstream.on('error', (error) => {
+      if (res.headersSent) {
+        res.destroy();
+        return;
+      }
+      res.status(502).type('text/plain').send(`Preview of port ${target.port} failed: ${error.message}`);
+    });
+    req.pipe(upstream);
+  };
+
+  /**
+   * Forward a WebSocket upgrade under /preview. Returns false for other paths.
+   */
+  handleUpgrade(req: http.IncomingMessage, socket: Duplex, head: Buffer): boolean {
+    const target = parsePreviewUrl(req.url);
+    if (!target) return false;
+
+    const reject = (status: string) => {
+      socket.end(`HTTP/1.1 ${status}\r\nConnection: close\r\n\r\n`);
+    };
+    if (!this.isAuthorized(req)) {
+      reject('401 Unauthorized');
+      return true;
+    }
+
+    void this.findPort(target.port).then((listening) => {
+      if (!listening) {
+        reject('404 Not Found');
+        return;
+      }
+      const upstream = net.connect(target.port, listening.host, () => {
+        const headers = this.forwardHeaders(req, target.port);
+        const lines = [`${req.method} ${target.path} HTTP/${req.httpVersion}`];
+        for (const [name, value] of Object.entries(headers)) {
+          for (const item of Array.isArray(value) ? value : [value]) {
+            if (item !== undefined) lines.push(`${name}: ${item}`);
+          }
+        }
+        upstream.write(lines.join('\r\n') + '\r\n\r\n');
+        if (head.length > 0) upstream.write(head);
+        upstream.pipe(socket);
+        socket.pipe(upstream);
+      });
+      upstream.on('error', () => socket.destroy());
+      socket.on('error', () => upstream.destroy());
+    });
+    return true;
+  }
+
+  private isAuthorized(req: http.IncomingMessage): boolean {
+    if (this.auth.mode === 'none') return true;
+    const header = req.headers.authorization;
+    const token = header?.startsWith('Bearer ')
+      ? header.slice('Bearer '.length).trim()
+      : readCookie(req.headers.cookie, TOKEN_COOKIE);
+    return findUserForToken(this.auth, token) !== undefined;
+  }
+
+  /**
+   * The detected port, scanning again in case it was opened since the last scan
+   */
+  private async findPort(port: number): Promise<ListeningPort | undefined> {
+    return this.ports.getPort(port) ?? (await this.ports.refresh()).find(candidate => candidate.port === port);
+  }
+
+  private forwardHeaders(req: http.IncomingMessage, port: number): http.OutgoingHttpHeaders {
+    const headers: http.OutgoingHttpHeaders = { ...req.headers };
+    // The editor's credentials are not for the previewed app
+    delete headers.authorization;
+    const cookie = stripTokenCookie(req.headers.cookie);
+    if (cookie) {
+      headers.cookie = cookie;
+    } else {
+      delete headers.cookie;
+    }
+    // Dev servers often only accept requests for localhost
+    headers.host = `localhost:${port}`;
+    if (req.headers.host) {
+      headers['x-forwarded-host'] = req.headers.host;
+    }
+    headers['x-forwarded-proto'] = (req.socket as { encrypted?: boolean }).encrypted ? 'https' : 'http';
+    headers['x-forwarded-prefix'] = `${PREVIEW_PATH}/${port}`;
+    if (req.socket.remoteAddress) {
+      headers['x-forwarded-for'] = req.socket.remoteAddress;
+    }
+    return headers;
+  }
+}
@@ -0,0 +1,195 @@
+import { randomInt, randomUUID } from 'crypto';
+
+/**
+ * One request in a .http / .rest file
+ */
+export interface HttpRequestBlock {
+  // From a "# @name login" comment
+  name?: string;
+  method: string;
+  // Unresolved; may still contain {{variables}}
+  url: string;
+  headers: Array<[string, string]>;
+  body?: string;
+  // Workspace-relative or file-relative path from a "< ./body.json" line
+  bodyFile?: string;
+  // 1-based line of the request line
+  line: number;
+  // 1-based line range of the whole block, separators excluded
+  startLine: number;
+  endLine: number;
+}
+
+export interface HttpFile {
+  // @name = value declarations, in file order
+  variables: Record<string, string>;
+  requests: HttpRequestBlock[];
+}
+
+export const HTTP_METHODS = ['GET', 'POST', 'PUT', 'PATCH', 'DELETE', 'HEAD', 'OPTIONS', 'TRACE', 'CONNECT'];
+
+const SEPARATOR = /^###/;
+const COMMENT = /^\s*(#|\/\/)/;
+const NAME = /^\s*(?:#|\/\/)\s*@name\s+(\S+)/;
+const VARIABLE = /^\s*@([\w.-]+)\s*=\s*(.*?)\s*$/;
+const REQUEST_LINE = new RegExp(`^(?:(${HTTP_METHODS.join('|')})\\s+)?(\\S.*?)(?:\\s+HTTP\\/[\\d.]+)?\\s*$`);
+const HEADER = /^([\w!#$%&'*+.^`|~-]+)\s*:\s*(.*)$/;
+const QUERY_CONTINUATION = /^\s+[?&]/;
+const VARIABLE_REFERENCE = /\{\{\s*(\$?[\w.-]+)((?:\s+[^}]+)?)\s*\}\}/g;
+
+/**
+ * Parse a .http file: requests separated by ### lines, each a request line
+ * ("POST {{host}}/users", GET when the method is left out), headers, a blank
+ * line and an optional body. @name = value lines declare file variables.
+ */
+export function parseHttpFile(text: string): HttpFile {
+  const lines = text.split(/\r?\n/);
+  const variables: Record<string, string> = {};
+  const requests: HttpRequestBlock[] = [];
+
+  let blockStart = 0;
+  for (let index = 0; index <= lines.length; index++) {
+    if (index < lines.length && !SEPARATOR.test(lines[index])) {
+      continue;
+    }
+    const request = parseBlock(lines, blockStart, index, variables);
+    if (request) {
+      requests.push(request);
+    }
+    blockStart = index + 1;
+  }
+  return { variables, requests };
+}
+
+function parseBlock(
+  lines: string[],
+  start: number,
+  end: number,
+  variables: Record<string, string>
+): HttpRequestBlock | undefined {
+  let name: string | undefined;
+  let index = start;
+
+  // Comments and variables before the request line
+  for (; index < end; index++) {
+    const text = lines[index];
+    const nameMatch = NAME.exec(text);
+    if (nameMatch) {
+      name = nameMatch[1];
+      continue;
+    }
+    const variable = VARIABLE.exec(text);
+    if (variable) {
+      variables[variable[1]] = variable[2];
+      continue;
+    }
+    if (!text.trim() || COMMENT.test(text)) {
+      continue;
+    }
+    break;
+  }
+  if (index >= end) {
+    return undefined;
+  }
+
+  const requestLine = REQUEST_LINE.exec(lines[index].trim());
+  if (!requestLine) {
+    return undefined;
+  }
+  const line = index + 1;
+  let url = requestLine[2];
+  index++;
+
+  // Query parameters split over several indented lines
+  for (; index < end && QUERY_CONTINUATION.test(lines[index]); index++) {
+    url += lines[index].trim();
+  }
+
+  const headers: Array<[string, string]> = [];
+  for (; index < end && lines[index].trim(); index++) {
+    if (COMMENT.test(lines[index])) {
+      continue;
+    }
+    const header = HEADER.exec(lines[index].trim());
+    if (header) {
+      headers.push([header[1], header[2]]);
+    }
+  }
+
+  const bodyLines = lines.slice(index + 1, end);
+  while (bodyLines.length > 0 && !bodyLines[bodyLines.length - 1].trim()) {
+    bodyLines.pop();
+  }
+  while (bodyLines.length > 0 && !bodyLines[0].trim()) {
+    bodyLines.shift();
+  }
+
+  const request: HttpRequestBlock = {
+    name,
+    method: (requestLine[1] || 'GET').toUpperCase(),
+    url,
+    headers,
+    line,
+    startLine: start + 1,
+    endLine: end
+  };
+  const bodyFile = bodyLines.length === 1 ? /^<\s+(\S.*)$/.exec(bodyLines[0].trim()) : null;
+  if (bodyFile) {
+    request.bodyFile = bodyFile[1];
+  } else if (bodyLines.length > 0) {
+    request.body = bodyLines.join('\n');
+  }
+  return request;
+}
+
+/**
+ * Find the request a 1-based line belongs to
+ */
+export function findRequestAt(file: HttpFile, line: number): HttpRequestBlock | undefined {
+  return file.requests.find(request => line >= request.startLine && line <= request.endLine);
+}
+
+/**
+ * Replace {{name}} references with variables, which may themselves refer to
+ * other variables, and the dynamic variables {{$timestamp}}, {{$guid}} and
+ * {{$randomInt min max}}. Unknown names are reported and left as they are.
+ */
+export function resolveVariables(
+  text: string,
+  variables: Record<string, string>,
+  missing: Set<string> = new Set()
+): string {
+  const resolve = (value: string, depth: number): string =>
+    value.replace(VARIABLE_REFERENCE, (reference, name: string, args: string) => {
+      if (name.startsWith('$')) {
+        const dynamic = resolveDynamic(name, args.trim().split(/\s+/).filter(Boolean));
+        if (dynamic !== undefined) {
+          return dynamic;
+        }
+      } else if (Object.prototype.hasOwnProperty.call(variables, name) && depth < 10) {
+        return resolve(variables[name], depth + 1);
+      }
+      missing.add(name);
+      return reference;
+    });
+  return resolve(text, 0);
+}
+
+function resolveDynamic(name: string, args: string[]): string | undefined {
+  switch (name) {
+    case '$timestamp':
+      return String(Math.floor(Date.now() / 1000));
+    case '$isoTimestamp':
+      return new Date().toISOString();
+    case '$guid':
+    case '$uuid':
+      return randomUUID();
+    case '$randomInt': {
+      const min = Number(args[0] ?? 0);
+      const max = Number(args[1] ?? 1000);
+      return Number.isInteger(min) && Number.isInteger(max) && max > min ? String(randomInt(min, max)) : undefined;
+    }
+    default:
+      return undefined;
+  }
+}
@@ -0,0 +1,342 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { randomUUID } from 'crypto';
+import type { ScratchDocuments } from '../fs/scratch.js';
+import { UriMapper } from '../fs/uris.js';
+import { isStatePath, WorkspaceState } from '../workspace/state.js';
+import { findRequestAt, parseHttpFile, resolveVariables } from './parser.js';
+
+export interface HttpSendOptions {
+  // Browser URI of the .http file: workspace:///... or an untitled buffer
+  uri: string;
+  // 1-based line inside the request to send
+  line: number;
+  // Environment from http-client.env.json
+  env?: string;
+  // Browser tab asking, which untitled buffers belong to
+  client?: string;
+}
+
+/**
+ * A sent request and its response, as kept in the history
+ */
+export interface HttpExchange {
+  id: string;
+  timestamp: string;
+  uri: string;
+  line: number;
+  name?: string;
+  env?: string;
+  method: string;
+  url: string;
+  requestHeaders: Record<string, string>;
+  requestBody?: string;
+  // 0 when no response was received; see error
+  status: number;
+  statusText: string;
+  headers: Record<string, string>;
+  body: string;
+  // Size of the whole response body in bytes
+  size: number;
+  // The body was cut short, see BODY_LIMIT
+  truncated: boolean;
+  durationMs: number;
+  error?: string;
+}
+
+export type HttpExchangeSummary = Omit<HttpExchange, 'requestHeaders' | 'requestBody' | 'headers' | 'body'>;
+
+export interface HttpEnvironments {
+  // Env file the environments came from, workspace-relative
+  file?: string;
+  environments: string[];
+}
+
+export const ENV_FILE = 'http-client.env.json';
+// Same format, meant to stay out of version control (tokens, passwords)
+export const PRIVATE_ENV_FILE = 'http-client.private.env.json';
+// Variables shared by all environments
+const SHARED_ENV = '$shared';
+
+const HISTORY_FILE = 'http-history.json';
+const HISTORY_LIMIT = 50;
+const REQUEST_TIMEOUT_MS = 30_000;
+// Responses bigger than this are cut short in the panel, and smaller still in the history
+const BODY_LIMIT = 1024 * 1024;
+const HISTORY_BODY_LIMIT = 64 * 1024;
+// The history is shared by every user of the workspace, so it keeps only the
+// values of these request headers, and never the private env file's values
+const HISTORY_HEADERS = new Set(['accept', 'accept-encoding', 'accept-language', 'cache-control', 'content-length', 'content-type', 'user-agent']);
+const REDACTED = '[redacted]';
+
+type EnvFile = Record<string, Record<string, unknown>>;
+
+/**
+ * HttpClientService sends requests written in .http / .rest files from the
+ * server, so they reach services on the machine the workspace lives on, and
+ * keeps a per-workspace history of the responses.
+ */
+export class HttpClientService {
+  private history: HttpExchange[] | null = null;
+
+  constructor(
+    private workspaceRoot: string,
+    private state: WorkspaceState,
+    private uris: UriMapper = new UriMapper(workspaceRoot),
+    private scratch?: ScratchDocuments
+  ) {}
+
+  /**
+   * List the environments available to a .http file
+   */
+  async listEnvironments(uri: string, client = ''): Promise<HttpEnvironments> {
+    const { file, environments } = await this.loadEnvironments(path.dirname(this.resolveFile(uri, client)));
+    return {
+      file: file && path.relative(this.workspaceRoot, file).split(path.sep).join('/'),
+      environments: Object.keys(environments).filter(name => name !== SHARED_ENV).sort()
+    };
+  }
+
+  /**
+   * Send the request at a line of a .http file and record it in the history
+   */
+  async send({ uri, line, env, client = '' }: HttpSendOptions): Promise<HttpExchange> {
+    const filePath = this.resolveFile(uri, client);
+    let text: string;
+    try {
+      text = await fs.readFile(filePath, 'utf-8');
+    } catch {
+      throw new Error(`File not found: ${uri}`);
+    }
+
+    const file = parseHttpFile(text);
+    const request = findRequestAt(file, line);
+    if (!request) {
+      throw new Error(`No request at line ${line}`);
+    }
+
+    const { environments, secrets } = await this.loadEnvironments(path.dirname(filePath));
+    if (env && !environments[env]) {
+      throw new Error(`Unknown environment: ${env}`);
+    }
+    const variables: Record<string, string> = {
+      ...toStrings(environments[SHARED_ENV]),
+      ...toStrings(env ? environments[env] : undefined),
+      ...file.variables
+    };
+
+    const missing = new Set<string>();
+    const url = resolveVariables(request.url, variables, missing);
+    const requestHeaders: Record<string, string> = {};
+    for (const [name, value] of request.headers) {
+      requestHeaders[name] = resolveVariables(value, variables, missing);
+    }
+    let requestBody = request.body !== undefined ? resolveVariables(request.body, variables, missing) : undefined;
+    if (request.bodyFile) {
+      requestBody = await this.readBodyFile(path.dirname(filePath), request.bodyFile);
+    }
+    if (missing.size > 0) {
+      throw new Error(`Undefined variables: ${[...missing].join(', ')}`);
+    }
+    if (!/^https?:\/\//i.test(url)) {
+      throw new Error(`Not an http(s) URL: ${url}`);
+    }
+
+    const exchange: HttpExchange = {
+      id: randomUUID(),
+      timestamp: new Date().toISOString(),
+      uri,
+      line: request.line,
+      name: request.name,
+      env,
+      method: request.method,
+      url,
+      requestHeaders,
+      requestBody,
+      status: 0,
+      statusText: '',
+      headers: {},
+      body: '',
+      size: 0,
+      truncated: false,
+      durationMs: 0
+    };
+
+    const started = performance.now();
+    try {
+      const response = await fetch(url, {
+        method: request.method,
+        headers: requestHeaders,
+        body: requestBody !== undefined && !['GET', 'HEAD'].includes(request.method) ? requestBody : undefined,
+        redirect: 'manual',
+        signal: AbortSignal.timeout(REQUEST_TIMEOUT_MS)
+      });
+      const body = Buffer.from(await response.arrayBuffer());
+      exchange.durationMs = Math.round(performance.now() - started);
+      exchange.status = response.status;
+      exchange.statusText = response.statusText;
+      response.headers.forEach((value, name) => {
+        exchange.headers[name] = value;
+      });
+      exchange.size = body.length;
+      exchange.truncated = body.length > BODY_LIMIT;
+      exchange.body = body.subarray(0, BODY_LIMIT).toString('utf-8');
+    } catch (error) {
+      exchange.durationMs = Math.round(performance.now() - started);
+      const cause = (error as Error & { cause?: Error }).cause;
+      exchange.error = (error as Error).name === 'TimeoutError'
+        ? `Timed out after ${REQUEST_TIMEOUT_MS / 1000}s`
+        : cause?.message || (error as Error).message;
+    }
+
+    const secretValues = Object.keys({ ...secrets[SHARED_ENV], ...(env ? secrets[env] : undefined) })
+      .map(name => resolveVariables(`{{${name}}}`, variables));
+    await this.record(redact(exchange, secretValues));
+    return exchange;
+  }
+
+  /**
+   * List past requests, newest first
+   */
+  async listHistory(): Promise<HttpExchangeSummary[]> {
+    const history = await this.loadHistory();
+    return history.map(({ requestHeaders, requestBody, headers, body, ...summary }) => summary);
+  }
+
+  /**
+   * Get a past request with its response
+   */
+  async getHistoryEntry(id: string): Promise<HttpExchange | undefined> {
+    const history = await this.loadHistory();
+    return history.find(entry => entry.id === id);
+  }
+
+  /**
+   * Forget all past requests
+   */
+  async clearHistory(): Promise<void> {
+    this.history = [];
+    await this.state.writeJson(HISTORY_FILE, this.history);
+  }
+
+  private async record(exchange: HttpExchange): Promise<void> {
+    const history = await this.loadHistory();
+    const stored = exchange.body.length > HISTORY_BODY_LIMIT
+      ? { ...exchange, body: exchange.body.slice(0, HISTORY_BODY_LIMIT), truncated: true }
+      : exchange;
+    history.unshift(stored);
+    history.splice(HISTORY_LIMIT);
+    await this.state.writeJson(HISTORY_FILE, history);
+  }
+
+  private async loadHistory(): Promise<HttpExchange[]> {
+    if (!this.history) {
+      const history = await this.state.readJson<HttpExchange[]>(HISTORY_FILE, []);
+      this.history = Array.isArray(history) ? history : [];
+    }
+    return this.history;
+  }
+
+  /**
+   * Merge the nearest env files, looking from the .http file's directory up
+   * to the workspace root. The private file overrides the shared one and is
+   * also returned on its own as `secrets`.
+   */
+  private async loadEnvironments(dir: string): Promise<{ file?: string; environments: EnvFile; secrets: EnvFile }> {
+    const root = path.resolve(this.workspaceRoot);
+    let current = dir.startsWith(root) ? dir : root;
+    for (;;) {
+      const shared = await readEnvFile(path.join(current, ENV_FILE));
+      const secret = await readEnvFile(path.join(current, PRIVATE_ENV_FILE));
+      if (shared || secret) {
+        const environments: EnvFile = { ...shared };
+        for (const [name, values] of Object.entries(secret ?? {})) {
+          environments[name] = { ...environments[name], ...values };
+        }
+        return { file: path.join(current, shared ? ENV_FILE : PRIVATE_ENV_FILE), environments, secrets: secret ?? {} };
+      }
+      if (current === root || path.dirname(current) === current) {
+        return { environments: {}, secrets: {} };
+      }
+      current = path.dirname(current);
+    }
+  }
+
+  private async readBodyFile(dir: string, file: string): Promise<string> {
+    const filePath = this.checkWorkspacePath(path.resolve(dir, file));
+    try {
+      return await fs.readFile(filePath, 'utf-8');
+    } catch {
+      throw new Error(`Body file not found: ${file}`);
+    }
+  }
+
+  private resolveFile(uri: string, client: string): string {
+    if (this.scratch?.isScratchUri(uri)) {
+      const document = this.scratch.get(client, uri);
+      if (!document) {
+        throw new Error(`Untitled document is not open: ${uri}`);
+      }
+      return document.filePath;
+    }
+    return this.checkWorkspacePath(path.resolve(this.uris.toDiskPath(uri)));
+  }
+
+  /**
+   * Refuse files outside the workspace and in the editor's state directory,
+   * since a body file is sent wherever the request goes
+   */
+  private checkWorkspacePath(filePath: string): string {
+    const relative = path.relative(path.resolve(this.workspaceRoot), filePath);
+    if (relative.startsWith('..') || path.isAbsolute(relative) || isStatePath(relative)) {
+      throw new Error(`Access denied: path outside workspace`);
+    }
+    return filePath;
+  }
+}
+
+async function readEnvFile(filePath: string): Promise<EnvFile | undefined> {
+  let raw: string;
+  try {
+    raw = await fs.readFile(filePath, 'utf-8');
+  } catch {
+    return undefined;
+  }
+  try {
+    const parsed = JSON.parse(raw);
+    return parsed && typeof parsed === 'object' && !Array.isArray(parsed) ? parsed : undefined;
+  } catch {
+    throw new Error(`Invalid JSON in ${path.basename(filePath)}`);
+  }
+}
+
+/**
+ * Copy of an exchange fit for the shared history: request header values are
+ * dropped, except harmless ones, and secret values are masked everywhere
+ */
+function redact(exchange: HttpExchange, secrets: string[]): HttpExchange {
+  // Longest first, so a secret containing another is masked whole
+  const values = secrets.filter(Boolean).sort((a, b) => b.length - a.length);
+  const mask = (text: string) => values.reduce((result, value) => result.split(value).join(REDACTED), text);
+  const maskHeaders = (headers: Record<string, string>, keep: (name: string) => boolean) => Object.fromEntries(
+    Object.entries(headers).map(([name, value]) => [name, keep(name.toLowerCase()) ? mask(value) : REDACTED])
+  );
+  return {
+    ...exchange,
+    url: mask(exchange.url),
+    requestHeaders: maskHeaders(exchange.requestHeaders, name => HISTORY_HEADERS.has(name)),
+    requestBody: exchange.requestBody !== undefined ? mask(exchange.requestBody) : undefined,
+    headers: maskHeaders(exchange.headers, name => name !== 'set-cookie'),
+    body: mask(exchange.body)
+  };
+}
+
+function toStrings(values: Record<string, unknown> | undefined): Record<string, string> {
+  const result: Record<string, string> = {};
+  for (const [name, value] of Object.entries(values ?? {})) {
+    if (value !== null && value !== undefined) {
+      result[name] = typeof value === 'object' ? JSON.stringify(value) : String(value);
+    }
+  }
+  return result;
+}
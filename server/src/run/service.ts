@@ -0,0 +1,213 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { pathToFileURL } from 'url';
+import type { ScratchDocuments } from '../fs/scratch.js';
+import { UriMapper } from '../fs/uris.js';
+import { TaskInfo, TaskManager } from '../tasks/manager.js';
+
+export type RunRuntime = 'go' | 'node' | 'tsx';
+
+export interface RunOptions {
+  // Browser URI of the file: workspace:///... or an untitled buffer
+  uri: string;
+  args?: string[];
+  env?: Record<string, string>;
+  // Browser tab asking, which untitled buffers belong to
+  client?: string;
+}
+
+/**
+ * A source location mentioned in a run's output, e.g. a compile error or a
+ * stack frame
+ */
+export interface RunProblem {
+  uri: string;
+  // 1-based
+  line: number;
+  column: number;
+  // Only set for errors, which are also shown in the Problems panel
+  message?: string;
+  // 0-based line of the output it was found on
+  outputLine: number;
+}
+
+type ProblemsListener = (uri: string, task: TaskInfo, problems: RunProblem[]) => void;
+
+const RUNTIMES: Record<string, RunRuntime> = {
+  '.go': 'go',
+  '.js': 'node',
+  '.mjs': 'node',
+  '.cjs': 'node',
+  '.ts': 'tsx',
+  '.mts': 'tsx',
+  '.cts': 'tsx',
+  '.tsx': 'tsx'
+};
+
+// Go compile errors: ./main.go:12:5: undefined: x
+const GO_ERROR = /^(\S+\.go):(\d+):(\d+): (.+)$/;
+// Any file:line[:column] reference, e.g. a stack frame
+const LOCATION = /(?:^|[\s(])((?:file:\/\/)?[^\s():]+\.(?:go|[cm]?[jt]sx?)):(\d+)(?::(\d+))?/;
+// Node prints the failing line as "<file>:<line>" before the error
+const NODE_ERROR_HEADER = /^(\S+\.[cm]?[jt]sx?):(\d+)$/;
+const NODE_ERROR = /^(?:[A-Z]\w*)?(?:Error|Exception)\b.*$/;
+
+/**
+ * Pick out source locations from a run's output. Relative paths are
+ * resolved against the directory the program ran in; `toUri` maps absolute
+ * paths to browser URIs and returns undefined for files the browser can't open.
+ */
+export function parseRunOutput(
+  output: string,
+  cwd: string,
+  toUri: (filePath: string) => string | undefined
+): RunProblem[] {
+  const problems: RunProblem[] = [];
+  const resolve = (file: string) => toUri(path.resolve(cwd, file.replace(/^file:\/\//, '')));
+  const lines = output.split('\n');
+  let nodeHeader: { uri: string; line: number; outputLine: number } | undefined;
+
+  lines.forEach((text, outputLine) => {
+    const goError = GO_ERROR.exec(text);
+    if (goError) {
+      const uri = resolve(goError[1]);
+      if (uri) {
+        problems.push({ uri, line: Number(goError[2]), column: Number(goError[3]), message: goError[4], outputLine });
+      }
+      return;
+    }
+
+    const header = NODE_ERROR_HEADER.exec(text);
+    if (header && !nodeHeader) {
+      const uri = resolve(header[1]);
+      if (uri) {
+        nodeHeader = { uri, line: Number(header[2]), outputLine };
+        problems.push({ uri, line: nodeHeader.line, column: 1, outputLine });
+      }
+      return;
+    }
+    if (nodeHeader && NODE_ERROR.test(text)) {
+      const headerProblem = problems.find(problem => problem.outputLine === nodeHeader!.outputLine);
+      if (headerProblem && !headerProblem.message) {
+        headerProblem.message = text.trim();
+      }
+    }
+
+    const location = LOCATION.exec(text);
+    if (location) {
+      const uri = resolve(location[1]);
+      if (uri) {
+        problems.push({ uri, line: Number(location[2]), column: Number(location[3] ?? 1), outputLine });
+      }
+    }
+  });
+  return problems;
+}
+
+/**
+ * RunService runs a single Go, JavaScript or TypeScript file (or untitled
+ * buffer) as a task: `go run`, `node` or `tsx`. When the run ends, locations
+ * in its output are reported so the browser can link them to the source.
+ */
+export class RunService {
+  private problemsListeners: ProblemsListener[] = [];
+  private runs: Map<string, { uri: string; cwd: string }> = new Map();
+
+  constructor(
+    private workspaceRoot: string,
+    private taskManager: TaskManager,
+    private uris: UriMapper = new UriMapper(workspaceRoot),
+    private scratch?: ScratchDocuments
+  ) {
+    taskManager.onExit((task) => {
+      const run = this.runs.get(task.id);
+      if (!run) return;
+      this.runs.delete(task.id);
+      const problems = parseRunOutput(
+        taskManager.getOutput(task.id) ?? '',
+        run.cwd,
+        filePath => this.toClientUri(filePath)
+      );
+      this.problemsListeners.forEach(listener => listener(run.uri, task, problems));
+    });
+  }
+
+  /**
+   * Start running a file, returning its task
+   */
+  async run({ uri, args = [], env, client = '' }: RunOptions): Promise<TaskInfo> {
+    const filePath = this.resolveFile(uri, client);
+    try {
+      await fs.access(filePath);
+    } catch {
+      throw new Error(`File not found: ${uri}`);
+    }
+
+    const runtime = RUNTIMES[path.extname(filePath).toLowerCase()];
+    if (!runtime) {
+      throw new Error(`Don't know how to run ${path.basename(filePath)}; Go, JavaScript and TypeScript files can be run`);
+    }
+
+    const cwd = path.dirname(filePath);
+    const file = path.basename(filePath);
+    const name = this.scratch?.isScratchUri(uri) ? uri.slice(uri.indexOf(':') + 1) : this.uris.toWorkspacePath(uri);
+    const command = runtime === 'go' ? 'go' : runtime === 'node' ? 'node' : await this.resolveCommand('tsx', cwd);
+    const task = this.taskManager.run({
+      label: `Run ${name}`,
+      command,
+      args: runtime === 'go' ? ['run', file, ...args] : [file, ...args],
+      cwd,
+      env
+    });
+    this.runs.set(task.id, { uri, cwd });
+    return task;
+  }
+
+  /**
+   * Register a listener for the source locations found in a finished run's
+   * output, with the URI of the file that was run
+   */
+  onProblems(listener: ProblemsListener): void {
+    this.problemsListeners.push(listener);
+  }
+
+  private resolveFile(uri: string, client: string): string {
+    if (this.scratch?.isScratchUri(uri)) {
+      const document = this.scratch.get(client, uri);
+      if (!document) {
+        throw new Error(`Untitled document is not open: ${uri}`);
+      }
+      return document.filePath;
+    }
+    const filePath = path.resolve(this.uris.toDiskPath(uri));
+    const relative = path.relative(path.resolve(this.workspaceRoot), filePath);
+    if (relative.startsWith('..') || path.isAbsolute(relative)) {
+      throw new Error(`Access denied: path outside workspace`);
+    }
+    return filePath;
+  }
+
+  private toClientUri(filePath: string): string | undefined {
+    const uri = this.uris.toClientUri(pathToFileURL(filePath).href);
+    return uri.startsWith('file:') ? undefined : uri;
+  }
+
+  /**
+   * Prefer a project-local binary (node_modules/.bin) over one on the PATH
+   */
+  private async resolveCommand(command: string, cwd: string): Promise<string> {
+    const root = path.resolve(this.workspaceRoot);
+    for (let current = cwd; ; current = path.dirname(current)) {
+      const local = path.join(current, 'node_modules', '.bin', command);
+      try {
+        await fs.access(local);
+        return local;
+      } catch {
+        // Not installed here
+      }
+      if (current === root || path.dirname(current) === current) {
+        return command;
+      }
+    }
+  }
+}
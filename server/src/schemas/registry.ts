@@ -0,0 +1,180 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+import { WorkspaceState } from '../workspace/state.js';
+
+const __filename = fileURLToPath(import.meta.url);
+const __dirname = path.dirname(__filename);
+
+// Bundled schemas live in server/schemas (two levels up from src/schemas or dist/schemas)
+export const BUILTIN_SCHEMAS_DIR = path.resolve(__dirname, '../../schemas');
+
+// Workspace state file holding the schema mappings
+export const SCHEMA_MAPPINGS_FILE = 'schemas.json';
+
+const BUILTIN_SCHEMAS: { id: string; file: string; fileMatch: string[] }[] = [
+  { id: 'package', file: 'package.schema.json', fileMatch: ['package.json'] },
+  { id: 'tsconfig', file: 'tsconfig.schema.json', fileMatch: ['tsconfig.json', 'tsconfig.*.json', 'jsconfig.json'] },
+  { id: 'oneline-editor-config', file: 'oneline-editor.config.schema.json', fileMatch: ['oneline-editor.config.json'] },
+  { id: 'template', file: 'template.schema.json', fileMatch: ['template.json'] }
+];
+
+/**
+ * Associates files with a schema. `schema` is either the ID of a built-in
+ * schema or the workspace path of a schema file (e.g. /schemas/deploy.json).
+ */
+export interface SchemaMapping {
+  fileMatch: string[];
+  schema: string;
+}
+
+export interface SchemaInfo {
+  // Built-in ID, or the workspace path of the schema file
+  id: string;
+  source: 'builtin' | 'workspace';
+  title?: string;
+  fileMatch: string[];
+}
+
+/**
+ * SchemaRegistry knows which JSON Schema applies to which workspace file:
+ * the bundled schemas for well-known files plus mappings configured per
+ * workspace. Everything is read from disk, so validation works offline.
+ */
+export class SchemaRegistry {
+  private mappings: SchemaMapping[] = [];
+
+  constructor(
+    private workspaceRoot: string,
+    private state: WorkspaceState,
+    private builtinDir: string = BUILTIN_SCHEMAS_DIR
+  ) {}
+
+  /**
+   * Load the workspace's schema mappings
+   */
+  async load(): Promise<void> {
+    const stored = await this.state.readJson<{ mappings?: SchemaMapping[] }>(SCHEMA_MAPPINGS_FILE, {});
+    this.mappings = Array.isArray(stored.mappings) ? stored.mappings : [];
+  }
+
+  getMappings(): SchemaMapping[] {
+    return this.mappings.map(mapping => ({ ...mapping, fileMatch: [...mapping.fileMatch] }));
+  }
+
+  /**
+   * Replace the workspace's schema mappings. Every referenced schema must
+   * exist and be valid JSON.
+   */
+  async setMappings(mappings: SchemaMapping[]): Promise<void> {
+    for (const mapping of mappings) {
+      await this.getSchema(mapping.schema);
+    }
+    await this.state.writeJson(SCHEMA_MAPPINGS_FILE, { mappings });
+    this.mappings = mappings.map(mapping => ({ ...mapping, fileMatch: [...mapping.fileMatch] }));
+  }
+
+  /**
+   * List the schemas in use with the files each applies to. Workspace
+   * mappings for a built-in schema extend its file patterns.
+   */
+  async list(): Promise<SchemaInfo[]> {
+    const schemas = new Map<string, SchemaInfo>();
+    for (const builtin of BUILTIN_SCHEMAS) {
+      schemas.set(builtin.id, { id: builtin.id, source: 'builtin', fileMatch: [...builtin.fileMatch] });
+    }
+    for (const mapping of this.mappings) {
+      const id = this.isBuiltin(mapping.schema) ? mapping.schema : this.normalizeWorkspacePath(mapping.schema);
+      const existing = schemas.get(id);
+      if (existing) {
+        existing.fileMatch.push(...mapping.fileMatch);
+      } else {
+        schemas.set(id, { id, source: 'workspace', fileMatch: [...mapping.fileMatch] });
+      }
+    }
+
+    const result: SchemaInfo[] = [];
+    for (const info of schemas.values()) {
+      try {
+        const schema = await this.getSchema(info.id);
+        result.push(typeof schema.title === 'string' ? { ...info, title: schema.title } : info);
+      } catch (error) {
+        console.warn(`[Schemas] Skipping ${info.id}: ${(error as Error).message}`);
+      }
+    }
+    return result;
+  }
+
+  /**
+   * Read a schema by built-in ID or workspace path
+   */
+  async getSchema(id: string): Promise<Record<string, any>> {
+    const filePath = this.resolveSchemaPath(id);
+    let raw: string;
+    try {
+      raw = await fs.readFile(filePath, 'utf-8');
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code === 'ENOENT') {
+        throw new Error(`Schema not found: ${id}`);
+      }
+      throw error;
+    }
+    let schema: unknown;
+    try {
+      schema = JSON.parse(raw);
+    } catch (error) {
+      throw new Error(`Schema ${id} is not valid JSON: ${(error as Error).message}`);
+    }
+    if (!schema || typeof schema !== 'object' || Array.isArray(schema)) {
+      throw new Error(`Schema ${id} must be a JSON object`);
+    }
+    return schema as Record<string, any>;
+  }
+
+  /**
+   * Add schema associations to the settings of the JSON and YAML language
+   * servers, pointing at the schema files on disk
+   */
+  async applyToLanguageServerSettings(
+    languageId: string,
+    settings: Record<string, any> | undefined
+  ): Promise<Record<string, any> | undefined> {
+    if (languageId !== 'json' && languageId !== 'yaml') {
+      return settings;
+    }
+    const schemas = (await this.list()).map(info => ({
+      fileMatch: info.fileMatch,
+      url: pathToFileURL(this.resolveSchemaPath(info.id)).toString()
+    }));
+
+    if (languageId === 'json') {
+      return { ...settings, json: { ...settings?.json, schemas } };
+    }
+    const yamlSchemas: Record<string, string[]> = {};
+    for (const schema of schemas) {
+      yamlSchemas[schema.url] = schema.fileMatch;
+    }
+    return { ...settings, yaml: { ...settings?.yaml, schemas: { ...settings?.yaml?.schemas, ...yamlSchemas } } };
+  }
+
+  private isBuiltin(id: string): boolean {
+    return BUILTIN_SCHEMAS.some(builtin => builtin.id === id);
+  }
+
+  private normalizeWorkspacePath(workspacePath: string): string {
+    return '/' + path.posix.normalize(workspacePath.replace(/\\/g, '/')).replace(/^(\.\.?\/|\/)+/, '');
+  }
+
+  private resolveSchemaPath(id: string): string {
+    const builtin = BUILTIN_SCHEMAS.find(b => b.id === id);
+    if (builtin) {
+      return path.join(this.builtinDir, builtin.file);
+    }
+    const root = path.resolve(this.workspaceRoot);
+    const resolved = path.resolve(root, this.normalizeWorkspacePath(id).slice(1));
+    if (resolved !== root && !resolved.startsWith(root + path.sep)) {
+      throw new Error(`Schema path is outside the workspace: ${id}`);
+    }
+    return resolved;
+  }
+}
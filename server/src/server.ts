@@ -0,0 +1,660 @@
+import express from 'express';
+import cors from 'cors';
+import { createServer, Server } from 'http';
+import path from 'path';
+import { fileURLToPath } from 'url';
+import { RealFileSystem } from './fs/real.js';
+import { VirtualDocumentProvider, detectVirtualDocumentRoots } from './fs/virtual.js';
+import { ScratchDocuments } from './fs/scratch.js';
+import { UriMapper } from './fs/uris.js';
+import { LanguageServerManager } from './lsp/manager.js';
+import { FileOperations } from './lsp/fileOperations.js';
+import { LSPWebSocketServer } from './transport/websocket.js';
+import { LSPProxy } from './lsp/proxy.js';
+import { TaskManager } from './tasks/manager.js';
+import { TemplateManager, BUILTIN_TEMPLATES_DIR } from './templates/manager.js';
+import { WorkspaceState } from './workspace/state.js';
+import { BookmarkStore } from './workspace/bookmarks.js';
+import { CommentStore } from './comments/store.js';
+import { TodoScanner } from './todo/scanner.js';
+import { SchemaRegistry } from './schemas/registry.js';
+import { GoModuleService } from './gomod/service.js';
+import { NpmService } from './npm/service.js';
+import { CoverageStore } from './coverage/store.js';
+import { LinterService } from './linters/service.js';
+import { RunService } from './run/service.js';
+import { HttpClientService } from './restclient/service.js';
+import { SnapshotStore } from './snapshots/store.js';
+import { PortDetector } from './ports/detector.js';
+import { PreviewProxy, PREVIEW_PATH, getPreviewPath } from './ports/proxy.js';
+import { ServerConfig } from './config/config.js';
+import { ANONYMOUS_USER, createAuthMiddleware, authenticateRequest, getClientLabel } from './auth/tokens.js';
+import { AuditLog } from './audit/log.js';
+import { EventBus } from './events/bus.js';
+import { WebhookDispatcher } from './events/webhooks.js';
+import { PluginHost } from './plugins/host.js';
+import { createV1Router } from './api/v1/routes.js';
+import { API_V1_SPEC } from './api/v1/spec.js';
+import { buildOpenApiDocument } from './api/openapi.js';
+import { ApiError } from './api/errors.js';
+import { apiErrorMiddleware, sendApiError } from './api/router.js';
+
+// ES module __dirname equivalent
+const __filename = fileURLToPath(import.meta.url);
+const __dirname = path.dirname(__filename);
+
+/**
+ * Start the HTTP and WebSocket server with the given configuration.
+ * Resolves once the server is listening.
+ */
+export async function startServer(config: ServerConfig): Promise<Server> {
+  const workspaceRoot = config.workspace.root;
+  const { host, port } = config.server;
+
+  // Create Express app
+  const app = express();
+  const server = createServer(app);
+
+  // Middleware
+  app.use(cors({
+    origin: config.cors.origins,
+    credentials: config.cors.credentials
+  }));
+
+  // Dev servers started from the workspace, proxied under /preview/<port>/.
+  // Mounted before the body parser so request bodies pass through untouched.
+  const taskManager = new TaskManager();
+  const ports = new PortDetector(workspaceRoot, taskManager, { ignore: [port] });
+  const preview = new PreviewProxy(ports, config.auth);
+  app.use(PREVIEW_PATH, (req, res, next) => {
+    preview.handleRequest(req, res).catch(next);
+  });
+  server.on('upgrade', (req, socket, head) => {
+    preview.handleUpgrade(req, socket, head);
+  });
+
+  app.use(express.json({ limit: config.limits.maxRequestBodyBytes }));
+  app.use(express.static(path.join(__dirname, '../../web/dist')));
+
+  // Health check endpoint
+  app.get('/health', (req, res) => {
+    res.json({
+      status: 'ok',
+      timestamp: new Date().toISOString(),
+      workspace: workspaceRoot
+    });
+  });
+
+  // The OpenAPI document is public so tooling can discover the API
+  app.get('/api/v1/openapi.json', (req, res) => {
+    res.json(buildOpenApiDocument(API_V1_SPEC));
+  });
+
+  // Everything else under /api requires a token when auth is enabled
+  app.use('/api', createAuthMiddleware(config.auth));
+
+  // Initialize core components
+  const fileSystem = new RealFileSystem(workspaceRoot);
+  const lsManager = new LanguageServerManager(workspaceRoot, config.languageServers);
+  lsManager.setIdleTimeout(config.limits.languageServerIdleTimeoutMs);
+
+  // Read-only access to the Go standard library, module cache and the like,
+  // so go-to-definition can leave the workspace
+  const virtualDocuments = new VirtualDocumentProvider(
+    await detectVirtualDocumentRoots(),
+    config.limits.maxFileSizeBytes
+  );
+  console.log(`[Server] Read-only document roots: ${Object.keys(virtualDocuments.getRoots()).join(', ') || 'none'}`);
+
+  // Untitled buffers get temporary files so language servers can see them
+  const scratch = new ScratchDocuments(workspaceRoot, (languageId) => lsManager.getFileExtension(languageId));
+  await scratch.clear();
+
+  // Browser and language servers name documents differently; see fs/uris.ts
+  const uris = new UriMapper(workspaceRoot, virtualDocuments, scratch);
+  lsManager.setUriMapper((uri) => uris.toClientUri(uri));
+
+  const wsServer = new LSPWebSocketServer(server, '/lsp', {
+    maxClients: config.limits.maxClients,
+    authenticate: (req) => authenticateRequest(config.auth, req)?.user,
+    describeClient: getClientLabel
+  });
+  const templateManager = new TemplateManager(
+    fileSystem,
+    taskManager,
+    config.workspace.templatesDir
+      ? [BUILTIN_TEMPLATES_DIR, config.workspace.templatesDir]
+      : [BUILTIN_TEMPLATES_DIR]
+  );
+
+  templateManager.load().catch((error) => {
+    console.error('[Server] Failed to load project templates:', error);
+  });
+
+  const workspaceState = new WorkspaceState(workspaceRoot);
+  const auditLog = config.audit.enabled
+    ? new AuditLog(config.audit.path || workspaceState.getStatePath('audit.jsonl'))
+    : undefined;
+  const bookmarkStore = new BookmarkStore(workspaceState);
+  const comments = new CommentStore(workspaceState, fileSystem);
+  const snapshots = new SnapshotStore(workspaceState);
+  void snapshots.prune().then((removed) => {
+    if (removed > 0) {
+      console.log(`[Snapshots] Removed ${removed} expired snapshot(s)`);
+    }
+  });
+
+  // JSON/YAML schemas: bundled ones plus the workspace's own mappings
+  const schemaRegistry = new SchemaRegistry(workspaceRoot, workspaceState);
+  schemaRegistry.load().catch((error) => {
+    console.error('[Server] Failed to load schema mappings:', error);
+  });
+  lsManager.setSettingsResolver((serverConfig) =>
+    schemaRegistry.applyToLanguageServerSettings(serverConfig.languageId, serverConfig.settings)
+  );
+
+  // Workspace activity, fanned out to webhooks and /api/v1/events subscribers
+  const events = new EventBus();
+  if (config.webhooks.length > 0) {
+    new WebhookDispatcher(config.webhooks).attach(events);
+  }
+  const todoScanner = new TodoScanner(workspaceRoot, config.workspace.todoTags);
+
+  // Per-workspace tag overrides take precedence over the configured tags
+  workspaceState.readJson<{ tags?: string[] }>('todo.json', {})
+    .then(async (settings) => {
+      if (Array.isArray(settings.tags) && settings.tags.length > 0) {
+        await todoScanner.setTags(settings.tags);
+      }
+      await todoScanner.start();
+    })
+    .catch((error) => {
+      console.error('[Server] Failed to start TODO scanner:', error);
+    });
+
+  const goModules = new GoModuleService(workspaceRoot, taskManager, config.go);
+  const npm = new NpmService(workspaceRoot, taskManager, config.npm);
+
+  // Latest test coverage, shown as line decorations and in the file tree
+  const coverage = new CoverageStore(workspaceRoot, workspaceState);
+  coverage.load().catch((error) => {
+    console.error('[Server] Failed to load coverage:', error);
+  });
+  coverage.onChange((summary) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/coverageChanged',
+      params: { source: summary.source, updatedAt: summary.updatedAt }
+    });
+  });
+
+  // External linters, reported next to the language servers' diagnostics
+  const linters = new LinterService(workspaceRoot, config.linters);
+  linters.onDiagnostics((path, diagnostics) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/lintDiagnostics',
+      params: { path, diagnostics }
+    });
+  });
+
+  // Requests written in .http files, sent from the server
+  const http = new HttpClientService(workspaceRoot, workspaceState, uris, scratch);
+
+  // Run current file; locations in the output link back to the source
+  const run = new RunService(workspaceRoot, taskManager, uris, scratch);
+  run.onProblems((uri, task, problems) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/runProblems',
+      params: { taskId: task.id, uri, problems }
+    });
+  });
+
+  const pluginHost = new PluginHost({
+    workspaceRoot,
+    wsServer,
+    lsManager,
+    taskManager,
+    events,
+    options: config.plugins.options
+  });
+
+  taskManager.onExit((task) => {
+    events.emit('task.finished', { ...task });
+    // A test run may have rewritten the coverage report
+    void coverage.refresh();
+  });
+
+  // Stream task output to clients for the NPM Scripts panel
+  taskManager.onStart((task) => {
+    wsServer.broadcast({ jsonrpc: '2.0', method: 'tasks/started', params: { task } });
+  });
+  taskManager.onOutput((taskId, stream, data) => {
+    wsServer.broadcast({ jsonrpc: '2.0', method: 'tasks/output', params: { taskId, stream, data } });
+  });
+  taskManager.onExit((task) => {
+    wsServer.broadcast({ jsonrpc: '2.0', method: 'tasks/finished', params: { task } });
+  });
+
+  // Keep the Ports panel current as dev servers start and stop
+  ports.onChange((listening) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/portsChanged',
+      params: { ports: listening.map(port => ({ ...port, previewPath: getPreviewPath(port.port) })) }
+    });
+  });
+  ports.start();
+
+  // Language servers republish unchanged diagnostics often; only report real changes
+  const lastDiagnostics = new Map<string, string>();
+  lsManager.onDiagnostics((uri, diagnostics) => {
+    const signature = JSON.stringify(diagnostics);
+    if (lastDiagnostics.get(uri) === signature) {
+      return;
+    }
+    lastDiagnostics.set(uri, signature);
+    events.emit('diagnostics.changed', {
+      uri,
+      path: uris.toWorkspacePath(uri),
+      errors: diagnostics.filter(d => d.severity === 1).length,
+      warnings: diagnostics.filter(d => d.severity === 2).length,
+      total: diagnostics.length
+    });
+  });
+
+  // Let clients refresh the Go Modules and NPM Scripts panels when manifests change
+  events.subscribe((event) => {
+    const paths = [event.data.path, event.data.oldPath, event.data.newPath].filter((p): p is string => typeof p === 'string');
+    if (!event.type.startsWith('file.')) {
+      return;
+    }
+    if (paths.some(p => /(^|\/)go\.(mod|sum)$/.test(p))) {
+      wsServer.broadcast({
+        jsonrpc: '2.0',
+        method: 'workspace/goModulesChanged',
+        params: { paths }
+      });
+    }
+    if (paths.some(p => /(^|\/)(package\.json|package-lock\.json|yarn\.lock|pnpm-lock\.yaml)$/.test(p))) {
+      wsServer.broadcast({
+        jsonrpc: '2.0',
+        method: 'workspace/npmPackagesChanged',
+        params: { paths }
+      });
+    }
+  });
+
+  events.subscribe((event) => {
+    if (event.type === 'file.saved' && typeof event.data.path === 'string') {
+      linters.lint(event.data.path, { onSave: true }).then((result) => {
+        result.errors.forEach(error => console.warn(`[Linters] ${error}`));
+      }).catch((error) => {
+        console.warn(`[Linters] Failed to lint ${event.data.path}: ${error.message}`);
+      });
+    }
+  });
+
+  // Review comments follow the text of saved files; tell clients when
+  // threads are added, change or move
+  events.subscribe((event) => {
+    if (event.type === 'file.saved' && typeof event.data.path === 'string') {
+      comments.sync(event.data.path).catch((error) => {
+        console.warn(`[Comments] Failed to re-anchor comments in ${event.data.path}: ${error.message}`);
+      });
+    }
+  });
+  comments.onChange((paths) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/commentsChanged',
+      params: { paths }
+    });
+  });
+
+  // Store proxies per client
+  const clientProxies = new Map<string, LSPProxy>();
+
+  // Language servers update imports when files move; edits to open files go
+  // to the editors that have them open, and clients are told which files
+  // changed on disk and which open files moved or went away
+  const fileOperations = new FileOperations(uris, lsManager, {
+    getClientsWithOpenDocument: (workspacePath) => Array.from(clientProxies)
+      .filter(([, proxy]) => proxy.hasOpenDocument(workspacePath))
+      .map(([clientId]) => clientId),
+    renameOpenDocuments: (oldPath, newPath) => clientProxies.forEach(proxy => proxy.renameDocuments(oldPath, newPath))
+  });
+  fileOperations.onDidEditOpenDocument((clientId, edit) => {
+    wsServer.sendToClient(clientId, {
+      jsonrpc: '2.0',
+      method: 'workspace/applyFileEdits',
+      params: edit
+    });
+  });
+  fileOperations.onDidEditFiles((paths) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/didEditFiles',
+      params: { paths }
+    });
+  });
+  events.subscribe((event) => {
+    if (event.type === 'file.renamed') {
+      wsServer.broadcast({
+        jsonrpc: '2.0',
+        method: 'workspace/didRenameFiles',
+        params: { files: [{ oldPath: event.data.oldPath, newPath: event.data.newPath }] }
+      });
+    } else if (event.type === 'file.deleted') {
+      wsServer.broadcast({
+        jsonrpc: '2.0',
+        method: 'workspace/didDeleteFiles',
+        params: { paths: [event.data.path] }
+      });
+    }
+  });
+
+  todoScanner.onChange((paths) => {
+    wsServer.broadcast({
+      jsonrpc: '2.0',
+      method: 'workspace/todosChanged',
+      params: { paths }
+    });
+  });
+
+  // Versioned REST API; see api/v1/spec.ts for the route definitions
+  app.use('/api/v1', createV1Router({
+    fileSystem,
+    templateManager,
+    bookmarkStore,
+    comments,
+    workspaceState,
+    todoScanner,
+    schemas: schemaRegistry,
+    goModules,
+    npm,
+    coverage,
+    linters,
+    run,
+    ports,
+    http,
+    snapshots,
+    taskManager,
+    fileOperations,
+    virtualDocuments,
+    auditLog,
+    events,
+    plugins: pluginHost,
+    limits: config.limits
+  }));
+
+  // Plugin routes: /api/plugins/<plugin name>/...
+  app.use('/api/plugins', pluginHost.router);
+
+  // Any other /api path is an error rather than the frontend fallback
+  app.use('/api', (req, res) => {
+    sendApiError(res, ApiError.notFound(`No such endpoint: ${req.method} ${req.originalUrl}`));
+  });
+  app.use('/api', apiErrorMiddleware);
+
+  // Handle LSP initialize
+  wsServer.onMethod('initialize', async (clientId, message) => {
+    console.log(`[Server] Client ${clientId} initializing`);
+
+    // Create proxy for this client
+    const client = wsServer['clients'].get(clientId);
+    if (client) {
+      const proxy = new LSPProxy(fileSystem, lsManager, client, pluginHost.getLspMiddleware(), clientId, uris, scratch, wsServer.getClientLabel(clientId));
+      clientProxies.set(clientId, proxy);
+    }
+
+    // Send initialize response
+    wsServer.sendToClient(clientId, {
+      jsonrpc: '2.0',
+      id: message.id,
+      result: {
+        capabilities: {
+          textDocumentSync: 1, // Full sync
+          completionProvider: {
+            resolveProvider: false,
+            triggerCharacters: ['.', ':', '<', '"', '/', '@']
+          },
+          hoverProvider: true,
+          definitionProvider: true,
+          referencesProvider: true,
+          documentFormattingProvider: true
+        },
+        serverInfo: {
+          name: 'online-editor-lsp-proxy',
+          version: '1.0.0'
+        }
+      }
+    });
+  });
+
+  // Handle initialized notification
+  wsServer.onMethod('initialized', async (clientId, message) => {
+    console.log(`[Server] Client ${clientId} initialized`);
+  });
+
+  // Handle WebSocket messages
+  wsServer.onMethod('textDocument/didOpen', async (clientId, message) => {
+    let proxy = clientProxies.get(clientId);
+    if (!proxy) {
+      const client = wsServer['clients'].get(clientId);
+      if (client) {
+        proxy = new LSPProxy(fileSystem, lsManager, client, pluginHost.getLspMiddleware(), clientId, uris, scratch, wsServer.getClientLabel(clientId));
+        clientProxies.set(clientId, proxy);
+      }
+    }
+
+    if (proxy) {
+      await proxy.handleMessage(message);
+    }
+  });
+
+  wsServer.onMethod('textDocument/didChange', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      await proxy.handleMessage(message);
+    }
+  });
+
+  wsServer.onMethod('textDocument/didClose', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      await proxy.handleMessage(message);
+    }
+  });
+
+  wsServer.onMethod('textDocument/didSave', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      await proxy.handleMessage(message);
+    }
+
+    const uri: string | undefined = message.params?.textDocument?.uri;
+    if (uri) {
+      const filePath = uris.toWorkspacePath(uri);
+      const user = wsServer.getClientUser(clientId) ?? ANONYMOUS_USER;
+      auditLog?.recordInBackground({
+        user,
+        client: wsServer.getClientLabel(clientId),
+        action: 'file.save',
+        path: filePath
+      });
+      events.emit('file.saved', { path: filePath, user });
+    }
+  });
+
+  wsServer.onMethod('textDocument/completion', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      const response = await proxy.handleMessage(message);
+      if (response) {
+        wsServer.sendToClient(clientId, response);
+      }
+    }
+  });
+
+  wsServer.onMethod('textDocument/hover', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      const response = await proxy.handleMessage(message);
+      if (response) {
+        wsServer.sendToClient(clientId, response);
+      }
+    }
+  });
+
+  wsServer.onMethod('textDocument/definition', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      const response = await proxy.handleMessage(message);
+      if (response) {
+        wsServer.sendToClient(clientId, response);
+      }
+    }
+  });
+
+  wsServer.onMethod('textDocument/references', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      const response = await proxy.handleMessage(message);
+      if (response) {
+        wsServer.sendToClient(clientId, response);
+      }
+    }
+  });
+
+  wsServer.onMethod('textDocument/formatting', async (clientId, message) => {
+    const proxy = clientProxies.get(clientId);
+    if (proxy) {
+      const response = await proxy.handleMessage(message);
+      if (response) {
+        wsServer.sendToClient(clientId, response);
+      }
+    }
+  });
+
+  wsServer.onConnect((clientId) => {
+    events.emit('client.connected', {
+      clientId,
+      user: wsServer.getClientUser(clientId) ?? ANONYMOUS_USER,
+      client: wsServer.getClientLabel(clientId)
+    });
+  });
+
+  // Handle client disconnect
+  wsServer.onDisconnect((clientId) => {
+    clientProxies.delete(clientId);
+    events.emit('client.disconnected', { clientId });
+    console.log(`[Server] Client ${clientId} disconnected, proxy removed`);
+  });
+
+  // Plugins are activated after the built-in handlers so they can't replace them
+  if (config.plugins.dir) {
+    await pluginHost.loadFromDirectory(config.plugins.dir);
+  }
+  await pluginHost.activateAll();
+
+  // Serve frontend in production
+  app.get('*', (req, res) => {
+    res.sendFile(path.join(__dirname, '../../web/dist/index.html'));
+  });
+
+  // Start server
+  await new Promise<void>((resolve, reject) => {
+    server.once('error', (error: NodeJS.ErrnoException) => {
+      if (error.code === 'EADDRINUSE') {
+        reject(new Error(`Port ${port} is already in use; choose another with --port or server.port`));
+      } else if (error.code === 'EACCES') {
+        reject(new Error(`Permission denied binding to ${host}:${port}`));
+      } else {
+        reject(error);
+      }
+    });
+    server.listen(port, host, () => resolve());
+  });
+
+  const plugins = pluginHost.list()
+    .map(p => `${p.name} (${p.status})`)
+    .join(', ');
+  const languages = lsManager.getConfigs()
+    .map(c => `  - ${c.languageId} (${c.command})`)
+    .join('\n');
+
+  console.log(`
+╔════════════════════════════════════════════════════════════╗
+║         Online Code Editor Server                         ║
+╚════════════════════════════════════════════════════════════╝
+
+Server running on: http://${host === '0.0.0.0' ? 'localhost' : host}:${port}
+WebSocket endpoint: ws://${host === '0.0.0.0' ? 'localhost' : host}:${port}/lsp
+Workspace root: ${workspaceRoot}
+Log level: ${config.logLevel}
+Auth: ${config.auth.mode === 'token' ? `token (${config.auth.tokens.length} users)` : 'disabled'}
+Webhooks: ${config.webhooks.length > 0 ? `${config.webhooks.length} configured` : 'none'}
+Plugins: ${plugins || 'none'}
+
+Supported languages:
+${languages}
+
+Press Ctrl+C to stop the server
+  `);
+
+  // Graceful shutdown
+  process.on('SIGINT', async () => {
+    console.log('\n[Server] Shutting down gracefully...');
+
+    try {
+      // Let plugins clean up while the services they use are still running
+      await pluginHost.deactivateAll();
+
+      // Stop all Language Server clients
+      await lsManager.stopAll();
+
+      // Kill any workspace commands still running
+      taskManager.killAll();
+      ports.stop();
+      todoScanner.stop();
+
+      // Close WebSocket server
+      await wsServer.close();
+
+      // Close HTTP server
+      server.close(() => {
+        console.log('[Server] Server closed');
+        process.exit(0);
+      });
+
+      // Force exit after 10 seconds
+      setTimeout(() => {
+        console.error('[Server] Forced shutdown after timeout');
+        process.exit(1);
+      }, 10000);
+    } catch (error) {
+      console.error('[Server] Error during shutdown:', error);
+      process.exit(1);
+    }
+  });
+
+  process.on('SIGTERM', async () => {
+    console.log('\n[Server] Received SIGTERM, shutting down...');
+    process.emit('SIGINT');
+  });
+
+  // Handle uncaught errors
+  process.on('uncaughtException', (error) => {
+    console.error('[Server] Uncaught exception:', error);
+    process.exit(1);
+  });
+
+  process.on('unhandledRejection', (reason, promise) => {
+    console.error('[Server] Unhandled rejection at:', promise, 'reason:', reason);
+    process.exit(1);
+  });
+
+  return server;
+}
@@ -0,0 +1,166 @@
+import * as fs from 'fs/promises';
+import { randomBytes } from 'crypto';
+import { WorkspaceState } from '../workspace/state.js';
+
+/**
+ * Hover text for a range of a snapshot file, captured from the language
+ * server when the snapshot was shared
+ */
+export interface SnapshotHover {
+  // 1-based, on a single line
+  line: number;
+  startColumn: number;
+  endColumn: number;
+  // Markdown
+  contents: string;
+}
+
+export interface SnapshotFile {
+  path: string;
+  languageId: string;
+  content: string;
+  hovers: SnapshotHover[];
+}
+
+export interface Snapshot {
+  id: string;
+  title?: string;
+  createdAt: string;
+  // Unset when the snapshot never expires
+  expiresAt?: string;
+  createdBy: string;
+  files: SnapshotFile[];
+}
+
+export type SnapshotSummary = Omit<Snapshot, 'files'> & { files: string[] };
+
+export interface CreateSnapshotOptions {
+  title?: string;
+  // 0 keeps the snapshot until it is revoked
+  expiresInDays: number;
+  createdBy: string;
+  files: SnapshotFile[];
+}
+
+const SNAPSHOTS_DIR = 'snapshots';
+// 128 random bits, URL-safe; doubles as the check that an ID can't name another file
+const ID_PATTERN = /^[A-Za-z0-9_-]{22}$/;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * SnapshotStore keeps immutable copies of shared files in the workspace
+ * state directory, one JSON document per snapshot. Expired snapshots are
+ * deleted when they are next looked at.
+ */
+export class SnapshotStore {
+  constructor(private state: WorkspaceState) {}
+
+  /**
+   * Store a snapshot under a new random ID
+   */
+  async create({ title, expiresInDays, createdBy, files }: CreateSnapshotOptions): Promise<Snapshot> {
+    const now = Date.now();
+    const snapshot: Snapshot = {
+      id: randomBytes(16).toString('base64url'),
+      title: title || undefined,
+      createdAt: new Date(now).toISOString(),
+      expiresAt: expiresInDays > 0 ? new Date(now + expiresInDays * DAY_MS).toISOString() : undefined,
+      createdBy,
+      files
+    };
+    await this.state.writeJson(this.getName(snapshot.id), snapshot);
+    return snapshot;
+  }
+
+  /**
+   * Get a snapshot, or undefined if it doesn't exist, was revoked or expired
+   */
+  async get(id: string): Promise<Snapshot | undefined> {
+    if (!ID_PATTERN.test(id)) {
+      return undefined;
+    }
+    const snapshot = await this.state.readJson<Snapshot | null>(this.getName(id), null);
+    if (!snapshot) {
+      return undefined;
+    }
+    if (this.isExpired(snapshot)) {
+      await this.remove(id);
+      return undefined;
+    }
+    return snapshot;
+  }
+
+  /**
+   * List live snapshots, newest first, optionally only those of one user
+   */
+  async list(createdBy?: string): Promise<SnapshotSummary[]> {
+    const summaries: SnapshotSummary[] = [];
+    for (const id of await this.listIds()) {
+      const snapshot = await this.get(id);
+      if (snapshot && (!createdBy || snapshot.createdBy === createdBy)) {
+        summaries.push(summarize(snapshot));
+      }
+    }
+    return summaries.sort((a, b) => b.createdAt.localeCompare(a.createdAt));
+  }
+
+  /**
+   * Delete a snapshot so its link stops working. Returns false if it didn't exist.
+   */
+  async revoke(id: string): Promise<boolean> {
+    if (!ID_PATTERN.test(id)) {
+      return false;
+    }
+    return this.remove(id);
+  }
+
+  /**
+   * Delete expired snapshots, returning how many were removed
+   */
+  async prune(): Promise<number> {
+    let removed = 0;
+    for (const id of await this.listIds()) {
+      const snapshot = await this.state.readJson<Snapshot | null>(this.getName(id), null);
+      if (snapshot && this.isExpired(snapshot) && await this.remove(id)) {
+        removed++;
+      }
+    }
+    return removed;
+  }
+
+  private isExpired(snapshot: Snapshot): boolean {
+    return snapshot.expiresAt !== undefined && Date.parse(snapshot.expiresAt) <= Date.now();
+  }
+
+  private async listIds(): Promise<string[]> {
+    try {
+      const entries = await fs.readdir(this.state.getStatePath(SNAPSHOTS_DIR));
+      return entries
+        .filter(entry => entry.endsWith('.json'))
+        .map(entry => entry.slice(0, -'.json'.length))
+        .filter(id => ID_PATTERN.test(id));
+    } catch {
+      return [];
+    }
+  }
+
+  private async remove(id: string): Promise<boolean> {
+    try {
+      await fs.unlink(this.state.getStatePath(this.getName(id)));
+      return true;
+    } catch {
+      return false;
+    }
+  }
+
+  private getName(id: string): string {
+    return `${SNAPSHOTS_DIR}/${id}.json`;
+  }
+}
+
+/**
+ * A snapshot without its file contents
+ */
+export function summarize({ files, ...snapshot }: Snapshot): SnapshotSummary {
+  return { ...snapshot, files: files.map(file => file.path) };
+}
@@ -0,0 +1,241 @@
+import { spawn, ChildProcess } from 'child_process';
+
+export type TaskStatus = 'running' | 'succeeded' | 'failed' | 'killed';
+
+export interface TaskOptions {
+  label?: string;
+  command: string;
+  args?: string[];
+  cwd: string;
+  env?: Record<string, string>;
+}
+
+export interface TaskInfo {
+  id: string;
+  label: string;
+  command: string;
+  args: string[];
+  cwd: string;
+  status: TaskStatus;
+  exitCode: number | null;
+  startedAt: string;
+  finishedAt?: string;
+}
+
+interface TaskRecord {
+  info: TaskInfo;
+  process: ChildProcess;
+  output: string;
+  done: Promise<TaskInfo>;
+}
+
+type OutputListener = (taskId: string, stream: 'stdout' | 'stderr', data: string) => void;
+type StartListener = (task: TaskInfo) => void;
+type ExitListener = (task: TaskInfo) => void;
+
+/**
+ * TaskManager runs workspace commands as child processes and keeps their
+ * output around so it can be returned to the client or streamed later.
+ */
+export class TaskManager {
+  private tasks: Map<string, TaskRecord> = new Map();
+  private startListeners: StartListener[] = [];
+  private outputListeners: OutputListener[] = [];
+  private exitListeners: ExitListener[] = [];
+  private maxOutputLength = 1024 * 1024; // Keep the last 1MB of output per task
+  private maxFinishedTasks = 50;
+
+  /**
+   * Start a command and return its task info immediately
+   */
+  run(options: TaskOptions): TaskInfo {
+    const args = options.args || [];
+    const info: TaskInfo = {
+      id: this.generateTaskId(),
+      label: options.label || [options.command, ...args].join(' '),
+      command: options.command,
+      args,
+      cwd: options.cwd,
+      status: 'running',
+      exitCode: null,
+      startedAt: new Date().toISOString()
+    };
+
+    console.log(`[Tasks] Starting ${info.id}: ${info.label} (cwd: ${info.cwd})`);
+
+    const child = spawn(options.command, args, {
+      cwd: options.cwd,
+      env: { ...process.env, ...options.env },
+      stdio: ['ignore', 'pipe', 'pipe'],
+      // In a process group of its own, so kill() reaches everything it starts
+      detached: process.platform !== 'win32'
+    });
+
+    let resolveDone: (info: TaskInfo) => void;
+    const done = new Promise<TaskInfo>((resolve) => {
+      resolveDone = resolve;
+    });
+
+    const record: TaskRecord = { info, process: child, output: '', done };
+    this.tasks.set(info.id, record);
+
+    const finish = (status: TaskStatus, exitCode: number | null) => {
+      if (info.status !== 'running') {
+        return;
+      }
+      info.status = status;
+      info.exitCode = exitCode;
+      info.finishedAt = new Date().toISOString();
+      console.log(`[Tasks] ${info.id} finished with status ${status} (exit code ${exitCode})`);
+      this.exitListeners.forEach(listener => listener({ ...info }));
+      this.pruneFinishedTasks();
+      resolveDone({ ...info });
+    };
+
+    this.startListeners.forEach(listener => listener({ ...info }));
+
+    child.stdout?.on('data', (data: Buffer) => this.appendOutput(record, 'stdout', data.toString()));
+    child.stderr?.on('data', (data: Buffer) => this.appendOutput(record, 'stderr', data.toString()));
+
+    child.on('error', (error) => {
+      this.appendOutput(record, 'stderr', `${error.message}\n`);
+      finish('failed', null);
+    });
+
+    child.on('close', (code, signal) => {
+      if (record.info.status === 'running' && signal && code === null) {
+        finish('killed', null);
+        return;
+      }
+      finish(code === 0 ? 'succeeded' : 'failed', code);
+    });
+
+    return { ...info };
+  }
+
+  /**
+   * Wait for a task to finish
+   */
+  async wait(taskId: string): Promise<TaskInfo> {
+    const record = this.tasks.get(taskId);
+    if (!record) {
+      throw new Error(`Unknown task: ${taskId}`);
+    }
+    return record.done;
+  }
+
+  /**
+   * Kill a running task
+   */
+  kill(taskId: string): boolean {
+    const record = this.tasks.get(taskId);
+    if (!record || record.info.status !== 'running') {
+      return false;
+    }
+    // `go run` and `npm run` don't pass SIGTERM on to the program they
+    // started, so signal the whole process group
+    const { pid } = record.process;
+    if (pid !== undefined && process.platform !== 'win32') {
+      try {
+        process.kill(-pid, 'SIGTERM');
+        return true;
+      } catch {
+        // The group is gone; fall back to the process itself
+      }
+    }
+    return record.process.kill('SIGTERM');
+  }
+
+  /**
+   * Get task info by ID
+   */
+  getTask(taskId: string): TaskInfo | undefined {
+    const record = this.tasks.get(taskId);
+    return record ? { ...record.info } : undefined;
+  }
+
+  /**
+   * Get the captured output of a task
+   */
+  getOutput(taskId: string): string | undefined {
+    return this.tasks.get(taskId)?.output;
+  }
+
+  /**
+   * List all known tasks, most recent first
+   */
+  listTasks(): TaskInfo[] {
+    return Array.from(this.tasks.values())
+      .map(record => ({ ...record.info }))
+      .sort((a, b) => b.startedAt.localeCompare(a.startedAt));
+  }
+
+  /**
+   * List running tasks with the process ID of their command
+   */
+  listProcesses(): Array<{ task: TaskInfo; pid: number }> {
+    return Array.from(this.tasks.values())
+      .filter(record => record.info.status === 'running' && record.process.pid !== undefined)
+      .map(record => ({ task: { ...record.info }, pid: record.process.pid! }));
+  }
+
+  /**
+   * Register a task start listener
+   */
+  onStart(listener: StartListener): void {
+    this.startListeners.push(listener);
+  }
+
+  /**
+   * Register an output listener
+   */
+  onOutput(listener: OutputListener): void {
+    this.outputListeners.push(listener);
+  }
+
+  /**
+   * Register a task exit listener
+   */
+  onExit(listener: ExitListener): void {
+    this.exitListeners.push(listener);
+  }
+
+  /**
+   * Kill all running tasks
+   */
+  killAll(): void {
+    this.tasks.forEach((record, taskId) => {
+      if (record.info.status === 'running') {
+        this.kill(taskId);
+      }
+    });
+  }
+
+  private appendOutput(record: TaskRecord, stream: 'stdout' | 'stderr', data: string): void {
+    record.output += data;
+    if (record.output.length > this.maxOutputLength) {
+      record.output = record.output.slice(record.output.length - this.maxOutputLength);
+    }
+    this.outputListeners.forEach(listener => listener(record.info.id, stream, data));
+  }
+
+  /**
+   * Drop the oldest finished tasks so memory doesn't grow unbounded
+   */
+  private pruneFinishedTasks(): void {
+    const finished = Array.from(this.tasks.values())
+      .filter(record => record.info.status !== 'running')
+      .sort((a, b) => a.info.startedAt.localeCompare(b.info.startedAt));
+
+    while (finished.length > this.maxFinishedTasks) {
+      const oldest = finished.shift();
+      if (oldest) {
+        this.tasks.delete(oldest.info.id);
+      }
+    }
+  }
+
+  private generateTaskId(): string {
+    return `task-${Date.now()}-${Math.random().toString(36).substring(7)}`;
+  }
+}
@@ -0,0 +1,261 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath } from 'url';
+import { RealFileSystem } from '../fs/real.js';
+import { TaskManager, TaskInfo } from '../tasks/manager.js';
+
+const __filename = fileURLToPath(import.meta.url);
+const __dirname = path.dirname(__filename);
+
+// Built-in templates live in server/templates (two levels up from src/templates or dist/templates)
+export const BUILTIN_TEMPLATES_DIR = path.resolve(__dirname, '../../templates');
+
+export interface TemplateVariable {
+  name: string;
+  description?: string;
+  default?: string;
+  pattern?: string;
+  required?: boolean;
+}
+
+export interface TemplateHook {
+  command: string;
+  args?: string[];
+  // Optional hooks don't fail project creation when they exit non-zero
+  optional?: boolean;
+}
+
+export interface TemplateDescriptor {
+  id: string;
+  name: string;
+  description: string;
+  language?: string;
+  variables: TemplateVariable[];
+  hooks: TemplateHook[];
+}
+
+export interface HookResult {
+  task: TaskInfo;
+  output: string;
+  optional: boolean;
+}
+
+export interface CreateProjectResult {
+  path: string;
+  files: string[];
+  hooks: HookResult[];
+}
+
+interface LoadedTemplate {
+  descriptor: TemplateDescriptor;
+  filesDir: string;
+}
+
+/**
+ * Replace {{name}} placeholders with variable values.
+ * Unknown placeholders are left untouched so file contents that happen to
+ * contain braces are not mangled.
+ */
+export function renderTemplateString(input: string, variables: Record<string, string>): string {
+  return input.replace(/\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}/g, (match, name: string) => {
+    return Object.prototype.hasOwnProperty.call(variables, name) ? variables[name] : match;
+  });
+}
+
+/**
+ * TemplateManager discovers project templates (directories containing a
+ * template.json and a files/ folder) and scaffolds them into the workspace.
+ */
+export class TemplateManager {
+  private templates: Map<string, LoadedTemplate> = new Map();
+
+  constructor(
+    private fileSystem: RealFileSystem,
+    private taskManager: TaskManager,
+    private templateDirs: string[] = [BUILTIN_TEMPLATES_DIR]
+  ) {}
+
+  /**
+   * Scan template directories and (re)load all template descriptors
+   */
+  async load(): Promise<void> {
+    this.templates.clear();
+
+    for (const dir of this.templateDirs) {
+      let entries;
+      try {
+        entries = await fs.readdir(dir, { withFileTypes: true });
+      } catch {
+        // Missing template directories are not an error
+        continue;
+      }
+
+      for (const entry of entries) {
+        if (!entry.isDirectory()) {
+          continue;
+        }
+        const templateDir = path.join(dir, entry.name);
+        try {
+          const raw = await fs.readFile(path.join(templateDir, 'template.json'), 'utf-8');
+          const descriptor = this.parseDescriptor(entry.name, JSON.parse(raw));
+          this.templates.set(descriptor.id, {
+            descriptor,
+            filesDir: path.join(templateDir, 'files')
+          });
+        } catch (error) {
+          console.error(`[Templates] Failed to load template from ${templateDir}:`, error);
+        }
+      }
+    }
+
+    console.log(`[Templates] Loaded ${this.templates.size} template(s)`);
+  }
+
+  /**
+   * List available templates
+   */
+  listTemplates(): TemplateDescriptor[] {
+    return Array.from(this.templates.values())
+      .map(t => t.descriptor)
+      .sort((a, b) => a.name.localeCompare(b.name));
+  }
+
+  /**
+   * Get a template descriptor by ID
+   */
+  getTemplate(id: string): TemplateDescriptor | undefined {
+    return this.templates.get(id)?.descriptor;
+  }
+
+  /**
+   * Resolve user-supplied values against the template's variable definitions.
+   * Defaults may reference earlier variables, e.g. "example.com/{{projectName}}".
+   */
+  resolveVariables(descriptor: TemplateDescriptor, values: Record<string, string>): Record<string, string> {
+    const resolved: Record<string, string> = {};
+
+    for (const variable of descriptor.variables) {
+      const provided = values[variable.name];
+      const value = provided !== undefined && provided !== ''
+        ? provided
+        : renderTemplateString(variable.default ?? '', resolved);
+
+      if (!value && variable.required !== false) {
+        throw new Error(`Missing value for template variable: ${variable.name}`);
+      }
+      if (value && variable.pattern && !new RegExp(variable.pattern).test(value)) {
+        throw new Error(`Invalid value for ${variable.name}: must match ${variable.pattern}`);
+      }
+      resolved[variable.name] = value;
+    }
+
+    return resolved;
+  }
+
+  /**
+   * Create a new project from a template at a workspace-relative path and run its hooks
+   */
+  async createProject(
+    templateId: string,
+    targetPath: string,
+    values: Record<string, string> = {}
+  ): Promise<CreateProjectResult> {
+    const template = this.templates.get(templateId);
+    if (!template) {
+      throw new Error(`Unknown template: ${templateId}`);
+    }
+
+    const variables = this.resolveVariables(template.descriptor, values);
+    const targetDir = this.fileSystem.resolveWorkspacePath(renderTemplateString(targetPath, variables));
+
+    // Refuse to scaffold over existing content
+    try {
+      const existing = await fs.readdir(targetDir);
+      if (existing.length > 0) {
+        throw new Error(`Target directory is not empty: ${targetPath}`);
+      }
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+        throw error;
+      }
+    }
+
+    console.log(`[Templates] Creating ${templateId} project at ${targetDir}`);
+    await fs.mkdir(targetDir, { recursive: true });
+
+    const files = await this.copyFiles(template.filesDir, targetDir, variables);
+    const hooks: HookResult[] = [];
+
+    for (const hook of template.descriptor.hooks) {
+      const command = renderTemplateString(hook.command, variables);
+      const args = (hook.args || []).map(arg => renderTemplateString(arg, variables));
+      const task = this.taskManager.run({
+        label: `${templateId}: ${[command, ...args].join(' ')}`,
+        command,
+        args,
+        cwd: targetDir
+      });
+      const finished = await this.taskManager.wait(task.id);
+      const output = this.taskManager.getOutput(task.id) || '';
+      hooks.push({ task: finished, output, optional: hook.optional === true });
+
+      if (finished.status !== 'succeeded' && !hook.optional) {
+        console.error(`[Templates] Hook failed for ${templateId}: ${finished.label}`);
+        break;
+      }
+    }
+
+    const workspaceRoot = path.resolve(this.fileSystem.getWorkspaceRoot());
+    const toWorkspacePath = (p: string) => '/' + path.relative(workspaceRoot, p).replace(/\\/g, '/');
+
+    return {
+      path: toWorkspacePath(targetDir),
+      files: files.map(toWorkspacePath),
+      hooks
+    };
+  }
+
+  /**
+   * Recursively copy template files, rendering both paths and contents
+   */
+  private async copyFiles(sourceDir: string, targetDir: string, variables: Record<string, string>): Promise<string[]> {
+    const written: string[] = [];
+    let entries;
+    try {
+      entries = await fs.readdir(sourceDir, { withFileTypes: true });
+    } catch {
+      return written;
+    }
+
+    for (const entry of entries) {
+      const sourcePath = path.join(sourceDir, entry.name);
+      const targetName = renderTemplateString(entry.name, variables);
+      const targetPath = path.join(targetDir, targetName);
+
+      if (entry.isDirectory()) {
+        await fs.mkdir(targetPath, { recursive: true });
+        written.push(...await this.copyFiles(sourcePath, targetPath, variables));
+      } else if (entry.isFile()) {
+        const content = await fs.readFile(sourcePath, 'utf-8');
+        await fs.writeFile(targetPath, renderTemplateString(content, variables), 'utf-8');
+        written.push(targetPath);
+      }
+    }
+
+    return written;
+  }
+
+  private parseDescriptor(dirName: string, raw: any): TemplateDescriptor {
+    if (!raw || typeof raw !== 'object') {
+      throw new Error('template.json must contain an object');
+    }
+    return {
+      id: typeof raw.id === 'string' ? raw.id : dirName,
+      name: typeof raw.name === 'string' ? raw.name : dirName,
+      description: typeof raw.description === 'string' ? raw.description : '',
+      language: typeof raw.language === 'string' ? raw.language : undefined,
+      variables: Array.isArray(raw.variables) ? raw.variables : [],
+      hooks: Array.isArray(raw.hooks) ? raw.hooks : []
+    };
+  }
+}
@@ -0,0 +1,253 @@
+import * as fs from 'fs/promises';
+import { watch, FSWatcher } from 'fs';
+import * as path from 'path';
+
+export interface TodoItem {
+  path: string;
+  line: number;
+  column: number;
+  tag: string;
+  text: string;
+}
+
+export const DEFAULT_TODO_TAGS = ['TODO', 'FIXME', 'HACK'];
+
+// Directories that are never worth scanning
+const IGNORED_DIRECTORIES = new Set(['node_modules', 'vendor', 'dist', 'build']);
+const MAX_FILE_SIZE = 1024 * 1024;
+
+/**
+ * Find tagged comments in file content.
+ * A tag only counts when it directly follows a comment marker, so identifiers
+ * such as `todoList` or strings mentioning TODO in prose are ignored.
+ */
+export function findTodos(content: string, tags: string[]): Omit<TodoItem, 'path'>[] {
+  if (tags.length === 0) {
+    return [];
+  }
+
+  const escaped = tags.map(tag => tag.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'));
+  const pattern = new RegExp(
+    `(?:\\/\\/+|#+|\\/\\*+|^\\s*\\*|<!--|--|;+)\\s*(${escaped.join('|')})\\b(?:\\([^)]*\\))?:?(.*)$`
+  );
+
+  const results: Omit<TodoItem, 'path'>[] = [];
+  const lines = content.split(/\r?\n/);
+
+  lines.forEach((lineText, index) => {
+    const match = pattern.exec(lineText);
+    if (!match) {
+      return;
+    }
+    const tag = match[1];
+    const text = match[2]
+      .replace(/\*\/\s*$/, '')
+      .replace(/-->\s*$/, '')
+      .trim();
+    results.push({
+      line: index + 1,
+      column: match.index + match[0].indexOf(tag) + 1,
+      tag,
+      text
+    });
+  });
+
+  return results;
+}
+
+/**
+ * TodoScanner scans the workspace for TODO/FIXME/HACK comments and keeps the
+ * result up to date as files change on disk.
+ */
+export class TodoScanner {
+  private items: Map<string, TodoItem[]> = new Map();
+  private watcher: FSWatcher | null = null;
+  private started = false;
+  private pendingRefreshes: Map<string, NodeJS.Timeout> = new Map();
+  private changeListeners: Array<(paths: string[]) => void> = [];
+  private debounceDelay = 200;
+
+  constructor(
+    private workspaceRoot: string,
+    private tags: string[] = DEFAULT_TODO_TAGS
+  ) {}
+
+  /**
+   * Run the initial scan and start watching the workspace
+   */
+  async start(): Promise<void> {
+    this.started = true;
+    await this.scan();
+
+    try {
+      this.watcher = watch(this.workspaceRoot, { recursive: true }, (_event, filename) => {
+        if (filename) {
+          this.scheduleRefresh('/' + filename.toString().replace(/\\/g, '/'));
+        }
+      });
+      this.watcher.on('error', (error) => {
+        console.error('[TODO] Workspace watcher error:', error);
+      });
+    } catch (error) {
+      console.error('[TODO] Failed to watch workspace, TODOs will only refresh on file operations:', error);
+    }
+  }
+
+  /**
+   * Stop watching the workspace
+   */
+  stop(): void {
+    this.started = false;
+    this.watcher?.close();
+    this.watcher = null;
+    this.pendingRefreshes.forEach(timer => clearTimeout(timer));
+    this.pendingRefreshes.clear();
+  }
+
+  /**
+   * Rescan the whole workspace
+   */
+  async scan(): Promise<void> {
+    this.items.clear();
+    await this.scanDirectory(this.workspaceRoot);
+    console.log(`[TODO] Indexed ${this.getItems().length} item(s) in ${this.items.size} file(s)`);
+    this.notifyChange(['/']);
+  }
+
+  /**
+   * Re-index a single workspace path (file or directory) after it changed
+   */
+  async refreshPath(workspacePath: string): Promise<void> {
+    if (this.isIgnored(workspacePath)) {
+      return;
+    }
+
+    const fullPath = path.join(this.workspaceRoot, workspacePath.replace(/^\//, ''));
+
+    // Forget everything at or below the path, then re-add what still exists
+    const prefix = workspacePath.endsWith('/') ? workspacePath : workspacePath + '/';
+    for (const key of Array.from(this.items.keys())) {
+      if (key === workspacePath || key.startsWith(prefix)) {
+        this.items.delete(key);
+      }
+    }
+
+    try {
+      const stats = await fs.stat(fullPath);
+      if (stats.isDirectory()) {
+        await this.scanDirectory(fullPath);
+      } else if (stats.isFile()) {
+        await this.scanFile(fullPath, stats.size);
+      }
+    } catch {
+      // Path was deleted
+    }
+
+    this.notifyChange([workspacePath]);
+  }
+
+  /**
+   * Get all TODO items sorted by path and line
+   */
+  getItems(): TodoItem[] {
+    return Array.from(this.items.values())
+      .flat()
+      .sort((a, b) => a.path.localeCompare(b.path) || a.line - b.line);
+  }
+
+  /**
+   * Get the configured tags
+   */
+  getTags(): string[] {
+    return [...this.tags];
+  }
+
+  /**
+   * Change the tags to look for, rescanning if the index is already running
+   */
+  async setTags(tags: string[]): Promise<void> {
+    this.tags = tags.filter(tag => tag.trim().length > 0);
+    if (this.started) {
+      await this.scan();
+    }
+  }
+
+  /**
+   * Register a listener called with the changed workspace paths
+   */
+  onChange(listener: (paths: string[]) => void): void {
+    this.changeListeners.push(listener);
+  }
+
+  private scheduleRefresh(workspacePath: string): void {
+    if (this.isIgnored(workspacePath)) {
+      return;
+    }
+    const existing = this.pendingRefreshes.get(workspacePath);
+    if (existing) {
+      clearTimeout(existing);
+    }
+    this.pendingRefreshes.set(workspacePath, setTimeout(() => {
+      this.pendingRefreshes.delete(workspacePath);
+      this.refreshPath(workspacePath).catch((error) => {
+        console.error(`[TODO] Failed to refresh ${workspacePath}:`, error);
+      });
+    }, this.debounceDelay));
+  }
+
+  private isIgnored(workspacePath: string): boolean {
+    return workspacePath
+      .split('/')
+      .some(segment => (segment.startsWith('.') && segment !== '.') || IGNORED_DIRECTORIES.has(segment));
+  }
+
+  private async scanDirectory(dir: string): Promise<void> {
+    let entries;
+    try {
+      entries = await fs.readdir(dir, { withFileTypes: true });
+    } catch {
+      return;
+    }
+
+    for (const entry of entries) {
+      if (entry.name.startsWith('.') || IGNORED_DIRECTORIES.has(entry.name)) {
+        continue;
+      }
+      const fullPath = path.join(dir, entry.name);
+      if (entry.isDirectory()) {
+        await this.scanDirectory(fullPath);
+      } else if (entry.isFile()) {
+        try {
+          const stats = await fs.stat(fullPath);
+          await this.scanFile(fullPath, stats.size);
+        } catch {
+          // File disappeared while scanning
+        }
+      }
+    }
+  }
+
+  private async scanFile(fullPath: string, size: number): Promise<void> {
+    if (size > MAX_FILE_SIZE) {
+      return;
+    }
+
+    const buffer = await fs.readFile(fullPath);
+    // Skip binary files
+    if (buffer.subarray(0, 8000).includes(0)) {
+      return;
+    }
+
+    const workspacePath = '/' + path.relative(this.workspaceRoot, fullPath).replace(/\\/g, '/');
+    const found = findTodos(buffer.toString('utf-8'), this.tags);
+    if (found.length > 0) {
+      this.items.set(workspacePath, found.map(item => ({ path: workspacePath, ...item })));
+    } else {
+      this.items.delete(workspacePath);
+    }
+  }
+
+  private notifyChange(paths: string[]): void {
+    this.changeListeners.forEach(listener => listener(paths));
+  }
+}
@@ -1,5 +1,6 @@
 import { WebSocketServer, WebSocket } from 'ws';
-import { Server } from 'http';
+import { Server, IncomingMessage } from 'http';
+import type { Duplex } from 'stream';
 
 export interface WebSocketMessage {
   jsonrpc: '2.0';
@@ -14,23 +15,65 @@ export interface WebSocketMessage {
   };
 }
 
+export interface LSPWebSocketServerOptions {
+  // Reject new connections once this many clients are connected
+  maxClients?: number;
+  // Return the user for an upgrade request, or undefined to reject it
+  authenticate?: (req: IncomingMessage) => string | undefined;
+  // Describe the client behind an upgrade request (e.g. its browser session id)
+  describeClient?: (req: IncomingMessage) => string;
+}
+
 export class LSPWebSocketServer {
   private wss: WebSocketServer;
   private clients: Map<string, WebSocket> = new Map();
+  private clientUsers: Map<string, string> = new Map();
+  private clientLabels: Map<string, string> = new Map();
   private messageHandlers: Map<string, (clientId: string, message: WebSocketMessage) => void> = new Map();
+  private connectHandlers: Array<(clientId: string) => void> = [];
   private disconnectHandlers: Array<(clientId: string) => void> = [];
 
-  constructor(server: Server, path: string = '/lsp') {
+  constructor(server: Server, path: string = '/lsp', private options: LSPWebSocketServerOptions = {}) {
+    // Upgrades for other paths (e.g. /preview) are left to other handlers
     this.wss = new WebSocketServer({
-      server,
-      path
+      noServer: true,
+      verifyClient: (info, callback) => {
+        if (this.options.maxClients !== undefined && this.clients.size >= this.options.maxClients) {
+          console.warn('[WebSocket] Rejecting connection: client limit reached');
+          callback(false, 503, 'Too many clients');
+          return;
+        }
+        if (this.options.authenticate && !this.options.authenticate(info.req)) {
+          console.warn('[WebSocket] Rejecting unauthenticated connection');
+          callback(false, 401, 'Unauthorized');
+          return;
+        }
+        callback(true);
+      }
+    });
+
+    server.on('upgrade', (req: IncomingMessage, socket: Duplex, head: Buffer) => {
+      if (new URL(req.url || '/', 'http://localhost').pathname !== path) {
+        return;
+      }
+      this.wss.handleUpgrade(req, socket, head, (ws) => {
+        this.wss.emit('connection', ws, req);
+      });
     });
 
     this.wss.on('connection', (ws: WebSocket, req) => {
       const clientId = this.generateClientId();
       this.clients.set(clientId, ws);
+      const user = this.options.authenticate?.(req);
+      if (user) {
+        this.clientUsers.set(clientId, user);
+      }
+      if (this.options.describeClient) {
+        this.clientLabels.set(clientId, this.options.describeClient(req));
+      }
 
       console.log(`[WebSocket] Client connected: ${clientId}`);
+      this.connectHandlers.forEach(handler => handler(clientId));
 
       ws.on('message', (data: Buffer) => {
         try {
@@ -83,6 +126,8 @@ export class LSPWebSocketServer {
    */
   private handleDisconnect(clientId: string): void {
     this.clients.delete(clientId);
+    this.clientUsers.delete(clientId);
+    this.clientLabels.delete(clientId);
     this.disconnectHandlers.forEach(handler => handler(clientId));
   }
 
@@ -93,6 +138,27 @@ export class LSPWebSocketServer {
     this.messageHandlers.set(method, handler);
   }
 
+  /**
+   * Check whether a handler is registered for a method
+   */
+  hasMethod(method: string): boolean {
+    return this.messageHandlers.has(method);
+  }
+
+  /**
+   * Remove the handler for a method
+   */
+  offMethod(method: string): void {
+    this.messageHandlers.delete(method);
+  }
+
+  /**
+   * Register a handler called when a client connects
+   */
+  onConnect(handler: (clientId: string) => void): void {
+    this.connectHandlers.push(handler);
+  }
+
   /**
    * Register a disconnect handler
    */
@@ -147,6 +213,20 @@ export class LSPWebSocketServer {
     return Array.from(this.clients.keys());
   }
 
+  /**
+   * Get the authenticated user for a client, if any
+   */
+  getClientUser(clientId: string): string | undefined {
+    return this.clientUsers.get(clientId);
+  }
+
+  /**
+   * Get a description of a client for logs, falling back to its ID
+   */
+  getClientLabel(clientId: string): string {
+    return this.clientLabels.get(clientId) ?? clientId;
+  }
+
   /**
    * Get client count
    */
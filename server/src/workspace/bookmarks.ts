@@ -0,0 +1,136 @@
+import { WorkspaceState } from './state.js';
+
+export interface Bookmark {
+  id: string;
+  path: string;
+  line: number;
+  label?: string;
+  createdAt: string;
+}
+
+const BOOKMARKS_FILE = 'bookmarks.json';
+
+/**
+ * BookmarkStore keeps line bookmarks for the workspace, persisted in the
+ * workspace state directory so they survive restarts.
+ */
+export class BookmarkStore {
+  private bookmarks: Bookmark[] | null = null;
+  private loading: Promise<Bookmark[]> | null = null;
+
+  constructor(private state: WorkspaceState) {}
+
+  /**
+   * List bookmarks, optionally only those in a single file
+   */
+  async list(filePath?: string): Promise<Bookmark[]> {
+    const bookmarks = await this.load();
+    return bookmarks
+      .filter(b => !filePath || b.path === filePath)
+      .sort((a, b) => a.path.localeCompare(b.path) || a.line - b.line);
+  }
+
+  /**
+   * Toggle a bookmark on a line. Returns the added bookmark, or undefined if one was removed.
+   */
+  async toggle(filePath: string, line: number, label?: string): Promise<Bookmark | undefined> {
+    const bookmarks = await this.load();
+    const index = bookmarks.findIndex(b => b.path === filePath && b.line === line);
+
+    if (index >= 0) {
+      bookmarks.splice(index, 1);
+      await this.save();
+      return undefined;
+    }
+
+    const bookmark: Bookmark = {
+      id: this.generateId(),
+      path: filePath,
+      line,
+      label: label || undefined,
+      createdAt: new Date().toISOString()
+    };
+    bookmarks.push(bookmark);
+    await this.save();
+    return bookmark;
+  }
+
+  /**
+   * Set or clear the label of a bookmark
+   */
+  async setLabel(id: string, label: string | undefined): Promise<Bookmark | undefined> {
+    const bookmarks = await this.load();
+    const bookmark = bookmarks.find(b => b.id === id);
+    if (!bookmark) {
+      return undefined;
+    }
+    bookmark.label = label || undefined;
+    await this.save();
+    return bookmark;
+  }
+
+  /**
+   * Remove a bookmark by ID
+   */
+  async remove(id: string): Promise<boolean> {
+    const bookmarks = await this.load();
+    const index = bookmarks.findIndex(b => b.id === id);
+    if (index < 0) {
+      return false;
+    }
+    bookmarks.splice(index, 1);
+    await this.save();
+    return true;
+  }
+
+  /**
+   * Move bookmarks along with a renamed file or directory
+   */
+  async renamePath(oldPath: string, newPath: string): Promise<void> {
+    const bookmarks = await this.load();
+    let changed = false;
+    for (const bookmark of bookmarks) {
+      if (bookmark.path === oldPath || bookmark.path.startsWith(oldPath + '/')) {
+        bookmark.path = newPath + bookmark.path.substring(oldPath.length);
+        changed = true;
+      }
+    }
+    if (changed) {
+      await this.save();
+    }
+  }
+
+  /**
+   * Drop bookmarks for a deleted file or directory
+   */
+  async removePath(targetPath: string): Promise<void> {
+    const bookmarks = await this.load();
+    const remaining = bookmarks.filter(b => b.path !== targetPath && !b.path.startsWith(targetPath + '/'));
+    if (remaining.length !== bookmarks.length) {
+      this.bookmarks = remaining;
+      await this.save();
+    }
+  }
+
+  private async load(): Promise<Bookmark[]> {
+    if (this.bookmarks) {
+      return this.bookmarks;
+    }
+    // Share a single read between concurrent callers
+    if (!this.loading) {
+      this.loading = this.state.readJson<Bookmark[]>(BOOKMARKS_FILE, []).then(stored => {
+        this.bookmarks = Array.isArray(stored) ? stored : [];
+        return this.bookmarks;
+      });
+    }
+    return this.loading;
+  }
+
+  private async save(): Promise<void> {
+    await this.state.writeJson(BOOKMARKS_FILE, this.bookmarks || []);
+  }
+
+  private generateId(): string {
+    return `bm-${Date.now()}-${Math.random().toString(36).substring(7)}`;
+  }
+}
@@ -0,0 +1,157 @@
+import * as fs from 'fs/promises';
+import { createWriteStream } from 'fs';
+import * as path from 'path';
+import { Readable } from 'stream';
+import { pipeline } from 'stream/promises';
+import { createGzip } from 'zlib';
+import { STATE_DIR_NAME } from './state.js';
+
+export interface ExportOptions {
+  // Include the .oneline-editor state directory (bookmarks, settings)
+  includeState?: boolean;
+  // Directory names skipped anywhere in the tree
+  exclude?: string[];
+}
+
+export interface ExportResult {
+  files: number;
+  bytes: number;
+}
+
+export const DEFAULT_EXPORT_EXCLUDES = ['node_modules', '.git'];
+
+const BLOCK_SIZE = 512;
+
+/**
+ * Write the workspace to a gzipped tar archive. Entries are stored relative
+ * to the workspace root; symlinks and special files are skipped.
+ */
+export async function exportWorkspace(
+  workspaceRoot: string,
+  outputPath: string,
+  options: ExportOptions = {}
+): Promise<ExportResult> {
+  const root = path.resolve(workspaceRoot);
+  const output = path.resolve(outputPath);
+  const exclude = new Set(options.exclude ?? DEFAULT_EXPORT_EXCLUDES);
+  if (!options.includeState) {
+    exclude.add(STATE_DIR_NAME);
+  }
+
+  const result: ExportResult = { files: 0, bytes: 0 };
+
+  async function* entries(dir: string): AsyncGenerator<Buffer> {
+    const children = await fs.readdir(dir, { withFileTypes: true });
+    children.sort((a, b) => a.name.localeCompare(b.name));
+
+    for (const child of children) {
+      const fullPath = path.join(dir, child.name);
+      // Never archive the archive itself when it's written inside the workspace
+      if (exclude.has(child.name) || fullPath === output) {
+        continue;
+      }
+      const name = path.relative(root, fullPath).split(path.sep).join('/');
+      const stats = await fs.stat(fullPath);
+
+      if (child.isDirectory()) {
+        yield* tarHeader(name + '/', '5', 0, stats.mode, stats.mtime);
+        yield* entries(fullPath);
+      } else if (child.isFile()) {
+        const content = await fs.readFile(fullPath);
+        yield* tarHeader(name, '0', content.length, stats.mode, stats.mtime);
+        yield content;
+        const padding = (BLOCK_SIZE - (content.length % BLOCK_SIZE)) % BLOCK_SIZE;
+        if (padding > 0) {
+          yield Buffer.alloc(padding);
+        }
+        result.files++;
+        result.bytes += content.length;
+      }
+    }
+  }
+
+  async function* archive(): AsyncGenerator<Buffer> {
+    yield* entries(root);
+    // Two zero blocks mark the end of the archive
+    yield Buffer.alloc(BLOCK_SIZE * 2);
+  }
+
+  await fs.mkdir(path.dirname(output), { recursive: true });
+  await pipeline(Readable.from(archive()), createGzip(), createWriteStream(output));
+  return result;
+}
+
+/**
+ * Build the header block(s) for one entry. Names that don't fit the ustar
+ * name/prefix fields get a preceding PAX extended header.
+ */
+function* tarHeader(name: string, type: '0' | '5', size: number, mode: number, mtime: Date): Generator<Buffer> {
+  let ustarName = name;
+  let prefix = '';
+
+  if (Buffer.byteLength(name) > 100) {
+    const split = splitUstarName(name);
+    if (split) {
+      [prefix, ustarName] = split;
+    } else {
+      const record = paxRecord('path', name);
+      yield* rawHeader('PaxHeader', 'x', record.length, 0o644, mtime, '');
+      yield record;
+      yield Buffer.alloc((BLOCK_SIZE - (record.length % BLOCK_SIZE)) % BLOCK_SIZE);
+      ustarName = name.slice(-100).replace(/^[^/]*\//, '');
+    }
+  }
+
+  yield* rawHeader(ustarName, type, size, mode & 0o7777, mtime, prefix);
+}
+
+function* rawHeader(name: string, type: string, size: number, mode: number, mtime: Date, prefix: string): Generator<Buffer> {
+  const header = Buffer.alloc(BLOCK_SIZE);
+  header.write(name, 0, 100, 'utf-8');
+  writeOctal(header, mode, 100, 8);
+  writeOctal(header, 0, 108, 8);
+  writeOctal(header, 0, 116, 8);
+  writeOctal(header, size, 124, 12);
+  writeOctal(header, Math.floor(mtime.getTime() / 1000), 136, 12);
+  header.fill(' ', 148, 156);
+  header.write(type, 156, 1, 'ascii');
+  header.write('ustar\0', 257, 6, 'ascii');
+  header.write('00', 263, 2, 'ascii');
+  header.write(prefix, 345, 155, 'utf-8');
+
+  let checksum = 0;
+  for (const byte of header) {
+    checksum += byte;
+  }
+  writeOctal(header, checksum, 148, 7);
+  header[155] = 0x20;
+
+  yield header;
+}
+
+function writeOctal(buffer: Buffer, value: number, offset: number, length: number): void {
+  buffer.write(value.toString(8).padStart(length - 1, '0') + '\0', offset, length, 'ascii');
+}
+
+function splitUstarName(name: string): [string, string] | undefined {
+  // Split at a slash so the prefix fits 155 bytes and the rest fits 100
+  for (let i = name.length - 1; i > 0; i--) {
+    if (name[i] !== '/') continue;
+    const prefix = name.slice(0, i);
+    const rest = name.slice(i + 1);
+    if (Buffer.byteLength(prefix) <= 155 && Buffer.byteLength(rest) <= 100 && rest.length > 0) {
+      return [prefix, rest];
+    }
+  }
+  return undefined;
+}
+
+function paxRecord(key: string, value: string): Buffer {
+  // The length prefix counts itself, so grow it until it's stable
+  const body = ` ${key}=${value}\n`;
+  let length = Buffer.byteLength(body);
+  while (String(length).length + Buffer.byteLength(body) !== length) {
+    length = String(length).length + Buffer.byteLength(body);
+  }
+  return Buffer.from(`${length}${body}`);
+}
@@ -0,0 +1,78 @@
+import * as fs from 'fs/promises';
+import * as path from 'path';
+
+// Hidden directory inside the workspace root that holds editor state.
+// Hidden entries are skipped by the file tree, so it never shows up in the UI.
+export const STATE_DIR_NAME = '.oneline-editor';
+
+/**
+ * Whether a workspace path (e.g. /.oneline-editor/audit.jsonl) is inside the
+ * state directory. The audit log, snapshots and comments live there, so the
+ * file API and language server documents must never reach it.
+ */
+export function isStatePath(workspacePath: string): boolean {
+  const [first] = workspacePath.replace(/\\/g, '/').replace(/^\/+/, '').split('/');
+  return first.toLowerCase() === STATE_DIR_NAME;
+}
+
+/**
+ * WorkspaceState persists small JSON documents (bookmarks, settings, ...)
+ * per workspace under <workspaceRoot>/.oneline-editor/
+ */
+export class WorkspaceState {
+  private writeLocks: Map<string, Promise<void>> = new Map();
+
+  constructor(private workspaceRoot: string) {}
+
+  /**
+   * Get the absolute path of the state directory
+   */
+  getStateDir(): string {
+    return path.join(this.workspaceRoot, STATE_DIR_NAME);
+  }
+
+  /**
+   * Get the absolute path of a state file
+   */
+  getStatePath(name: string): string {
+    return path.join(this.getStateDir(), name);
+  }
+
+  /**
+   * Read a JSON state file, returning the fallback if it doesn't exist or is invalid
+   */
+  async readJson<T>(name: string, fallback: T): Promise<T> {
+    try {
+      const raw = await fs.readFile(this.getStatePath(name), 'utf-8');
+      return JSON.parse(raw) as T;
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') {
+        console.error(`[WorkspaceState] Failed to read ${name}:`, error);
+      }
+      return fallback;
+    }
+  }
+
+  /**
+   * Write a JSON state file atomically (write to a temp file, then rename).
+   * Writes to the same file are serialized.
+   */
+  async writeJson(name: string, data: unknown): Promise<void> {
+    const prev = this.writeLocks.get(name) || Promise.resolve();
+    const current = prev.catch(() => undefined).then(async () => {
+      const target = this.getStatePath(name);
+      await fs.mkdir(path.dirname(target), { recursive: true });
+      const tmp = `${target}.${process.pid}.tmp`;
+      await fs.writeFile(tmp, JSON.stringify(data, null, 2), 'utf-8');
+      await fs.rename(tmp, target);
+    });
+    this.writeLocks.set(name, current);
+    try {
+      await current;
+    } finally {
+      if (this.writeLocks.get(name) === current) {
+        this.writeLocks.delete(name);
+      }
+    }
+  }
+}
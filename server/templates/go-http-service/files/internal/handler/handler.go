@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Health reports that the service is up.
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// Hello greets the caller.
+func Hello(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("Hello from {{projectName}}\n"))
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"{{modulePath}}/internal/handler"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "{{port}}"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handler.Health)
+	mux.HandleFunc("/", handler.Hello)
+
+	log.Printf("{{projectName}} listening on :%s", port)
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,6 @@
+/**
+ * Greet someone by name
+ */
+export function greet(name: string): string {
+  return `Hello, ${name}! This is {{packageName}}.`;
+}
@@ -0,0 +1,86 @@
+import { describe, it, expect } from 'vitest';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import { fileURLToPath } from 'url';
+import { generateClient } from '../../src/api/client-generator.js';
+import { buildOpenApiDocument } from '../../src/api/openapi.js';
+import { API_V1_SPEC } from '../../src/api/v1/spec.js';
+import { ApiClient, ApiRequestError } from '../../../web/lib/api/generated.js';
+
+const __dirname = path.dirname(fileURLToPath(import.meta.url));
+
+describe('API v1 spec', () => {
+  it('should have unique operation IDs and method/path pairs', () => {
+    const ids = API_V1_SPEC.routes.map(route => route.operationId);
+    const endpoints = API_V1_SPEC.routes.map(route => `${route.method} ${route.path}`);
+    expect(new Set(ids).size).toBe(ids.length);
+    expect(new Set(endpoints).size).toBe(endpoints.length);
+  });
+
+  it('should only reference defined components', () => {
+    const refs = JSON.stringify(API_V1_SPEC).match(/#\/components\/schemas\/\w+/g) || [];
+    for (const ref of refs) {
+      expect(API_V1_SPEC.components).toHaveProperty(ref.split('/').pop()!);
+    }
+  });
+
+  it('should build an OpenAPI document with every operation', () => {
+    const document = buildOpenApiDocument(API_V1_SPEC) as any;
+    expect(document.openapi).toBe('3.0.3');
+    expect(document.paths['/bookmarks/{id}'].patch.operationId).toBe('updateBookmark');
+    expect(document.paths['/files/content'].get.parameters).toEqual([
+      expect.objectContaining({ name: 'path', in: 'query', required: true })
+    ]);
+    const operations = Object.values(document.paths).flatMap((methods: any) => Object.values(methods));
+    expect(operations).toHaveLength(API_V1_SPEC.routes.length);
+  });
+
+  it('should match the checked-in web client (run npm run generate:client)', async () => {
+    const checkedIn = await fs.readFile(path.resolve(__dirname, '../../../web/lib/api/generated.ts'), 'utf-8');
+    expect(checkedIn).toBe(generateClient(API_V1_SPEC));
+  });
+});
+
+describe('Generated API client', () => {
+  function fakeFetch(status: number, body: unknown, calls: Array<{ url: string; init: any }>) {
+    return (async (url: string, init: any) => {
+      calls.push({ url, init });
+      return new Response(JSON.stringify(body), { status });
+    }) as unknown as typeof fetch;
+  }
+
+  it('should build URLs, headers and bodies', async () => {
+    const calls: Array<{ url: string; init: any }> = [];
+    const client = new ApiClient({
+      baseUrl: 'http://localhost:3001/api/v1',
+      getToken: () => 'secret-token',
+      fetch: fakeFetch(200, { path: '/a b.go', content: 'x', size: 1 }, calls)
+    });
+
+    const file = await client.readFile({ path: '/a b.go' });
+    expect(file.content).toBe('x');
+    expect(calls[0].url).toBe('http://localhost:3001/api/v1/files/content?path=%2Fa+b.go');
+    expect(calls[0].init.headers.Authorization).toBe('Bearer secret-token');
+
+    await client.updateBookmark('bm/1', { label: 'x' });
+    expect(calls[1].url).toBe('http://localhost:3001/api/v1/bookmarks/bm%2F1');
+    expect(calls[1].init.method).toBe('PATCH');
+    expect(JSON.parse(calls[1].init.body)).toEqual({ label: 'x' });
+  });
+
+  it('should raise ApiRequestError with the server error code', async () => {
+    const client = new ApiClient({
+      baseUrl: '/api/v1',
+      fetch: fakeFetch(400, { error: { code: 'validation_failed', message: 'bad', details: ['body.path: is required'] } }, [])
+    });
+
+    try {
+      await client.createFolder({ path: '' });
+      expect.fail('expected an error');
+    } catch (error) {
+      expect(error).toBeInstanceOf(ApiRequestError);
+      expect((error as ApiRequestError).code).toBe('validation_failed');
+      expect((error as ApiRequestError).details).toEqual(['body.path: is required']);
+    }
+  });
+});
@@ -0,0 +1,101 @@
+import { describe, it, expect } from 'vitest';
+import { coerceQuery, validateSchema, JsonSchema } from '../../src/api/schema.js';
+import { ApiError, toApiError } from '../../src/api/errors.js';
+
+describe('API schema validation', () => {
+  const components: Record<string, JsonSchema> = {
+    Node: {
+      type: 'object',
+      properties: {
+        name: { type: 'string', minLength: 1 },
+        children: { type: 'array', items: { $ref: '#/components/schemas/Node' } }
+      },
+      required: ['name']
+    }
+  };
+
+  it('should accept valid values', () => {
+    const schema: JsonSchema = {
+      type: 'object',
+      properties: {
+        path: { type: 'string', pattern: '^/' },
+        line: { type: 'integer', minimum: 1 },
+        kind: { type: 'string', enum: ['file', 'directory'] },
+        exitCode: { type: 'integer', nullable: true }
+      },
+      required: ['path'],
+      additionalProperties: false
+    };
+    expect(validateSchema(schema, { path: '/a.go', line: 3, kind: 'file', exitCode: null }, 'body')).toEqual([]);
+  });
+
+  it('should report each problem with its location', () => {
+    const schema: JsonSchema = {
+      type: 'object',
+      properties: {
+        path: { type: 'string', pattern: '^/' },
+        line: { type: 'integer', minimum: 1 }
+      },
+      required: ['path', 'line'],
+      additionalProperties: false
+    };
+    expect(validateSchema(schema, { path: 'a.go', line: 0, extra: true }, 'body')).toEqual([
+      'body.path: must match ^/',
+      'body.line: must be >= 1',
+      'body.extra: unknown property'
+    ]);
+    expect(validateSchema(schema, {}, 'body')).toEqual([
+      'body.path: is required',
+      'body.line: is required'
+    ]);
+    expect(validateSchema(schema, [], 'body')).toEqual(['body: must be an object']);
+  });
+
+  it('should follow $ref into components, including recursive schemas', () => {
+    const schema: JsonSchema = { $ref: '#/components/schemas/Node' };
+    expect(validateSchema(schema, { name: 'root', children: [{ name: 'leaf' }] }, 'body', components)).toEqual([]);
+    expect(validateSchema(schema, { name: 'root', children: [{ name: '' }] }, 'body', components))
+      .toEqual(['body.children[0].name: must not be empty']);
+  });
+
+  it('should validate additionalProperties schemas', () => {
+    const schema: JsonSchema = { type: 'object', additionalProperties: { type: 'string' } };
+    expect(validateSchema(schema, { a: 'x', b: 2 }, 'body.variables')).toEqual(['body.variables.b: must be a string']);
+  });
+
+  it('should coerce query strings to their declared types', () => {
+    const schema: JsonSchema = {
+      type: 'object',
+      properties: {
+        line: { type: 'integer' },
+        recursive: { type: 'boolean' },
+        path: { type: 'string' }
+      }
+    };
+    expect(coerceQuery(schema, { line: '12', recursive: 'true', path: '/12' }))
+      .toEqual({ line: 12, recursive: true, path: '/12' });
+    const coerced = coerceQuery(schema, { line: 'abc' });
+    expect(validateSchema(schema, coerced, 'query')).toEqual(['query.line: must be a number']);
+  });
+});
+
+describe('API errors', () => {
+  it('should serialize to the standard error body', () => {
+    expect(ApiError.validation(['body.path: is required']).toBody()).toEqual({
+      error: {
+        code: 'validation_failed',
+        message: 'Request validation failed',
+        details: ['body.path: is required']
+      }
+    });
+    expect(ApiError.notFound('missing').toBody()).toEqual({ error: { code: 'not_found', message: 'missing' } });
+  });
+
+  it('should map file system errors to status codes', () => {
+    expect(toApiError(new Error('Access denied: path outside workspace')).status).toBe(403);
+    expect(toApiError(new Error('Failed to read file: /a')).status).toBe(404);
+    expect(toApiError(Object.assign(new Error('exists'), { code: 'EEXIST' })).code).toBe('conflict');
+    expect(toApiError(Object.assign(new Error('too big'), { type: 'entity.too.large' })).status).toBe(413);
+    expect(toApiError(new Error('boom')).code).toBe('internal_error');
+  });
+});
@@ -0,0 +1,75 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { AuditLog } from '../../src/audit/log.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('AuditLog', () => {
+  let dir: string;
+  let logPath: string;
+  let log: AuditLog;
+
+  beforeEach(async () => {
+    dir = path.join(os.tmpdir(), `test-audit-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    logPath = path.join(dir, '.oneline-editor', 'audit.jsonl');
+    log = new AuditLog(logPath);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(dir, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should append entries as JSON lines with increasing ids', async () => {
+    await Promise.all([
+      log.record({ user: 'alice', client: 'tab-1', action: 'file.create', path: '/a.go' }),
+      log.record({ user: 'bob', client: 'tab-2', action: 'path.delete', path: '/b.go' })
+    ]);
+
+    const lines = (await fs.readFile(logPath, 'utf-8')).trim().split('\n').map(line => JSON.parse(line));
+    expect(lines.map(entry => entry.id)).toEqual([1, 2]);
+    expect(lines[0]).toMatchObject({ user: 'alice', client: 'tab-1', action: 'file.create', path: '/a.go' });
+    expect(typeof lines[0].timestamp).toBe('string');
+  });
+
+  it('should continue numbering after a restart', async () => {
+    await log.record({ user: 'alice', client: 'c', action: 'file.save', path: '/a.go' });
+    const reopened = new AuditLog(logPath);
+    const entry = await reopened.record({ user: 'alice', client: 'c', action: 'file.save', path: '/a.go' });
+    expect(entry.id).toBe(2);
+  });
+
+  it('should filter by user, action and path, newest first', async () => {
+    await log.record({ user: 'alice', client: 'c', action: 'file.create', path: '/src/a.go' });
+    await log.record({ user: 'bob', client: 'c', action: 'path.delete', path: '/src/b.go' });
+    await log.record({ user: 'alice', client: 'c', action: 'path.rename', path: '/docs/x.md', details: { newPath: '/src/x.md' } });
+    await log.record({ user: 'alice', client: 'c', action: 'file.save', path: '/srcfoo.go' });
+
+    expect((await log.query({ user: 'alice' })).entries.map(e => e.id)).toEqual([4, 3, 1]);
+    expect((await log.query({ action: 'path.delete' })).entries.map(e => e.user)).toEqual(['bob']);
+    expect((await log.query({ path: '/src' })).entries.map(e => e.id)).toEqual([3, 2, 1]);
+  });
+
+  it('should page with limit and before', async () => {
+    for (let i = 0; i < 5; i++) {
+      await log.record({ user: 'alice', client: 'c', action: 'file.save', path: `/f${i}` });
+    }
+
+    const first = await log.query({ limit: 2 });
+    expect(first.entries.map(e => e.id)).toEqual([5, 4]);
+    expect(first.hasMore).toBe(true);
+
+    const last = await log.query({ limit: 2, before: 2 });
+    expect(last.entries.map(e => e.id)).toEqual([1]);
+    expect(last.hasMore).toBe(false);
+  });
+
+  it('should skip a torn last line', async () => {
+    await log.record({ user: 'alice', client: 'c', action: 'file.save', path: '/a' });
+    await fs.appendFile(logPath, '{"id":2,"user"');
+    expect((await new AuditLog(logPath).query()).entries).toHaveLength(1);
+  });
+});
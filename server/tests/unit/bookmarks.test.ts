@@ -0,0 +1,65 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { BookmarkStore } from '../../src/workspace/bookmarks.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('BookmarkStore', () => {
+  let workspaceRoot: string;
+  let state: WorkspaceState;
+  let store: BookmarkStore;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-bookmarks-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(workspaceRoot, { recursive: true });
+    state = new WorkspaceState(workspaceRoot);
+    store = new BookmarkStore(state);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should toggle bookmarks on and off', async () => {
+    const added = await store.toggle('/main.go', 3, 'entry point');
+    expect(added?.label).toBe('entry point');
+    expect(await store.list()).toHaveLength(1);
+
+    const removed = await store.toggle('/main.go', 3);
+    expect(removed).toBeUndefined();
+    expect(await store.list()).toHaveLength(0);
+  });
+
+  it('should persist bookmarks across instances', async () => {
+    await store.toggle('/main.go', 3);
+    await store.toggle('/a.go', 10);
+
+    const reloaded = new BookmarkStore(new WorkspaceState(workspaceRoot));
+    const bookmarks = await reloaded.list();
+    expect(bookmarks.map(b => `${b.path}:${b.line}`)).toEqual(['/a.go:10', '/main.go:3']);
+  });
+
+  it('should update labels', async () => {
+    const bookmark = await store.toggle('/main.go', 1);
+    const updated = await store.setLabel(bookmark!.id, 'start');
+    expect(updated?.label).toBe('start');
+    expect(await store.setLabel('missing', 'x')).toBeUndefined();
+  });
+
+  it('should follow renamed directories and drop deleted paths', async () => {
+    await store.toggle('/pkg/a.go', 1);
+    await store.toggle('/pkg/b.go', 2);
+    await store.toggle('/other.go', 3);
+
+    await store.renamePath('/pkg', '/lib');
+    expect((await store.list()).map(b => b.path)).toEqual(['/lib/a.go', '/lib/b.go', '/other.go']);
+
+    await store.removePath('/lib');
+    expect((await store.list()).map(b => b.path)).toEqual(['/other.go']);
+  });
+});
@@ -0,0 +1,130 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { ConfigError, loadConfig, validateConfigFile } from '../../src/config/config.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('Server configuration', () => {
+  let configDir: string;
+
+  beforeEach(async () => {
+    configDir = path.join(os.tmpdir(), `test-config-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(configDir, { recursive: true });
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(configDir, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  async function writeConfig(config: unknown): Promise<string> {
+    const configPath = path.join(configDir, 'oneline-editor.config.json');
+    await fs.writeFile(configPath, typeof config === 'string' ? config : JSON.stringify(config));
+    return configPath;
+  }
+
+  it('should accept a complete config file', () => {
+    const issues = validateConfigFile({
+      server: { host: '127.0.0.1', port: 4000 },
+      workspace: { root: './workspace', todoTags: ['TODO'] },
+      cors: { origins: ['http://localhost:3000'], credentials: true },
+      languageServers: [
+        { languageId: 'go', command: 'gopls', args: [], fileExtensions: ['.go'] },
+        { languageId: 'python', command: 'pylsp', fileExtensions: ['.py'], settings: { pylsp: { plugins: {} } } }
+      ],
+      auth: { mode: 'token', tokens: [{ user: 'alice', token: 'a-long-enough-secret' }] },
+      npm: { registry: 'http://localhost:4873' },
+      limits: { maxClients: 5 },
+      logLevel: 'debug'
+    });
+    expect(issues).toEqual([]);
+  });
+
+  it('should report every problem with its location', () => {
+    const issues = validateConfigFile({
+      server: { port: 70000 },
+      languageServers: [{ languageId: 'go', fileExtensions: ['go'] }],
+      auth: { mode: 'token', tokens: [{ user: 'bob', token: 'short' }] },
+      npm: { registry: 'localhost:4873' },
+      limit: {}
+    });
+    expect(issues).toContain('server.port: must be an integer between 1 and 65535');
+    expect(issues).toContain('languageServers[0].command: is required');
+    expect(issues).toContain('languageServers[0].fileExtensions: extensions must start with "."');
+    expect(issues).toContain('auth.tokens[0].token: must be a string of at least 16 characters');
+    expect(issues).toContain('npm.registry: must be an http:// or https:// URL');
+    expect(issues).toContain('limit: unknown key');
+  });
+
+  it('should require tokens when token auth is enabled', () => {
+    expect(validateConfigFile({ auth: { mode: 'token' } }))
+      .toContain('auth.tokens: at least one token is required when auth.mode is "token"');
+  });
+
+  it('should validate webhook definitions', () => {
+    expect(validateConfigFile({
+      webhooks: [{ url: 'https://hooks.example.com/editor', secret: 'abc', events: ['file.saved'], maxAttempts: 3 }]
+    })).toEqual([]);
+
+    const issues = validateConfigFile({
+      webhooks: [{ url: 'ftp://example.com', events: ['file.exploded'], maxAttempts: 0 }]
+    });
+    expect(issues).toContain('webhooks[0].url: must be an http:// or https:// URL');
+    expect(issues).toContain('webhooks[0].events: unknown event type "file.exploded"');
+    expect(issues).toContain('webhooks[0].maxAttempts: must be an integer between 1 and 20');
+  });
+
+  it('should validate linter definitions', () => {
+    expect(validateConfigFile({
+      linters: [{ name: 'golangci-lint', format: 'golangci-lint' }, { name: 'eslint', format: 'eslint', runOnSave: false }]
+    })).toEqual([]);
+
+    const issues = validateConfigFile({
+      linters: [{ name: 'lint', format: 'pylint' }, { name: 'lint', format: 'eslint', fileExtensions: ['ts'] }]
+    });
+    expect(issues).toContain('linters[0].format: must be "golangci-lint" or "eslint"');
+    expect(issues).toContain('linters[1].fileExtensions: extensions must start with "."');
+    expect(issues).toContain('linters[1].name: duplicate linter "lint"');
+  });
+
+  it('should merge the config file over defaults and resolve relative paths', async () => {
+    const configPath = await writeConfig({
+      server: { port: 4000 },
+      workspace: { root: 'projects' },
+      limits: { maxClients: 3 }
+    });
+
+    const config = await loadConfig(configPath);
+    expect(config.server.port).toBe(4000);
+    expect(config.server.host).toBe('0.0.0.0');
+    expect(config.workspace.root).toBe(path.join(configDir, 'projects'));
+    expect(config.limits.maxClients).toBe(3);
+    expect(config.limits.maxFileSizeBytes).toBe(5 * 1024 * 1024);
+    expect(config.languageServers).toBeUndefined();
+  });
+
+  it('should let command-line overrides win over the config file', async () => {
+    const configPath = await writeConfig({ server: { port: 4000 } });
+    const config = await loadConfig(configPath, { port: 5000, workspace: configDir });
+    expect(config.server.port).toBe(5000);
+    expect(config.workspace.root).toBe(configDir);
+  });
+
+  it('should throw a ConfigError for invalid files', async () => {
+    const configPath = await writeConfig({ server: { port: 'abc' } });
+    await expect(loadConfig(configPath)).rejects.toThrow(ConfigError);
+    await expect(loadConfig(configPath)).rejects.toThrow('server.port');
+
+    const brokenPath = await writeConfig('{ not json');
+    await expect(loadConfig(brokenPath)).rejects.toThrow('invalid JSON');
+
+    await expect(loadConfig(path.join(configDir, 'missing.json'))).rejects.toThrow('cannot read config file');
+  });
+
+  it('should reject an invalid port override', async () => {
+    await expect(loadConfig(undefined, { port: NaN })).rejects.toThrow('--port');
+  });
+});
@@ -0,0 +1,114 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { detectCoverageFormat, parseGoCoverProfile, parseIstanbulJson, parseLcov } from '../../src/coverage/parsers.js';
+import { CoverageStore } from '../../src/coverage/store.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const GO_PROFILE = `mode: set
+example.com/app/main.go:5.13,7.2 1 1
+example.com/app/main.go:9.20,11.3 2 0
+example.com/app/main.go:11.3,12.2 1 1
+example.com/tools/gen.go:3.14,4.2 1 0
+github.com/other/lib/x.go:1.1,2.2 1 1
+`;
+
+const LCOV = `TN:
+SF:src/index.ts
+FN:1,main
+DA:1,1
+DA:2,1
+DA:4,0
+end_of_record
+SF:/elsewhere/outside.ts
+DA:1,1
+end_of_record
+`;
+
+describe('Coverage', () => {
+  let workspaceRoot: string;
+  let store: CoverageStore;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-coverage-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(path.join(workspaceRoot, 'tools'), { recursive: true });
+    await fs.mkdir(path.join(workspaceRoot, 'web', 'src'), { recursive: true });
+    await fs.mkdir(path.join(workspaceRoot, 'web', 'coverage'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'go.mod'), 'module example.com/app\n\ngo 1.22\n');
+    await fs.writeFile(path.join(workspaceRoot, 'tools', 'go.mod'), 'module example.com/tools\n\ngo 1.22\n');
+    await fs.writeFile(path.join(workspaceRoot, 'coverage.out'), GO_PROFILE);
+    await fs.writeFile(path.join(workspaceRoot, 'web', 'src', 'index.ts'), 'export {};\n');
+    await fs.writeFile(path.join(workspaceRoot, 'web', 'coverage', 'lcov.info'), LCOV);
+    store = new CoverageStore(workspaceRoot, new WorkspaceState(workspaceRoot));
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should parse Go profiles, lcov and istanbul JSON', () => {
+    expect(detectCoverageFormat(GO_PROFILE)).toBe('go');
+    expect(detectCoverageFormat(LCOV)).toBe('lcov');
+    expect(detectCoverageFormat('{"a.js":{}}')).toBe('istanbul');
+    expect(detectCoverageFormat('hello')).toBeUndefined();
+
+    const go = parseGoCoverProfile(GO_PROFILE);
+    // Line 11 ends one block that never ran and starts one that did
+    expect(Object.fromEntries(go.get('example.com/app/main.go')!)).toEqual({ 5: 1, 6: 1, 7: 1, 9: 0, 10: 0, 11: 1, 12: 1 });
+
+    const lcov = parseLcov(LCOV);
+    expect(Object.fromEntries(lcov.get('src/index.ts')!)).toEqual({ 1: 1, 2: 1, 4: 0 });
+
+    const istanbul = parseIstanbulJson(JSON.stringify({
+      '/repo/a.js': {
+        path: '/repo/a.js',
+        statementMap: { 0: { start: { line: 1 }, end: { line: 3 } }, 1: { start: { line: 5 }, end: { line: 5 } } },
+        s: { 0: 2, 1: 0 }
+      }
+    }));
+    expect(Object.fromEntries(istanbul.get('/repo/a.js')!)).toEqual({ 1: 2, 5: 0 });
+  });
+
+  it('should map Go import paths through the workspace go.mod files', async () => {
+    const summary = await store.import();
+    expect(summary).toMatchObject({ source: '/coverage.out', format: 'go', covered: 5, total: 9 });
+    expect(summary.files).toEqual([
+      { path: '/main.go', covered: 5, total: 7, percent: 71.4 },
+      { path: '/tools/gen.go', covered: 0, total: 2, percent: 0 }
+    ]);
+    expect(store.getFile('/main.go')).toEqual({ path: '/main.go', covered: [5, 6, 7, 11, 12], uncovered: [9, 10] });
+    expect(store.getFile('/other.go')).toBeUndefined();
+  });
+
+  it('should resolve lcov paths relative to where the tool ran and persist coverage', async () => {
+    const changes: string[] = [];
+    store.onChange(summary => changes.push(summary.source ?? 'cleared'));
+
+    await store.import('/web/coverage/lcov.info');
+    expect(store.getFile('/web/src/index.ts')).toEqual({ path: '/web/src/index.ts', covered: [1, 2], uncovered: [4] });
+    expect(store.getSummary().files.map(f => f.path)).toEqual(['/web/src/index.ts']);
+
+    const reloaded = new CoverageStore(workspaceRoot, new WorkspaceState(workspaceRoot));
+    await reloaded.load();
+    expect(reloaded.getSummary()).toEqual(store.getSummary());
+
+    await store.clear();
+    expect(store.getSummary()).toEqual({ covered: 0, total: 0, percent: 0, files: [] });
+    expect(changes).toEqual(['/web/coverage/lcov.info', 'cleared']);
+  });
+
+  it('should reject missing, unknown and out-of-workspace reports', async () => {
+    await expect(store.import('/missing.out')).rejects.toThrow('Coverage report not found');
+    await fs.writeFile(path.join(workspaceRoot, 'notes.txt'), 'hello');
+    await expect(store.import('/notes.txt')).rejects.toThrow('Unrecognised coverage report format');
+    await expect(store.import('/../outside.out')).rejects.toThrow('Access denied');
+
+    await fs.rm(path.join(workspaceRoot, 'coverage.out'));
+    await expect(store.import()).rejects.toThrow('No coverage report found');
+  });
+});
@@ -0,0 +1,155 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import {
+  applyTextEdits,
+  FileOperations,
+  FileOperationServer,
+  globToRegExp,
+  matchesFileOperationFilters
+} from '../../src/lsp/fileOperations.js';
+import { UriMapper } from '../../src/fs/uris.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const TS_FILTERS = [{ scheme: 'file', pattern: { glob: '**/*.{ts,tsx}', matches: 'file' as const } }];
+
+describe('File operations', () => {
+  let workspaceRoot: string;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-file-ops-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(path.join(workspaceRoot, 'src'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'src', 'util.ts'), 'export const a = 1;\n');
+    await fs.writeFile(path.join(workspaceRoot, 'src', 'index.ts'), "const { a } = require('./util');\nconsole.log(a);\n");
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should match LSP glob patterns', () => {
+    expect(globToRegExp('**/*.ts').test('/w/src/a.ts')).toBe(true);
+    expect(globToRegExp('**/*.ts').test('a.ts')).toBe(true);
+    expect(globToRegExp('**/*.{ts,js}').test('/w/a.js')).toBe(true);
+    expect(globToRegExp('*.go').test('/w/main.go')).toBe(false);
+    expect(globToRegExp('/w/?.[a-c]').test('/w/x.b')).toBe(true);
+    expect(globToRegExp('/w/[!a]*').test('/w/abc')).toBe(false);
+    expect(globToRegExp('**/*.TS', true).test('/w/a.ts')).toBe(true);
+
+    expect(matchesFileOperationFilters(TS_FILTERS, 'file:///w/src/a.tsx', false)).toBe(true);
+    expect(matchesFileOperationFilters(TS_FILTERS, 'file:///w/src', true)).toBe(false);
+    expect(matchesFileOperationFilters([{ pattern: { glob: '/w/my dir/*.ts' } }], 'file:///w/my%20dir/a.ts', false)).toBe(true);
+    expect(matchesFileOperationFilters(TS_FILTERS, 'untitled:///w/a.ts', false)).toBe(false);
+  });
+
+  it('should apply text edits from the end of the document', () => {
+    const content = 'line one\nline two\n';
+    expect(applyTextEdits(content, [
+      { range: { start: { line: 0, character: 5 }, end: { line: 0, character: 8 } }, newText: '1' },
+      { range: { start: { line: 1, character: 0 }, end: { line: 1, character: 4 } }, newText: 'row' },
+      { range: { start: { line: 2, character: 0 }, end: { line: 2, character: 0 } }, newText: 'end\n' }
+    ])).toBe('line 1\nrow two\nend\n');
+  });
+
+  it('should apply edits returned by willRenameFiles and notify didRenameFiles', async () => {
+    const calls: [string, any][] = [];
+    const server: FileOperationServer = {
+      languageId: 'typescript',
+      capabilities: {
+        workspace: { fileOperations: { willRename: { filters: TS_FILTERS }, didRename: { filters: TS_FILTERS } } }
+      },
+      request: async (method, params: any) => {
+        calls.push([method, params]);
+        return {
+          documentChanges: [{
+            textDocument: { uri: `file://${workspaceRoot}/src/index.ts`, version: 1 },
+            edits: [{ range: { start: { line: 0, character: 22 }, end: { line: 0, character: 30 } }, newText: "'./helpers'" }]
+          }]
+        };
+      },
+      notify: async (method, params) => { calls.push([method, params]); }
+    };
+    const operations = new FileOperations(new UriMapper(workspaceRoot), { getFileOperationServers: () => [server] });
+    const notified: string[][] = [];
+    operations.onDidEditFiles(paths => notified.push(paths));
+
+    const edited = await operations.willRename([{ oldPath: '/src/util.ts', newPath: '/src/helpers.ts' }]);
+    expect(edited).toEqual([{ path: '/src/index.ts', written: true }]);
+    expect(notified).toEqual([['/src/index.ts']]);
+    expect(await fs.readFile(path.join(workspaceRoot, 'src', 'index.ts'), 'utf-8'))
+      .toBe("const { a } = require('./helpers');\nconsole.log(a);\n");
+
+    await fs.rename(path.join(workspaceRoot, 'src', 'util.ts'), path.join(workspaceRoot, 'src', 'helpers.ts'));
+    await operations.didRename([{ oldPath: '/src/util.ts', newPath: '/src/helpers.ts' }]);
+
+    const files = [{ oldUri: `file://${workspaceRoot}/src/util.ts`, newUri: `file://${workspaceRoot}/src/helpers.ts` }];
+    expect(calls).toEqual([
+      ['workspace/willRenameFiles', { files }],
+      ['workspace/didRenameFiles', { files }]
+    ]);
+  });
+
+  it('should send edits to files open in an editor to the clients instead of the disk', async () => {
+    const edits = [{ range: { start: { line: 0, character: 22 }, end: { line: 0, character: 30 } }, newText: "'./helpers'" }];
+    const server: FileOperationServer = {
+      languageId: 'typescript',
+      capabilities: { workspace: { fileOperations: { willRename: { filters: TS_FILTERS } } } },
+      request: async () => ({ changes: { [`file://${workspaceRoot}/src/index.ts`]: edits } }),
+      notify: async () => {}
+    };
+    const renamed: string[][] = [];
+    const operations = new FileOperations(
+      new UriMapper(workspaceRoot),
+      { getFileOperationServers: () => [server] },
+      {
+        getClientsWithOpenDocument: (workspacePath) => workspacePath === '/src/index.ts' ? ['client-1'] : [],
+        renameOpenDocuments: (oldPath, newPath) => renamed.push([oldPath, newPath])
+      }
+    );
+    const notified: string[][] = [];
+    const sent: [string, any][] = [];
+    operations.onDidEditFiles(paths => notified.push(paths));
+    operations.onDidEditOpenDocument((clientId, edit) => sent.push([clientId, edit]));
+
+    expect(await operations.willRename([{ oldPath: '/src/util.ts', newPath: '/src/helpers.ts' }]))
+      .toEqual([{ path: '/src/index.ts', written: false }]);
+    expect(sent).toEqual([['client-1', { path: '/src/index.ts', edits }]]);
+    expect(notified).toEqual([]);
+    expect(await fs.readFile(path.join(workspaceRoot, 'src', 'index.ts'), 'utf-8'))
+      .toBe("const { a } = require('./util');\nconsole.log(a);\n");
+
+    // Open documents follow the rename, so late edits for the old path can't recreate it
+    expect(renamed).toEqual([]);
+    await operations.didRename([{ oldPath: '/src/util.ts', newPath: '/src/helpers.ts' }]);
+    expect(renamed).toEqual([['/src/util.ts', '/src/helpers.ts']]);
+  });
+
+  it('should skip servers without matching filters and ignore edits outside the workspace', async () => {
+    const calls: string[] = [];
+    const goServer: FileOperationServer = {
+      languageId: 'go',
+      capabilities: { workspace: { fileOperations: { willDelete: { filters: [{ pattern: { glob: '**/*.go' } }] } } } },
+      request: async (method) => { calls.push(method); return null; },
+      notify: async (method) => { calls.push(method); }
+    };
+    const tsServer: FileOperationServer = {
+      languageId: 'typescript',
+      capabilities: { workspace: { fileOperations: { willDelete: { filters: TS_FILTERS }, didDelete: { filters: TS_FILTERS } } } },
+      request: async () => ({
+        changes: { 'file:///etc/hosts': [{ range: { start: { line: 0, character: 0 }, end: { line: 0, character: 0 } }, newText: 'x' }] }
+      }),
+      notify: async (method) => { calls.push(method); }
+    };
+    const operations = new FileOperations(new UriMapper(workspaceRoot), { getFileOperationServers: () => [goServer, tsServer] });
+
+    expect(await operations.willDelete(['/src/util.ts'])).toEqual([]);
+    // Folders don't pass filters that only match files
+    await operations.didDelete(['/src'], ['/src']);
+    await operations.didDelete(['/src/util.ts']);
+    expect(calls).toEqual(['workspace/didDeleteFiles']);
+  });
+});
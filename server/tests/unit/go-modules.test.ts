@@ -0,0 +1,152 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { compareGoVersions, escapeModulePath, parseGoMod, parseGoSum } from '../../src/gomod/parser.js';
+import { GoVulnDatabase } from '../../src/gomod/vulndb.js';
+import { GoModuleService } from '../../src/gomod/service.js';
+import { TaskManager } from '../../src/tasks/manager.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+import { pathToFileURL } from 'url';
+
+const GO_MOD = `module example.com/app
+
+go 1.22
+
+require github.com/google/uuid v1.3.0
+
+require (
+\tgolang.org/x/net v0.17.0 // indirect
+\t"github.com/BurntSushi/toml" v1.2.0
+)
+
+replace golang.org/x/net => ../net
+`;
+
+const VULNS = [
+  {
+    id: 'GO-2023-2102',
+    summary: 'HTTP/2 rapid reset can cause excessive work in net/http',
+    aliases: ['CVE-2023-39325'],
+    affected: [{
+      package: { name: 'golang.org/x/net', ecosystem: 'Go' },
+      ranges: [{ type: 'SEMVER', events: [{ introduced: '0' }, { fixed: '0.17.0' }] }]
+    }]
+  },
+  {
+    id: 'GO-2024-0001',
+    summary: 'Made-up issue',
+    affected: [{
+      package: { name: 'github.com/google/uuid', ecosystem: 'Go' },
+      ranges: [{ type: 'SEMVER', events: [{ introduced: '1.2.0' }, { fixed: '1.4.0' }, { introduced: '1.5.0' }] }]
+    }]
+  }
+];
+
+describe('Go modules', () => {
+  let workspaceRoot: string;
+  let proxyDir: string;
+
+  beforeEach(async () => {
+    const base = path.join(os.tmpdir(), `test-gomod-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    workspaceRoot = path.join(base, 'workspace');
+    proxyDir = path.join(base, 'proxy');
+    await fs.mkdir(path.join(workspaceRoot, 'node_modules', 'x'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'go.mod'), GO_MOD);
+    await fs.writeFile(
+      path.join(workspaceRoot, 'go.sum'),
+      'github.com/google/uuid v1.3.0 h1:abc=\ngithub.com/google/uuid v1.3.0/go.mod h1:def=\n'
+    );
+    await fs.writeFile(path.join(workspaceRoot, 'node_modules', 'x', 'go.mod'), 'module ignored\n');
+    await fs.mkdir(path.join(workspaceRoot, 'tools'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'tools', 'go.mod'), 'module example.com/tools\n\ngo 1.21\n');
+
+    const uuidVersions = path.join(proxyDir, 'github.com', 'google', 'uuid', '@v');
+    await fs.mkdir(uuidVersions, { recursive: true });
+    await fs.writeFile(path.join(uuidVersions, 'list'), 'v1.3.0\nv1.6.0\nv1.7.0-rc.1\n');
+    const tomlVersions = path.join(proxyDir, 'github.com', '!burnt!sushi', 'toml', '@v');
+    await fs.mkdir(tomlVersions, { recursive: true });
+    await fs.writeFile(path.join(tomlVersions, 'v1.2.0.info'), '{}');
+    await fs.writeFile(path.join(base, 'vulns.json'), JSON.stringify(VULNS));
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(path.dirname(workspaceRoot), { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should parse go.mod and go.sum', () => {
+    const goMod = parseGoMod(GO_MOD);
+    expect(goMod.module).toBe('example.com/app');
+    expect(goMod.goVersion).toBe('1.22');
+    expect(goMod.requires).toEqual([
+      { path: 'github.com/google/uuid', version: 'v1.3.0', indirect: false, line: 5, column: 9 },
+      { path: 'golang.org/x/net', version: 'v0.17.0', indirect: true, line: 8, column: 2 },
+      { path: 'github.com/BurntSushi/toml', version: 'v1.2.0', indirect: false, line: 9, column: 2 }
+    ]);
+    expect(goMod.replaces).toEqual([{ path: 'golang.org/x/net', version: undefined, target: '../net', line: 12 }]);
+
+    const sums = parseGoSum('a.com/b v1.0.0 h1:x=\na.com/b v1.0.0/go.mod h1:y=\nc.com/d v2.0.0+incompatible/go.mod h1:z=\n');
+    expect(Array.from(sums)).toEqual(['a.com/b@v1.0.0']);
+  });
+
+  it('should compare versions by semver precedence', () => {
+    const sorted = ['v1.10.0', 'v1.2.0', 'v1.2.0-rc.1', 'v0.0.0-20240101120000-abcdef123456', 'v2.0.0+incompatible', 'v1.2.0-beta']
+      .sort(compareGoVersions);
+    expect(sorted).toEqual([
+      'v0.0.0-20240101120000-abcdef123456', 'v1.2.0-beta', 'v1.2.0-rc.1', 'v1.2.0', 'v1.10.0', 'v2.0.0+incompatible'
+    ]);
+    expect(compareGoVersions('1.4.0', 'v1.4.0')).toBe(0);
+    expect(escapeModulePath('github.com/BurntSushi/toml')).toBe('github.com/!burnt!sushi/toml');
+  });
+
+  it('should match vulnerabilities against OSV ranges', () => {
+    const db = new GoVulnDatabase(VULNS);
+    expect(db.check('golang.org/x/net', 'v0.16.0').map(v => v.id)).toEqual(['GO-2023-2102']);
+    expect(db.check('golang.org/x/net', 'v0.16.0')[0]).toMatchObject({ fixed: '0.17.0', aliases: ['CVE-2023-39325'] });
+    expect(db.check('golang.org/x/net', 'v0.17.0')).toEqual([]);
+
+    expect(db.check('github.com/google/uuid', 'v1.1.0')).toEqual([]);
+    expect(db.check('github.com/google/uuid', 'v1.3.0')[0].fixed).toBe('1.4.0');
+    expect(db.check('github.com/google/uuid', 'v1.4.2')).toEqual([]);
+    expect(db.check('github.com/google/uuid', 'v1.6.0')[0].fixed).toBeUndefined();
+  });
+
+  it('should list workspace modules with outdated and vulnerable dependencies', async () => {
+    const service = new GoModuleService(workspaceRoot, new TaskManager(), {
+      proxy: pathToFileURL(proxyDir).toString(),
+      vulnDb: path.join(path.dirname(workspaceRoot), 'vulns.json')
+    });
+
+    const modules = await service.listModules();
+    expect(modules.map(m => [m.path, m.module])).toEqual([
+      ['/', 'example.com/app'],
+      ['/tools', 'example.com/tools']
+    ]);
+
+    const [app] = modules;
+    const uuid = app.dependencies.find(d => d.path === 'github.com/google/uuid')!;
+    expect(uuid).toMatchObject({ checksum: true, latest: 'v1.6.0' });
+    expect(uuid.vulnerabilities.map(v => v.id)).toEqual(['GO-2024-0001']);
+
+    const toml = app.dependencies.find(d => d.path === 'github.com/BurntSushi/toml')!;
+    expect(toml).toMatchObject({ checksum: false, latest: undefined, vulnerabilities: [] });
+
+    const net = app.dependencies.find(d => d.path === 'golang.org/x/net')!;
+    expect(net).toMatchObject({ indirect: true, replace: '../net', vulnerabilities: [] });
+
+    expect(app.diagnostics).toEqual([
+      expect.objectContaining({ line: 5, column: 9, endColumn: 31, severity: 'warning', code: 'GO-2024-0001' }),
+      expect.objectContaining({ line: 5, severity: 'info', code: 'outdated', message: 'github.com/google/uuid can be upgraded to v1.6.0' })
+    ]);
+    expect(app.diagnostics[0].message).toContain('fixed in v1.4.0');
+  });
+
+  it('should refuse module paths outside the workspace', async () => {
+    const service = new GoModuleService(workspaceRoot, new TaskManager(), { proxy: 'off' });
+    await expect(service.getModule('/../..')).rejects.toThrow('Access denied');
+    await expect(service.runCommand('/missing', 'tidy')).rejects.toThrow();
+  });
+});
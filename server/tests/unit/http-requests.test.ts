@@ -0,0 +1,150 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { findRequestAt, parseHttpFile, resolveVariables } from '../../src/restclient/parser.js';
+import { HttpClientService } from '../../src/restclient/service.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as http from 'http';
+import * as path from 'path';
+import * as os from 'os';
+import { AddressInfo } from 'net';
+
+const FILE = [
+  '@host = http://localhost:{{port}}',
+  '@user = gopher',
+  '',
+  '# @name createUser',
+  'POST {{host}}/users',
+  '    ?verbose=1',
+  '    &name={{user}}',
+  'Content-Type: application/json',
+  'Authorization: Bearer {{token}}',
+  '',
+  '{"name": "{{user}}"}',
+  '',
+  '###',
+  '',
+  '// Method defaults to GET',
+  '{{host}}/health HTTP/1.1',
+  '',
+  '### Upload',
+  'PUT {{host}}/upload',
+  '',
+  '< ./payload.json'
+].join('\n');
+
+describe('HTTP requests', () => {
+  let workspaceRoot: string;
+
+  beforeEach(async () => {
+    workspaceRoot = await fs.realpath(await fs.mkdtemp(path.join(os.tmpdir(), 'test-http-')));
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+      await fs.rm(`${workspaceRoot}-secrets`, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should parse requests, headers, bodies and file variables', () => {
+    const file = parseHttpFile(FILE);
+    expect(file.variables).toEqual({ host: 'http://localhost:{{port}}', user: 'gopher' });
+    expect(file.requests).toEqual([
+      {
+        name: 'createUser',
+        method: 'POST',
+        url: '{{host}}/users?verbose=1&name={{user}}',
+        headers: [['Content-Type', 'application/json'], ['Authorization', 'Bearer {{token}}']],
+        body: '{"name": "{{user}}"}',
+        line: 5,
+        startLine: 1,
+        endLine: 12
+      },
+      { name: undefined, method: 'GET', url: '{{host}}/health', headers: [], line: 16, startLine: 14, endLine: 17 },
+      { name: undefined, method: 'PUT', url: '{{host}}/upload', headers: [], bodyFile: './payload.json', line: 19, startLine: 19, endLine: 21 }
+    ]);
+    expect(findRequestAt(file, 9)?.name).toBe('createUser');
+    expect(findRequestAt(file, 13)).toBeUndefined();
+    expect(findRequestAt(file, 21)?.method).toBe('PUT');
+  });
+
+  it('should resolve nested, dynamic and missing variables', () => {
+    const missing = new Set<string>();
+    const url = resolveVariables('{{host}}/users/{{id}}?n={{$randomInt 5 6}}', { host: 'http://localhost:{{port}}', port: '8080' }, missing);
+    expect(url).toBe('http://localhost:8080/users/{{id}}?n=5');
+    expect([...missing]).toEqual(['id']);
+    expect(resolveVariables('{{$guid}}', {})).toMatch(/^[0-9a-f-]{36}$/);
+  });
+
+  it('should send requests with env file variables and keep a history', async () => {
+    const server = http.createServer((request, response) => {
+      let body = '';
+      request.on('data', chunk => (body += chunk));
+      request.on('end', () => {
+        response.setHeader('Content-Type', 'application/json');
+        response.end(JSON.stringify({ method: request.method, url: request.url, auth: request.headers.authorization, body }));
+      });
+    });
+    await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+    const port = (server.address() as AddressInfo).port;
+
+    try {
+      await fs.mkdir(path.join(workspaceRoot, 'api'));
+      await fs.writeFile(path.join(workspaceRoot, 'api', 'users.http'), FILE);
+      await fs.writeFile(path.join(workspaceRoot, 'http-client.env.json'), JSON.stringify({
+        $shared: { token: 'shared' },
+        dev: { port },
+        prod: { port: 1 }
+      }));
+      await fs.writeFile(path.join(workspaceRoot, 'http-client.private.env.json'), JSON.stringify({ dev: { token: 'secret' } }));
+
+      const service = new HttpClientService(workspaceRoot, new WorkspaceState(workspaceRoot));
+      expect(await service.listEnvironments('workspace:///api/users.http')).toEqual({
+        file: 'http-client.env.json',
+        environments: ['dev', 'prod']
+      });
+
+      const exchange = await service.send({ uri: 'workspace:///api/users.http', line: 8, env: 'dev' });
+      expect(exchange.status).toBe(200);
+      expect(exchange.url).toBe(`http://localhost:${port}/users?verbose=1&name=gopher`);
+      expect(exchange.headers['content-type']).toBe('application/json');
+      expect(JSON.parse(exchange.body)).toEqual({
+        method: 'POST',
+        url: '/users?verbose=1&name=gopher',
+        auth: 'Bearer secret',
+        body: '{"name": "gopher"}'
+      });
+
+      await expect(service.send({ uri: 'workspace:///api/users.http', line: 8 })).rejects.toThrow('Undefined variables: port');
+      await expect(service.send({ uri: 'workspace:///api/users.http', line: 8, env: 'staging' })).rejects.toThrow('Unknown environment');
+      await fs.writeFile(path.join(workspaceRoot, 'api', 'escape.http'), 'POST http://localhost/users\n\n< ../../outside.json');
+      await expect(service.send({ uri: 'workspace:///api/escape.http', line: 1 })).rejects.toThrow('Access denied');
+      // A sibling folder sharing the workspace's name as a prefix is still outside
+      await fs.mkdir(`${workspaceRoot}-secrets`);
+      await fs.writeFile(path.join(`${workspaceRoot}-secrets`, 'key'), 'secret');
+      await fs.writeFile(path.join(workspaceRoot, 'api', 'sibling.http'), `POST http://localhost/users\n\n< ../../${path.basename(workspaceRoot)}-secrets/key`);
+      await expect(service.send({ uri: 'workspace:///api/sibling.http', line: 1 })).rejects.toThrow('Access denied');
+
+      const failed = await service.send({ uri: 'workspace:///api/users.http', line: 16, env: 'prod' });
+      expect(failed.status).toBe(0);
+      expect(failed.error).toBeTruthy();
+
+      // History survives a restart, newest first, without bodies in the list
+      const reloaded = new HttpClientService(workspaceRoot, new WorkspaceState(workspaceRoot));
+      const history = await reloaded.listHistory();
+      expect(history.map(entry => entry.url)).toEqual(['http://localhost:1/health', exchange.url]);
+      expect(history[1]).not.toHaveProperty('body');
+      // Shared with every user, so without header values and private env values
+      const stored = await reloaded.getHistoryEntry(exchange.id);
+      expect(stored?.requestHeaders).toEqual({ 'Content-Type': 'application/json', Authorization: '[redacted]' });
+      expect(stored?.body).toBe(exchange.body.replace('Bearer secret', 'Bearer [redacted]'));
+
+      await reloaded.clearHistory();
+      expect(await reloaded.listHistory()).toEqual([]);
+    } finally {
+      server.close();
+    }
+  });
+});
@@ -0,0 +1,135 @@
+import { describe, it, expect } from 'vitest';
+import { getDefaultLanguageServers, getLanguageIdForPath } from '../../src/lsp/languages.js';
+import { ConfiguredTransport } from '../../src/lsp/transport.js';
+import { ServerConfiguration, ServerWindow } from '../../src/lsp/host.js';
+
+/**
+ * Transport whose writer records every message it is asked to send
+ */
+function createRecordingTransport() {
+  const written: any[] = [];
+  const writer = {
+    write: async (message: any) => { written.push(message); },
+    onError: () => ({ dispose: () => {} }),
+    onClose: () => ({ dispose: () => {} }),
+    end: () => {},
+    dispose: () => {}
+  };
+  const listeners: Array<(message: any) => void> = [];
+  const reader = {
+    listen: (callback: (message: any) => void) => {
+      listeners.push(callback);
+      return { dispose: () => {} };
+    }
+  };
+  const transport = { connect: async () => ({ reader: reader as any, writer: writer as any }) };
+  const receive = (message: any) => listeners.forEach(listener => listener(message));
+  return { transport, written, receive };
+}
+
+describe('Language servers', () => {
+  it('should infer languages from extensions and well-known file names', () => {
+    expect(getLanguageIdForPath('/src/main.py')).toBe('python');
+    expect(getLanguageIdForPath('file:///crates/lib.rs')).toBe('rust');
+    expect(getLanguageIdForPath('/deploy/values.YML')).toBe('yaml');
+    expect(getLanguageIdForPath('/web/styles.scss')).toBe('scss');
+    expect(getLanguageIdForPath('/index.htm')).toBe('html');
+    expect(getLanguageIdForPath('/.babelrc')).toBe('json');
+    expect(getLanguageIdForPath('/deploy/Dockerfile')).toBe('dockerfile');
+    // Same as the browser, which has no Go mode for go.mod
+    expect(getLanguageIdForPath('/go.mod')).toBe('plaintext');
+    expect(getLanguageIdForPath('/Makefile')).toBe('plaintext');
+  });
+
+  it('should ship a server for every new language with unique extensions', () => {
+    const servers = getDefaultLanguageServers({});
+    const byLanguage = Object.fromEntries(servers.map(s => [s.languageId, s]));
+
+    expect(Object.keys(byLanguage)).toEqual(
+      expect.arrayContaining(['go', 'typescript', 'javascript', 'python', 'rust', 'json', 'yaml', 'html', 'css', 'scss', 'less'])
+    );
+    expect(byLanguage.python.command).toBe('pyright-langserver');
+    expect(byLanguage.json.initializationOptions).toMatchObject({ provideFormatter: true });
+    expect(byLanguage.yaml.settings).toMatchObject({ yaml: { schemaStore: { enable: false } } });
+
+    const extensions = servers.flatMap(s => s.fileExtensions);
+    expect(new Set(extensions).size).toBe(extensions.length);
+    for (const server of servers) {
+      for (const extension of server.fileExtensions) {
+        expect(getLanguageIdForPath(`/file${extension}`)).toBe(server.languageId);
+      }
+    }
+  });
+
+  it('should let environment variables override commands', () => {
+    const servers = getDefaultLanguageServers({ RUST_ANALYZER_PATH: '/opt/rust-analyzer', CSS_SERVER_PATH: 'css-ls' });
+    expect(servers.find(s => s.languageId === 'rust')!.command).toBe('/opt/rust-analyzer');
+    expect(servers.filter(s => s.command === 'css-ls').map(s => s.languageId)).toEqual(['css', 'scss', 'less']);
+  });
+
+  it('should add initialization options and push settings after initialized', async () => {
+    const { transport, written } = createRecordingTransport();
+    const configured = new ConfiguredTransport(transport as any, {
+      initializationOptions: { provideFormatter: true },
+      settings: { json: { validate: { enable: true } } }
+    });
+    const { writer } = await configured.connect();
+
+    await writer.write({ jsonrpc: '2.0', id: 1, method: 'initialize', params: { rootUri: 'file:///w', initializationOptions: { a: 1 } } } as any);
+    await writer.write({ jsonrpc: '2.0', method: 'initialized', params: {} } as any);
+    await writer.write({ jsonrpc: '2.0', method: 'textDocument/didOpen', params: {} } as any);
+
+    expect(written.map(m => m.method)).toEqual(['initialize', 'initialized', 'workspace/didChangeConfiguration', 'textDocument/didOpen']);
+    expect(written[0].params).toEqual({ rootUri: 'file:///w', initializationOptions: { a: 1, provideFormatter: true } });
+    expect(written[2].params).toEqual({ settings: { json: { validate: { enable: true } } } });
+  });
+
+  it('should pass messages through unchanged without options', async () => {
+    const { transport, written } = createRecordingTransport();
+    const { writer } = await new ConfiguredTransport(transport as any, {}).connect();
+    const initialize = { jsonrpc: '2.0', id: 1, method: 'initialize', params: { rootUri: 'file:///w' } };
+
+    await writer.write(initialize as any);
+    await writer.write({ jsonrpc: '2.0', method: 'initialized', params: {} } as any);
+
+    expect(written).toEqual([initialize, { jsonrpc: '2.0', method: 'initialized', params: {} }]);
+  });
+
+  it('should forward window/showDocument from the server to the browser', async () => {
+    const { transport, written, receive } = createRecordingTransport();
+    const sent: any[] = [];
+    const ws = { readyState: 1, send: (data: string) => sent.push(JSON.parse(data)) };
+    const window = new ServerWindow(ws as any, uri => uri.replace('file:///w/', 'workspace:///'));
+    const configured = new ConfiguredTransport(transport as any, {});
+    configured.onRequest('window/showDocument', params => window.showDocument(params));
+    const received: any[] = [];
+    const { reader } = await configured.connect();
+    reader.listen(message => received.push(message));
+
+    const selection = { start: { line: 2, character: 0 }, end: { line: 2, character: 4 } };
+    receive({ jsonrpc: '2.0', id: 7, method: 'window/showDocument', params: { uri: 'file:///w/doc.md', selection, takeFocus: true } });
+    receive({ jsonrpc: '2.0', id: 8, method: 'window/showDocument', params: { uri: 'file:///etc/hosts' } });
+    receive({ jsonrpc: '2.0', id: 9, method: 'window/showDocument', params: { uri: 'https://pkg.go.dev/fmt', external: true } });
+    receive({ jsonrpc: '2.0', id: 10, method: 'workspace/configuration', params: { items: [] } });
+    await new Promise(resolve => setTimeout(resolve, 0));
+
+    expect(written).toEqual([
+      { jsonrpc: '2.0', id: 7, result: { success: true } },
+      { jsonrpc: '2.0', id: 8, result: { success: false } },
+      { jsonrpc: '2.0', id: 9, result: { success: true } }
+    ]);
+    expect(sent.map(message => message.params)).toEqual([
+      { uri: 'workspace:///doc.md', selection, takeFocus: true },
+      { uri: 'https://pkg.go.dev/fmt', external: true }
+    ]);
+    // Other requests still reach the LSP client
+    expect(received.map(message => message.id)).toEqual([10]);
+  });
+
+  it('should answer nested configuration sections', () => {
+    const configuration = new ServerConfiguration({ python: { analysis: { typeCheckingMode: 'strict' } } });
+    expect(configuration.get('python.analysis')).toEqual({ typeCheckingMode: 'strict' });
+    expect(configuration.get('python')).toEqual({ analysis: { typeCheckingMode: 'strict' } });
+    expect(configuration.get('rust')).toEqual({});
+  });
+});
@@ -0,0 +1,167 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { parseEslintOutput, parseGolangciLintOutput } from '../../src/linters/parsers.js';
+import { LinterService } from '../../src/linters/service.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const SOURCE = 'const a = 1;;\nvar b = 2;\n';
+
+const ESLINT_OUTPUT = JSON.stringify([
+  {
+    filePath: '/repo/src/index.js',
+    messages: [
+      {
+        ruleId: 'no-extra-semi',
+        severity: 2,
+        message: 'Unnecessary semicolon.',
+        line: 1,
+        column: 13,
+        endLine: 1,
+        endColumn: 14,
+        fix: { range: [12, 13], text: '' }
+      },
+      {
+        ruleId: 'no-var',
+        severity: 1,
+        message: 'Unexpected var, use let or const instead.',
+        line: 2,
+        column: 1,
+        suggestions: [{ desc: 'Use let.', fix: { range: [14, 17], text: 'let' } }]
+      }
+    ]
+  },
+  { filePath: '/repo/src/clean.js', messages: [] }
+]);
+
+const GOLANGCI_OUTPUT = JSON.stringify({
+  Issues: [
+    {
+      FromLinter: 'gofmt',
+      Text: 'File is not properly formatted',
+      Severity: '',
+      Pos: { Filename: 'main.go', Line: 3, Column: 1 },
+      LineRange: { From: 3, To: 4 },
+      Replacement: { NewLines: ['func main() {', '}'] }
+    },
+    {
+      FromLinter: 'misspell',
+      Text: '`recieve` is a misspelling of `receive`',
+      Severity: 'error',
+      Pos: { Filename: 'main.go', Line: 5, Column: 4 },
+      Replacement: { Inline: { StartCol: 3, Length: 7, NewString: 'receive' } }
+    },
+    {
+      FromLinter: 'unused',
+      Text: 'func `old` is unused',
+      Pos: { Filename: 'util/old.go', Line: 7, Column: 6 },
+      Replacement: { NeedOnlyDelete: true }
+    }
+  ]
+}) + '\n0 issues.\n';
+
+describe('Linters', () => {
+  let workspaceRoot: string;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-linters-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(path.join(workspaceRoot, 'src'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'package.json'), '{}');
+    await fs.writeFile(path.join(workspaceRoot, 'src', 'index.js'), SOURCE);
+    // Stands in for eslint: reports one problem in whichever file it's given
+    await fs.writeFile(path.join(workspaceRoot, 'fake-eslint.js'), `
+const file = require('path').resolve(process.argv[2]);
+console.log(JSON.stringify([{ filePath: file, messages: [{ ruleId: 'semi', severity: 2, message: 'Missing semicolon.', line: 2, column: 10 }] }]));
+`);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should parse eslint output with fixes and suggestions', async () => {
+    const problems = await parseEslintOutput(ESLINT_OUTPUT, async () => SOURCE);
+    expect(problems).toHaveLength(2);
+    expect(problems[0]).toMatchObject({
+      file: '/repo/src/index.js',
+      line: 1,
+      column: 13,
+      endLine: 1,
+      endColumn: 14,
+      severity: 'error',
+      code: 'no-extra-semi',
+      fixes: [{
+        title: 'Fix: Unnecessary semicolon. (no-extra-semi)',
+        edits: [{ startLine: 1, startColumn: 13, endLine: 1, endColumn: 14, text: '' }]
+      }]
+    });
+    expect(problems[1]).toMatchObject({
+      severity: 'warning',
+      endLine: undefined,
+      fixes: [{ title: 'Use let.', edits: [{ startLine: 2, startColumn: 1, endLine: 2, endColumn: 4, text: 'let' }] }]
+    });
+  });
+
+  it('should parse golangci-lint output with replacements', () => {
+    const problems = parseGolangciLintOutput(GOLANGCI_OUTPUT);
+    expect(problems.map(p => [p.file, p.line, p.severity, p.code])).toEqual([
+      ['main.go', 3, 'warning', 'gofmt'],
+      ['main.go', 5, 'error', 'misspell'],
+      ['util/old.go', 7, 'warning', 'unused']
+    ]);
+    expect(problems[0].fixes[0].edits).toEqual([{ startLine: 3, startColumn: 1, endLine: 5, endColumn: 1, text: 'func main() {\n}\n' }]);
+    expect(problems[1].fixes[0].edits).toEqual([{ startLine: 5, startColumn: 4, endLine: 5, endColumn: 11, text: 'receive' }]);
+    expect(problems[2].fixes[0].edits).toEqual([{ startLine: 7, startColumn: 1, endLine: 8, endColumn: 1, text: '' }]);
+  });
+
+  it('should run linters and report diagnostics per file', async () => {
+    const service = new LinterService(workspaceRoot, [
+      { name: 'fake-eslint', format: 'eslint', command: process.execPath, args: ['fake-eslint.js', '${file}'] }
+    ]);
+    const notified: [string, number][] = [];
+    service.onDiagnostics((filePath, diagnostics) => notified.push([filePath, diagnostics.length]));
+
+    const result = await service.lint('/src/index.js');
+    expect(result.errors).toEqual([]);
+    expect(result.linters).toEqual(['fake-eslint']);
+    // The end of a position-only problem is the end of its line
+    expect(result.diagnostics).toEqual([{
+      path: '/src/index.js',
+      line: 2,
+      column: 10,
+      endLine: 2,
+      endColumn: 11,
+      severity: 'error',
+      message: 'Missing semicolon.',
+      code: 'semi',
+      source: 'fake-eslint',
+      fixes: []
+    }]);
+    expect(service.listDiagnostics()).toEqual([{ path: '/src/index.js', diagnostics: result.diagnostics }]);
+    expect(notified).toEqual([['/src/index.js', 1]]);
+
+    // Files of other types are left alone
+    expect(await service.lint('/package.json')).toMatchObject({ linters: [], diagnostics: [] });
+    await expect(service.lint('/../outside.js')).rejects.toThrow('Access denied');
+  });
+
+  it('should skip linters not run on save and report missing commands', async () => {
+    const service = new LinterService(workspaceRoot, [
+      { name: 'manual', format: 'eslint', command: process.execPath, args: ['fake-eslint.js', '${file}'], runOnSave: false },
+      { name: 'missing', format: 'eslint', command: 'definitely-not-a-linter' }
+    ]);
+
+    const saved = await service.lint('/src/index.js', { onSave: true });
+    expect(saved.linters).toEqual(['missing']);
+    expect(saved.errors).toEqual(['missing: command not found: definitely-not-a-linter']);
+    expect(saved.diagnostics).toEqual([]);
+
+    const manual = await service.lint('/src/index.js');
+    expect(manual.linters).toEqual(['manual', 'missing']);
+    expect(manual.diagnostics.map(d => d.source)).toEqual(['manual']);
+  });
+});
@@ -0,0 +1,189 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { compareVersions, satisfies } from '../../src/npm/semver.js';
+import { parsePackageLock, parsePnpmLock, parseYarnLock } from '../../src/npm/lockfiles.js';
+import { NpmService } from '../../src/npm/service.js';
+import { TaskManager, TaskOptions } from '../../src/tasks/manager.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const YARN_LOCK = `# yarn lockfile v1
+
+"@types/node@^20.0.0":
+  version "20.11.5"
+  resolved "https://registry.yarnpkg.com/@types/node/-/node-20.11.5.tgz"
+
+lodash@^4.17.0, lodash@^4.17.21:
+  version "4.17.21"
+`;
+
+const PNPM_LOCK = `lockfileVersion: '6.0'
+
+importers:
+
+  .:
+    dependencies:
+      react:
+        specifier: ^18.2.0
+        version: 18.2.0
+    devDependencies:
+      '@types/react':
+        specifier: ^18.0.0
+        version: 18.2.48(@types/prop-types@15.7.11)
+
+  packages/app:
+    dependencies:
+      react:
+        specifier: ^17.0.0
+        version: 17.0.2
+
+packages:
+
+  /react@18.2.0:
+    resolution: {integrity: sha512-abc}
+`;
+
+async function writeJson(file: string, value: unknown): Promise<void> {
+  await fs.mkdir(path.dirname(file), { recursive: true });
+  await fs.writeFile(file, JSON.stringify(value, null, 2));
+}
+
+describe('npm packages', () => {
+  let workspaceRoot: string;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-npm-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await writeJson(path.join(workspaceRoot, 'package.json'), {
+      name: 'root',
+      version: '1.0.0',
+      private: true,
+      scripts: { build: 'tsc', test: 'vitest' },
+      dependencies: { express: '^4.18.0', lodash: '~4.17.0', local: 'file:../local' },
+      devDependencies: { typescript: '^5.0.0' },
+      peerDependencies: { react: '>=18' }
+    });
+    await writeJson(path.join(workspaceRoot, 'package-lock.json'), {
+      lockfileVersion: 3,
+      packages: {
+        '': { name: 'root' },
+        'node_modules/express': { version: '4.18.2' },
+        'node_modules/lodash': { version: '4.17.21' },
+        'node_modules/chalk': { version: '5.3.0' },
+        'web/node_modules/chalk': { version: '4.1.2' }
+      }
+    });
+    await writeJson(path.join(workspaceRoot, 'node_modules', 'express', 'package.json'), { version: '4.18.2' });
+    await writeJson(path.join(workspaceRoot, 'node_modules', 'lodash', 'package.json'), { version: '4.18.0' });
+    await writeJson(path.join(workspaceRoot, 'node_modules', 'chalk', 'package.json'), { version: '5.3.0' });
+    await writeJson(path.join(workspaceRoot, 'node_modules', 'nested', 'package.json'), { name: 'ignored' });
+
+    await writeJson(path.join(workspaceRoot, 'web', 'package.json'), {
+      name: 'web',
+      dependencies: { chalk: '^4.0.0', express: '^4.0.0' }
+    });
+    await writeJson(path.join(workspaceRoot, 'web', 'node_modules', 'chalk', 'package.json'), { version: '4.1.2' });
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should match versions against npm ranges', () => {
+    expect(satisfies('1.4.2', '^1.2.0')).toBe(true);
+    expect(satisfies('2.0.0', '^1.2.0')).toBe(false);
+    expect(satisfies('0.2.5', '^0.2.1')).toBe(true);
+    expect(satisfies('0.3.0', '^0.2.1')).toBe(false);
+    expect(satisfies('1.2.9', '~1.2.3')).toBe(true);
+    expect(satisfies('1.3.0', '~1.2.3')).toBe(false);
+    expect(satisfies('1.9.0', '1.x')).toBe(true);
+    expect(satisfies('3.0.0', '>=1.0.0 <2.0.0 || ^3.0.0')).toBe(true);
+    expect(satisfies('1.5.0', '1.0.0 - 1.4')).toBe(false);
+    expect(satisfies('5.0.0', '*')).toBe(true);
+    expect(satisfies('2.0.0-beta.1', '^1.0.0 || ^2.0.0')).toBe(false);
+    expect(satisfies('2.0.0-beta.2', '>=2.0.0-beta.1')).toBe(true);
+    expect(satisfies('4.17.21', 'npm:lodash@^4.17.0')).toBe(true);
+    expect(satisfies('1.0.0', 'file:../local')).toBeUndefined();
+    expect(satisfies('1.0.0', 'workspace:*')).toBeUndefined();
+    expect(satisfies('1.0.0', 'github:user/repo')).toBeUndefined();
+
+    expect(['1.10.0', '1.2.0', '1.2.0-rc.1', '1.2.0-beta'].sort(compareVersions))
+      .toEqual(['1.2.0-beta', '1.2.0-rc.1', '1.2.0', '1.10.0']);
+  });
+
+  it('should read versions from npm, yarn and pnpm lockfiles', () => {
+    const npmV1 = parsePackageLock(JSON.stringify({ lockfileVersion: 1, dependencies: { lodash: { version: '4.17.21' } } }));
+    expect(npmV1.get('node_modules/lodash')).toBe('4.17.21');
+
+    const yarn = parseYarnLock(YARN_LOCK);
+    expect(yarn.get('@types/node@^20.0.0')).toBe('20.11.5');
+    expect(yarn.get('lodash@^4.17.0')).toBe('4.17.21');
+    expect(yarn.get('lodash@^4.17.21')).toBe('4.17.21');
+
+    const berry = parseYarnLock('"lodash@npm:^4.17.0":\n  version: 4.17.21\n  resolution: "lodash@npm:4.17.21"\n');
+    expect(berry.get('lodash@^4.17.0')).toBe('4.17.21');
+
+    const pnpm = parsePnpmLock(PNPM_LOCK);
+    expect(Object.fromEntries(pnpm)).toEqual({ react: '18.2.0', '@types/react': '18.2.48' });
+
+    const pnpmV5 = parsePnpmLock('lockfileVersion: 5.4\n\nspecifiers:\n  react: ^18.2.0\n\ndependencies:\n  react: 18.2.0\n');
+    expect(Object.fromEntries(pnpmV5)).toEqual({ react: '18.2.0' });
+  });
+
+  it('should list packages with installed, locked and declared versions', async () => {
+    const service = new NpmService(workspaceRoot, new TaskManager());
+    const packages = await service.listPackages();
+    expect(packages.map(p => [p.path, p.name, p.packageManager, p.lockfile])).toEqual([
+      ['/', 'root', 'npm', '/package-lock.json'],
+      ['/web', 'web', 'npm', '/package-lock.json']
+    ]);
+
+    const [root, web] = packages;
+    expect(root.scripts).toEqual({ build: 'tsc', test: 'vitest' });
+    expect(root.dependencies).toEqual([
+      { name: 'express', type: 'dependencies', declared: '^4.18.0', installed: '4.18.2', locked: '4.18.2', status: 'ok' },
+      { name: 'lodash', type: 'dependencies', declared: '~4.17.0', installed: '4.18.0', locked: '4.17.21', status: 'mismatch' },
+      { name: 'local', type: 'dependencies', declared: 'file:../local', installed: undefined, locked: undefined, status: 'missing' },
+      { name: 'typescript', type: 'devDependencies', declared: '^5.0.0', installed: undefined, locked: undefined, status: 'missing' },
+      { name: 'react', type: 'peerDependencies', declared: '>=18', installed: undefined, locked: undefined, status: 'unknown' }
+    ]);
+
+    // Nested packages use their own node_modules first, then the hoisted copy
+    expect(web.dependencies).toEqual([
+      { name: 'chalk', type: 'dependencies', declared: '^4.0.0', installed: '4.1.2', locked: '4.1.2', status: 'ok' },
+      { name: 'express', type: 'dependencies', declared: '^4.0.0', installed: '4.18.2', locked: '4.18.2', status: 'ok' }
+    ]);
+  });
+
+  it('should run scripts and installs as tasks with the registry mirror', async () => {
+    const started: TaskOptions[] = [];
+    const taskManager = {
+      run: (options: TaskOptions) => {
+        started.push(options);
+        return { id: 'task-1', label: options.label, command: options.command, args: options.args, cwd: options.cwd, status: 'running' };
+      }
+    } as unknown as TaskManager;
+    const service = new NpmService(workspaceRoot, taskManager, { registry: 'http://localhost:4873' });
+
+    const script = await service.runScript('/web', 'build').catch(error => error);
+    expect(script.message).toBe('Unknown script: build');
+    await service.runScript('/', 'test');
+    await service.runCommand('/web', 'update', 'chalk');
+    await expect(service.runCommand('/', 'update', 'chalk')).rejects.toThrow('Unknown dependency: chalk');
+    await expect(service.getPackage('/../..')).rejects.toThrow('Access denied');
+
+    expect(started).toEqual([
+      { label: 'npm run test', command: 'npm', args: ['run', 'test'], cwd: workspaceRoot },
+      {
+        label: 'npm update chalk (/web)',
+        command: 'npm',
+        args: ['update', 'chalk'],
+        cwd: path.join(workspaceRoot, 'web'),
+        env: { npm_config_registry: 'http://localhost:4873', YARN_NPM_REGISTRY_SERVER: 'http://localhost:4873' }
+      }
+    ]);
+  });
+});
@@ -0,0 +1,231 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { PluginHost, PluginHostDependencies } from '../../src/plugins/host.js';
+import { EventBus } from '../../src/events/bus.js';
+import { LanguageServerConfig } from '../../src/lsp/manager.js';
+import { ServerPlugin } from '../../src/plugins/types.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+/**
+ * Minimal stand-ins for the WebSocket server and language server manager
+ */
+function createDependencies() {
+  const methods = new Map<string, (clientId: string, message: any) => void>();
+  const sent: any[] = [];
+  const configs: LanguageServerConfig[] = [];
+  const events = new EventBus();
+
+  const deps: PluginHostDependencies = {
+    workspaceRoot: os.tmpdir(),
+    wsServer: {
+      onMethod: (method, handler) => { methods.set(method, handler); },
+      offMethod: (method) => { methods.delete(method); },
+      hasMethod: (method) => methods.has(method),
+      sendToClient: (clientId, message) => { sent.push({ clientId, ...message }); return true; },
+      sendError: (clientId, code, message, id) => { sent.push({ clientId, id, error: { code, message } }); },
+      broadcast: (message) => { sent.push({ clientId: '*', ...message }); },
+      getClientUser: () => 'alice'
+    },
+    lsManager: {
+      addConfig: (config) => { configs.push(config); },
+      removeConfig: async (languageId) => {
+        configs.splice(configs.findIndex(c => c.languageId === languageId), 1);
+      }
+    },
+    taskManager: {
+      run: () => { throw new Error('not used'); },
+      wait: () => { throw new Error('not used'); },
+      kill: () => false
+    },
+    events,
+    options: { greeter: { greeting: 'Hi' } }
+  };
+  return { deps, methods, sent, configs, events };
+}
+
+describe('PluginHost', () => {
+  let env: ReturnType<typeof createDependencies>;
+  let host: PluginHost;
+
+  beforeEach(() => {
+    env = createDependencies();
+    host = new PluginHost(env.deps);
+  });
+
+  it('should give plugins their options and register JSON-RPC methods', async () => {
+    const plugin: ServerPlugin = {
+      name: 'greeter',
+      activate(context) {
+        context.registerMethod('greeter/hello', (params, { user }) => `${context.options.greeting}, ${params.name} from ${user}`);
+      }
+    };
+    host.add(plugin, '/plugins/greeter.js');
+    await host.activateAll();
+
+    env.methods.get('greeter/hello')!('client-1', { jsonrpc: '2.0', id: 7, method: 'greeter/hello', params: { name: 'Bob' } });
+    await new Promise(resolve => setTimeout(resolve, 0));
+
+    expect(env.sent).toEqual([{ clientId: 'client-1', jsonrpc: '2.0', id: 7, result: 'Hi, Bob from alice' }]);
+    expect(host.list()[0]).toMatchObject({ name: 'greeter', status: 'active', methods: ['greeter/hello'] });
+  });
+
+  it('should roll back registrations when activation fails', async () => {
+    host.add({
+      name: 'broken',
+      activate(context) {
+        context.registerMethod('broken/ping', () => 'pong');
+        context.registerLanguageServer({ languageId: 'zig', command: 'zls', args: [], fileExtensions: ['.zig'] });
+        throw new Error('missing dependency');
+      }
+    }, '/plugins/broken.js');
+    await host.activateAll();
+
+    expect(env.methods.has('broken/ping')).toBe(false);
+    expect(env.configs).toEqual([]);
+    expect(host.list()[0]).toMatchObject({ status: 'failed', error: 'missing dependency', methods: [] });
+  });
+
+  it('should not let plugins replace existing methods', async () => {
+    env.methods.set('initialize', () => {});
+    host.add({
+      name: 'sneaky',
+      activate(context) {
+        context.registerMethod('initialize', () => null);
+      }
+    }, '/plugins/sneaky.js');
+    await host.activateAll();
+
+    expect(host.list()[0].status).toBe('failed');
+    expect(host.list()[0].error).toMatch(/already registered/);
+  });
+
+  it('should isolate errors thrown by handlers and middleware', async () => {
+    host.add({
+      name: 'flaky',
+      activate(context) {
+        context.registerMethod('flaky/run', () => {
+          throw new Error('kaboom');
+        });
+        context.registerLspMiddleware({
+          incoming: () => {
+            throw new Error('bad middleware');
+          }
+        });
+        context.onEvent(async () => {
+          throw new Error('bad listener');
+        });
+      }
+    }, '/plugins/flaky.js');
+    await host.activateAll();
+
+    env.methods.get('flaky/run')!('client-1', { jsonrpc: '2.0', id: 1, method: 'flaky/run' });
+    const message = { jsonrpc: '2.0' as const, method: 'textDocument/hover' };
+    const passedThrough = await host.getLspMiddleware()[0].incoming!(message, { clientId: 'client-1' });
+    env.events.emit('file.saved', { path: '/a.go' });
+    await new Promise(resolve => setTimeout(resolve, 0));
+
+    expect(passedThrough).toBe(message);
+    expect(env.sent[0].error.message).toBe('Plugin flaky failed: kaboom');
+    expect(host.list()[0]).toMatchObject({ status: 'active', errorCount: 3 });
+  });
+
+  it('should remove registrations on deactivate', async () => {
+    let deactivated = false;
+    host.add({
+      name: 'lang',
+      activate(context) {
+        context.registerLanguageServer({ languageId: 'zig', command: 'zls', args: [], fileExtensions: ['.zig'] });
+        context.registerLspMiddleware({ outgoing: (response) => response });
+      },
+      deactivate() {
+        deactivated = true;
+      }
+    }, '/plugins/lang.js');
+    await host.activateAll();
+    expect(env.configs.map(c => c.languageId)).toEqual(['zig']);
+
+    await host.deactivateAll();
+    expect(deactivated).toBe(true);
+    expect(env.configs).toEqual([]);
+    expect(host.getLspMiddleware()).toEqual([]);
+    expect(host.list()[0].status).toBe('inactive');
+  });
+
+  it('should kill only tasks still running on deactivate', async () => {
+    const finish = new Map<string, () => void>();
+    const killed: string[] = [];
+    env.deps.taskManager = {
+      run: () => ({ id: `task-${finish.size + 1}` }) as any,
+      wait: (taskId) => new Promise(resolve => finish.set(taskId, () => resolve({} as any))),
+      kill: (taskId) => { killed.push(taskId); return true; }
+    };
+    host = new PluginHost(env.deps);
+    host.add({
+      name: 'builder',
+      activate(context) {
+        context.runTask({ command: 'make' });
+        context.runTask({ command: 'make', args: ['watch'] });
+      }
+    }, '/plugins/builder.js');
+    await host.activateAll();
+
+    finish.get('task-1')!();
+    await new Promise(resolve => setTimeout(resolve, 0));
+    await host.deactivateAll();
+    expect(killed).toEqual(['task-2']);
+  });
+
+  it('should expose web extensions and push notifications to clients', async () => {
+    host.add({
+      name: 'notes',
+      activate(context) {
+        context.registerWebExtension('/plugins/notes/web.mjs');
+        context.notifyClient('client-1', 'notes/changed', { count: 1 });
+        context.broadcast('notes/reset');
+      }
+    }, '/plugins/notes');
+    await host.activateAll();
+
+    expect(host.list()[0].webExtension).toBe('/api/plugins/notes/extension.js');
+    expect(env.sent).toEqual([
+      { clientId: 'client-1', jsonrpc: '2.0', method: 'notes/changed', params: { count: 1 } },
+      { clientId: '*', jsonrpc: '2.0', method: 'notes/reset', params: undefined }
+    ]);
+
+    await host.deactivateAll();
+    expect(host.list()[0].webExtension).toBeUndefined();
+  });
+
+  describe('loadFromDirectory', () => {
+    let dir: string;
+
+    beforeEach(async () => {
+      dir = path.join(os.tmpdir(), `test-plugins-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+      await fs.mkdir(path.join(dir, 'folder-plugin'), { recursive: true });
+      await fs.writeFile(path.join(dir, 'single.mjs'), "export default { name: 'single', activate() {} };");
+      await fs.writeFile(path.join(dir, 'folder-plugin', 'index.mjs'), "export default { name: 'folder', version: '1.2.0', activate() {} };");
+      await fs.writeFile(path.join(dir, 'invalid.mjs'), 'export default { name: 42 };');
+      await fs.writeFile(path.join(dir, 'README.md'), 'not a plugin');
+    });
+
+    afterEach(async () => {
+      try {
+        await fs.rm(dir, { recursive: true, force: true });
+      } catch (error) {
+        // Ignore cleanup errors
+      }
+    });
+
+    it('should load files and folders and report invalid plugins', async () => {
+      await host.loadFromDirectory(dir);
+      await host.activateAll();
+
+      const plugins = Object.fromEntries(host.list().map(p => [p.name, p]));
+      expect(Object.keys(plugins).sort()).toEqual(['folder', 'invalid', 'single']);
+      expect(plugins.folder).toMatchObject({ status: 'active', version: '1.2.0' });
+      expect(plugins.single.status).toBe('active');
+      expect(plugins.invalid.status).toBe('failed');
+    });
+  });
+});
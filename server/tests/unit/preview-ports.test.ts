@@ -0,0 +1,106 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { parseProcNetTcp, PortDetector } from '../../src/ports/detector.js';
+import { parsePreviewUrl, rewriteLocation } from '../../src/ports/proxy.js';
+import { TaskInfo, TaskManager } from '../../src/tasks/manager.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const TCP = [
+  '  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode',
+  '   0: 00000000:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 111 1 0000000000000000 100 0 0 10 0',
+  '   1: 0100007F:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 222 1 0000000000000000 100 0 0 10 0',
+  '   2: 0100007F:0BB8 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000  1000        0 444 1 0000000000000000 20 4 30 10 -1',
+  '   3: 0100007F:0BB9 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 555 1 0000000000000000 100 0 0 10 0'
+].join('\n');
+
+const TCP6 = [
+  '  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode',
+  '   0: 00000000000000000000000001000000:1435 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 333 1 0000000000000000 100 0 0 10 0'
+].join('\n');
+
+describe('Preview ports', () => {
+  let tempDir: string;
+  let procRoot: string;
+  let workspaceRoot: string;
+
+  const addProcess = async (pid: number, ppid: number, comm: string, cwd: string, inodes: string[]) => {
+    const dir = path.join(procRoot, String(pid));
+    await fs.mkdir(path.join(dir, 'fd'), { recursive: true });
+    await fs.writeFile(path.join(dir, 'stat'), `${pid} (${comm}) S ${ppid} ${pid} ${pid} 0 -1 4194304`);
+    await fs.symlink(cwd, path.join(dir, 'cwd'));
+    await fs.symlink('/dev/null', path.join(dir, 'fd', '0'));
+    for (const [index, inode] of inodes.entries()) {
+      await fs.symlink(`socket:[${inode}]`, path.join(dir, 'fd', String(index + 3)));
+    }
+  };
+
+  beforeEach(async () => {
+    tempDir = await fs.realpath(await fs.mkdtemp(path.join(os.tmpdir(), 'test-ports-')));
+    procRoot = path.join(tempDir, 'proc');
+    workspaceRoot = path.join(tempDir, 'workspace');
+    await fs.mkdir(path.join(procRoot, 'net'), { recursive: true });
+    await fs.mkdir(path.join(workspaceRoot, 'web'), { recursive: true });
+    await fs.writeFile(path.join(procRoot, 'net', 'tcp'), TCP);
+    await fs.writeFile(path.join(procRoot, 'net', 'tcp6'), TCP6);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(tempDir, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should read listening sockets from /proc/net/tcp', () => {
+    expect(parseProcNetTcp(TCP)).toEqual([
+      { inode: '111', port: 3000, address: '0.0.0.0' },
+      { inode: '222', port: 5432, address: '127.0.0.1' },
+      { inode: '555', port: 3001, address: '127.0.0.1' }
+    ]);
+    expect(parseProcNetTcp(TCP6)).toEqual([{ inode: '333', port: 5173, address: '::1' }]);
+  });
+
+  it('should find ports of tasks, their children and processes running in the workspace', async () => {
+    // go run (task) -> compiled program listening on 3000
+    await addProcess(100, 1, 'go', workspaceRoot, []);
+    await addProcess(101, 100, 'main', '/tmp', ['111']);
+    // A database elsewhere on the machine
+    await addProcess(200, 1, 'postgres', '/var/lib', ['222']);
+    // A dev server started from a workspace folder
+    await addProcess(300, 1, 'node', path.join(workspaceRoot, 'web'), ['333']);
+    // The editor's own port
+    await addProcess(400, 1, 'node', workspaceRoot, ['555']);
+
+    const task = { id: 'task-1', label: 'Run /main.go' } as TaskInfo;
+    const taskManager = { listProcesses: () => [{ task, pid: 100 }] } as unknown as TaskManager;
+    const detector = new PortDetector(workspaceRoot, taskManager, { procRoot, ignore: [3001] });
+    const changes: number[][] = [];
+    detector.onChange(ports => changes.push(ports.map(port => port.port)));
+
+    expect(await detector.refresh()).toEqual([
+      { port: 3000, host: '127.0.0.1', pid: 101, command: 'main', taskId: 'task-1', taskLabel: 'Run /main.go' },
+      { port: 5173, host: '::1', pid: 300, command: 'node' }
+    ]);
+    expect(detector.getPort(5432)).toBeUndefined();
+
+    // Only changes are reported
+    await detector.refresh();
+    await fs.rm(path.join(procRoot, '300'), { recursive: true });
+    await detector.refresh();
+    expect(changes).toEqual([[3000, 5173], [3000]]);
+  });
+
+  it('should map preview URLs to the forwarded port and path', () => {
+    expect(parsePreviewUrl('/preview/3000/assets/app.js?v=1')).toEqual({ port: 3000, path: '/assets/app.js?v=1', bare: false });
+    expect(parsePreviewUrl('/preview/5173')).toEqual({ port: 5173, path: '/', bare: true });
+    expect(parsePreviewUrl('/preview/70000/')).toBeUndefined();
+    expect(parsePreviewUrl('/api/v1/ports')).toBeUndefined();
+
+    expect(rewriteLocation('/login', 3000)).toBe('/preview/3000/login');
+    expect(rewriteLocation('http://localhost:3000/done?ok=1', 3000)).toBe('/preview/3000/done?ok=1');
+    expect(rewriteLocation('http://localhost:8080/', 3000)).toBe('http://localhost:8080/');
+    expect(rewriteLocation('https://example.com/', 3000)).toBe('https://example.com/');
+  });
+});
@@ -103,6 +103,18 @@ describe('RealFileSystem', () => {
     expect(rfs.getFileCount()).toBe(1);
   });
 
+  it('should refuse paths in the state directory', async () => {
+    await fs.mkdir(path.join(testWorkspaceRoot, '.oneline-editor'));
+    await fs.writeFile(path.join(testWorkspaceRoot, '.oneline-editor', 'audit.jsonl'), '{}\n');
+
+    await expect(rfs.readFileContent('/.oneline-editor/audit.jsonl')).rejects.toThrow('Access denied');
+    await expect(rfs.readFileContent('/src/../.Oneline-Editor/audit.jsonl')).rejects.toThrow('Access denied');
+    await expect(rfs.deletePath('/.oneline-editor')).rejects.toThrow('Access denied');
+    await expect(rfs.renamePath('/.oneline-editor/audit.jsonl', '/audit.jsonl')).rejects.toThrow('Access denied');
+    await expect(rfs.updateFile('workspace:///.oneline-editor/audit.jsonl', '')).rejects.toThrow('Access denied');
+    expect(await fs.readFile(path.join(testWorkspaceRoot, '.oneline-editor', 'audit.jsonl'), 'utf-8')).toBe('{}\n');
+  });
+
   it('should clear tracking data', async () => {
     await rfs.createFile('file:///test.go', 'package main', 'go');
     rfs.clear();
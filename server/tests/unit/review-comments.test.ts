@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { diffLineMap, reanchor } from '../../src/comments/anchor.js';
+import { CommentStore } from '../../src/comments/store.js';
+import { RealFileSystem } from '../../src/fs/real.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('Comment anchors', () => {
+  it('should follow lines through insertions and deletions', () => {
+    const oldLines = ['package main', '', 'func a() {}', 'func b() {}', 'func c() {}'];
+    const newLines = ['package main', '', 'import "fmt"', '', 'func a() {}', 'func c() {}'];
+    const lineMap = diffLineMap(oldLines, newLines);
+    expect(lineMap).toEqual([0, 1, 4, -1, 5]);
+
+    expect(reanchor({ startLine: 3, endLine: 3, text: 'func a() {}' }, lineMap, newLines))
+      .toEqual({ startLine: 5, endLine: 5, outdated: false });
+    // The removed line leaves the thread after the line above it
+    expect(reanchor({ startLine: 4, endLine: 4, text: 'func b() {}' }, lineMap, newLines))
+      .toEqual({ startLine: 6, endLine: 6, outdated: true });
+  });
+
+  it('should find anchored text that moved elsewhere', () => {
+    const oldLines = ['a', 'b', 'moved', 'c'];
+    const newLines = ['moved', 'a', 'b', 'c'];
+    const lineMap = diffLineMap(oldLines, newLines);
+    expect(reanchor({ startLine: 3, endLine: 3, text: 'moved' }, lineMap, newLines))
+      .toEqual({ startLine: 1, endLine: 1, outdated: false });
+  });
+});
+
+describe('CommentStore', () => {
+  let workspaceRoot: string;
+  let store: CommentStore;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-comments-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(workspaceRoot, { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'main.go'), 'package main\n\nfunc main() {\n}\n');
+    store = new CommentStore(new WorkspaceState(workspaceRoot), new RealFileSystem(workspaceRoot));
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should re-anchor threads when the file changes', async () => {
+    const thread = await store.create({ path: '/main.go', startLine: 3, endLine: 4, author: 'alice', body: 'Add a doc comment' });
+    expect(thread?.text).toBe('func main() {\n}');
+
+    await fs.writeFile(path.join(workspaceRoot, 'main.go'), 'package main\n\nimport "os"\n\nfunc main() {\n}\n');
+    const [moved] = await store.list({ path: '/main.go' });
+    expect(moved).toMatchObject({ startLine: 5, endLine: 6, outdated: false });
+
+    await fs.writeFile(path.join(workspaceRoot, 'main.go'), 'package main\n\nimport "os"\n');
+    const [outdated] = await store.list();
+    expect(outdated.outdated).toBe(true);
+  });
+
+  it('should filter threads by author and status', async () => {
+    const first = await store.create({ path: '/main.go', startLine: 1, endLine: 1, author: 'alice', body: 'Rename' });
+    await store.create({ path: '/main.go', startLine: 3, endLine: 3, author: 'bob', body: 'Why?' });
+    await store.reply(first!.id, 'bob', 'Agreed');
+    await store.setStatus(first!.id, 'resolved', 'alice');
+
+    expect(await store.list({ author: 'bob' })).toHaveLength(2);
+    expect(await store.list({ author: 'alice' })).toHaveLength(1);
+    const open = await store.list({ status: 'open' });
+    expect(open.map(t => t.comments[0].author)).toEqual(['bob']);
+
+    // Persisted across instances
+    const reloaded = new CommentStore(new WorkspaceState(workspaceRoot), new RealFileSystem(workspaceRoot));
+    const [resolved] = await reloaded.list({ status: 'resolved' });
+    expect(resolved).toMatchObject({ resolvedBy: 'alice', status: 'resolved' });
+    expect(resolved.comments).toHaveLength(2);
+  });
+});
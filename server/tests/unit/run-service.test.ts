@@ -0,0 +1,147 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { parseRunOutput, RunProblem, RunService } from '../../src/run/service.js';
+import { ScratchDocuments } from '../../src/fs/scratch.js';
+import { UriMapper } from '../../src/fs/uris.js';
+import { TaskInfo, TaskManager, TaskOptions } from '../../src/tasks/manager.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('Run service', () => {
+  let workspaceRoot: string;
+
+  beforeEach(async () => {
+    workspaceRoot = await fs.mkdtemp(path.join(os.tmpdir(), 'test-run-'));
+    await fs.mkdir(path.join(workspaceRoot, 'cmd', 'tool'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'cmd', 'tool', 'main.go'), 'package main\n');
+    await fs.mkdir(path.join(workspaceRoot, 'scripts'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'scripts', 'seed.ts'), 'console.log(1);\n');
+    await fs.writeFile(path.join(workspaceRoot, 'scripts', 'notes.md'), '# Notes\n');
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should link compile errors, Node errors and stack frames to workspace URIs', () => {
+    const cwd = path.join(workspaceRoot, 'cmd', 'tool');
+    const uris = new UriMapper(workspaceRoot);
+    const toUri = (filePath: string) => {
+      const uri = uris.toClientUri(`file://${filePath}`);
+      return uri.startsWith('file:') ? undefined : uri;
+    };
+
+    const goOutput = [
+      '# command-line-arguments',
+      './main.go:7:2: undefined: fmt',
+      '/usr/lib/go/src/fmt/print.go:12:1: not ours'
+    ].join('\n');
+    expect(parseRunOutput(goOutput, cwd, toUri)).toEqual([
+      { uri: 'workspace:///cmd/tool/main.go', line: 7, column: 2, message: 'undefined: fmt', outputLine: 1 }
+    ]);
+
+    const nodeOutput = [
+      `${workspaceRoot}/scripts/seed.ts:3`,
+      '  throw new Error("no seed");',
+      '  ^',
+      '',
+      'Error: no seed',
+      `    at main (${workspaceRoot}/scripts/seed.ts:3:9)`,
+      '    at node:internal/main/run_main_module:28:49'
+    ].join('\n');
+    const problems: RunProblem[] = parseRunOutput(nodeOutput, cwd, toUri);
+    expect(problems).toEqual([
+      { uri: 'workspace:///scripts/seed.ts', line: 3, column: 1, message: 'Error: no seed', outputLine: 0 },
+      { uri: 'workspace:///scripts/seed.ts', line: 3, column: 9, outputLine: 5 }
+    ]);
+  });
+
+  it('should pick the runtime from the file extension and run in its directory', async () => {
+    const started: TaskOptions[] = [];
+    const taskManager = {
+      run: (options: TaskOptions) => {
+        started.push(options);
+        return { id: `task-${started.length}`, label: options.label, command: options.command, args: options.args, cwd: options.cwd, status: 'running' };
+      },
+      onExit: () => {}
+    } as unknown as TaskManager;
+    const service = new RunService(workspaceRoot, taskManager);
+
+    await service.run({ uri: 'workspace:///cmd/tool/main.go', args: ['-v'], env: { DEBUG: '1' } });
+    // A project-local tsx is preferred over one on the PATH
+    await fs.mkdir(path.join(workspaceRoot, 'node_modules', '.bin'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'node_modules', '.bin', 'tsx'), '');
+    await service.run({ uri: 'workspace:///scripts/seed.ts' });
+
+    await expect(service.run({ uri: 'workspace:///scripts/notes.md' })).rejects.toThrow("Don't know how to run notes.md");
+    await expect(service.run({ uri: 'workspace:///scripts/missing.js' })).rejects.toThrow('File not found');
+    await expect(service.run({ uri: 'untitled:Untitled-1' })).rejects.toThrow('File not found');
+
+    expect(started).toEqual([
+      {
+        label: 'Run /cmd/tool/main.go',
+        command: 'go',
+        args: ['run', 'main.go', '-v'],
+        cwd: path.join(workspaceRoot, 'cmd', 'tool'),
+        env: { DEBUG: '1' }
+      },
+      {
+        label: 'Run /scripts/seed.ts',
+        command: path.join(workspaceRoot, 'node_modules', '.bin', 'tsx'),
+        args: ['seed.ts'],
+        cwd: path.join(workspaceRoot, 'scripts'),
+        env: undefined
+      }
+    ]);
+  });
+
+  it('should run untitled buffers and report problems against them when the run ends', async () => {
+    let onExit: (task: TaskInfo) => void = () => {};
+    const taskManager = {
+      run: (options: TaskOptions) => ({ id: 'task-1', label: options.label, command: options.command, args: options.args, cwd: options.cwd, status: 'running' }),
+      onExit: (listener: (task: TaskInfo) => void) => {
+        onExit = listener;
+      },
+      getOutput: () => './Untitled-1.go:3:5: x declared and not used\n'
+    } as unknown as TaskManager;
+    const scratch = new ScratchDocuments(workspaceRoot);
+    const service = new RunService(workspaceRoot, taskManager, new UriMapper(workspaceRoot, undefined, scratch), scratch);
+    const reports: Array<{ uri: string; problems: RunProblem[] }> = [];
+    service.onProblems((uri, task, problems) => reports.push({ uri, problems }));
+
+    await expect(service.run({ uri: 'untitled:Untitled-1', client: 'tab-1' })).rejects.toThrow('Untitled document is not open');
+    await scratch.open('tab-1', 'untitled:Untitled-1', 'go', 'package main\n');
+    // Another tab's Untitled-1 is a different buffer
+    await expect(service.run({ uri: 'untitled:Untitled-1', client: 'tab-2' })).rejects.toThrow('Untitled document is not open');
+    const task = await service.run({ uri: 'untitled:Untitled-1', client: 'tab-1' });
+    expect(task.label).toBe('Run Untitled-1');
+
+    onExit({ ...task, status: 'failed', exitCode: 1 });
+    onExit({ ...task, id: 'other-task', status: 'succeeded', exitCode: 0 });
+    expect(reports).toEqual([
+      {
+        uri: 'untitled:Untitled-1',
+        problems: [{ uri: 'untitled:Untitled-1', line: 3, column: 5, message: 'x declared and not used', outputLine: 0 }]
+      }
+    ]);
+  });
+
+  it('should stop everything a run started when it is killed', async () => {
+    const tasks = new TaskManager();
+    // Like `go run`, the shell doesn't pass SIGTERM on to its child
+    const task = tasks.run({ command: 'sh', args: ['-c', 'sleep 30 & wait'], cwd: workspaceRoot });
+    await new Promise(resolve => setTimeout(resolve, 100));
+
+    expect(tasks.kill(task.id)).toBe(true);
+    // The task only ends once sleep, which holds its output open, is gone too
+    const finished = await Promise.race([
+      tasks.wait(task.id),
+      new Promise<undefined>(resolve => setTimeout(resolve, 2000))
+    ]);
+    expect(finished?.status).toBe('killed');
+  });
+});
@@ -0,0 +1,95 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { SchemaRegistry } from '../../src/schemas/registry.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+import { pathToFileURL } from 'url';
+
+describe('SchemaRegistry', () => {
+  let workspaceRoot: string;
+  let state: WorkspaceState;
+  let registry: SchemaRegistry;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-schemas-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(path.join(workspaceRoot, 'schemas'), { recursive: true });
+    await fs.writeFile(
+      path.join(workspaceRoot, 'schemas', 'deploy.json'),
+      JSON.stringify({ title: 'Deployment', type: 'object' })
+    );
+    state = new WorkspaceState(workspaceRoot);
+    registry = new SchemaRegistry(workspaceRoot, state);
+    await registry.load();
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should list the bundled schemas with their titles', async () => {
+    const schemas = await registry.list();
+    const ids = schemas.map(s => s.id);
+    expect(ids).toEqual(expect.arrayContaining(['package', 'tsconfig', 'oneline-editor-config', 'template']));
+    for (const schema of schemas) {
+      expect(schema.source).toBe('builtin');
+      expect(schema.title).toBeTruthy();
+    }
+
+    const tsconfig = await registry.getSchema('tsconfig');
+    expect(tsconfig.type).toBe('object');
+  });
+
+  it('should persist workspace mappings and merge them into the list', async () => {
+    await registry.setMappings([
+      { fileMatch: ['deploy/*.json'], schema: 'schemas/deploy.json' },
+      { fileMatch: ['package.*.json'], schema: 'package' }
+    ]);
+
+    const reloaded = new SchemaRegistry(workspaceRoot, state);
+    await reloaded.load();
+    expect(reloaded.getMappings()).toHaveLength(2);
+
+    const schemas = await reloaded.list();
+    expect(schemas.find(s => s.id === '/schemas/deploy.json')).toEqual({
+      id: '/schemas/deploy.json',
+      source: 'workspace',
+      title: 'Deployment',
+      fileMatch: ['deploy/*.json']
+    });
+    expect(schemas.find(s => s.id === 'package')?.fileMatch).toEqual(['package.json', 'package.*.json']);
+  });
+
+  it('should reject mappings to missing, invalid or outside schemas', async () => {
+    await fs.writeFile(path.join(workspaceRoot, 'schemas', 'broken.json'), '{ not json');
+
+    await expect(registry.setMappings([{ fileMatch: ['*.json'], schema: '/schemas/missing.json' }]))
+      .rejects.toThrow('Schema not found');
+    await expect(registry.setMappings([{ fileMatch: ['*.json'], schema: '/schemas/broken.json' }]))
+      .rejects.toThrow('not valid JSON');
+    await expect(registry.getSchema('../../etc/passwd')).rejects.toThrow();
+    expect(registry.getMappings()).toEqual([]);
+  });
+
+  it('should add schema associations to JSON and YAML language server settings', async () => {
+    await registry.setMappings([{ fileMatch: ['deploy/*.yaml'], schema: '/schemas/deploy.json' }]);
+    const deployUrl = pathToFileURL(path.join(workspaceRoot, 'schemas', 'deploy.json')).toString();
+
+    const json = await registry.applyToLanguageServerSettings('json', { json: { validate: { enable: false } } });
+    expect(json?.json.validate).toEqual({ enable: false });
+    expect(json?.json.schemas).toEqual(
+      expect.arrayContaining([{ fileMatch: ['deploy/*.yaml'], url: deployUrl }])
+    );
+
+    const yaml = await registry.applyToLanguageServerSettings('yaml', { yaml: { validate: true } });
+    expect(yaml?.yaml.validate).toBe(true);
+    expect(yaml?.yaml.schemas[deployUrl]).toEqual(['deploy/*.yaml']);
+
+    const go = { gopls: {} };
+    expect(await registry.applyToLanguageServerSettings('go', go)).toBe(go);
+  });
+});
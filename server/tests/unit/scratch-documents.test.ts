@@ -0,0 +1,73 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { ScratchDocuments } from '../../src/fs/scratch.js';
+import { UriMapper } from '../../src/fs/uris.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('Scratch documents', () => {
+  let tempDir: string;
+  let scratch: ScratchDocuments;
+
+  beforeEach(async () => {
+    tempDir = await fs.mkdtemp(path.join(os.tmpdir(), 'test-scratch-'));
+    scratch = new ScratchDocuments(tempDir);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(tempDir, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should back untitled buffers with temporary files named after their language', async () => {
+    const document = await scratch.open('tab-1', 'untitled:Untitled-1', 'go', 'package main\n');
+    expect(document.filePath).toBe(path.join(tempDir, '.oneline-editor', 'scratch', 'tab-1', 'Untitled-1', 'Untitled-1.go'));
+    expect(await fs.readFile(document.filePath, 'utf-8')).toBe('package main\n');
+
+    await scratch.update('tab-1', 'untitled:Untitled-1', 'package main\n\nfunc main() {}\n');
+    expect(await fs.readFile(document.filePath, 'utf-8')).toBe('package main\n\nfunc main() {}\n');
+
+    // Switching language moves the buffer to a file with the new extension
+    const moved = await scratch.open('tab-1', 'untitled:Untitled-1', 'python', 'print(1)\n');
+    expect(moved.filePath.endsWith(path.join('Untitled-1', 'Untitled-1.py'))).toBe(true);
+    await expect(fs.access(document.filePath)).rejects.toThrow();
+
+    await scratch.close('tab-1', 'untitled:Untitled-1');
+    await expect(fs.access(moved.filePath)).rejects.toThrow();
+    expect(scratch.get('tab-1', 'untitled:Untitled-1')).toBeUndefined();
+  });
+
+  it('should keep names inside the scratch directory', async () => {
+    const document = await scratch.open('../..', 'untitled:../../etc/passwd', 'plaintext', '');
+    expect(path.relative(scratch.getDir(), document.filePath).startsWith('..')).toBe(false);
+  });
+
+  it('should keep each client\'s buffers apart', async () => {
+    const first = await scratch.open('tab-1', 'untitled:Untitled-1', 'go', 'package one\n');
+    const second = await scratch.open('tab-2', 'untitled:Untitled-1', 'go', 'package two\n');
+    expect(second.filePath).not.toBe(first.filePath);
+    expect(scratch.toClientUri(second.filePath)).toBe('untitled:Untitled-1');
+
+    await scratch.close('tab-2', 'untitled:Untitled-1');
+    expect(await fs.readFile(first.filePath, 'utf-8')).toBe('package one\n');
+    expect(scratch.get('tab-1', 'untitled:Untitled-1')).toBe(first);
+    expect(scratch.get('tab-2', 'untitled:Untitled-1')).toBeUndefined();
+  });
+
+  it('should translate untitled URIs for language servers and back', async () => {
+    const uris = new UriMapper(tempDir, undefined, scratch);
+    const { filePath } = await scratch.open('tab-1', 'untitled:Untitled-2', 'typescript', 'let a = 1;\n');
+    const fileUri = scratch.toServerUri('tab-1', 'untitled:Untitled-2')!;
+
+    expect(fileUri.endsWith('/.oneline-editor/scratch/tab-1/Untitled-2/Untitled-2.ts')).toBe(true);
+    expect(uris.toClientUri(fileUri)).toBe('untitled:Untitled-2');
+    expect(uris.toClient({ uri: fileUri, diagnostics: [] })).toEqual({ uri: 'untitled:Untitled-2', diagnostics: [] });
+    expect(scratch.toClientUri(filePath)).toBe('untitled:Untitled-2');
+    expect(scratch.toServerUri('tab-2', 'untitled:Untitled-2')).toBeUndefined();
+    // Which file an untitled URI means depends on the client, so the mapper leaves it as is
+    expect(uris.toServerUri('untitled:Untitled-2')).toBe('untitled:Untitled-2');
+  });
+});
@@ -0,0 +1,71 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { SnapshotFile, SnapshotStore } from '../../src/snapshots/store.js';
+import { WorkspaceState } from '../../src/workspace/state.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+const FILE: SnapshotFile = {
+  path: '/main.go',
+  languageId: 'go',
+  content: 'package main\n\nfunc main() {}\n',
+  hovers: [{ line: 3, startColumn: 6, endColumn: 10, contents: '```go\nfunc main()\n```' }]
+};
+
+describe('SnapshotStore', () => {
+  let workspaceRoot: string;
+  let state: WorkspaceState;
+
+  beforeEach(async () => {
+    workspaceRoot = await fs.mkdtemp(path.join(os.tmpdir(), 'test-snapshots-'));
+    state = new WorkspaceState(workspaceRoot);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should store snapshots under random IDs and list them without contents', async () => {
+    const store = new SnapshotStore(state);
+    const first = await store.create({ expiresInDays: 7, createdBy: 'alice', files: [FILE] });
+    const second = await store.create({ title: 'Forever', expiresInDays: 0, createdBy: 'bob', files: [FILE] });
+
+    expect(first.id).toMatch(/^[A-Za-z0-9_-]{22}$/);
+    expect(first.id).not.toBe(second.id);
+    expect(Date.parse(first.expiresAt!) - Date.parse(first.createdAt)).toBe(7 * 24 * 60 * 60 * 1000);
+    expect(second.expiresAt).toBeUndefined();
+
+    // Another instance reads them back from disk
+    const reloaded = new SnapshotStore(new WorkspaceState(workspaceRoot));
+    expect((await reloaded.get(first.id))?.files).toEqual([FILE]);
+    expect((await reloaded.list()).map(snapshot => snapshot.id).sort()).toEqual([first.id, second.id].sort());
+    expect(await reloaded.list('bob')).toEqual([
+      { id: second.id, title: 'Forever', createdAt: second.createdAt, createdBy: 'bob', files: ['/main.go'] }
+    ]);
+  });
+
+  it('should forget revoked and expired snapshots', async () => {
+    const store = new SnapshotStore(state);
+    const revoked = await store.create({ expiresInDays: 7, createdBy: 'alice', files: [FILE] });
+    const expired = await store.create({ expiresInDays: 1, createdBy: 'alice', files: [FILE] });
+    const kept = await store.create({ expiresInDays: 1, createdBy: 'alice', files: [FILE] });
+
+    expect(await store.revoke(revoked.id)).toBe(true);
+    expect(await store.revoke(revoked.id)).toBe(false);
+    expect(await store.get(revoked.id)).toBeUndefined();
+
+    const expiredPath = state.getStatePath(`snapshots/${expired.id}.json`);
+    await state.writeJson(`snapshots/${expired.id}.json`, { ...expired, expiresAt: new Date(Date.now() - 1000).toISOString() });
+    expect(await store.prune()).toBe(1);
+    await expect(fs.access(expiredPath)).rejects.toThrow();
+    expect((await store.list()).map(snapshot => snapshot.id)).toEqual([kept.id]);
+
+    // IDs can't be used to reach other state files
+    expect(await store.get('../bookmarks')).toBeUndefined();
+    expect(await store.revoke('../../package.json')).toBe(false);
+  });
+});
@@ -0,0 +1,108 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { TemplateManager, renderTemplateString, BUILTIN_TEMPLATES_DIR } from '../../src/templates/manager.js';
+import { TaskManager } from '../../src/tasks/manager.js';
+import { RealFileSystem } from '../../src/fs/real.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('renderTemplateString', () => {
+  it('should substitute known variables', () => {
+    expect(renderTemplateString('module {{ modulePath }}', { modulePath: 'example.com/app' }))
+      .toBe('module example.com/app');
+  });
+
+  it('should leave unknown placeholders untouched', () => {
+    expect(renderTemplateString('{{name}} {{other}}', { name: 'app' })).toBe('app {{other}}');
+  });
+});
+
+describe('TemplateManager', () => {
+  let workspaceRoot: string;
+  let templatesDir: string;
+  let manager: TemplateManager;
+
+  beforeEach(async () => {
+    const base = path.join(os.tmpdir(), `test-templates-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    workspaceRoot = path.join(base, 'workspace');
+    templatesDir = path.join(base, 'templates');
+    await fs.mkdir(workspaceRoot, { recursive: true });
+
+    const templateDir = path.join(templatesDir, 'sample');
+    await fs.mkdir(path.join(templateDir, 'files', 'src'), { recursive: true });
+    await fs.writeFile(path.join(templateDir, 'template.json'), JSON.stringify({
+      name: 'Sample',
+      description: 'Sample template',
+      variables: [
+        { name: 'projectName', default: 'demo', pattern: '^[a-z-]+$' },
+        { name: 'modulePath', default: 'example.com/{{projectName}}' }
+      ],
+      hooks: [
+        { command: process.execPath, args: ['-e', "require('fs').writeFileSync('hook.txt', process.argv[1])", '{{modulePath}}'] }
+      ]
+    }));
+    await fs.writeFile(path.join(templateDir, 'files', 'src', '{{projectName}}.txt'), 'module {{modulePath}}');
+
+    manager = new TemplateManager(new RealFileSystem(workspaceRoot), new TaskManager(), [templatesDir]);
+    await manager.load();
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(path.dirname(workspaceRoot), { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should load templates from directories', () => {
+    const templates = manager.listTemplates();
+    expect(templates).toHaveLength(1);
+    expect(templates[0].id).toBe('sample');
+    expect(templates[0].name).toBe('Sample');
+  });
+
+  it('should resolve defaults that reference other variables', () => {
+    const descriptor = manager.getTemplate('sample')!;
+    const variables = manager.resolveVariables(descriptor, { projectName: 'api' });
+    expect(variables.modulePath).toBe('example.com/api');
+  });
+
+  it('should reject values that do not match the variable pattern', () => {
+    const descriptor = manager.getTemplate('sample')!;
+    expect(() => manager.resolveVariables(descriptor, { projectName: 'Bad Name' })).toThrow(/projectName/);
+  });
+
+  it('should render files and run hooks in the project directory', async () => {
+    const result = await manager.createProject('sample', '/projects/{{projectName}}', { projectName: 'api' });
+
+    expect(result.path).toBe('/projects/api');
+    expect(result.files).toEqual(['/projects/api/src/api.txt']);
+    expect(result.hooks).toHaveLength(1);
+    expect(result.hooks[0].task.status).toBe('succeeded');
+
+    const content = await fs.readFile(path.join(workspaceRoot, 'projects/api/src/api.txt'), 'utf-8');
+    expect(content).toBe('module example.com/api');
+    const hookOutput = await fs.readFile(path.join(workspaceRoot, 'projects/api/hook.txt'), 'utf-8');
+    expect(hookOutput).toBe('example.com/api');
+  });
+
+  it('should refuse to scaffold into a non-empty directory', async () => {
+    await fs.mkdir(path.join(workspaceRoot, 'existing'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'existing', 'file.txt'), 'x');
+
+    await expect(manager.createProject('sample', '/existing')).rejects.toThrow(/not empty/);
+  });
+
+  it('should refuse target paths outside the workspace', async () => {
+    await expect(manager.createProject('sample', '/../outside')).rejects.toThrow(/Access denied/);
+  });
+
+  it('should ship the built-in templates', async () => {
+    const builtin = new TemplateManager(new RealFileSystem(workspaceRoot), new TaskManager());
+    await builtin.load();
+    const ids = builtin.listTemplates().map(t => t.id);
+    expect(ids).toEqual(expect.arrayContaining(['go-module', 'go-http-service', 'typescript-library']));
+    expect(BUILTIN_TEMPLATES_DIR).toContain('templates');
+  });
+});
@@ -0,0 +1,94 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { TodoScanner, findTodos } from '../../src/todo/scanner.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('findTodos', () => {
+  const tags = ['TODO', 'FIXME', 'HACK'];
+
+  it('should find tags after comment markers', () => {
+    const content = [
+      'package main',
+      '// TODO: handle errors',
+      'x := 1 # FIXME(alice) wrong value',
+      '/* HACK temporary workaround */',
+    ].join('\n');
+
+    expect(findTodos(content, tags)).toEqual([
+      { line: 2, column: 4, tag: 'TODO', text: 'handle errors' },
+      { line: 3, column: 10, tag: 'FIXME', text: 'wrong value' },
+      { line: 4, column: 4, tag: 'HACK', text: 'temporary workaround' },
+    ]);
+  });
+
+  it('should ignore tags outside comments and partial words', () => {
+    const content = [
+      'const TODO = 1;',
+      'todoList.push(x) // TODOS are fine',
+      '// nothing to see',
+    ].join('\n');
+
+    expect(findTodos(content, tags)).toEqual([]);
+  });
+
+  it('should only report configured tags', () => {
+    expect(findTodos('// XXX fix me\n// TODO later', ['XXX'])).toEqual([
+      { line: 1, column: 4, tag: 'XXX', text: 'fix me' },
+    ]);
+  });
+});
+
+describe('TodoScanner', () => {
+  let workspaceRoot: string;
+  let scanner: TodoScanner;
+
+  beforeEach(async () => {
+    workspaceRoot = path.join(os.tmpdir(), `test-todos-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    await fs.mkdir(path.join(workspaceRoot, 'src'), { recursive: true });
+    await fs.mkdir(path.join(workspaceRoot, 'node_modules', 'dep'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'main.go'), 'package main\n// TODO: write main\n');
+    await fs.writeFile(path.join(workspaceRoot, 'src', 'app.ts'), 'export {};\n// FIXME broken\n');
+    await fs.writeFile(path.join(workspaceRoot, 'node_modules', 'dep', 'index.js'), '// TODO ignored\n');
+    scanner = new TodoScanner(workspaceRoot);
+  });
+
+  afterEach(async () => {
+    scanner.stop();
+    try {
+      await fs.rm(workspaceRoot, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should scan the workspace skipping ignored directories', async () => {
+    await scanner.scan();
+    const items = scanner.getItems();
+    expect(items.map(i => `${i.path}:${i.line}:${i.tag}`)).toEqual([
+      '/main.go:2:TODO',
+      '/src/app.ts:2:FIXME',
+    ]);
+  });
+
+  it('should refresh a single file incrementally', async () => {
+    await scanner.scan();
+    await fs.writeFile(path.join(workspaceRoot, 'main.go'), 'package main\n');
+    await scanner.refreshPath('/main.go');
+    expect(scanner.getItems().map(i => i.path)).toEqual(['/src/app.ts']);
+  });
+
+  it('should drop items for deleted directories', async () => {
+    await scanner.scan();
+    await fs.rm(path.join(workspaceRoot, 'src'), { recursive: true });
+    await scanner.refreshPath('/src');
+    expect(scanner.getItems().map(i => i.path)).toEqual(['/main.go']);
+  });
+
+  it('should notify listeners of changed paths', async () => {
+    const changes: string[][] = [];
+    scanner.onChange(paths => changes.push(paths));
+    await scanner.refreshPath('/main.go');
+    expect(changes).toEqual([['/main.go']]);
+  });
+});
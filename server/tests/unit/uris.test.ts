@@ -0,0 +1,65 @@
+import { describe, it, expect } from 'vitest';
+import { UriMapper, workspacePathToUri } from '../../src/fs/uris.js';
+import { VirtualDocumentProvider } from '../../src/fs/virtual.js';
+
+describe('UriMapper', () => {
+  const mapper = new UriMapper(
+    '/srv/workspace',
+    new VirtualDocumentProvider({ goroot: '/usr/local/go' })
+  );
+
+  it('should build browser URIs from workspace paths', () => {
+    expect(workspacePathToUri('/src/main.go')).toBe('workspace:///src/main.go');
+    expect(workspacePathToUri('src/my file(1).go')).toBe('workspace:///src/my%20file%281%29.go');
+  });
+
+  it('should map browser URIs to language server URIs', () => {
+    expect(mapper.toServerUri('workspace:///src/main.go')).toBe('file:///srv/workspace/src/main.go');
+    expect(mapper.toServerUri('workspace:///src/my%20file.go')).toBe('file:///srv/workspace/src/my%20file.go');
+    // Older clients still send file:// URIs relative to the workspace
+    expect(mapper.toServerUri('file:///src/main.go')).toBe('file:///srv/workspace/src/main.go');
+    expect(mapper.toServerUri('deps:///goroot/src/fmt/print.go')).toBe('file:///usr/local/go/src/fmt/print.go');
+    expect(mapper.toServerUri('untitled:Untitled-1')).toBe('untitled:Untitled-1');
+  });
+
+  it('should keep paths inside the workspace', () => {
+    expect(mapper.toWorkspacePath('workspace:///../../etc/passwd')).toBe('/etc/passwd');
+    expect(mapper.toWorkspacePath('workspace:///src/%2E%2E/%2E%2E/x')).toBe('/x');
+    expect(mapper.toDiskPath('workspace:///../secret')).toBe('/srv/workspace/secret');
+  });
+
+  it('should map language server URIs to browser URIs', () => {
+    expect(mapper.toClientUri('file:///srv/workspace/src/main.go')).toBe('workspace:///src/main.go');
+    expect(mapper.toClientUri('file:///srv/workspace/a%20b.go')).toBe('workspace:///a%20b.go');
+    expect(mapper.toClientUri('file:///usr/local/go/src/fmt/print.go')).toBe('deps:///goroot/src/fmt/print.go');
+    expect(mapper.toClientUri('file:///srv/workspace-other/x.go')).toBe('file:///srv/workspace-other/x.go');
+  });
+
+  it('should map every URI field of a message', () => {
+    const range = { start: { line: 0, character: 0 }, end: { line: 0, character: 1 } };
+    expect(mapper.toClient({
+      uri: 'file:///srv/workspace/a.go',
+      diagnostics: [{ range, relatedInformation: [{ location: { uri: 'file:///srv/workspace/b.go', range } }] }]
+    })).toEqual({
+      uri: 'workspace:///a.go',
+      diagnostics: [{ range, relatedInformation: [{ location: { uri: 'workspace:///b.go', range } }] }]
+    });
+
+    expect(mapper.toClient([{ targetUri: 'file:///usr/local/go/src/fmt/print.go', targetRange: range }])).toEqual([
+      { targetUri: 'deps:///goroot/src/fmt/print.go', targetRange: range }
+    ]);
+
+    expect(mapper.toClient({
+      changes: { 'file:///srv/workspace/a.go': [{ range, newText: 'x' }] },
+      documentChanges: [{ kind: 'rename', oldUri: 'file:///srv/workspace/a.go', newUri: 'file:///srv/workspace/b.go' }]
+    })).toEqual({
+      changes: { 'workspace:///a.go': [{ range, newText: 'x' }] },
+      documentChanges: [{ kind: 'rename', oldUri: 'workspace:///a.go', newUri: 'workspace:///b.go' }]
+    });
+
+    expect(mapper.toServer({ textDocument: { uri: 'workspace:///a.go' }, position: { line: 1, character: 2 } })).toEqual({
+      textDocument: { uri: 'file:///srv/workspace/a.go' },
+      position: { line: 1, character: 2 }
+    });
+  });
+});
@@ -0,0 +1,58 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { VirtualDocumentProvider } from '../../src/fs/virtual.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+
+describe('Virtual documents', () => {
+  let tempDir: string;
+  let goroot: string;
+  let provider: VirtualDocumentProvider;
+
+  beforeEach(async () => {
+    tempDir = await fs.realpath(await fs.mkdtemp(path.join(os.tmpdir(), 'test-virtual-docs-')));
+    goroot = path.join(tempDir, 'go');
+    await fs.mkdir(path.join(goroot, 'src', 'fmt'), { recursive: true });
+    await fs.writeFile(path.join(goroot, 'src', 'fmt', 'print.go'), 'package fmt\n');
+    await fs.writeFile(path.join(tempDir, 'secret.txt'), 'secret');
+    provider = new VirtualDocumentProvider({ goroot }, 1024);
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(tempDir, { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should translate between file and virtual URIs', () => {
+    const uri = 'deps:///goroot/src/fmt/print.go';
+    expect(provider.toVirtualUri(`file://${goroot}/src/fmt/print.go`)).toBe(uri);
+    expect(provider.toVirtualUri(path.join(tempDir, 'secret.txt'))).toBeUndefined();
+    expect(provider.toRealUri(uri)).toBe(`file://${goroot}/src/fmt/print.go`);
+    expect(provider.isVirtualUri(uri)).toBe(true);
+    expect(provider.isVirtualUri('file:///src/main.go')).toBe(false);
+  });
+
+  it('should read files inside the allowed roots only', async () => {
+    expect(await provider.read('deps:///goroot/src/fmt/print.go')).toEqual({
+      uri: 'deps:///goroot/src/fmt/print.go',
+      name: 'goroot/src/fmt/print.go',
+      content: 'package fmt\n',
+      size: 12
+    });
+
+    await expect(provider.read('deps:///goroot/../secret.txt')).rejects.toThrow('Access denied');
+    await expect(provider.read('deps:///goroot/%2E%2E/secret.txt')).rejects.toThrow('Access denied');
+    await expect(provider.read('deps:///gomodcache/x.go')).rejects.toThrow('Access denied');
+    await expect(provider.read('deps:///constructor/x.go')).rejects.toThrow('Access denied');
+
+    // Symlinks can't lead out of a root
+    await fs.symlink(path.join(tempDir, 'secret.txt'), path.join(goroot, 'link.txt'));
+    await expect(provider.read('deps:///goroot/link.txt')).rejects.toThrow('Access denied');
+
+    await fs.writeFile(path.join(goroot, 'big.go'), 'x'.repeat(2048));
+    await expect(provider.read('deps:///goroot/big.go')).rejects.toThrow('File is too large');
+  });
+});
@@ -0,0 +1,124 @@
+import { describe, it, expect } from 'vitest';
+import { EventBus } from '../../src/events/bus.js';
+import { WebhookDispatcher, signPayload } from '../../src/events/webhooks.js';
+import { formatServerSentEvent } from '../../src/events/stream.js';
+
+interface RecordedRequest {
+  url: string;
+  headers: Record<string, string>;
+  body: string;
+}
+
+/**
+ * Fake fetch answering with the given statuses in order (the last one repeats)
+ */
+function fakeFetch(statuses: Array<number | Error>) {
+  const requests: RecordedRequest[] = [];
+  const impl = (async (url: string, init: RequestInit) => {
+    requests.push({ url, headers: init.headers as Record<string, string>, body: init.body as string });
+    const status = statuses[Math.min(requests.length - 1, statuses.length - 1)];
+    if (status instanceof Error) {
+      throw status;
+    }
+    return new Response(null, { status });
+  }) as unknown as typeof fetch;
+  return { impl, requests };
+}
+
+describe('EventBus', () => {
+  it('should deliver events to subscribers until they unsubscribe', () => {
+    const bus = new EventBus();
+    const received: string[] = [];
+    const unsubscribe = bus.subscribe(event => received.push(event.type));
+
+    bus.emit('file.saved', { path: '/a.go' });
+    unsubscribe();
+    bus.emit('file.deleted', { path: '/a.go' });
+
+    expect(received).toEqual(['file.saved']);
+  });
+
+  it('should keep a bounded history for catching up', () => {
+    const bus = new EventBus(2);
+    bus.emit('file.created', { path: '/a' });
+    bus.emit('file.created', { path: '/b' });
+    bus.emit('file.created', { path: '/c' });
+
+    expect(bus.getEventsSince(0).map(event => event.data.path)).toEqual(['/b', '/c']);
+    expect(bus.getEventsSince(2).map(event => event.id)).toEqual([3]);
+  });
+
+  it('should isolate failing subscribers', () => {
+    const bus = new EventBus();
+    const received: number[] = [];
+    bus.subscribe(() => {
+      throw new Error('boom');
+    });
+    bus.subscribe(event => received.push(event.id));
+
+    bus.emit('client.connected', { clientId: 'c1' });
+    expect(received).toEqual([1]);
+  });
+
+  it('should format server-sent events', () => {
+    const bus = new EventBus();
+    const event = bus.emit('task.finished', { status: 'succeeded' });
+    expect(formatServerSentEvent(event)).toBe(`id: 1\nevent: task.finished\ndata: ${JSON.stringify(event)}\n\n`);
+  });
+});
+
+describe('WebhookDispatcher', () => {
+  it('should sign deliveries with the webhook secret', async () => {
+    const bus = new EventBus();
+    const { impl, requests } = fakeFetch([204]);
+    new WebhookDispatcher([{ url: 'http://hooks.test/a', secret: 's3cret' }], { fetch: impl }).attach(bus);
+
+    const event = bus.emit('file.saved', { path: '/main.go' });
+    await new Promise(resolve => setTimeout(resolve, 0));
+
+    expect(requests).toHaveLength(1);
+    const { headers, body } = requests[0];
+    expect(JSON.parse(body)).toEqual(event);
+    expect(headers['X-Oneline-Event']).toBe('file.saved');
+    expect(headers['X-Oneline-Signature']).toBe(signPayload('s3cret', headers['X-Oneline-Timestamp'], body));
+  });
+
+  it('should only deliver subscribed event types', async () => {
+    const { impl, requests } = fakeFetch([200]);
+    const dispatcher = new WebhookDispatcher([{ url: 'http://hooks.test/a', events: ['file.deleted'] }], { fetch: impl });
+    const bus = new EventBus();
+    dispatcher.attach(bus);
+
+    bus.emit('file.saved', { path: '/a.go' });
+    bus.emit('file.deleted', { path: '/a.go' });
+    await dispatcher.flush();
+
+    expect(requests.map(r => r.headers['X-Oneline-Event'])).toEqual(['file.deleted']);
+    expect(requests[0].headers['X-Oneline-Signature']).toBeUndefined();
+  });
+
+  it('should retry server errors and network failures with the same delivery id', async () => {
+    const { impl, requests } = fakeFetch([503, new Error('ECONNREFUSED'), 200]);
+    const dispatcher = new WebhookDispatcher([], { fetch: impl, retryDelayMs: 1 });
+    const event = new EventBus().emit('file.created', { path: '/a.go' });
+
+    const delivered = await dispatcher.deliver({ url: 'http://hooks.test/a' }, event);
+
+    expect(delivered).toBe(true);
+    expect(requests).toHaveLength(3);
+    expect(new Set(requests.map(r => r.headers['X-Oneline-Delivery'])).size).toBe(1);
+  });
+
+  it('should give up after maxAttempts and not retry client errors', async () => {
+    const failing = fakeFetch([500]);
+    const dispatcher = new WebhookDispatcher([], { fetch: failing.impl, retryDelayMs: 1 });
+    const event = new EventBus().emit('file.created', { path: '/a.go' });
+    expect(await dispatcher.deliver({ url: 'http://hooks.test/a', maxAttempts: 3 }, event)).toBe(false);
+    expect(failing.requests).toHaveLength(3);
+
+    const rejected = fakeFetch([400]);
+    const other = new WebhookDispatcher([], { fetch: rejected.impl, retryDelayMs: 1 });
+    expect(await other.deliver({ url: 'http://hooks.test/a' }, event)).toBe(false);
+    expect(rejected.requests).toHaveLength(1);
+  });
+});
@@ -0,0 +1,103 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { exportWorkspace } from '../../src/workspace/export.js';
+import * as fs from 'fs/promises';
+import * as path from 'path';
+import * as os from 'os';
+import { gunzipSync } from 'zlib';
+
+interface TarEntry {
+  name: string;
+  type: string;
+  content: string;
+}
+
+// Minimal reader for the archives written by exportWorkspace
+function readTar(archive: Buffer): TarEntry[] {
+  const entries: TarEntry[] = [];
+  let offset = 0;
+  let paxPath: string | undefined;
+
+  while (offset + 512 <= archive.length) {
+    const header = archive.subarray(offset, offset + 512);
+    if (header.every(byte => byte === 0)) break;
+
+    const field = (start: number, length: number) =>
+      header.subarray(start, start + length).toString('utf-8').replace(/\0.*$/s, '');
+    const size = parseInt(field(124, 12), 8);
+    const type = field(156, 1);
+    const prefix = field(345, 155);
+    const content = archive.subarray(offset + 512, offset + 512 + size).toString('utf-8');
+    offset += 512 + Math.ceil(size / 512) * 512;
+
+    if (type === 'x') {
+      paxPath = content.match(/ path=(.*)\n/)?.[1];
+      continue;
+    }
+    const name = paxPath ?? (prefix ? `${prefix}/${field(0, 100)}` : field(0, 100));
+    paxPath = undefined;
+    entries.push({ name, type, content });
+  }
+  return entries;
+}
+
+describe('exportWorkspace', () => {
+  let workspaceRoot: string;
+  let outputDir: string;
+
+  beforeEach(async () => {
+    const base = path.join(os.tmpdir(), `test-export-${Date.now()}-${Math.random().toString(36).substring(7)}`);
+    workspaceRoot = path.join(base, 'workspace');
+    outputDir = path.join(base, 'out');
+    await fs.mkdir(workspaceRoot, { recursive: true });
+  });
+
+  afterEach(async () => {
+    try {
+      await fs.rm(path.dirname(workspaceRoot), { recursive: true, force: true });
+    } catch (error) {
+      // Ignore cleanup errors
+    }
+  });
+
+  it('should archive files and directories', async () => {
+    await fs.mkdir(path.join(workspaceRoot, 'src'));
+    await fs.writeFile(path.join(workspaceRoot, 'go.mod'), 'module example\n');
+    await fs.writeFile(path.join(workspaceRoot, 'src', 'main.go'), 'package main\n');
+
+    const output = path.join(outputDir, 'export.tar.gz');
+    const result = await exportWorkspace(workspaceRoot, output);
+    expect(result.files).toBe(2);
+
+    const entries = readTar(gunzipSync(await fs.readFile(output)));
+    expect(entries.map(e => e.name)).toEqual(['go.mod', 'src/', 'src/main.go']);
+    expect(entries.find(e => e.name === 'src/main.go')?.content).toBe('package main\n');
+  });
+
+  it('should skip editor state and excluded directories by default', async () => {
+    await fs.mkdir(path.join(workspaceRoot, '.oneline-editor'));
+    await fs.writeFile(path.join(workspaceRoot, '.oneline-editor', 'bookmarks.json'), '[]');
+    await fs.mkdir(path.join(workspaceRoot, 'node_modules', 'dep'), { recursive: true });
+    await fs.writeFile(path.join(workspaceRoot, 'node_modules', 'dep', 'index.js'), '');
+    await fs.writeFile(path.join(workspaceRoot, 'index.ts'), 'export {};\n');
+
+    const output = path.join(outputDir, 'export.tar.gz');
+    await exportWorkspace(workspaceRoot, output);
+    expect(readTar(gunzipSync(await fs.readFile(output))).map(e => e.name)).toEqual(['index.ts']);
+
+    await exportWorkspace(workspaceRoot, output, { includeState: true });
+    expect(readTar(gunzipSync(await fs.readFile(output))).map(e => e.name))
+      .toEqual(['.oneline-editor/', '.oneline-editor/bookmarks.json', 'index.ts']);
+  });
+
+  it('should preserve long paths', async () => {
+    const longDir = path.join(workspaceRoot, 'a'.repeat(80), 'b'.repeat(80));
+    await fs.mkdir(longDir, { recursive: true });
+    const longName = 'c'.repeat(120) + '.txt';
+    await fs.writeFile(path.join(longDir, longName), 'deep');
+
+    const output = path.join(outputDir, 'export.tar.gz');
+    await exportWorkspace(workspaceRoot, output);
+    const names = readTar(gunzipSync(await fs.readFile(output))).map(e => e.name);
+    expect(names).toContain(`${'a'.repeat(80)}/${'b'.repeat(80)}/${longName}`);
+  });
+});
@@ -1,13 +1,35 @@
 "use client";
 
+import { AccessibilityManager } from "@/components/AccessibilityManager";
+import { AuditLogPanel } from "@/components/AuditLogPanel";
+import { BookmarksPanel } from "@/components/BookmarksPanel";
+import { CommentsPanel } from "@/components/CommentsPanel";
+import { ExtensionPanels } from "@/components/ExtensionPanels";
 import { FileTree, FileTreeNode } from "@/components/FileTree";
+import { GoModulesPanel } from "@/components/GoModulesPanel";
+import { HttpResponsePanel } from "@/components/HttpResponsePanel";
+import { NewProjectDialog } from "@/components/NewProjectDialog";
+import { NpmPanel } from "@/components/NpmPanel";
+import { OutputPanel } from "@/components/OutputPanel";
+import { PortsPanel } from "@/components/PortsPanel";
+import { PreviewPane } from "@/components/PreviewPane";
 import { ProblemsPanel } from "@/components/ProblemsPanel";
+import { SnapshotsPanel } from "@/components/SnapshotsPanel";
 import { StatusBar } from "@/components/StatusBar";
 import { ThemeManager } from "@/components/ThemeManager";
+import { TodoPanel } from "@/components/TodoPanel";
 import { TopBar } from "@/components/TopBar";
+import { api } from "@/lib/api";
+import { loadExtensions } from "@/lib/extensions/host";
+import { detectLanguage } from "@/lib/languages";
+import {
+  getOpenedLink,
+  openLinkedFile,
+  type OpenedLink,
+} from "@/lib/permalinks";
 import { useEditorStore } from "@/lib/store";
 import dynamic from "next/dynamic";
-import React, { useCallback, useEffect, useState } from "react";
+import React, { useCallback, useEffect, useRef, useState } from "react";
 
 const CodeEditor = dynamic(
   () => import("@/components/CodeEditor").then((mod) => mod.CodeEditor),
@@ -17,21 +39,40 @@ const CodeEditor = dynamic(
   },
 );
 
-// API configuration
-const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:3001";
-
-const getLanguageIdFromPath = (path: string): string => {
-  if (path.endsWith(".ts") || path.endsWith(".tsx")) return "typescript";
-  if (path.endsWith(".js") || path.endsWith(".jsx")) return "javascript";
-  if (path.endsWith(".go")) return "go";
-  if (path.endsWith(".json")) return "json";
-  if (path.endsWith(".md")) return "markdown";
-  return "plaintext";
-};
+const SnapshotViewer = dynamic(
+  () =>
+    import("@/components/SnapshotViewer").then((mod) => mod.SnapshotViewer),
+  { ssr: false },
+);
 
 export default function Page() {
-  const { editorManager, setCurrentFile, setCurrentLanguageId } =
-    useEditorStore();
+  // Snapshot links open a read-only viewer instead of the workspace. The URL
+  // is only known in the browser, so this is decided after mounting.
+  const [link, setLink] = useState<OpenedLink | null | undefined>(undefined);
+
+  useEffect(() => {
+    setLink(getOpenedLink() ?? null);
+  }, []);
+
+  if (link === undefined) return null;
+  if (link && "snapshot" in link) {
+    return (
+      <>
+        <ThemeManager />
+        <SnapshotViewer id={link.snapshot} range={link.range} />
+      </>
+    );
+  }
+  return <Workspace />;
+}
+
+function Workspace() {
+  const {
+    editorManager,
+    setCurrentFile,
+    setCurrentLanguageId,
+    fileTreeVersion,
+  } = useEditorStore();
   const [files, setFiles] = useState<FileTreeNode[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   // Store pending file to open when editorManager is not yet ready
@@ -41,21 +82,21 @@ export default function Page() {
     languageId: string;
   } | null>(null);
 
-  // Fetch file tree on mount
+  // Fetch file tree and load extensions on mount
   useEffect(() => {
     fetchFiles();
+    loadExtensions();
   }, []);
 
+  // Files created outside the Explorer, e.g. by Save As
+  useEffect(() => {
+    if (fileTreeVersion > 0) fetchFiles();
+  }, [fileTreeVersion]);
+
   const fetchFiles = async () => {
     setIsLoading(true);
     try {
-      const response = await fetch(`${API_BASE_URL}/api/files`);
-      if (response.ok) {
-        const fileTree = await response.json();
-        setFiles(fileTree);
-      } else {
-        console.error("Failed to fetch file tree");
-      }
+      setFiles(await api.listFiles());
     } catch (error) {
       console.error("Error fetching file tree:", error);
     } finally {
@@ -63,6 +104,15 @@ export default function Page() {
     }
   };
 
+  // A link to a file (?file=/src/main.go#L10-L20) opens it once the editor
+  // is ready
+  const hasOpenedLinkRef = useRef(false);
+  useEffect(() => {
+    if (!editorManager || hasOpenedLinkRef.current) return;
+    hasOpenedLinkRef.current = true;
+    void openLinkedFile();
+  }, [editorManager]);
+
   // Open pending file when editorManager becomes ready
   useEffect(() => {
     if (editorManager && pendingFile) {
@@ -77,18 +127,13 @@ export default function Page() {
 
   const handleFileSelect = useCallback(
     async (path: string) => {
-      const languageId = getLanguageIdFromPath(path);
+      const languageId = detectLanguage(path).id;
       setCurrentFile(path);
       setCurrentLanguageId(languageId);
 
       try {
         // Fetch file content from server
-        const response = await fetch(`${API_BASE_URL}/api/file${path}`);
-        if (!response.ok) {
-          console.error("Failed to fetch file content");
-          return;
-        }
-        const content = await response.text();
+        const { content } = await api.readFile({ path });
 
         if (!editorManager) {
           // Store pending file to open when editor is ready
@@ -108,6 +153,7 @@ export default function Page() {
   return (
     <main className="flex h-screen flex-col overflow-hidden bg-background">
       <ThemeManager />
+      <AccessibilityManager />
       <TopBar />
       <div className="flex flex-1 overflow-hidden">
         <FileTree
@@ -121,9 +167,22 @@ export default function Page() {
             <CodeEditor />
           </div>
           <ProblemsPanel />
+          <TodoPanel />
+          <BookmarksPanel />
+          <CommentsPanel />
+          <AuditLogPanel />
+          <GoModulesPanel />
+          <NpmPanel />
+          <OutputPanel />
+          <PortsPanel />
+          <HttpResponsePanel />
+          <SnapshotsPanel />
+          <ExtensionPanels />
         </div>
+        <PreviewPane />
       </div>
       <StatusBar />
+      <NewProjectDialog onCreated={fetchFiles} />
     </main>
   );
 }
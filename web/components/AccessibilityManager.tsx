@@ -0,0 +1,99 @@
+"use client";
+
+import {
+  ANNOUNCE_EVENT,
+  announce,
+  getStoredScreenReaderMode,
+  installFocusRegionShortcuts,
+  type Announcement,
+} from "@/lib/a11y";
+import { useEditorStore } from "@/lib/store";
+import { useEffect, useRef, useState } from "react";
+
+// Diagnostics arrive in bursts while typing; announce the totals once they settle
+const DIAGNOSTICS_ANNOUNCE_DELAY_MS = 1500;
+
+function pluralize(count: number, noun: string): string {
+  return `${count} ${noun}${count === 1 ? "" : "s"}`;
+}
+
+/**
+ * Screen reader live regions and F6 region navigation. Announces messages
+ * from the language servers and changes in the workspace's problem counts.
+ */
+export function AccessibilityManager() {
+  const setScreenReaderMode = useEditorStore(
+    (state) => state.setScreenReaderMode,
+  );
+  const diagnosticsByUri = useEditorStore((state) => state.diagnosticsByUri);
+  const [messages, setMessages] = useState<Record<Announcement["politeness"], string>>({
+    polite: "",
+    assertive: "",
+  });
+  const lastTotals = useRef<string | null>(null);
+
+  useEffect(() => {
+    setScreenReaderMode(getStoredScreenReaderMode());
+  }, [setScreenReaderMode]);
+
+  useEffect(() => installFocusRegionShortcuts(), []);
+
+  useEffect(() => {
+    const timers: Partial<
+      Record<Announcement["politeness"], ReturnType<typeof setTimeout>>
+    > = {};
+    const handleAnnounce = (event: Event) => {
+      const { message, politeness } = (event as CustomEvent<Announcement>)
+        .detail;
+      // Clear first so the same message is read again when repeated
+      setMessages((current) => ({ ...current, [politeness]: "" }));
+      clearTimeout(timers[politeness]);
+      timers[politeness] = setTimeout(
+        () => setMessages((current) => ({ ...current, [politeness]: message })),
+        50,
+      );
+    };
+    const handleLspNotification = (event: Event) => {
+      const { level, message } = (
+        event as CustomEvent<{ level: string; message: string }>
+      ).detail;
+      announce(message, level === "error" ? "assertive" : "polite");
+    };
+
+    window.addEventListener(ANNOUNCE_EVENT, handleAnnounce);
+    window.addEventListener("lsp-notification", handleLspNotification);
+    return () => {
+      Object.values(timers).forEach(clearTimeout);
+      window.removeEventListener(ANNOUNCE_EVENT, handleAnnounce);
+      window.removeEventListener("lsp-notification", handleLspNotification);
+    };
+  }, []);
+
+  useEffect(() => {
+    const summaries = Object.values(diagnosticsByUri);
+    const errors = summaries.reduce((acc, s) => acc + (s?.errors ?? 0), 0);
+    const warnings = summaries.reduce((acc, s) => acc + (s?.warnings ?? 0), 0);
+    const totals = `${pluralize(errors, "error")}, ${pluralize(warnings, "warning")}`;
+    if (lastTotals.current === null) {
+      lastTotals.current = totals;
+      return;
+    }
+    const timer = setTimeout(() => {
+      if (totals === lastTotals.current) return;
+      lastTotals.current = totals;
+      announce(`Problems: ${totals}`);
+    }, DIAGNOSTICS_ANNOUNCE_DELAY_MS);
+    return () => clearTimeout(timer);
+  }, [diagnosticsByUri]);
+
+  return (
+    <>
+      <div className="sr-only" role="status" aria-live="polite" aria-atomic="true">
+        {messages.polite}
+      </div>
+      <div className="sr-only" role="alert" aria-live="assertive" aria-atomic="true">
+        {messages.assertive}
+      </div>
+    </>
+  );
+}
@@ -0,0 +1,289 @@
+"use client";
+
+import {
+  api,
+  ApiRequestError,
+  describeApiError,
+  type AuditEntry,
+  type ListAuditEntriesQuery,
+} from "@/lib/api";
+import { openWorkspaceFile } from "@/lib/navigation";
+import { useEditorStore } from "@/lib/store";
+import { cn } from "@/lib/utils";
+import { RefreshCw, ScrollText, XCircle } from "lucide-react";
+import React, { useCallback, useEffect, useState } from "react";
+
+type AuditAction = AuditEntry["action"];
+
+const ACTIONS: AuditAction[] = [
+  "file.create",
+  "file.save",
+  "folder.create",
+  "path.delete",
+  "path.rename",
+  "project.create",
+  "settings.update",
+  "command.run",
+  "http.request",
+  "http.history.clear",
+  "task.kill",
+  "snapshot.create",
+  "snapshot.revoke",
+  "edit.apply",
+];
+
+const actionColor: Partial<Record<AuditAction, string>> = {
+  "path.delete": "text-red-500",
+  "path.rename": "text-amber-500",
+  "file.create": "text-emerald-500",
+  "folder.create": "text-emerald-500",
+  "project.create": "text-blue-500",
+  "command.run": "text-blue-500",
+  "http.request": "text-blue-500",
+  "http.history.clear": "text-red-500",
+  "task.kill": "text-red-500",
+  "snapshot.create": "text-emerald-500",
+  "snapshot.revoke": "text-red-500",
+  "edit.apply": "text-amber-500",
+};
+
+const PAGE_SIZE = 100;
+
+function formatTime(timestamp: string): string {
+  const date = new Date(timestamp);
+  return date.toLocaleString(undefined, {
+    month: "short",
+    day: "numeric",
+    hour: "2-digit",
+    minute: "2-digit",
+    second: "2-digit",
+  });
+}
+
+function describeEntry(entry: AuditEntry): string {
+  if (entry.action === "path.rename" && entry.details?.newPath) {
+    return `${entry.path} → ${entry.details.newPath}`;
+  }
+  if (entry.action === "settings.update" && entry.details?.setting) {
+    return String(entry.details.setting);
+  }
+  if (entry.action === "command.run" && entry.details?.command) {
+    return `${entry.path ?? ""} $ ${entry.details.command}`;
+  }
+  if (entry.action === "task.kill" && entry.details?.command) {
+    return `${entry.details.command} (${entry.details.task})`;
+  }
+  if (entry.action === "snapshot.create" && entry.details?.files) {
+    return `${(entry.details.files as string[]).join(", ")} (${entry.details.id})`;
+  }
+  if (entry.action === "edit.apply" && entry.details?.operation) {
+    return `${entry.path} (before ${entry.details.operation})`;
+  }
+  if (entry.action === "http.request" && entry.details?.url) {
+    return `${entry.details.method} ${entry.details.url} → ${entry.details.status}`;
+  }
+  return entry.path ?? "";
+}
+
+export function AuditLogPanel() {
+  const { isAuditLogOpen, setAuditLogOpen } = useEditorStore();
+  const [entries, setEntries] = useState<AuditEntry[]>([]);
+  const [hasMore, setHasMore] = useState(false);
+  const [isLoading, setIsLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [user, setUser] = useState("");
+  const [action, setAction] = useState<AuditAction | "">("");
+  const [path, setPath] = useState("");
+
+  const load = useCallback(
+    async (before?: number) => {
+      setIsLoading(true);
+      setError(null);
+      const query: ListAuditEntriesQuery = { limit: PAGE_SIZE, before };
+      if (user.trim()) query.user = user.trim();
+      if (action) query.action = action;
+      if (path.trim()) {
+        query.path = path.trim().startsWith("/") ? path.trim() : `/${path.trim()}`;
+      }
+
+      try {
+        const result = await api.listAuditEntries(query);
+        setEntries((prev) =>
+          before === undefined ? result.entries : [...prev, ...result.entries],
+        );
+        setHasMore(result.hasMore);
+      } catch (err) {
+        console.error("Error loading audit log:", err);
+        setError(
+          err instanceof ApiRequestError && err.status === 403
+            ? "The audit log is only available to administrators."
+            : describeApiError(err),
+        );
+      } finally {
+        setIsLoading(false);
+      }
+    },
+    [user, action, path],
+  );
+
+  // Reload when opened or when the action filter changes; text filters apply on Enter
+  // biome-ignore lint/correctness/useExhaustiveDependencies: text filters are applied explicitly
+  useEffect(() => {
+    if (isAuditLogOpen) load();
+  }, [isAuditLogOpen, action]);
+
+  if (!isAuditLogOpen) return null;
+
+  const handleFilterKeyDown = (event: React.KeyboardEvent<HTMLInputElement>) => {
+    if (event.key === "Enter") load();
+  };
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Audit Log"
+      data-focus-region="Audit Log"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Audit Log
+          </span>
+          <span className="flex items-center gap-1 text-muted-foreground">
+            <ScrollText className="h-3.5 w-3.5" />
+            <span className="tabular-nums">
+              {entries.length}
+              {hasMore ? "+" : ""}
+            </span>
+          </span>
+          <div className="flex items-center gap-1">
+            <input
+              className="w-28 rounded border bg-background px-1.5 py-[1px] text-[11px]"
+              placeholder="User"
+              value={user}
+              onChange={(e) => setUser(e.target.value)}
+              onKeyDown={handleFilterKeyDown}
+              aria-label="Filter by user"
+            />
+            <select
+              className="rounded border bg-background px-1 py-[1px] text-[11px]"
+              value={action}
+              onChange={(e) => setAction(e.target.value as AuditAction | "")}
+              aria-label="Filter by action"
+            >
+              <option value="">All actions</option>
+              {ACTIONS.map((value) => (
+                <option key={value} value={value}>
+                  {value}
+                </option>
+              ))}
+            </select>
+            <input
+              className="w-40 rounded border bg-background px-1.5 py-[1px] font-mono text-[11px]"
+              placeholder="Path or folder"
+              value={path}
+              onChange={(e) => setPath(e.target.value)}
+              onKeyDown={handleFilterKeyDown}
+              aria-label="Filter by path"
+            />
+          </div>
+        </div>
+        <div className="flex items-center gap-1">
+          <button
+            type="button"
+            onClick={() => load()}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            title="Refresh"
+          >
+            <RefreshCw className={cn("h-4 w-4", isLoading && "animate-spin")} />
+          </button>
+          <button
+            type="button"
+            onClick={() => setAuditLogOpen(false)}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            aria-label="Close Audit Log"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {error ? (
+          <div className="px-4 py-3 text-red-500">{error}</div>
+        ) : entries.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            {isLoading ? "Loading..." : "No matching audit entries."}
+          </div>
+        ) : (
+          <table className="w-full border-collapse text-left">
+            <thead className="sticky top-0 bg-background text-[11px] uppercase tracking-wide text-muted-foreground">
+              <tr>
+                <th className="px-3 py-1 font-medium">Time</th>
+                <th className="px-2 py-1 font-medium">User</th>
+                <th className="px-2 py-1 font-medium">Client</th>
+                <th className="px-2 py-1 font-medium">Action</th>
+                <th className="px-2 py-1 font-medium">Path</th>
+              </tr>
+            </thead>
+            <tbody>
+              {entries.map((entry) => (
+                <tr key={entry.id} className="hover:bg-muted/40">
+                  <td className="px-3 py-0.5 whitespace-nowrap tabular-nums text-muted-foreground">
+                    {formatTime(entry.timestamp)}
+                  </td>
+                  <td className="px-2 py-0.5 whitespace-nowrap">{entry.user}</td>
+                  <td
+                    className="px-2 py-0.5 max-w-[10rem] truncate font-mono text-xs text-muted-foreground"
+                    title={entry.client}
+                  >
+                    {entry.client}
+                  </td>
+                  <td
+                    className={cn(
+                      "px-2 py-0.5 whitespace-nowrap font-mono text-xs",
+                      actionColor[entry.action] ?? "text-foreground",
+                    )}
+                  >
+                    {entry.action}
+                  </td>
+                  <td className="px-2 py-0.5 font-mono text-xs">
+                    {entry.path && entry.action !== "path.delete" ? (
+                      <button
+                        type="button"
+                        className="hover:underline text-left"
+                        onClick={() =>
+                          openWorkspaceFile(
+                            String(entry.details?.newPath ?? entry.path),
+                          )
+                        }
+                      >
+                        {describeEntry(entry)}
+                      </button>
+                    ) : (
+                      describeEntry(entry)
+                    )}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+        {hasMore && !error && (
+          <button
+            type="button"
+            className="w-full py-1 text-xs text-muted-foreground hover:bg-muted/40 hover:text-foreground"
+            onClick={() => load(entries[entries.length - 1]?.id)}
+            disabled={isLoading}
+          >
+            Load older entries
+          </button>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,107 @@
+"use client";
+
+import { fetchBookmarks, labelBookmark, removeBookmark } from "@/lib/bookmarks";
+import { openWorkspaceFile } from "@/lib/navigation";
+import { useEditorStore } from "@/lib/store";
+import { Bookmark as BookmarkIcon, Edit2, Trash2, XCircle } from "lucide-react";
+import React, { useEffect } from "react";
+
+export function BookmarksPanel() {
+  const { bookmarks, isBookmarksOpen, setBookmarksOpen } = useEditorStore();
+
+  useEffect(() => {
+    fetchBookmarks();
+  }, []);
+
+  if (!isBookmarksOpen) return null;
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Bookmarks"
+      data-focus-region="Bookmarks"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Bookmarks
+          </span>
+          <span className="flex items-center gap-1 text-muted-foreground">
+            <BookmarkIcon className="h-3.5 w-3.5" />
+            <span className="tabular-nums">{bookmarks.length}</span>
+          </span>
+        </div>
+        <button
+          type="button"
+          onClick={() => setBookmarksOpen(false)}
+          className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+          aria-label="Close Bookmarks"
+        >
+          <XCircle className="h-4 w-4" />
+        </button>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {bookmarks.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No bookmarks yet. Use Toggle Bookmark (Ctrl+Alt+K) in the editor to add one.
+          </div>
+        ) : (
+          <div className="py-1">
+            {bookmarks.map((bookmark) => (
+              <div
+                key={bookmark.id}
+                className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40 cursor-pointer"
+                onClick={() => openWorkspaceFile(bookmark.path, bookmark.line)}
+              >
+                <BookmarkIcon className="h-4 w-4 flex-shrink-0 text-blue-500" />
+                <span className="flex-1 min-w-0 truncate">
+                  {bookmark.label ? (
+                    <>
+                      <span className="text-foreground">{bookmark.label}</span>
+                      <span className="text-muted-foreground ml-2">{bookmark.path}</span>
+                    </>
+                  ) : (
+                    <span className="text-foreground">{bookmark.path}</span>
+                  )}
+                </span>
+                <span className="text-muted-foreground tabular-nums flex-shrink-0 text-xs">
+                  [{bookmark.line}]
+                </span>
+                <button
+                  type="button"
+                  className="rounded p-0.5 opacity-0 group-hover:opacity-100 hover:bg-muted"
+                  title="Edit Label"
+                  onClick={(event) => {
+                    event.stopPropagation();
+                    const label = prompt("Bookmark label:", bookmark.label ?? "");
+                    if (label !== null) {
+                      labelBookmark(bookmark.id, label);
+                    }
+                  }}
+                >
+                  <Edit2 className="h-3.5 w-3.5" />
+                </button>
+                <button
+                  type="button"
+                  className="rounded p-0.5 opacity-0 group-hover:opacity-100 hover:bg-muted"
+                  title="Remove Bookmark"
+                  onClick={(event) => {
+                    event.stopPropagation();
+                    removeBookmark(bookmark.id);
+                  }}
+                >
+                  <Trash2 className="h-3.5 w-3.5" />
+                </button>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -1,78 +1,427 @@
 "use client";
 
+import { announce } from "@/lib/a11y";
+import { labelBookmark, toggleBookmark } from "@/lib/bookmarks";
+import {
+  addCommentAtSelection,
+  fetchCommentThreads,
+  toggleThreadsAtLine,
+  watchCommentThreads,
+} from "@/lib/comments";
+import {
+  clearCoverage,
+  fetchCoverage,
+  getFileCoverage,
+  importCoverage,
+} from "@/lib/coverage";
 import { EditorManager } from "@/lib/editor/manager";
+import { disableBuiltinTypeScriptFeatures } from "@/lib/editor/setup";
+import { executeCommand } from "@/lib/extensions/host";
+import {
+  getActiveExtensionTheme,
+  useExtensionStore,
+} from "@/lib/extensions/registry";
+import { watchFileOperations } from "@/lib/fileOperations";
+import { registerHttpLanguage, sendRequestAtCursor } from "@/lib/httpRequests";
+import {
+  fetchLintDiagnostics,
+  lintFile,
+  watchLintDiagnostics,
+} from "@/lib/linters";
 import { FrontendLSPManager } from "@/lib/lsp/client";
+import {
+  openWorkspaceFile,
+  registerWorkspaceOpener,
+  uriToWorkspacePath,
+  watchShowDocument,
+} from "@/lib/navigation";
+import { copyLinkToSelection } from "@/lib/permalinks";
+import { fetchPorts, watchPorts } from "@/lib/ports";
+import { configureRun, runCurrentFile, watchRunProblems } from "@/lib/run";
+import { associateSchema, isWorkspaceSchema, loadSchemas } from "@/lib/schemas";
+import { shareCurrentFile, shareOpenFiles } from "@/lib/snapshots";
 import { useEditorStore } from "@/lib/store";
-import Editor, { Monaco, loader } from "@monaco-editor/react";
+import { getMonacoTheme } from "@/lib/theme";
+import { newUntitledDocument, saveUntitledAs } from "@/lib/untitled";
+import { isUntitledUri } from "@/lib/uris";
+import Editor, { Monaco } from "@monaco-editor/react";
 import * as monaco from "monaco-editor";
-import React, { useCallback, useEffect, useRef } from "react";
+import React, { useCallback, useEffect, useRef, useState } from "react";
+import { CommentThreads } from "./CommentThreads";
 
-type MonacoEnvironmentShape = typeof self & {
-  MonacoEnvironment?: {
-    getWorker: (_: string, label: string) => Worker;
-  };
-};
-
-// Configure Monaco loader to use local files instead of CDN and register workers
-if (typeof window !== "undefined") {
-  const globalSelf = self as MonacoEnvironmentShape;
-
-  if (!globalSelf.MonacoEnvironment) {
-    globalSelf.MonacoEnvironment = {
-      getWorker(_moduleId, label) {
-        const workerOptions: WorkerOptions = { type: "module" };
-        switch (label) {
-          case "json":
-            return new Worker(
-              new URL(
-                "monaco-editor/esm/vs/language/json/json.worker.js",
-                import.meta.url,
-              ),
-              workerOptions,
-            );
-          case "css":
-          case "scss":
-          case "less":
-            return new Worker(
-              new URL(
-                "monaco-editor/esm/vs/language/css/css.worker.js",
-                import.meta.url,
-              ),
-              workerOptions,
-            );
-          case "html":
-          case "handlebars":
-          case "razor":
-            return new Worker(
-              new URL(
-                "monaco-editor/esm/vs/language/html/html.worker.js",
-                import.meta.url,
-              ),
-              workerOptions,
-            );
-          case "typescript":
-          case "javascript":
-            return new Worker(
-              new URL(
-                "monaco-editor/esm/vs/language/typescript/ts.worker.js",
-                import.meta.url,
-              ),
-              workerOptions,
-            );
-          default:
-            return new Worker(
-              new URL(
-                "monaco-editor/esm/vs/editor/editor.worker.js",
-                import.meta.url,
-              ),
-              workerOptions,
-            );
-        }
-      },
+/**
+ * Jump to the next (or previous) bookmark relative to the cursor, wrapping around
+ */
+function goToAdjacentBookmark(
+  editor: monaco.editor.IStandaloneCodeEditor,
+  direction: 1 | -1,
+): void {
+  const { bookmarks } = useEditorStore.getState();
+  if (bookmarks.length === 0) return;
+
+  const model = editor.getModel();
+  const currentPath = model ? uriToWorkspacePath(model.uri.toString()) : "";
+  const currentLine = editor.getPosition()?.lineNumber ?? 0;
+  const compare = (path: string, line: number) =>
+    path.localeCompare(currentPath) || line - currentLine;
+
+  const sorted = [...bookmarks].sort(
+    (a, b) => a.path.localeCompare(b.path) || a.line - b.line,
+  );
+  const target =
+    direction === 1
+      ? sorted.find((b) => compare(b.path, b.line) > 0) ?? sorted[0]
+      : [...sorted].reverse().find((b) => compare(b.path, b.line) < 0) ??
+        sorted[sorted.length - 1];
+
+  openWorkspaceFile(target.path, target.line);
+}
+
+function registerBookmarkActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  const getCursor = () => {
+    const model = editor.getModel();
+    const position = editor.getPosition();
+    if (!model || !position) return null;
+    return {
+      path: uriToWorkspacePath(model.uri.toString()),
+      line: position.lineNumber,
     };
-  }
+  };
+
+  editor.addAction({
+    id: "bookmarks.toggle",
+    label: "Toggle Bookmark",
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyK,
+    ],
+    contextMenuGroupId: "navigation",
+    run: () => {
+      const cursor = getCursor();
+      if (cursor) {
+        void toggleBookmark(cursor.path, cursor.line);
+      }
+    },
+  });
+
+  editor.addAction({
+    id: "bookmarks.label",
+    label: "Label Bookmark...",
+    run: () => {
+      const cursor = getCursor();
+      if (!cursor) return;
+      const existing = useEditorStore
+        .getState()
+        .bookmarks.find((b) => b.path === cursor.path && b.line === cursor.line);
+      const label = prompt("Bookmark label:", existing?.label ?? "");
+      if (label === null) return;
+      if (existing) {
+        void labelBookmark(existing.id, label);
+      } else {
+        void toggleBookmark(cursor.path, cursor.line, label);
+      }
+    },
+  });
+
+  editor.addAction({
+    id: "bookmarks.next",
+    label: "Go to Next Bookmark",
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyL,
+    ],
+    run: () => goToAdjacentBookmark(editor, 1),
+  });
+
+  editor.addAction({
+    id: "bookmarks.previous",
+    label: "Go to Previous Bookmark",
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyJ,
+    ],
+    run: () => goToAdjacentBookmark(editor, -1),
+  });
+
+  editor.addAction({
+    id: "bookmarks.list",
+    label: "Show Bookmarks",
+    run: () => useEditorStore.getState().setBookmarksOpen(true),
+  });
+
+  editor.addAction({
+    id: "todo.show",
+    label: "Show TODOs",
+    run: () => useEditorStore.getState().setTodoOpen(true),
+  });
+
+  editor.addAction({
+    id: "goModules.show",
+    label: "Show Go Modules",
+    run: () => useEditorStore.getState().setGoModulesOpen(true),
+  });
+
+  editor.addAction({
+    id: "npm.show",
+    label: "Show NPM Scripts",
+    run: () => useEditorStore.getState().setNpmOpen(true),
+  });
+}
+
+function registerSchemaActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "schemas.associate",
+    label: "Associate JSON Schema with Current File...",
+    run: async () => {
+      const model = editor.getModel();
+      if (!model) return;
+      const index = await loadSchemas();
+      const choices = index?.schemas.map((schema) => schema.id).join(", ");
+      const schema = prompt(
+        `Schema ID or workspace path of a schema file (${choices ?? "none loaded"}):`,
+      );
+      if (!schema?.trim()) return;
+      await associateSchema(
+        uriToWorkspacePath(model.uri.toString()),
+        schema.trim(),
+      );
+    },
+  });
+
+  editor.addAction({
+    id: "schemas.reload",
+    label: "Reload JSON Schemas",
+    run: () => {
+      void loadSchemas();
+    },
+  });
+}
+
+function registerCoverageActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "coverage.toggle",
+    label: "Toggle Coverage Overlay",
+    run: async () => {
+      const { coverage, isCoverageVisible, setCoverageVisible } =
+        useEditorStore.getState();
+      if (!isCoverageVisible && !coverage?.source) {
+        // Nothing imported yet; try the usual report locations
+        await importCoverage();
+        return;
+      }
+      setCoverageVisible(!isCoverageVisible);
+    },
+  });
 
-  loader.config({ monaco });
+  editor.addAction({
+    id: "coverage.load",
+    label: "Load Coverage Report...",
+    run: async () => {
+      const path = prompt(
+        "Workspace path of a coverage report (go test -coverprofile, lcov.info or coverage-final.json); leave empty to look for one:",
+        useEditorStore.getState().coverage?.source ?? "",
+      );
+      if (path === null) return;
+      await importCoverage(path.trim() || undefined);
+    },
+  });
+
+  editor.addAction({
+    id: "coverage.clear",
+    label: "Clear Coverage",
+    run: () => clearCoverage(),
+  });
+}
+
+function registerAccessibilityActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "accessibility.toggleScreenReaderMode",
+    label: "Toggle Screen Reader Optimized Mode",
+    keybindings: [monaco.KeyMod.CtrlCmd | monaco.KeyCode.KeyE],
+    run: () => {
+      const { isScreenReaderMode, setScreenReaderMode } =
+        useEditorStore.getState();
+      setScreenReaderMode(!isScreenReaderMode);
+      announce(
+        `Screen reader optimized mode ${isScreenReaderMode ? "off" : "on"}`,
+        "assertive",
+      );
+    },
+  });
+}
+
+function registerLintActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "lint.currentFile",
+    label: "Lint Current File",
+    run: async () => {
+      const model = editor.getModel();
+      if (!model) return;
+      await lintFile(uriToWorkspacePath(model.uri.toString()));
+    },
+  });
+}
+
+function registerUntitledActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "file.newUntitled",
+    label: "New Untitled File",
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyN,
+    ],
+    run: () => {
+      newUntitledDocument();
+    },
+  });
+
+  editor.addAction({
+    id: "file.saveAs",
+    label: "Save As...",
+    run: async () => {
+      const model = editor.getModel();
+      if (!model) return;
+      if (!isUntitledUri(model.uri.toString())) {
+        announce("Only untitled files can be saved as a new file");
+        return;
+      }
+      await saveUntitledAs(model.uri.toString());
+    },
+  });
+}
+
+function registerRunActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "run.currentFile",
+    label: "Run Current File",
+    keybindings: [monaco.KeyMod.CtrlCmd | monaco.KeyCode.F5],
+    run: async () => {
+      await runCurrentFile();
+    },
+  });
+
+  editor.addAction({
+    id: "run.configure",
+    label: "Set Run Arguments and Environment...",
+    run: () => {
+      const model = editor.getModel();
+      if (model) configureRun(model.uri.toString());
+    },
+  });
+
+  editor.addAction({
+    id: "ports.show",
+    label: "Show Ports",
+    run: () => useEditorStore.getState().setPortsOpen(true),
+  });
+
+  editor.addAction({
+    id: "run.showOutput",
+    label: "Show Output",
+    run: () => useEditorStore.getState().setOutputOpen(true),
+  });
+}
+
+function registerHttpActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "http.sendRequest",
+    label: "Send Request",
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyR,
+    ],
+    run: async () => {
+      await sendRequestAtCursor(editor);
+    },
+  });
+
+  editor.addAction({
+    id: "http.showResponse",
+    label: "Show HTTP Response",
+    run: () => useEditorStore.getState().setHttpOpen(true),
+  });
+}
+
+function registerShareActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "share.copyLink",
+    label: "Copy Link to Selection",
+    contextMenuGroupId: "9_cutcopypaste",
+    contextMenuOrder: 10,
+    run: async () => {
+      await copyLinkToSelection(editor);
+    },
+  });
+
+  editor.addAction({
+    id: "share.snapshot",
+    label: "Share Snapshot...",
+    run: async () => {
+      await shareCurrentFile(editor);
+    },
+  });
+
+  editor.addAction({
+    id: "share.snapshotOpenFiles",
+    label: "Share Snapshot of Open Files...",
+    run: async () => {
+      await shareOpenFiles();
+    },
+  });
+
+  editor.addAction({
+    id: "share.showSnapshots",
+    label: "Show Shared Snapshots",
+    run: () => useEditorStore.getState().setSnapshotsOpen(true),
+  });
+}
+
+function registerCommentActions(
+  editor: monaco.editor.IStandaloneCodeEditor,
+): void {
+  editor.addAction({
+    id: "comments.add",
+    label: "Add Comment...",
+    contextMenuGroupId: "navigation",
+    contextMenuOrder: 10,
+    keybindings: [
+      monaco.KeyMod.CtrlCmd | monaco.KeyMod.Alt | monaco.KeyCode.KeyM,
+    ],
+    run: () => addCommentAtSelection(editor),
+  });
+
+  editor.addAction({
+    id: "comments.toggleLine",
+    label: "Toggle Comments on Line",
+    run: () => {
+      const model = editor.getModel();
+      const line = editor.getPosition()?.lineNumber;
+      if (!model || !line) return;
+      const path = uriToWorkspacePath(model.uri.toString());
+      if (!toggleThreadsAtLine(path, line)) {
+        announce("No comments on this line");
+      }
+    },
+  });
+
+  editor.addAction({
+    id: "comments.list",
+    label: "Show Comments",
+    run: () => useEditorStore.getState().setCommentsOpen(true),
+  });
 }
 
 export function CodeEditor() {
@@ -83,13 +432,30 @@ export function CodeEditor() {
     setLSPManager,
     setIsConnected,
     resolvedTheme,
+    bookmarks,
+    coverage,
+    isCoverageVisible,
+    isScreenReaderMode,
   } = useEditorStore();
+  const extensionCommands = useExtensionStore((state) => state.commands);
+  const extensionTheme = useExtensionStore(getActiveExtensionTheme);
   const editorRef = useRef<monaco.editor.IStandaloneCodeEditor | null>(null);
   const monacoRef = useRef<Monaco | null>(null);
+  const bookmarkDecorationsRef =
+    useRef<monaco.editor.IEditorDecorationsCollection | null>(null);
+  const coverageDecorationsRef =
+    useRef<monaco.editor.IEditorDecorationsCollection | null>(null);
+  const [activeModelUri, setActiveModelUri] = useState<string | null>(null);
 
   const handleSave = useCallback(async () => {
     const model = editorManager?.getCurrentModel();
-    if (!model) return;
+    if (!model || editorManager?.isReadOnly(model.uri.toString())) return;
+
+    // Untitled buffers have no file yet; saving asks where to put one
+    if (isUntitledUri(model.uri.toString())) {
+      await saveUntitledAs(model.uri.toString());
+      return;
+    }
 
     try {
       await lspManager?.formatDocument(editorManager, model);
@@ -102,6 +468,11 @@ export function CodeEditor() {
 
     // Send didSave to LSP server so diagnostics stay up-to-date
     lspManager?.didSaveTextDocument(uri, content);
+    useEditorStore.getState().reloadPreview();
+
+    if (isWorkspaceSchema(uriToWorkspacePath(uri))) {
+      void loadSchemas();
+    }
   }, [editorManager, lspManager]);
 
   const handleEditorDidMount = (
@@ -110,21 +481,25 @@ export function CodeEditor() {
   ) => {
     editorRef.current = editor;
     monacoRef.current = monacoInstance;
-    const disableDefaultTSFeatures = {
-      completionItems: false,
-      hovers: false,
-      documentSymbols: false,
-      definitions: false,
-      references: false,
-      documentHighlights: false,
-      rename: false,
-      diagnostics: false,
-      documentRangeFormattingEdits: false,
-      signatureHelp: false,
-      onTypeFormattingEdits: false,
-      codeActions: false,
-      inlayHints: false,
-    };
+
+    bookmarkDecorationsRef.current = editor.createDecorationsCollection();
+    coverageDecorationsRef.current = editor.createDecorationsCollection();
+    editor.onDidChangeModel(() => {
+      setActiveModelUri(editor.getModel()?.uri.toString() ?? null);
+    });
+    registerBookmarkActions(editor);
+    registerSchemaActions(editor);
+    registerCoverageActions(editor);
+    registerLintActions(editor);
+    registerAccessibilityActions(editor);
+    registerUntitledActions(editor);
+    registerRunActions(editor);
+    registerHttpActions(editor);
+    registerShareActions(editor);
+    registerCommentActions(editor);
+    registerHttpLanguage(monacoInstance);
+    registerWorkspaceOpener(monacoInstance);
+    void fetchCoverage();
 
     // Initialize Managers
     const editorManager = new EditorManager();
@@ -156,12 +531,30 @@ export function CodeEditor() {
     );
 
     // Disable built-in JS/TS features so completion/diagnostics rely on LSP only
-    monacoInstance.languages.typescript.typescriptDefaults.setModeConfiguration(
-      disableDefaultTSFeatures,
-    );
-    monacoInstance.languages.typescript.javascriptDefaults.setModeConfiguration(
-      disableDefaultTSFeatures,
-    );
+    disableBuiltinTypeScriptFeatures(monacoInstance);
+
+    // JSON is validated by Monaco against the server's schema registry
+    void loadSchemas();
+
+    // CSS and HTML have language servers; keep only Monaco's highlighting,
+    // folding and colour features for them
+    for (const defaults of [
+      monacoInstance.languages.css.cssDefaults,
+      monacoInstance.languages.css.scssDefaults,
+      monacoInstance.languages.css.lessDefaults,
+    ]) {
+      defaults.setModeConfiguration({
+        colors: true,
+        foldingRanges: true,
+        selectionRanges: true,
+      });
+    }
+    monacoInstance.languages.html.htmlDefaults.setModeConfiguration({
+      links: true,
+      colors: true,
+      foldingRanges: true,
+      selectionRanges: true,
+    });
   };
 
   useEffect(() => {
@@ -194,15 +587,172 @@ export function CodeEditor() {
     };
   }, [handleSave]);
 
+  // List extension commands in the command palette (F1)
+  useEffect(() => {
+    const editor = editorRef.current;
+    if (!editor || !editorManager) return;
+
+    const actions = extensionCommands.map((command) =>
+      editor.addAction({
+        id: command.id,
+        label: `${command.extension}: ${command.title}`,
+        run: () => {
+          executeCommand(command.id).catch(() => {});
+        },
+      }),
+    );
+    return () => actions.forEach((action) => action.dispose());
+  }, [extensionCommands, editorManager]);
+
+  // Show bookmarks of the active file in the glyph margin
+  useEffect(() => {
+    const collection = bookmarkDecorationsRef.current;
+    if (!collection) return;
+    if (!activeModelUri) {
+      collection.clear();
+      return;
+    }
+
+    const path = uriToWorkspacePath(activeModelUri);
+    collection.set(
+      bookmarks
+        .filter((bookmark) => bookmark.path === path)
+        .map((bookmark) => ({
+          range: new monaco.Range(bookmark.line, 1, bookmark.line, 1),
+          options: {
+            isWholeLine: true,
+            glyphMarginClassName: "bookmark-glyph",
+            glyphMarginHoverMessage: {
+              value: bookmark.label ? `Bookmark: ${bookmark.label}` : "Bookmark",
+            },
+          },
+        })),
+    );
+  }, [bookmarks, activeModelUri]);
+
+  // Review comment threads, re-anchored by the server as files change
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchCommentThreads(lspManager);
+    void fetchCommentThreads();
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Reload coverage when a report is imported, e.g. after a test run
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = lspManager.onNotification(
+      "workspace/coverageChanged",
+      () => {
+        void fetchCoverage();
+      },
+    );
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // External linter diagnostics, updated as linters run on save
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchLintDiagnostics(lspManager);
+    void fetchLintDiagnostics();
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Open files follow renames and deletes, and pick up import updates
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchFileOperations(lspManager);
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Open documents and links language servers and plugins ask to show
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchShowDocument(lspManager);
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Compile errors and stack frames from Run Current File
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchRunProblems(lspManager);
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Ports of dev servers started from the workspace, for the preview
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = watchPorts(lspManager);
+    void fetchPorts();
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  // Mark covered and uncovered lines of the active file next to the line numbers
+  useEffect(() => {
+    const collection = coverageDecorationsRef.current;
+    if (!collection) return;
+    if (!activeModelUri || !isCoverageVisible || !coverage?.source) {
+      collection.clear();
+      return;
+    }
+
+    let cancelled = false;
+    getFileCoverage(uriToWorkspacePath(activeModelUri)).then((file) => {
+      if (cancelled) return;
+      const lineDecoration = (line: number, covered: boolean) => ({
+        range: new monaco.Range(line, 1, line, 1),
+        options: {
+          isWholeLine: true,
+          linesDecorationsClassName: covered
+            ? "coverage-covered"
+            : "coverage-uncovered",
+          overviewRuler: covered
+            ? undefined
+            : {
+                color: "rgba(239, 68, 68, 0.6)",
+                position: monaco.editor.OverviewRulerLane.Left,
+              },
+        },
+      });
+      collection.set(
+        file
+          ? [
+              ...file.covered.map((line) => lineDecoration(line, true)),
+              ...file.uncovered.map((line) => lineDecoration(line, false)),
+            ]
+          : [],
+      );
+    });
+    return () => {
+      cancelled = true;
+    };
+  }, [coverage, isCoverageVisible, activeModelUri]);
+
   return (
-    <div className="h-full w-full overflow-hidden rounded-md border bg-background">
+    <div
+      className="h-full w-full overflow-hidden rounded-md border bg-background"
+      role="region"
+      aria-label="Editor"
+      data-focus-region="Editor"
+      data-focus-self
+      tabIndex={-1}
+      onFocus={(event) => {
+        // F6 focuses the region; hand focus to the text area
+        if (event.target === event.currentTarget) {
+          editorRef.current?.focus();
+        }
+      }}
+    >
       <Editor
         height="100%"
         defaultLanguage="typescript"
-        theme={resolvedTheme === "dark" ? "vs-dark" : "vs"}
+        theme={extensionTheme?.id ?? getMonacoTheme(resolvedTheme)}
         onMount={handleEditorDidMount}
         options={{
+          accessibilitySupport: isScreenReaderMode ? "on" : "auto",
+          ariaLabel: "Editor content",
           minimap: { enabled: true },
+          glyphMargin: true,
           fontSize: 14,
           automaticLayout: true,
           padding: { top: 10 },
@@ -213,6 +763,7 @@ export function CodeEditor() {
           },
         }}
       />
+      <CommentThreads editor={editorRef.current} modelUri={activeModelUri} />
     </div>
   );
 }
@@ -0,0 +1,370 @@
+"use client";
+
+import type { CommentThread } from "@/lib/api";
+import {
+  deleteThread,
+  replyToThread,
+  setThreadStatus,
+  submitDraft,
+  useCommentsStore,
+  type CommentDraft,
+} from "@/lib/comments";
+import { uriToWorkspacePath } from "@/lib/navigation";
+import { cn } from "@/lib/utils";
+import { Check, RotateCcw, Trash2, XCircle } from "lucide-react";
+import * as monaco from "monaco-editor";
+import React, { useEffect, useMemo, useRef, useState } from "react";
+import { createPortal } from "react-dom";
+
+const DRAFT_KEY = "draft";
+const MAX_HOVER_LENGTH = 200;
+
+interface ZoneItem {
+  key: string;
+  afterLineNumber: number;
+  thread?: CommentThread;
+  draft?: CommentDraft;
+}
+
+interface Zone {
+  id: string;
+  content: HTMLDivElement;
+  zone: monaco.editor.IViewZone;
+  observer: ResizeObserver;
+}
+
+function formatDate(timestamp: string): string {
+  return new Date(timestamp).toLocaleString(undefined, {
+    month: "short",
+    day: "numeric",
+    hour: "2-digit",
+    minute: "2-digit",
+  });
+}
+
+function formatLines(startLine: number, endLine: number): string {
+  return endLine > startLine
+    ? `Lines ${startLine}–${endLine}`
+    : `Line ${startLine}`;
+}
+
+/**
+ * Review comment threads of the file in the editor: an icon in the glyph
+ * margin on each thread's first line, and expanded threads and the draft as
+ * view zones below their lines
+ */
+export function CommentThreads({
+  editor,
+  modelUri,
+}: {
+  editor: monaco.editor.IStandaloneCodeEditor | null;
+  modelUri: string | null;
+}) {
+  const { threads, expanded, draft, setExpanded } = useCommentsStore();
+  const path = modelUri ? uriToWorkspacePath(modelUri) : null;
+  const fileThreads = useMemo(
+    () => threads.filter((thread) => thread.path === path),
+    [threads, path],
+  );
+  const decorationsRef =
+    useRef<monaco.editor.IEditorDecorationsCollection | null>(null);
+  const zonesRef = useRef(new Map<string, Zone>());
+  const [portals, setPortals] = useState<
+    Array<{ item: ZoneItem; content: HTMLDivElement }>
+  >([]);
+
+  const items = useMemo(() => {
+    const result: ZoneItem[] = fileThreads
+      .filter((thread) => expanded.includes(thread.id))
+      .map((thread) => ({
+        key: thread.id,
+        afterLineNumber: thread.endLine,
+        thread,
+      }));
+    if (draft && draft.path === path) {
+      result.push({ key: DRAFT_KEY, afterLineNumber: draft.endLine, draft });
+    }
+    return result;
+  }, [fileThreads, expanded, draft, path]);
+
+  // Gutter icons
+  useEffect(() => {
+    if (!editor) return;
+    decorationsRef.current ??= editor.createDecorationsCollection();
+    decorationsRef.current.set(
+      fileThreads.map((thread) => {
+        const [first] = thread.comments;
+        return {
+          range: new monaco.Range(thread.startLine, 1, thread.endLine, 1),
+          options: {
+            isWholeLine: true,
+            glyphMarginClassName:
+              thread.status === "resolved"
+                ? "comment-glyph comment-glyph-resolved"
+                : "comment-glyph",
+            glyphMarginHoverMessage: {
+              value: first
+                ? `${first.author}: ${first.body.slice(0, MAX_HOVER_LENGTH)}`
+                : "Comment thread",
+            },
+            linesDecorationsClassName: expanded.includes(thread.id)
+              ? "comment-range"
+              : undefined,
+          },
+        };
+      }),
+    );
+  }, [editor, fileThreads, expanded]);
+
+  // Clicking a thread's icon expands or collapses it
+  useEffect(() => {
+    if (!editor) return;
+    const subscription = editor.onMouseDown((event) => {
+      if (
+        event.target.type !== monaco.editor.MouseTargetType.GUTTER_GLYPH_MARGIN
+      ) {
+        return;
+      }
+      const line = event.target.position?.lineNumber;
+      const onLine = fileThreads.filter(
+        (thread) => thread.startLine === line,
+      );
+      if (onLine.length === 0) return;
+      const isOpen = onLine.some((thread) => expanded.includes(thread.id));
+      onLine.forEach((thread) => setExpanded(thread.id, !isOpen));
+    });
+    return () => subscription.dispose();
+  }, [editor, fileThreads, expanded, setExpanded]);
+
+  // View zones, kept across renders so typing in them isn't lost
+  useEffect(() => {
+    if (!editor) return;
+    const zones = zonesRef.current;
+    const keys = new Set(items.map((item) => item.key));
+    editor.changeViewZones((accessor) => {
+      for (const [key, zone] of zones) {
+        if (!keys.has(key)) {
+          accessor.removeZone(zone.id);
+          zone.observer.disconnect();
+          zones.delete(key);
+        }
+      }
+      for (const item of items) {
+        const existing = zones.get(item.key);
+        if (existing) {
+          if (existing.zone.afterLineNumber !== item.afterLineNumber) {
+            existing.zone.afterLineNumber = item.afterLineNumber;
+            accessor.layoutZone(existing.id);
+          }
+          continue;
+        }
+        const domNode = document.createElement("div");
+        const content = document.createElement("div");
+        domNode.appendChild(content);
+        const zone: monaco.editor.IViewZone = {
+          afterLineNumber: item.afterLineNumber,
+          heightInPx: 120,
+          domNode,
+        };
+        const id = accessor.addZone(zone);
+        const observer = new ResizeObserver(() => {
+          const height = content.offsetHeight;
+          if (height > 0 && height !== zone.heightInPx) {
+            zone.heightInPx = height;
+            editor.changeViewZones((layout) => layout.layoutZone(id));
+          }
+        });
+        observer.observe(content);
+        zones.set(item.key, { id, content, zone, observer });
+      }
+    });
+    setPortals(
+      items.map((item) => ({ item, content: zones.get(item.key)!.content })),
+    );
+  }, [editor, items]);
+
+  // Zones belong to the editor, not the file
+  useEffect(() => {
+    const zones = zonesRef.current;
+    return () => {
+      editor?.changeViewZones((accessor) => {
+        zones.forEach((zone) => {
+          accessor.removeZone(zone.id);
+          zone.observer.disconnect();
+        });
+      });
+      zones.clear();
+    };
+  }, [editor, modelUri]);
+
+  return (
+    <>
+      {portals.map(({ item, content }) =>
+        createPortal(
+          <CommentThreadWidget thread={item.thread} draft={item.draft} />,
+          content,
+          item.key,
+        ),
+      )}
+    </>
+  );
+}
+
+/**
+ * A thread's comments with a reply box, or the box for a new thread
+ */
+function CommentThreadWidget({
+  thread,
+  draft,
+}: {
+  thread?: CommentThread;
+  draft?: CommentDraft;
+}) {
+  const { setExpanded, setDraft } = useCommentsStore();
+  const [body, setBody] = useState("");
+  const [isSubmitting, setIsSubmitting] = useState(false);
+  const textareaRef = useRef<HTMLTextAreaElement | null>(null);
+
+  useEffect(() => {
+    if (draft) textareaRef.current?.focus();
+  }, [draft]);
+
+  const close = () => {
+    if (thread) setExpanded(thread.id, false);
+    else setDraft(null);
+  };
+
+  const submit = async () => {
+    if (!body.trim() || isSubmitting) return;
+    setIsSubmitting(true);
+    const ok = thread
+      ? await replyToThread(thread.id, body)
+      : await submitDraft(body);
+    setIsSubmitting(false);
+    if (ok) setBody("");
+  };
+
+  const startLine = thread?.startLine ?? draft!.startLine;
+  const endLine = thread?.endLine ?? draft!.endLine;
+
+  return (
+    <div className="py-1 pr-4">
+      <div
+        className="rounded-md border border-l-4 border-l-amber-500 bg-card text-[13px] shadow-sm"
+        role="group"
+        aria-label={`Comments on ${formatLines(startLine, endLine).toLowerCase()}`}
+      >
+        <div className="flex items-center gap-2 border-b px-3 py-1 text-xs">
+          <span className="font-medium text-foreground">
+            {formatLines(startLine, endLine)}
+          </span>
+          {thread?.outdated && (
+            <span className="rounded bg-amber-500/20 px-1.5 text-amber-600 dark:text-amber-400">
+              Outdated
+            </span>
+          )}
+          {thread?.status === "resolved" && (
+            <span className="text-muted-foreground">
+              Resolved by {thread.resolvedBy}
+            </span>
+          )}
+          <span className="flex-1" />
+          {thread && (
+            <>
+              <button
+                type="button"
+                className="flex items-center gap-1 rounded px-1.5 py-0.5 text-muted-foreground hover:bg-muted hover:text-foreground"
+                onClick={() =>
+                  setThreadStatus(
+                    thread.id,
+                    thread.status === "resolved" ? "open" : "resolved",
+                  )
+                }
+              >
+                {thread.status === "resolved" ? (
+                  <>
+                    <RotateCcw className="h-3.5 w-3.5" aria-hidden="true" />
+                    Reopen
+                  </>
+                ) : (
+                  <>
+                    <Check className="h-3.5 w-3.5" aria-hidden="true" />
+                    Resolve
+                  </>
+                )}
+              </button>
+              <button
+                type="button"
+                className="rounded p-0.5 text-muted-foreground hover:bg-muted hover:text-foreground"
+                onClick={() => deleteThread(thread.id)}
+                title="Delete Thread"
+              >
+                <Trash2 className="h-3.5 w-3.5" />
+              </button>
+            </>
+          )}
+          <button
+            type="button"
+            className="rounded p-0.5 text-muted-foreground hover:bg-muted hover:text-foreground"
+            onClick={close}
+            aria-label={thread ? "Collapse Thread" : "Discard Comment"}
+          >
+            <XCircle className="h-3.5 w-3.5" />
+          </button>
+        </div>
+
+        {thread?.outdated && (
+          <pre className="mx-3 mt-2 overflow-x-auto rounded bg-muted/50 px-2 py-1 font-mono text-xs text-muted-foreground">
+            {thread.text}
+          </pre>
+        )}
+
+        {thread?.comments.map((comment) => (
+          <div key={comment.id} className="px-3 py-1.5">
+            <div className="text-xs">
+              <span className="font-medium text-foreground">
+                {comment.author}
+              </span>
+              <span className="ml-2 text-muted-foreground">
+                {formatDate(comment.createdAt)}
+              </span>
+            </div>
+            <div className="whitespace-pre-wrap break-words text-foreground">
+              {comment.body}
+            </div>
+          </div>
+        ))}
+
+        <div className={cn("flex gap-2 px-3 py-2", thread && "border-t")}>
+          <textarea
+            ref={textareaRef}
+            value={body}
+            onChange={(event) => setBody(event.target.value)}
+            onKeyDown={(event) => {
+              if (event.key === "Enter" && (event.ctrlKey || event.metaKey)) {
+                event.preventDefault();
+                void submit();
+              } else if (event.key === "Escape") {
+                close();
+              }
+            }}
+            rows={2}
+            placeholder={
+              thread ? "Reply... (Ctrl+Enter)" : "Add a comment... (Ctrl+Enter)"
+            }
+            aria-label={thread ? "Reply" : "Comment"}
+            className="min-h-[2.5rem] flex-1 resize-y rounded border bg-background px-2 py-1 text-[13px] text-foreground outline-none focus:ring-1 focus:ring-ring"
+          />
+          <button
+            type="button"
+            onClick={() => void submit()}
+            disabled={!body.trim() || isSubmitting}
+            className="self-end rounded bg-primary px-3 py-1 text-xs font-medium text-primary-foreground disabled:opacity-50"
+          >
+            {thread ? "Reply" : "Comment"}
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,197 @@
+"use client";
+
+import {
+  deleteThread,
+  fetchCommentThreads,
+  filterCommentThreads,
+  getCommentAuthors,
+  openCommentThread,
+  setThreadStatus,
+  useCommentsStore,
+  type CommentStatusFilter,
+} from "@/lib/comments";
+import { useEditorStore } from "@/lib/store";
+import { cn } from "@/lib/utils";
+import {
+  CheckCircle2,
+  MessageSquare,
+  RefreshCw,
+  RotateCcw,
+  Trash2,
+  XCircle,
+} from "lucide-react";
+import React, { useEffect } from "react";
+
+export function CommentsPanel() {
+  const { isCommentsOpen, setCommentsOpen } = useEditorStore();
+  const {
+    threads,
+    authorFilter,
+    statusFilter,
+    setAuthorFilter,
+    setStatusFilter,
+  } = useCommentsStore();
+
+  useEffect(() => {
+    if (isCommentsOpen) fetchCommentThreads();
+  }, [isCommentsOpen]);
+
+  if (!isCommentsOpen) return null;
+
+  const authors = getCommentAuthors(threads);
+  const visible = filterCommentThreads(threads, authorFilter, statusFilter);
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Comments"
+      data-focus-region="Comments"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Comments
+          </span>
+          <span className="flex items-center gap-1 text-muted-foreground">
+            <MessageSquare className="h-3.5 w-3.5" />
+            <span className="tabular-nums">{visible.length}</span>
+          </span>
+          <div className="flex items-center gap-1">
+            <select
+              className="rounded border bg-background px-1 py-[1px] text-[11px]"
+              value={authorFilter ?? ""}
+              onChange={(e) => setAuthorFilter(e.target.value || null)}
+              aria-label="Filter by author"
+            >
+              <option value="">All authors</option>
+              {authors.map((author) => (
+                <option key={author} value={author}>
+                  {author}
+                </option>
+              ))}
+            </select>
+            <select
+              className="rounded border bg-background px-1 py-[1px] text-[11px]"
+              value={statusFilter}
+              onChange={(e) =>
+                setStatusFilter(e.target.value as CommentStatusFilter)
+              }
+              aria-label="Filter by status"
+            >
+              <option value="open">Open</option>
+              <option value="resolved">Resolved</option>
+              <option value="all">All</option>
+            </select>
+          </div>
+        </div>
+        <div className="flex items-center gap-1">
+          <button
+            type="button"
+            onClick={() => fetchCommentThreads()}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setCommentsOpen(false)}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            aria-label="Close Comments"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {visible.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            {threads.length === 0
+              ? "No comments yet. Use Add Comment (Ctrl+Alt+M) in the editor to start a thread on the selected lines."
+              : "No threads match the filters."}
+          </div>
+        ) : (
+          <div className="py-1">
+            {visible.map((thread) => {
+              const [first] = thread.comments;
+              const isResolved = thread.status === "resolved";
+              return (
+                <div
+                  key={thread.id}
+                  className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40 cursor-pointer"
+                  onClick={() => openCommentThread(thread)}
+                >
+                  {isResolved ? (
+                    <CheckCircle2 className="h-4 w-4 flex-shrink-0 text-muted-foreground" />
+                  ) : (
+                    <MessageSquare className="h-4 w-4 flex-shrink-0 text-amber-500" />
+                  )}
+                  <span
+                    className={cn(
+                      "flex-1 min-w-0 truncate",
+                      isResolved ? "text-muted-foreground" : "text-foreground",
+                    )}
+                  >
+                    <span className="font-medium">{first?.author}</span>
+                    <span className="ml-2">{first?.body}</span>
+                  </span>
+                  {thread.outdated && (
+                    <span className="rounded bg-amber-500/20 px-1.5 text-xs text-amber-600 dark:text-amber-400 flex-shrink-0">
+                      Outdated
+                    </span>
+                  )}
+                  {thread.comments.length > 1 && (
+                    <span className="text-muted-foreground text-xs flex-shrink-0">
+                      {thread.comments.length - 1}{" "}
+                      {thread.comments.length === 2 ? "reply" : "replies"}
+                    </span>
+                  )}
+                  <span className="text-muted-foreground flex-shrink-0 text-xs">
+                    {thread.path}
+                  </span>
+                  <span className="text-muted-foreground tabular-nums flex-shrink-0 text-xs">
+                    [{thread.startLine}]
+                  </span>
+                  <button
+                    type="button"
+                    className="rounded p-0.5 opacity-0 group-hover:opacity-100 hover:bg-muted"
+                    title={isResolved ? "Reopen Thread" : "Resolve Thread"}
+                    onClick={(event) => {
+                      event.stopPropagation();
+                      setThreadStatus(
+                        thread.id,
+                        isResolved ? "open" : "resolved",
+                      );
+                    }}
+                  >
+                    {isResolved ? (
+                      <RotateCcw className="h-3.5 w-3.5" />
+                    ) : (
+                      <CheckCircle2 className="h-3.5 w-3.5" />
+                    )}
+                  </button>
+                  <button
+                    type="button"
+                    className="rounded p-0.5 opacity-0 group-hover:opacity-100 hover:bg-muted"
+                    title="Delete Thread"
+                    onClick={(event) => {
+                      event.stopPropagation();
+                      deleteThread(thread.id);
+                    }}
+                  >
+                    <Trash2 className="h-3.5 w-3.5" />
+                  </button>
+                </div>
+              );
+            })}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
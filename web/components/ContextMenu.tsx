@@ -14,9 +14,15 @@ interface ContextMenuProps {
   items: ContextMenuItem[];
   position: { x: number; y: number };
   onClose: () => void;
+  label?: string;
 }
 
-export function ContextMenu({ items, position, onClose }: ContextMenuProps) {
+export function ContextMenu({
+  items,
+  position,
+  onClose,
+  label = "Context menu",
+}: ContextMenuProps) {
   const menuRef = useRef<HTMLDivElement>(null);
 
   useEffect(() => {
@@ -41,25 +47,72 @@ export function ContextMenu({ items, position, onClose }: ContextMenuProps) {
     };
   }, [onClose]);
 
+  // Focus the first item, and give focus back to whatever opened the menu
+  useEffect(() => {
+    const previous = document.activeElement as HTMLElement | null;
+    menuRef.current
+      ?.querySelector<HTMLElement>('[role="menuitem"]:not([disabled])')
+      ?.focus();
+    return () => previous?.focus();
+  }, []);
+
+  const handleKeyDown = (event: React.KeyboardEvent) => {
+    const menuItems = Array.from(
+      menuRef.current?.querySelectorAll<HTMLElement>(
+        '[role="menuitem"]:not([disabled])',
+      ) ?? [],
+    );
+    if (menuItems.length === 0) return;
+    const index = menuItems.indexOf(document.activeElement as HTMLElement);
+    let next: number | undefined;
+    switch (event.key) {
+      case "ArrowDown":
+        next = (index + 1) % menuItems.length;
+        break;
+      case "ArrowUp":
+        next = (index - 1 + menuItems.length) % menuItems.length;
+        break;
+      case "Home":
+        next = 0;
+        break;
+      case "End":
+        next = menuItems.length - 1;
+        break;
+      case "Tab":
+        event.preventDefault();
+        onClose();
+        return;
+      default:
+        return;
+    }
+    event.preventDefault();
+    menuItems[next].focus();
+  };
+
   return (
     <div
       ref={menuRef}
+      role="menu"
+      aria-label={label}
       className="fixed z-50 min-w-[200px] rounded-md border bg-popover p-1 shadow-md"
       style={{
         left: `${position.x}px`,
         top: `${position.y}px`,
       }}
+      onKeyDown={handleKeyDown}
     >
       {items.map((item, index) => (
         <button
           // biome-ignore lint/suspicious/noArrayIndexKey: Menu items are static and don't reorder
           key={index}
           type="button"
+          role="menuitem"
+          tabIndex={-1}
           className={cn(
             "flex w-full items-center gap-2 rounded-sm px-2 py-1.5 text-sm outline-none transition-colors",
             item.disabled
               ? "cursor-not-allowed text-muted-foreground opacity-50"
-              : "cursor-pointer hover:bg-accent hover:text-accent-foreground",
+              : "cursor-pointer hover:bg-accent hover:text-accent-foreground focus:bg-accent focus:text-accent-foreground",
           )}
           onClick={() => {
             if (!item.disabled) {
@@ -69,7 +122,11 @@ export function ContextMenu({ items, position, onClose }: ContextMenuProps) {
           }}
           disabled={item.disabled}
         >
-          {item.icon && <span className="h-4 w-4">{item.icon}</span>}
+          {item.icon && (
+            <span className="h-4 w-4" aria-hidden="true">
+              {item.icon}
+            </span>
+          )}
           <span>{item.label}</span>
         </button>
       ))}
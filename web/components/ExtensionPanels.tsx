@@ -0,0 +1,75 @@
+"use client";
+
+import { useExtensionStore } from "@/lib/extensions/registry";
+import { Puzzle, XCircle } from "lucide-react";
+import React, { useEffect, useRef } from "react";
+
+/**
+ * Bottom panel showing the open extension panel. The extension renders into
+ * a container it owns; React never touches its children.
+ */
+export function ExtensionPanels() {
+  const { panels, openPanelId, setOpenPanel } = useExtensionStore();
+  const containerRef = useRef<HTMLDivElement>(null);
+  const panel = panels.find((p) => p.id === openPanelId);
+
+  useEffect(() => {
+    const container = containerRef.current;
+    if (!panel || !container) return;
+
+    let cleanup: void | (() => void);
+    try {
+      cleanup = panel.render(container);
+    } catch (error) {
+      console.error(`[Extension:${panel.extension}] Panel ${panel.id} failed to render:`, error);
+      container.textContent = `Failed to render panel: ${
+        error instanceof Error ? error.message : String(error)
+      }`;
+    }
+
+    return () => {
+      try {
+        cleanup?.();
+      } catch (error) {
+        console.error(`[Extension:${panel.extension}] Panel ${panel.id} cleanup failed:`, error);
+      }
+      container.replaceChildren();
+    };
+  }, [panel]);
+
+  if (!panel) return null;
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label={panel.title}
+      data-focus-region={panel.title}
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            {panel.title}
+          </span>
+          <span className="flex items-center gap-1 text-muted-foreground">
+            <Puzzle className="h-3.5 w-3.5" />
+            <span>{panel.extension}</span>
+          </span>
+        </div>
+        <button
+          type="button"
+          onClick={() => setOpenPanel(null)}
+          className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+          aria-label={`Close ${panel.title}`}
+        >
+          <XCircle className="h-4 w-4" />
+        </button>
+      </div>
+
+      {/* Content */}
+      <div ref={containerRef} className="flex-1 overflow-auto px-3 py-2 text-[13px]" />
+    </div>
+  );
+}
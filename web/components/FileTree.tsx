@@ -1,5 +1,13 @@
 "use client";
 
+import {
+  api,
+  describeApiError,
+  type CoverageFileSummary,
+  type FileTreeNode,
+} from "@/lib/api";
+import { useExtensionStore } from "@/lib/extensions/registry";
+import { useEditorStore } from "@/lib/store";
 import { cn } from "@/lib/utils";
 import {
   Edit2,
@@ -7,21 +15,15 @@ import {
   FilePlus,
   Folder,
   FolderPlus,
+  LayoutTemplate,
+  Puzzle,
   RefreshCw,
   Trash2,
 } from "lucide-react";
-import React, { useState } from "react";
+import React, { useMemo, useRef, useState } from "react";
 import { ContextMenu, ContextMenuItem } from "./ContextMenu";
 
-// API configuration
-const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:3001";
-
-export interface FileTreeNode {
-  name: string;
-  path: string;
-  type: "file" | "directory";
-  children?: FileTreeNode[];
-}
+export type { FileTreeNode };
 
 interface FileTreeProps {
   files: FileTreeNode[];
@@ -36,15 +38,163 @@ export function FileTree({
   onRefresh,
   isLoading = false,
 }: FileTreeProps) {
+  const setNewProjectOpen = useEditorStore((state) => state.setNewProjectOpen);
+  const coverage = useEditorStore((state) =>
+    state.isCoverageVisible ? state.coverage : null,
+  );
+  const coverageByPath = useMemo(
+    () => new Map(coverage?.files.map((file) => [file.path, file]) ?? []),
+    [coverage],
+  );
+  const currentFile = useEditorStore((state) => state.currentFile);
   const [contextMenu, setContextMenu] = useState<{
     position: { x: number; y: number };
     items: ContextMenuItem[];
+    label: string;
   } | null>(null);
+  const [expanded, setExpanded] = useState<Set<string>>(new Set());
+  const [activePath, setActivePath] = useState<string | null>(null);
+  const treeRef = useRef<HTMLDivElement>(null);
+  const typeahead = useRef({ text: "", time: 0 });
+
+  // Items in display order, with their parents, for keyboard navigation
+  const visibleNodes = useMemo(() => {
+    const result: { node: FileTreeNode; parent?: string }[] = [];
+    const visit = (nodes: FileTreeNode[], parent?: string) => {
+      for (const node of nodes) {
+        result.push({ node, parent });
+        if (node.children && expanded.has(node.path)) {
+          visit(node.children, node.path);
+        }
+      }
+    };
+    visit(files);
+    return result;
+  }, [files, expanded]);
+
+  // The one item reachable with Tab; arrow keys move it
+  const focusPath = visibleNodes.some(({ node }) => node.path === activePath)
+    ? activePath
+    : (visibleNodes[0]?.node.path ?? null);
+
+  const toggleExpanded = (path: string, open?: boolean) => {
+    setExpanded((current) => {
+      const next = new Set(current);
+      if (open ?? !next.has(path)) {
+        next.add(path);
+      } else {
+        next.delete(path);
+      }
+      return next;
+    });
+  };
+
+  const activate = (node: FileTreeNode) => {
+    setActivePath(node.path);
+    if (node.type === "directory") {
+      toggleExpanded(node.path);
+    } else {
+      onFileSelect(node.path);
+    }
+  };
+
+  const focusItem = (path: string) => {
+    setActivePath(path);
+    treeRef.current
+      ?.querySelector<HTMLElement>(`[data-path="${CSS.escape(path)}"]`)
+      ?.focus();
+  };
+
+  const handleTreeKeyDown = (event: React.KeyboardEvent) => {
+    const index = visibleNodes.findIndex(({ node }) => node.path === focusPath);
+    const current = visibleNodes[index];
+    if (!current) return;
+    const { node, parent } = current;
+    const isExpanded = expanded.has(node.path);
+
+    switch (event.key) {
+      case "ArrowDown":
+        if (index < visibleNodes.length - 1) {
+          focusItem(visibleNodes[index + 1].node.path);
+        }
+        break;
+      case "ArrowUp":
+        if (index > 0) {
+          focusItem(visibleNodes[index - 1].node.path);
+        }
+        break;
+      case "Home":
+        focusItem(visibleNodes[0].node.path);
+        break;
+      case "End":
+        focusItem(visibleNodes[visibleNodes.length - 1].node.path);
+        break;
+      case "ArrowRight":
+        if (node.type !== "directory") break;
+        if (!isExpanded) {
+          toggleExpanded(node.path, true);
+        } else if (node.children?.length) {
+          focusItem(node.children[0].path);
+        }
+        break;
+      case "ArrowLeft":
+        if (node.type === "directory" && isExpanded) {
+          toggleExpanded(node.path, false);
+        } else if (parent) {
+          focusItem(parent);
+        }
+        break;
+      case "Enter":
+      case " ":
+        activate(node);
+        break;
+      case "ContextMenu":
+      case "F10": {
+        if (event.key === "F10" && !event.shiftKey) return;
+        const rect = (event.target as HTMLElement).getBoundingClientRect();
+        openContextMenu({ x: rect.left + 16, y: rect.bottom }, node);
+        break;
+      }
+      default: {
+        // Type the start of a name to jump to the next item with it
+        if (event.key.length !== 1 || event.ctrlKey || event.metaKey || event.altKey) {
+          return;
+        }
+        const now = Date.now();
+        const text =
+          (now - typeahead.current.time < 700 ? typeahead.current.text : "") +
+          event.key.toLowerCase();
+        typeahead.current = { text, time: now };
+        const ordered = [
+          ...visibleNodes.slice(index + (text.length === 1 ? 1 : 0)),
+          ...visibleNodes.slice(0, index + (text.length === 1 ? 1 : 0)),
+        ];
+        const match = ordered.find(({ node: candidate }) =>
+          candidate.name.toLowerCase().startsWith(text),
+        );
+        if (match) {
+          focusItem(match.node.path);
+        }
+        break;
+      }
+    }
+    event.preventDefault();
+    event.stopPropagation();
+  };
 
   const handleContextMenu = (event: React.MouseEvent, node?: FileTreeNode) => {
     event.preventDefault();
     event.stopPropagation();
+    if (node) {
+      setActivePath(node.path);
+    }
+    openContextMenu({ x: event.clientX, y: event.clientY }, node);
+  };
 
+  const openContextMenu = (
+    position: { x: number; y: number },
+    node?: FileTreeNode,
+  ) => {
     const items: ContextMenuItem[] = [];
 
     if (node) {
@@ -153,30 +303,75 @@ export function FileTree({
       );
     }
 
+    // Items contributed by web extensions
+    const target = node ? { path: node.path, type: node.type } : null;
+    for (const item of useExtensionStore.getState().contextMenuItems) {
+      const applies =
+        item.when === "any"
+          ? Boolean(target)
+          : item.when === "empty"
+            ? !target
+            : target?.type === item.when;
+      if (!applies) continue;
+      items.push({
+        label: item.label,
+        icon: <Puzzle className="h-4 w-4" />,
+        onClick: async () => {
+          try {
+            await item.run(target);
+          } catch (error) {
+            console.error(`[Extension:${item.extension}] ${item.label} failed:`, error);
+          }
+        },
+      });
+    }
+
     setContextMenu({
-      position: { x: event.clientX, y: event.clientY },
+      position,
       items,
+      label: node ? `Actions for ${node.name}` : "Explorer actions",
     });
   };
 
   return (
-    <div className="h-full w-64 border-r bg-muted/10 flex flex-col">
+    <nav
+      className="h-full w-64 border-r bg-muted/10 flex flex-col"
+      aria-label="Explorer"
+      data-focus-region="Explorer"
+    >
       <div className="p-2 border-b flex items-center justify-between">
-        <span className="font-semibold text-sm">Explorer</span>
+        <span className="font-semibold text-sm" id="explorer-heading">
+          Explorer
+        </span>
         <div className="flex gap-1">
+          <button
+            type="button"
+            className="p-1 hover:bg-muted rounded"
+            title="New Project from Template"
+            aria-label="New Project from Template"
+            onClick={() => setNewProjectOpen(true)}
+          >
+            <LayoutTemplate className="h-4 w-4" aria-hidden="true" />
+          </button>
           <button
             type="button"
             className="p-1 hover:bg-muted rounded"
             title="Refresh"
+            aria-label="Refresh Explorer"
             onClick={onRefresh}
           >
-            <RefreshCw className="h-4 w-4" />
+            <RefreshCw className="h-4 w-4" aria-hidden="true" />
           </button>
         </div>
       </div>
       <div
+        ref={treeRef}
         className="flex-1 overflow-auto p-2"
+        role="tree"
+        aria-labelledby="explorer-heading"
+        aria-busy={isLoading}
         onContextMenu={(e) => handleContextMenu(e)}
+        onKeyDown={handleTreeKeyDown}
       >
         {isLoading ? (
           <div className="text-sm text-muted-foreground p-2">
@@ -187,12 +382,18 @@ export function FileTree({
             No files found
           </div>
         ) : (
-          files.map((file) => (
+          files.map((file, index) => (
             <FileTreeNodeItem
               key={file.path}
               node={file}
-              onSelect={onFileSelect}
+              position={index + 1}
+              setSize={files.length}
+              expanded={expanded}
+              focusPath={focusPath}
+              selectedPath={currentFile}
+              onActivate={activate}
               onContextMenu={handleContextMenu}
+              coverageByPath={coverageByPath}
             />
           ))
         )}
@@ -201,122 +402,78 @@ export function FileTree({
         <ContextMenu
           items={contextMenu.items}
           position={contextMenu.position}
+          label={contextMenu.label}
           onClose={() => setContextMenu(null)}
         />
       )}
-    </div>
+    </nav>
   );
 }
 
 // API helper functions
 async function createFile(path: string): Promise<void> {
   try {
-    const response = await fetch(`${API_BASE_URL}/api/file${path}`, {
-      method: "POST",
-      headers: { "Content-Type": "application/json" },
-      body: JSON.stringify({ content: "", languageId: "plaintext" }),
-    });
-    if (!response.ok) {
-      const error = await response.json();
-      throw new Error(error.error || "Failed to create file");
-    }
+    await api.createFile({ path, content: "", languageId: "plaintext" });
   } catch (error) {
     console.error("Error creating file:", error);
-    alert(
-      `Failed to create file: ${
-        error instanceof Error ? error.message : "Unknown error"
-      }`,
-    );
+    alert(`Failed to create file: ${describeApiError(error)}`);
   }
 }
 
 async function createFolder(path: string): Promise<void> {
   try {
-    const response = await fetch(`${API_BASE_URL}/api/folder${path}`, {
-      method: "POST",
-      headers: { "Content-Type": "application/json" },
-    });
-    if (!response.ok) {
-      const error = await response.json();
-      throw new Error(error.error || "Failed to create folder");
-    }
+    await api.createFolder({ path });
   } catch (error) {
     console.error("Error creating folder:", error);
-    alert(
-      `Failed to create folder: ${
-        error instanceof Error ? error.message : "Unknown error"
-      }`,
-    );
+    alert(`Failed to create folder: ${describeApiError(error)}`);
   }
 }
 
 async function renamePath(oldPath: string, newPath: string): Promise<void> {
   try {
-    const response = await fetch(`${API_BASE_URL}/api/rename`, {
-      method: "PUT",
-      headers: { "Content-Type": "application/json" },
-      body: JSON.stringify({ oldPath, newPath }),
-    });
-    if (!response.ok) {
-      const error = await response.json();
-      throw new Error(error.error || "Failed to rename");
-    }
+    await api.renamePath({ oldPath, newPath });
   } catch (error) {
     console.error("Error renaming:", error);
-    alert(
-      `Failed to rename: ${
-        error instanceof Error ? error.message : "Unknown error"
-      }`,
-    );
+    alert(`Failed to rename: ${describeApiError(error)}`);
   }
 }
 
 async function deletePath(path: string): Promise<void> {
   try {
-    const response = await fetch(`${API_BASE_URL}/api/path${path}`, {
-      method: "DELETE",
-    });
-    if (!response.ok) {
-      const error = await response.json();
-      throw new Error(error.error || "Failed to delete");
-    }
+    await api.deletePath({ path });
   } catch (error) {
     console.error("Error deleting:", error);
-    alert(
-      `Failed to delete: ${
-        error instanceof Error ? error.message : "Unknown error"
-      }`,
-    );
+    alert(`Failed to delete: ${describeApiError(error)}`);
   }
 }
 
 function FileTreeNodeItem({
   node,
-  onSelect,
+  expanded,
+  focusPath,
+  selectedPath,
+  onActivate,
   onContextMenu,
+  coverageByPath,
+  position,
+  setSize,
   level = 0,
 }: {
   node: FileTreeNode;
-  onSelect: (path: string) => void;
+  // Place among its siblings, for screen readers
+  position: number;
+  setSize: number;
+  expanded: Set<string>;
+  focusPath: string | null;
+  selectedPath: string | null;
+  onActivate: (node: FileTreeNode) => void;
   onContextMenu: (event: React.MouseEvent, node: FileTreeNode) => void;
+  coverageByPath: Map<string, CoverageFileSummary>;
   level?: number;
 }) {
-  const [isOpen, setIsOpen] = React.useState(false);
-
-  const handleClick = () => {
-    if (node.type === "directory") {
-      setIsOpen(!isOpen);
-    } else {
-      onSelect(node.path);
-    }
-  };
-
-  const handleKeyDown = (event: React.KeyboardEvent) => {
-    if (event.key === "Enter" || event.key === " ") {
-      event.preventDefault();
-      handleClick();
-    }
-  };
+  const isDirectory = node.type === "directory";
+  const isOpen = isDirectory && expanded.has(node.path);
+  const fileCoverage = isDirectory ? undefined : coverageByPath.get(node.path);
 
   const handleContextMenu = (event: React.MouseEvent) => {
     event.preventDefault();
@@ -324,35 +481,64 @@ function FileTreeNodeItem({
     onContextMenu(event, node);
   };
 
+  // Items are focused rows with their level, rather than nested groups, so
+  // focus and the treeitem role stay on the same element
   return (
-    <div>
+    <div role="none">
       <div
+        role="treeitem"
+        aria-level={level + 1}
+        aria-posinset={position}
+        aria-setsize={setSize}
+        aria-expanded={isDirectory ? isOpen : undefined}
+        aria-selected={node.path === selectedPath}
         className={cn(
-          "flex items-center gap-2 py-1 px-2 hover:bg-accent/50 rounded cursor-pointer text-sm",
+          "flex items-center gap-2 py-1 px-2 hover:bg-accent/50 rounded cursor-pointer text-sm focus:bg-accent focus:text-accent-foreground",
           level > 0 && "ml-4",
+          node.path === selectedPath && "bg-accent/60",
         )}
-        onClick={handleClick}
-        onKeyDown={handleKeyDown}
+        onClick={() => onActivate(node)}
         onContextMenu={handleContextMenu}
         style={{ paddingLeft: `${level * 12 + 8}px` }}
-        role="button"
-        tabIndex={0}
+        data-path={node.path}
+        tabIndex={node.path === focusPath ? 0 : -1}
       >
-        {node.type === "directory" ? (
-          <Folder className="h-4 w-4 text-blue-400" />
+        {isDirectory ? (
+          <Folder className="h-4 w-4 text-blue-400" aria-hidden="true" />
         ) : (
-          <File className="h-4 w-4 text-gray-400" />
+          <File className="h-4 w-4 text-gray-400" aria-hidden="true" />
         )}
         <span>{node.name}</span>
+        {fileCoverage && (
+          <span
+            className={cn(
+              "ml-auto text-[11px] tabular-nums",
+              fileCoverage.percent >= 80
+                ? "text-green-600"
+                : fileCoverage.percent >= 50
+                  ? "text-amber-500"
+                  : "text-red-500",
+            )}
+            title={`${fileCoverage.covered} of ${fileCoverage.total} lines covered`}
+          >
+            {Math.round(fileCoverage.percent)}%
+          </span>
+        )}
       </div>
       {isOpen && node.children && (
-        <div>
-          {node.children.map((child) => (
+        <div role="none">
+          {node.children.map((child, index) => (
             <FileTreeNodeItem
               key={child.path}
               node={child}
-              onSelect={onSelect}
+              position={index + 1}
+              setSize={node.children!.length}
+              expanded={expanded}
+              focusPath={focusPath}
+              selectedPath={selectedPath}
+              onActivate={onActivate}
               onContextMenu={onContextMenu}
+              coverageByPath={coverageByPath}
               level={level + 1}
             />
           ))}
@@ -0,0 +1,316 @@
+"use client";
+
+import type { GoModuleCommandResult } from "@/lib/api";
+import { fetchGoModules, getGoModPath, runGoModuleCommand } from "@/lib/gomod";
+import { openWorkspaceFile } from "@/lib/navigation";
+import { useEditorStore } from "@/lib/store";
+import { cn } from "@/lib/utils";
+import {
+  ArrowUpCircle,
+  Boxes,
+  Loader2,
+  Package,
+  RefreshCw,
+  ShieldAlert,
+  Wand2,
+  XCircle,
+} from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+export function GoModulesPanel() {
+  const { goModules, isGoModulesOpen, setGoModulesOpen, lspManager } =
+    useEditorStore();
+  const [selectedPath, setSelectedPath] = useState<string | null>(null);
+  const [showIndirect, setShowIndirect] = useState(true);
+  const [running, setRunning] = useState<string | null>(null);
+  const [lastResult, setLastResult] = useState<GoModuleCommandResult | null>(
+    null,
+  );
+  const [showOutput, setShowOutput] = useState(false);
+
+  // Module diagnostics belong in Problems even while the panel is closed
+  useEffect(() => {
+    fetchGoModules();
+  }, []);
+
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = lspManager.onNotification(
+      "workspace/goModulesChanged",
+      () => {
+        fetchGoModules();
+      },
+    );
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  const module = useMemo(
+    () =>
+      goModules.find((m) => m.path === selectedPath) ?? goModules[0] ?? null,
+    [goModules, selectedPath],
+  );
+
+  const dependencies = useMemo(
+    () =>
+      (module?.dependencies ?? [])
+        .filter((dependency) => showIndirect || !dependency.indirect)
+        .sort(
+          (a, b) =>
+            Number(a.indirect) - Number(b.indirect) ||
+            a.path.localeCompare(b.path),
+        ),
+    [module, showIndirect],
+  );
+
+  if (!isGoModulesOpen) return null;
+
+  const outdatedCount =
+    module?.dependencies.filter((dependency) => dependency.latest).length ?? 0;
+  const vulnerableCount =
+    module?.dependencies.filter(
+      (dependency) => dependency.vulnerabilities.length > 0,
+    ).length ?? 0;
+
+  const run = async (
+    command: "update" | "tidy" | "vendor",
+    dependency?: string,
+  ) => {
+    if (!module) return;
+    setRunning(dependency ?? command);
+    const result = await runGoModuleCommand(module.path, command, dependency);
+    setRunning(null);
+    if (result) {
+      setLastResult(result);
+      setShowOutput(result.task.status !== "succeeded");
+    }
+  };
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Go Modules"
+      data-focus-region="Go Modules"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Go Modules
+          </span>
+          {goModules.length > 1 && (
+            <select
+              className="rounded border bg-background px-1 py-[1px] text-xs"
+              value={module?.path ?? ""}
+              onChange={(event) => setSelectedPath(event.target.value)}
+            >
+              {goModules.map((m) => (
+                <option key={m.path} value={m.path}>
+                  {m.module || m.path}
+                </option>
+              ))}
+            </select>
+          )}
+          {goModules.length === 1 && module && (
+            <span className="font-mono text-muted-foreground">
+              {module.module}
+            </span>
+          )}
+          {module && (
+            <div className="flex items-center gap-3 text-muted-foreground">
+              <span className="flex items-center gap-1" title="Dependencies">
+                <Boxes className="h-3.5 w-3.5" />
+                <span className="tabular-nums">{module.dependencies.length}</span>
+              </span>
+              <span className="flex items-center gap-1" title="Outdated">
+                <ArrowUpCircle className="h-3.5 w-3.5 text-blue-500" />
+                <span className="tabular-nums">{outdatedCount}</span>
+              </span>
+              <span className="flex items-center gap-1" title="Vulnerable">
+                <ShieldAlert className="h-3.5 w-3.5 text-amber-500" />
+                <span className="tabular-nums">{vulnerableCount}</span>
+              </span>
+            </div>
+          )}
+          <label className="flex items-center gap-1 text-muted-foreground">
+            <input
+              type="checkbox"
+              checked={showIndirect}
+              onChange={(event) => setShowIndirect(event.target.checked)}
+            />
+            Indirect
+          </label>
+        </div>
+        <div className="flex items-center gap-1">
+          {running && (
+            <Loader2 className="h-4 w-4 animate-spin text-muted-foreground" />
+          )}
+          <button
+            type="button"
+            onClick={() => run("update")}
+            disabled={!module || running !== null}
+            className={headerButton}
+            title="Update All (go get -u ./...)"
+          >
+            <ArrowUpCircle className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => run("tidy")}
+            disabled={!module || running !== null}
+            className={headerButton}
+            title="Tidy (go mod tidy)"
+          >
+            <Wand2 className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => run("vendor")}
+            disabled={!module || running !== null}
+            className={headerButton}
+            title="Vendor (go mod vendor)"
+          >
+            <Package className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => fetchGoModules()}
+            className={headerButton}
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setGoModulesOpen(false)}
+            className={headerButton}
+            aria-label="Close Go Modules"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {lastResult && (
+        <button
+          type="button"
+          onClick={() => setShowOutput(!showOutput)}
+          className={cn(
+            "flex items-center gap-2 border-b px-3 py-0.5 text-left text-xs hover:bg-muted/40",
+            lastResult.task.status === "succeeded"
+              ? "text-muted-foreground"
+              : "text-red-500",
+          )}
+        >
+          <span className="font-mono">{lastResult.task.label}</span>
+          <span>{lastResult.task.status}</span>
+          <span className="ml-auto">{showOutput ? "Hide output" : "Show output"}</span>
+        </button>
+      )}
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {showOutput && lastResult ? (
+          <pre className="whitespace-pre-wrap px-3 py-2 font-mono text-xs text-foreground">
+            {lastResult.output || "(no output)"}
+          </pre>
+        ) : !module ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No go.mod found in the workspace.
+          </div>
+        ) : dependencies.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            {module.module} has no {showIndirect ? "" : "direct "}dependencies.
+          </div>
+        ) : (
+          <div className="py-1">
+            {dependencies.map((dependency) => (
+              <div
+                key={dependency.path}
+                className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40 cursor-pointer"
+                onClick={() =>
+                  openWorkspaceFile(getGoModPath(module), dependency.line)
+                }
+              >
+                <span
+                  className={cn(
+                    "truncate font-mono",
+                    dependency.indirect ? "text-muted-foreground" : "text-foreground",
+                  )}
+                >
+                  {dependency.path}
+                </span>
+                <span className="font-mono text-xs text-muted-foreground">
+                  {dependency.version}
+                </span>
+                {dependency.latest && (
+                  <span className="font-mono text-xs text-blue-500">
+                    → {dependency.latest}
+                  </span>
+                )}
+                {dependency.indirect && (
+                  <span className="rounded-full border px-1.5 text-[11px] text-muted-foreground">
+                    indirect
+                  </span>
+                )}
+                {dependency.replace && (
+                  <span
+                    className="truncate rounded-full border px-1.5 text-[11px] text-muted-foreground"
+                    title={`Replaced by ${dependency.replace}`}
+                  >
+                    ⇒ {dependency.replace}
+                  </span>
+                )}
+                {!dependency.checksum && (
+                  <span
+                    className="rounded-full border px-1.5 text-[11px] text-amber-500"
+                    title="go.sum has no checksum for this version; run Tidy"
+                  >
+                    no go.sum
+                  </span>
+                )}
+                {dependency.vulnerabilities.map((vulnerability) => (
+                  <span
+                    key={vulnerability.id}
+                    className="flex items-center gap-0.5 rounded-full border border-amber-500/50 px-1.5 text-[11px] text-amber-500"
+                    title={[
+                      vulnerability.summary,
+                      vulnerability.aliases.join(", "),
+                      vulnerability.fixed
+                        ? `Fixed in ${vulnerability.fixed}`
+                        : "No fix available",
+                    ]
+                      .filter(Boolean)
+                      .join("\n")}
+                  >
+                    <ShieldAlert className="h-3 w-3" />
+                    {vulnerability.id}
+                  </span>
+                ))}
+                <span className="flex-1" />
+                {dependency.latest && (
+                  <button
+                    type="button"
+                    onClick={(event) => {
+                      event.stopPropagation();
+                      run("update", dependency.path);
+                    }}
+                    disabled={running !== null}
+                    className="invisible rounded border px-1.5 text-[11px] text-foreground hover:bg-background group-hover:visible disabled:opacity-50"
+                    title={`go get -u ${dependency.path}`}
+                  >
+                    {running === dependency.path ? "Updating…" : "Update"}
+                  </button>
+                )}
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
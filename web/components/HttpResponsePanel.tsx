@@ -0,0 +1,304 @@
+"use client";
+
+import {
+  clearHttpHistory,
+  fetchHttpHistory,
+  formatResponseBody,
+  formatSize,
+  openHttpHistoryEntry,
+  sendHttpRequest,
+  useHttpStore,
+} from "@/lib/httpRequests";
+import { openWorkspaceFile, uriToWorkspacePath } from "@/lib/navigation";
+import { useEditorStore } from "@/lib/store";
+import { isUntitledUri } from "@/lib/uris";
+import { cn } from "@/lib/utils";
+import { Loader2, RotateCw, Trash2, XCircle } from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+type View = "body" | "headers" | "request" | "history";
+
+const VIEWS: Array<{ id: View; label: string }> = [
+  { id: "body", label: "Body" },
+  { id: "headers", label: "Headers" },
+  { id: "request", label: "Request" },
+  { id: "history", label: "History" },
+];
+
+function statusColor(status: number): string {
+  if (status === 0 || status >= 500) return "text-red-500";
+  if (status >= 400) return "text-amber-500";
+  if (status >= 300) return "text-sky-600";
+  return "text-emerald-600";
+}
+
+function formatTime(timestamp: string): string {
+  return new Date(timestamp).toLocaleTimeString(undefined, {
+    hour: "2-digit",
+    minute: "2-digit",
+    second: "2-digit",
+  });
+}
+
+function HeaderList({ headers }: { headers: Record<string, string> }) {
+  const entries = Object.entries(headers);
+  if (entries.length === 0) {
+    return <div className="text-muted-foreground">(no headers)</div>;
+  }
+  return (
+    <>
+      {entries.map(([name, value]) => (
+        <div key={name}>
+          <span className="text-sky-600">{name}</span>: {value}
+        </div>
+      ))}
+    </>
+  );
+}
+
+export function HttpResponsePanel() {
+  const { isHttpOpen, setHttpOpen } = useEditorStore();
+  const { exchange, isSending, history, environments, envFile, env, setEnv } =
+    useHttpStore();
+  const [view, setView] = useState<View>("body");
+
+  useEffect(() => {
+    if (isHttpOpen) fetchHttpHistory();
+  }, [isHttpOpen]);
+
+  // A new response replaces the history list
+  useEffect(() => {
+    if (exchange) {
+      setView((current) => (current === "history" ? "body" : current));
+    }
+  }, [exchange]);
+
+  const body = useMemo(
+    () => (exchange ? formatResponseBody(exchange) : ""),
+    [exchange],
+  );
+
+  if (!isHttpOpen) return null;
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="HTTP Response"
+      data-focus-region="HTTP Response"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex min-w-0 items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            HTTP
+          </span>
+          {isSending && (
+            <Loader2 className="h-4 w-4 animate-spin text-muted-foreground" />
+          )}
+          {exchange && (
+            <span className="flex min-w-0 items-center gap-2 text-muted-foreground">
+              <span className={cn("font-medium", statusColor(exchange.status))}>
+                {exchange.error
+                  ? "Failed"
+                  : `${exchange.status} ${exchange.statusText}`}
+              </span>
+              <span>{exchange.durationMs} ms</span>
+              {!exchange.error && <span>{formatSize(exchange.size)}</span>}
+              <span className="truncate font-mono" title={exchange.url}>
+                {exchange.method} {exchange.url}
+              </span>
+            </span>
+          )}
+        </div>
+        <div className="flex items-center gap-1">
+          <select
+            value={env ?? ""}
+            onChange={(event) => setEnv(event.target.value || null)}
+            className="rounded border bg-background px-1 py-[1px] text-xs"
+            aria-label="Environment"
+            title={
+              envFile
+                ? `Environments from ${envFile}`
+                : "Add an http-client.env.json file to define environments"
+            }
+          >
+            <option value="">No environment</option>
+            {environments.map((name) => (
+              <option key={name} value={name}>
+                {name}
+              </option>
+            ))}
+            {env && !environments.includes(env) && (
+              <option value={env}>{env}</option>
+            )}
+          </select>
+          <button
+            type="button"
+            onClick={() =>
+              exchange && sendHttpRequest(exchange.uri, exchange.line)
+            }
+            disabled={!exchange || isSending}
+            className={headerButton}
+            title="Send Again"
+          >
+            <RotateCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setHttpOpen(false)}
+            className={headerButton}
+            aria-label="Close HTTP Response"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Tabs */}
+      <div
+        className="flex items-center gap-1 border-b px-2 text-xs"
+        role="tablist"
+      >
+        {VIEWS.map((item) => (
+          <button
+            key={item.id}
+            type="button"
+            role="tab"
+            aria-selected={view === item.id}
+            onClick={() => setView(item.id)}
+            className={cn(
+              "border-b-2 px-2 py-1",
+              view === item.id
+                ? "border-primary text-foreground"
+                : "border-transparent text-muted-foreground hover:text-foreground",
+            )}
+          >
+            {item.label}
+            {item.id === "history" &&
+              history.length > 0 &&
+              ` ${history.length}`}
+          </button>
+        ))}
+        <span className="flex-1" />
+        {view === "history" && history.length > 0 && (
+          <button
+            type="button"
+            onClick={() => clearHttpHistory()}
+            className={headerButton}
+            title="Clear History"
+          >
+            <Trash2 className="h-3.5 w-3.5" />
+          </button>
+        )}
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto">
+        {view === "history" ? (
+          history.length === 0 ? (
+            <div className="px-4 py-3 text-[13px] text-muted-foreground">
+              No requests sent from this workspace yet.
+            </div>
+          ) : (
+            <div className="py-1 text-[13px]">
+              {history.map((entry) => (
+                <button
+                  key={entry.id}
+                  type="button"
+                  onClick={() => openHttpHistoryEntry(entry.id)}
+                  className={cn(
+                    "flex w-full items-center gap-2 px-3 py-0.5 text-left hover:bg-muted/40",
+                    exchange?.id === entry.id && "bg-muted/60",
+                  )}
+                >
+                  <span className="w-16 text-xs text-muted-foreground tabular-nums">
+                    {formatTime(entry.timestamp)}
+                  </span>
+                  <span
+                    className={cn(
+                      "w-10 font-mono text-xs tabular-nums",
+                      statusColor(entry.status),
+                    )}
+                  >
+                    {entry.error ? "ERR" : entry.status}
+                  </span>
+                  <span className="truncate font-mono text-xs text-foreground">
+                    {entry.method} {entry.url}
+                  </span>
+                  <span className="flex-1" />
+                  {entry.env && (
+                    <span className="text-xs text-muted-foreground">
+                      {entry.env}
+                    </span>
+                  )}
+                  <span className="text-xs text-muted-foreground tabular-nums">
+                    {entry.durationMs} ms
+                  </span>
+                </button>
+              ))}
+            </div>
+          )
+        ) : !exchange ? (
+          <div className="px-4 py-3 text-[13px] text-muted-foreground">
+            Open a .http or .rest file and click Send Request above a request
+            (or press Ctrl+Alt+R) to see the response here.
+          </div>
+        ) : (
+          <pre className="whitespace-pre-wrap break-all px-3 py-2 font-mono text-xs text-foreground">
+            {view === "body" &&
+              (exchange.error ? (
+                <span className="text-red-500">{exchange.error}</span>
+              ) : (
+                <>
+                  {body || (
+                    <span className="text-muted-foreground">(empty body)</span>
+                  )}
+                  {exchange.truncated && (
+                    <div className="mt-1 text-muted-foreground">
+                      … truncated, {formatSize(exchange.size)} in total
+                    </div>
+                  )}
+                </>
+              ))}
+            {view === "headers" && <HeaderList headers={exchange.headers} />}
+            {view === "request" && (
+              <>
+                <button
+                  type="button"
+                  onClick={() =>
+                    !isUntitledUri(exchange.uri) &&
+                    openWorkspaceFile(
+                      uriToWorkspacePath(exchange.uri),
+                      exchange.line,
+                    )
+                  }
+                  className="mb-1 block text-left text-muted-foreground hover:underline"
+                  title="Go to the request"
+                >
+                  {isUntitledUri(exchange.uri)
+                    ? exchange.uri
+                    : `${uriToWorkspacePath(exchange.uri)}:${exchange.line}`}
+                  {exchange.name && ` (${exchange.name})`}
+                  {exchange.env && ` · ${exchange.env}`}
+                </button>
+                <div>
+                  <span className="text-sky-600">{exchange.method}</span>{" "}
+                  {exchange.url}
+                </div>
+                <HeaderList headers={exchange.requestHeaders} />
+                {exchange.requestBody && (
+                  <div className="mt-2">{exchange.requestBody}</div>
+                )}
+              </>
+            )}
+          </pre>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,256 @@
+"use client";
+
+import {
+  api,
+  describeApiError,
+  type CreateProjectResult,
+  type ProjectTemplate,
+} from "@/lib/api";
+import { useEditorStore } from "@/lib/store";
+import { cn } from "@/lib/utils";
+import { CheckCircle2, LayoutTemplate, Loader2, X, XCircle } from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+interface NewProjectDialogProps {
+  onCreated: () => void;
+}
+
+export function NewProjectDialog({ onCreated }: NewProjectDialogProps) {
+  const { isNewProjectOpen, setNewProjectOpen } = useEditorStore();
+  const [templates, setTemplates] = useState<ProjectTemplate[]>([]);
+  const [selectedId, setSelectedId] = useState<string | null>(null);
+  const [values, setValues] = useState<Record<string, string>>({});
+  const [targetPath, setTargetPath] = useState("/{{projectName}}");
+  const [isCreating, setIsCreating] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [result, setResult] = useState<CreateProjectResult | null>(null);
+
+  useEffect(() => {
+    if (!isNewProjectOpen) return;
+
+    setResult(null);
+    setError(null);
+    api
+      .listTemplates()
+      .then((list) => {
+        setTemplates(list);
+        setSelectedId((current) => current ?? list[0]?.id ?? null);
+      })
+      .catch((err) => {
+        console.error("Error loading templates:", err);
+        setError(describeApiError(err));
+      });
+  }, [isNewProjectOpen]);
+
+  const selected = useMemo(
+    () => templates.find((t) => t.id === selectedId) ?? null,
+    [templates, selectedId],
+  );
+
+  // Reset variable inputs when switching templates
+  useEffect(() => {
+    setValues({});
+    setResult(null);
+    setError(null);
+  }, [selectedId]);
+
+  useEffect(() => {
+    if (!isNewProjectOpen) return;
+    const handleEscape = (event: KeyboardEvent) => {
+      if (event.key === "Escape" && !isCreating) {
+        setNewProjectOpen(false);
+      }
+    };
+    document.addEventListener("keydown", handleEscape);
+    return () => document.removeEventListener("keydown", handleEscape);
+  }, [isNewProjectOpen, isCreating, setNewProjectOpen]);
+
+  if (!isNewProjectOpen) return null;
+
+  const handleCreate = async () => {
+    if (!selected) return;
+    setIsCreating(true);
+    setError(null);
+    setResult(null);
+
+    try {
+      setResult(
+        await api.createProject(selected.id, { targetPath, variables: values }),
+      );
+      onCreated();
+    } catch (err) {
+      console.error("Error creating project:", err);
+      setError(describeApiError(err));
+    } finally {
+      setIsCreating(false);
+    }
+  };
+
+  return (
+    <div className="fixed inset-0 z-50 flex items-center justify-center bg-black/40">
+      <div
+        role="dialog"
+        aria-modal="true"
+        aria-labelledby="new-project-title"
+        className="flex max-h-[80vh] w-[720px] max-w-[95vw] flex-col rounded-md border bg-popover text-popover-foreground shadow-md"
+      >
+        <div className="flex items-center justify-between border-b px-4 py-2">
+          <div className="flex items-center gap-2">
+            <LayoutTemplate className="h-4 w-4" />
+            <span id="new-project-title" className="text-sm font-semibold">
+              New Project from Template
+            </span>
+          </div>
+          <button
+            type="button"
+            onClick={() => setNewProjectOpen(false)}
+            className="rounded p-0.5 text-muted-foreground hover:bg-muted hover:text-foreground"
+            aria-label="Close"
+            disabled={isCreating}
+          >
+            <X className="h-4 w-4" />
+          </button>
+        </div>
+
+        <div className="flex min-h-0 flex-1">
+          <div className="w-56 overflow-y-auto border-r p-2">
+            {templates.length === 0 ? (
+              <div className="p-2 text-sm text-muted-foreground">
+                No templates available
+              </div>
+            ) : (
+              templates.map((template) => (
+                <button
+                  key={template.id}
+                  type="button"
+                  onClick={() => setSelectedId(template.id)}
+                  className={cn(
+                    "w-full rounded px-2 py-1.5 text-left text-sm transition-colors",
+                    template.id === selectedId
+                      ? "bg-accent text-accent-foreground"
+                      : "hover:bg-accent/50",
+                  )}
+                >
+                  <div className="font-medium">{template.name}</div>
+                  {template.language && (
+                    <div className="text-xs text-muted-foreground">
+                      {template.language}
+                    </div>
+                  )}
+                </button>
+              ))
+            )}
+          </div>
+
+          <div className="flex-1 overflow-y-auto p-4 text-sm">
+            {selected ? (
+              <div className="flex flex-col gap-3">
+                <p className="text-muted-foreground">{selected.description}</p>
+
+                <label className="flex flex-col gap-1">
+                  <span className="font-medium">Target folder</span>
+                  <input
+                    className="rounded border bg-background px-2 py-1 font-mono text-xs"
+                    value={targetPath}
+                    onChange={(e) => setTargetPath(e.target.value)}
+                  />
+                  <span className="text-xs text-muted-foreground">
+                    Workspace path; may reference variables like {"{{projectName}}"}
+                  </span>
+                </label>
+
+                {selected.variables.map((variable) => (
+                  <label key={variable.name} className="flex flex-col gap-1">
+                    <span className="font-medium">
+                      {variable.name}
+                      {variable.required === false && (
+                        <span className="ml-1 text-xs font-normal text-muted-foreground">
+                          (optional)
+                        </span>
+                      )}
+                    </span>
+                    <input
+                      className="rounded border bg-background px-2 py-1 font-mono text-xs"
+                      placeholder={variable.default}
+                      value={values[variable.name] ?? ""}
+                      onChange={(e) =>
+                        setValues((prev) => ({
+                          ...prev,
+                          [variable.name]: e.target.value,
+                        }))
+                      }
+                    />
+                    {variable.description && (
+                      <span className="text-xs text-muted-foreground">
+                        {variable.description}
+                      </span>
+                    )}
+                  </label>
+                ))}
+
+                {error && (
+                  <div className="rounded border border-red-500/40 bg-red-500/10 px-2 py-1 text-red-500">
+                    {error}
+                  </div>
+                )}
+
+                {result && (
+                  <div className="flex flex-col gap-2 rounded border bg-muted/20 p-2">
+                    <div className="font-medium">
+                      Created {result.path} ({result.files.length} file
+                      {result.files.length === 1 ? "" : "s"})
+                    </div>
+                    {result.hooks.map((hook) => (
+                      <div key={hook.task.id} className="flex flex-col gap-1">
+                        <div className="flex items-center gap-1.5 text-xs">
+                          {hook.task.status === "succeeded" ? (
+                            <CheckCircle2 className="h-3.5 w-3.5 text-emerald-500" />
+                          ) : (
+                            <XCircle
+                              className={cn(
+                                "h-3.5 w-3.5",
+                                hook.optional ? "text-amber-500" : "text-red-500",
+                              )}
+                            />
+                          )}
+                          <span className="font-mono">{hook.task.label}</span>
+                        </div>
+                        {hook.output && (
+                          <pre className="max-h-32 overflow-auto whitespace-pre-wrap rounded bg-background p-1.5 text-[11px] text-muted-foreground">
+                            {hook.output}
+                          </pre>
+                        )}
+                      </div>
+                    ))}
+                  </div>
+                )}
+              </div>
+            ) : (
+              <div className="text-muted-foreground">Select a template</div>
+            )}
+          </div>
+        </div>
+
+        <div className="flex justify-end gap-2 border-t px-4 py-2">
+          <button
+            type="button"
+            className="rounded px-3 py-1.5 text-sm hover:bg-muted"
+            onClick={() => setNewProjectOpen(false)}
+            disabled={isCreating}
+          >
+            {result ? "Close" : "Cancel"}
+          </button>
+          <button
+            type="button"
+            className="flex items-center gap-1.5 rounded bg-primary px-3 py-1.5 text-sm text-primary-foreground disabled:opacity-50"
+            onClick={handleCreate}
+            disabled={!selected || isCreating}
+          >
+            {isCreating && <Loader2 className="h-3.5 w-3.5 animate-spin" />}
+            Create
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,342 @@
+"use client";
+
+import { fetchNpmPackages, runNpmCommand, runNpmScript } from "@/lib/npm";
+import { useEditorStore, type NpmPackage } from "@/lib/store";
+import { killTask, useTaskStore, watchTasks } from "@/lib/tasks";
+import { cn } from "@/lib/utils";
+import {
+  ArrowUpCircle,
+  Download,
+  Loader2,
+  Play,
+  RefreshCw,
+  Square,
+  XCircle,
+} from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+const tabButton = "rounded px-1.5 py-[1px] uppercase tracking-wide";
+
+const STATUS_STYLES: Record<NpmPackage["dependencies"][number]["status"], string> = {
+  ok: "text-green-600",
+  missing: "text-red-500",
+  mismatch: "text-amber-500",
+  unknown: "text-muted-foreground",
+};
+
+const STATUS_TITLES: Record<NpmPackage["dependencies"][number]["status"], string> = {
+  ok: "The installed version satisfies the declared range",
+  missing: "Not installed; run Install",
+  mismatch: "The installed version doesn't satisfy the declared range",
+  unknown: "Not a semver range, or an optional or peer dependency",
+};
+
+export function NpmPanel() {
+  const { npmPackages, isNpmOpen, setNpmOpen, lspManager } = useEditorStore();
+  const { tasks, output } = useTaskStore();
+  const [selectedPath, setSelectedPath] = useState<string | null>(null);
+  const [view, setView] = useState<"scripts" | "dependencies">("scripts");
+  const [taskId, setTaskId] = useState<string | null>(null);
+  const [showOutput, setShowOutput] = useState(false);
+
+  useEffect(() => {
+    if (isNpmOpen) fetchNpmPackages();
+  }, [isNpmOpen]);
+
+  useEffect(() => {
+    if (!lspManager) return;
+    watchTasks(lspManager);
+    const subscription = lspManager.onNotification(
+      "workspace/npmPackagesChanged",
+      () => {
+        if (useEditorStore.getState().isNpmOpen) fetchNpmPackages();
+      },
+    );
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  const task = taskId ? tasks[taskId] : undefined;
+  const isRunning = task?.status === "running";
+
+  // Installs change node_modules, so versions are reread once they finish
+  useEffect(() => {
+    if (taskId && !isRunning) fetchNpmPackages();
+  }, [taskId, isRunning]);
+
+  const pkg = useMemo(
+    () =>
+      npmPackages.find((p) => p.path === selectedPath) ?? npmPackages[0] ?? null,
+    [npmPackages, selectedPath],
+  );
+
+  if (!isNpmOpen) return null;
+
+  const scripts = Object.entries(pkg?.scripts ?? {});
+  const problemCount =
+    pkg?.dependencies.filter(
+      (dependency) =>
+        dependency.status === "missing" || dependency.status === "mismatch",
+    ).length ?? 0;
+
+  const start = async (
+    run: () => Promise<{ id: string } | undefined>,
+  ) => {
+    const started = await run();
+    if (started) {
+      setTaskId(started.id);
+      setShowOutput(true);
+    }
+  };
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="NPM Scripts"
+      data-focus-region="NPM Scripts"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            NPM Scripts
+          </span>
+          {npmPackages.length > 1 && (
+            <select
+              className="rounded border bg-background px-1 py-[1px] text-xs"
+              value={pkg?.path ?? ""}
+              onChange={(event) => setSelectedPath(event.target.value)}
+            >
+              {npmPackages.map((p) => (
+                <option key={p.path} value={p.path}>
+                  {p.name ? `${p.name} (${p.path})` : p.path}
+                </option>
+              ))}
+            </select>
+          )}
+          {npmPackages.length === 1 && pkg && (
+            <span className="font-mono text-muted-foreground">
+              {pkg.name ?? pkg.path}
+            </span>
+          )}
+          {pkg && (
+            <div className="flex items-center gap-1">
+              <button
+                type="button"
+                onClick={() => {
+                  setView("scripts");
+                  setShowOutput(false);
+                }}
+                className={cn(
+                  tabButton,
+                  view === "scripts" && !showOutput
+                    ? "bg-background text-foreground"
+                    : "text-muted-foreground hover:text-foreground",
+                )}
+              >
+                Scripts {scripts.length}
+              </button>
+              <button
+                type="button"
+                onClick={() => {
+                  setView("dependencies");
+                  setShowOutput(false);
+                }}
+                className={cn(
+                  tabButton,
+                  view === "dependencies" && !showOutput
+                    ? "bg-background text-foreground"
+                    : "text-muted-foreground hover:text-foreground",
+                )}
+              >
+                Dependencies {pkg.dependencies.length}
+                {problemCount > 0 && (
+                  <span className="ml-1 text-amber-500">({problemCount})</span>
+                )}
+              </button>
+            </div>
+          )}
+          {pkg && (
+            <span className="text-muted-foreground" title={pkg.lockfile ?? "No lockfile"}>
+              {pkg.packageManager}
+            </span>
+          )}
+        </div>
+        <div className="flex items-center gap-1">
+          {isRunning && (
+            <Loader2 className="h-4 w-4 animate-spin text-muted-foreground" />
+          )}
+          <button
+            type="button"
+            onClick={() => pkg && start(() => runNpmCommand(pkg.path, "install"))}
+            disabled={!pkg || isRunning}
+            className={headerButton}
+            title={`Install (${pkg?.packageManager ?? "npm"} install)`}
+          >
+            <Download className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => pkg && start(() => runNpmCommand(pkg.path, "update"))}
+            disabled={!pkg || isRunning}
+            className={headerButton}
+            title="Update All"
+          >
+            <ArrowUpCircle className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => fetchNpmPackages()}
+            className={headerButton}
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setNpmOpen(false)}
+            className={headerButton}
+            aria-label="Close NPM Scripts"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {task && (
+        <div
+          className={cn(
+            "flex items-center gap-2 border-b px-3 py-0.5 text-xs",
+            task.status === "failed" ? "text-red-500" : "text-muted-foreground",
+          )}
+        >
+          <span className="font-mono">{task.label}</span>
+          <span>{task.status}</span>
+          {task.exitCode !== null && task.status !== "succeeded" && (
+            <span>(exit code {task.exitCode})</span>
+          )}
+          {isRunning && (
+            <button
+              type="button"
+              onClick={() => killTask(task.id)}
+              className="flex items-center gap-1 rounded border px-1.5 text-[11px] text-foreground hover:bg-muted"
+              title="Stop"
+            >
+              <Square className="h-3 w-3" />
+              Stop
+            </button>
+          )}
+          <button
+            type="button"
+            onClick={() => setShowOutput(!showOutput)}
+            className="ml-auto hover:text-foreground"
+          >
+            {showOutput ? "Hide output" : "Show output"}
+          </button>
+        </div>
+      )}
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {showOutput && task ? (
+          <pre className="whitespace-pre-wrap px-3 py-2 font-mono text-xs text-foreground">
+            {output[task.id] || (isRunning ? "" : "(no output)")}
+          </pre>
+        ) : !pkg ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No package.json found in the workspace.
+          </div>
+        ) : view === "scripts" ? (
+          scripts.length === 0 ? (
+            <div className="px-4 py-3 text-muted-foreground">
+              {pkg.name ?? pkg.path} has no scripts.
+            </div>
+          ) : (
+            <div className="py-1">
+              {scripts.map(([name, command]) => (
+                <div
+                  key={name}
+                  className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40"
+                >
+                  <button
+                    type="button"
+                    onClick={() => start(() => runNpmScript(pkg.path, name))}
+                    disabled={isRunning}
+                    className="rounded p-0.5 text-green-600 hover:bg-muted disabled:opacity-50"
+                    title={`${pkg.packageManager} run ${name}`}
+                  >
+                    <Play className="h-3.5 w-3.5" />
+                  </button>
+                  <span className="font-mono text-foreground">{name}</span>
+                  <span className="truncate font-mono text-xs text-muted-foreground">
+                    {command}
+                  </span>
+                </div>
+              ))}
+            </div>
+          )
+        ) : pkg.dependencies.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            {pkg.name ?? pkg.path} has no dependencies.
+          </div>
+        ) : (
+          <div className="py-1">
+            {pkg.dependencies.map((dependency) => (
+              <div
+                key={dependency.name}
+                className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40"
+              >
+                <span className="truncate font-mono text-foreground">
+                  {dependency.name}
+                </span>
+                <span className="font-mono text-xs text-muted-foreground" title="Declared">
+                  {dependency.declared}
+                </span>
+                {dependency.installed && (
+                  <span className="font-mono text-xs text-foreground" title="Installed">
+                    → {dependency.installed}
+                  </span>
+                )}
+                {dependency.locked && dependency.locked !== dependency.installed && (
+                  <span
+                    className="rounded-full border px-1.5 text-[11px] text-muted-foreground"
+                    title={`Locked in ${pkg.lockfile}`}
+                  >
+                    lock {dependency.locked}
+                  </span>
+                )}
+                {dependency.type !== "dependencies" && (
+                  <span className="rounded-full border px-1.5 text-[11px] text-muted-foreground">
+                    {dependency.type.replace("Dependencies", "")}
+                  </span>
+                )}
+                <span
+                  className={cn("text-[11px]", STATUS_STYLES[dependency.status])}
+                  title={STATUS_TITLES[dependency.status]}
+                >
+                  {dependency.status}
+                </span>
+                <span className="flex-1" />
+                <button
+                  type="button"
+                  onClick={() =>
+                    start(() => runNpmCommand(pkg.path, "update", dependency.name))
+                  }
+                  disabled={isRunning}
+                  className="invisible rounded border px-1.5 text-[11px] text-foreground hover:bg-background group-hover:visible disabled:opacity-50"
+                  title={`Update ${dependency.name} within its declared range`}
+                >
+                  Update
+                </button>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
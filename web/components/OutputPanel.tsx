@@ -0,0 +1,164 @@
+"use client";
+
+import {
+  configureRun,
+  openRunProblem,
+  runFile,
+  useRunStore,
+  type RunProblem,
+} from "@/lib/run";
+import { useEditorStore } from "@/lib/store";
+import { killTask, useTaskStore, watchTasks } from "@/lib/tasks";
+import { cn } from "@/lib/utils";
+import { Loader2, Play, Settings2, Square, XCircle } from "lucide-react";
+import React, { useEffect, useMemo, useRef } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+export function OutputPanel() {
+  const { isOutputOpen, setOutputOpen, lspManager } = useEditorStore();
+  const { taskId, uri, problems } = useRunStore();
+  const task = useTaskStore((state) =>
+    taskId ? state.tasks[taskId] : undefined,
+  );
+  const output = useTaskStore((state) =>
+    taskId ? state.output[taskId] : undefined,
+  );
+  const scrollRef = useRef<HTMLDivElement | null>(null);
+
+  useEffect(() => {
+    if (lspManager) watchTasks(lspManager);
+  }, [lspManager]);
+
+  // Follow the output as it streams in
+  useEffect(() => {
+    const element = scrollRef.current;
+    if (element) element.scrollTop = element.scrollHeight;
+  }, [output, isOutputOpen]);
+
+  // Output lines that mention a source location link to it
+  const problemsByLine = useMemo(() => {
+    const byLine = new Map<number, RunProblem>();
+    for (const problem of problems) {
+      if (!byLine.has(problem.outputLine)) {
+        byLine.set(problem.outputLine, problem);
+      }
+    }
+    return byLine;
+  }, [problems]);
+
+  if (!isOutputOpen) return null;
+
+  const isRunning = task?.status === "running";
+  const lines = output ? output.replace(/\n$/, "").split("\n") : [];
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Output"
+      data-focus-region="Output"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Output
+          </span>
+          {task && (
+            <span
+              className={cn(
+                "flex items-center gap-2",
+                task.status === "failed"
+                  ? "text-red-500"
+                  : "text-muted-foreground",
+              )}
+            >
+              <span className="font-mono">{task.label}</span>
+              <span>{task.status}</span>
+              {task.exitCode !== null && (
+                <span>(exit code {task.exitCode})</span>
+              )}
+            </span>
+          )}
+        </div>
+        <div className="flex items-center gap-1">
+          {isRunning && (
+            <>
+              <Loader2 className="h-4 w-4 animate-spin text-muted-foreground" />
+              <button
+                type="button"
+                onClick={() => killTask(task.id)}
+                className="flex items-center gap-1 rounded border px-1.5 text-[11px] text-foreground hover:bg-muted"
+                title="Stop"
+              >
+                <Square className="h-3 w-3" />
+                Stop
+              </button>
+            </>
+          )}
+          <button
+            type="button"
+            onClick={() => uri && runFile(uri)}
+            disabled={!uri || isRunning}
+            className={headerButton}
+            title="Run Again"
+          >
+            <Play className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => uri && configureRun(uri)}
+            disabled={!uri}
+            className={headerButton}
+            title="Arguments and Environment..."
+          >
+            <Settings2 className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setOutputOpen(false)}
+            className={headerButton}
+            aria-label="Close Output"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div ref={scrollRef} className="flex-1 overflow-y-auto">
+        {!task ? (
+          <div className="px-4 py-3 text-[13px] text-muted-foreground">
+            Run a Go, JavaScript or TypeScript file (Ctrl+F5) to see its
+            output here.
+          </div>
+        ) : (
+          <pre className="whitespace-pre-wrap px-3 py-2 font-mono text-xs text-foreground">
+            {lines.length === 0 && !isRunning && "(no output)"}
+            {lines.map((line, index) => {
+              const problem = problemsByLine.get(index);
+              if (!problem) return <div key={index}>{line || " "}</div>;
+              return (
+                <button
+                  key={index}
+                  type="button"
+                  onClick={() => openRunProblem(problem)}
+                  className={cn(
+                    "block w-full text-left hover:bg-muted/40 hover:underline",
+                    problem.message ? "text-red-500" : "text-sky-600",
+                  )}
+                  title={`Go to line ${problem.line}`}
+                >
+                  {line}
+                </button>
+              );
+            })}
+          </pre>
+        )}
+      </div>
+    </div>
+  );
+}
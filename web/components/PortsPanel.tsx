@@ -0,0 +1,112 @@
+"use client";
+
+import { fetchPorts, getPreviewUrl, openPreview } from "@/lib/ports";
+import { useEditorStore } from "@/lib/store";
+import { ExternalLink, Eye, RefreshCw, XCircle } from "lucide-react";
+import React, { useEffect } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+export function PortsPanel() {
+  const { ports, isPortsOpen, setPortsOpen, previewPort } = useEditorStore();
+
+  useEffect(() => {
+    if (isPortsOpen) fetchPorts();
+  }, [isPortsOpen]);
+
+  if (!isPortsOpen) return null;
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Ports"
+      data-focus-region="Ports"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Ports
+          </span>
+          <span className="text-muted-foreground">{ports.length}</span>
+        </div>
+        <div className="flex items-center gap-1">
+          <button
+            type="button"
+            onClick={() => fetchPorts(true)}
+            className={headerButton}
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setPortsOpen(false)}
+            className={headerButton}
+            aria-label="Close Ports"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {ports.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No process started from the workspace is listening on a port.
+            Start a dev server (e.g. an NPM script or Run Current File) and it
+            shows up here.
+          </div>
+        ) : (
+          <div className="py-1">
+            {ports.map((port) => (
+              <div
+                key={port.port}
+                className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40"
+              >
+                <span className="w-14 font-mono tabular-nums text-foreground">
+                  {port.port}
+                </span>
+                <span
+                  className="font-mono text-xs text-muted-foreground"
+                  title={`PID ${port.pid} on ${port.host}`}
+                >
+                  {port.command}
+                </span>
+                {port.taskLabel && (
+                  <span className="truncate text-xs text-muted-foreground">
+                    {port.taskLabel}
+                  </span>
+                )}
+                <span className="flex-1" />
+                <button
+                  type="button"
+                  onClick={() => openPreview(port.port)}
+                  className="flex items-center gap-1 rounded border px-1.5 text-[11px] text-foreground hover:bg-background"
+                  aria-pressed={previewPort === port.port}
+                  title="Preview in the editor"
+                >
+                  <Eye className="h-3 w-3" />
+                  Preview
+                </button>
+                <a
+                  href={getPreviewUrl(port.port)}
+                  target="_blank"
+                  rel="noopener noreferrer"
+                  className={headerButton}
+                  title="Open in a new browser tab"
+                >
+                  <ExternalLink className="h-3.5 w-3.5" />
+                </a>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
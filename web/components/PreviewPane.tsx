@@ -0,0 +1,137 @@
+"use client";
+
+import { getPreviewUrl } from "@/lib/ports";
+import { useEditorStore } from "@/lib/store";
+import { cn } from "@/lib/utils";
+import { ExternalLink, RefreshCw, XCircle } from "lucide-react";
+import React, { useEffect, useRef, useState } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+// Give dev servers a moment to rebuild after a save before reloading
+const RELOAD_DELAY_MS = 500;
+
+export function PreviewPane() {
+  const { previewPort, setPreviewPort, ports, previewReloadVersion } =
+    useEditorStore();
+  const [path, setPath] = useState("/");
+  const [address, setAddress] = useState("/");
+  const [frameKey, setFrameKey] = useState(0);
+  const [autoReload, setAutoReload] = useState(true);
+  const frameRef = useRef<HTMLIFrameElement | null>(null);
+
+  useEffect(() => {
+    setPath("/");
+    setAddress("/");
+  }, [previewPort]);
+
+  const reload = () => {
+    try {
+      // Keeps the page the user navigated to when the frame is same-origin
+      frameRef.current?.contentWindow?.location.reload();
+    } catch {
+      setFrameKey((key) => key + 1);
+    }
+  };
+
+  useEffect(() => {
+    if (!autoReload || previewReloadVersion === 0) return;
+    const timer = setTimeout(reload, RELOAD_DELAY_MS);
+    return () => clearTimeout(timer);
+  }, [previewReloadVersion, autoReload]);
+
+  if (previewPort === null) return null;
+
+  const isListening = ports.some((port) => port.port === previewPort);
+  const url = getPreviewUrl(previewPort, path);
+
+  return (
+    <div
+      className="flex w-[40%] min-w-[280px] flex-col border-l bg-background"
+      role="region"
+      aria-label="Preview"
+      data-focus-region="Preview"
+    >
+      {/* Header */}
+      <div className="flex items-center gap-2 border-b px-3 py-1.5 text-xs bg-muted/30">
+        <span className="font-medium text-foreground uppercase tracking-wide">
+          Preview
+        </span>
+        <span
+          className={cn(
+            "font-mono",
+            isListening ? "text-muted-foreground" : "text-red-500",
+          )}
+          title={isListening ? undefined : "Nothing is listening on this port"}
+        >
+          :{previewPort}
+        </span>
+        <form
+          className="flex-1"
+          onSubmit={(event) => {
+            event.preventDefault();
+            const next = `/${address.trim().replace(/^\/+/, "")}`;
+            setAddress(next);
+            if (next === path) {
+              reload();
+            } else {
+              setPath(next);
+            }
+          }}
+        >
+          <input
+            value={address}
+            onChange={(event) => setAddress(event.target.value)}
+            className="w-full rounded border bg-background px-1.5 py-[1px] font-mono text-xs"
+            aria-label="Preview path"
+          />
+        </form>
+        <label
+          className="flex items-center gap-1 text-muted-foreground"
+          title="Reload the preview when a file is saved"
+        >
+          <input
+            type="checkbox"
+            checked={autoReload}
+            onChange={(event) => setAutoReload(event.target.checked)}
+          />
+          On save
+        </label>
+        <button
+          type="button"
+          onClick={reload}
+          className={headerButton}
+          title="Reload"
+        >
+          <RefreshCw className="h-4 w-4" />
+        </button>
+        <a
+          href={url}
+          target="_blank"
+          rel="noopener noreferrer"
+          className={headerButton}
+          title="Open in a new browser tab"
+        >
+          <ExternalLink className="h-4 w-4" />
+        </a>
+        <button
+          type="button"
+          onClick={() => setPreviewPort(null)}
+          className={headerButton}
+          aria-label="Close Preview"
+        >
+          <XCircle className="h-4 w-4" />
+        </button>
+      </div>
+
+      <iframe
+        key={`${previewPort}-${frameKey}`}
+        ref={frameRef}
+        src={url}
+        title={`Preview of port ${previewPort}`}
+        className="flex-1 bg-white"
+      />
+    </div>
+  );
+}
@@ -1,5 +1,7 @@
 "use client";
 
+import { api } from "@/lib/api";
+import { detectLanguage } from "@/lib/languages";
 import { useEditorStore } from "@/lib/store";
 import { AlertTriangle, ChevronDown, ChevronRight, Info, XCircle } from "lucide-react";
 import React, { useMemo, useState } from "react";
@@ -132,29 +134,16 @@ export function ProblemsPanel() {
     const path = getRelativePath(item.uri);
     
     // Check if file is already open
-    const currentUri = editorManager.getCurrentUri();
-    if (currentUri && currentUri.includes(path)) {
+    if (editorManager.getCurrentUri() === item.uri) {
       // File is already open, just reveal position
       editorManager.revealPosition(item.line, item.column);
     } else {
       // Need to open the file first
       try {
-        const response = await fetch(`http://localhost:3001/api/file${path}`);
-        if (!response.ok) {
-          console.error('Failed to fetch file content');
-          return;
-        }
-        const content = await response.text();
-        
-        // Determine language ID from path
-        let languageId = 'plaintext';
-        if (path.endsWith('.ts') || path.endsWith('.tsx')) languageId = 'typescript';
-        else if (path.endsWith('.js') || path.endsWith('.jsx')) languageId = 'javascript';
-        else if (path.endsWith('.json')) languageId = 'json';
-        else if (path.endsWith('.md')) languageId = 'markdown';
-        
+        const { content } = await api.readFile({ path });
+
         // Open file
-        editorManager.openFile(path, content, languageId);
+        editorManager.openFile(path, content, detectLanguage(path).id);
         
         // Reveal position after a short delay to ensure file is loaded
         setTimeout(() => {
@@ -166,21 +155,38 @@ export function ProblemsPanel() {
     }
   };
 
+  // Arrow keys move between file headers and problems, like a list
+  const handleListKeyDown = (event: React.KeyboardEvent<HTMLDivElement>) => {
+    if (event.key !== "ArrowDown" && event.key !== "ArrowUp") return;
+    const buttons = Array.from(event.currentTarget.querySelectorAll("button"));
+    const index = buttons.indexOf(document.activeElement as HTMLButtonElement);
+    const next = buttons[index + (event.key === "ArrowDown" ? 1 : -1)];
+    if (next) {
+      event.preventDefault();
+      next.focus();
+    }
+  };
+
   const hasProblems = sortedFiles.length > 0;
 
   return (
-    <div className="border-t bg-background flex flex-col" style={{ height: '200px' }}>
+    <section
+      className="border-t bg-background flex flex-col"
+      style={{ height: '200px' }}
+      aria-label="Problems"
+      data-focus-region="Problems"
+    >
       {/* Header */}
       <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
         <div className="flex items-center gap-4">
           <span className="font-medium text-foreground uppercase tracking-wide">Problems</span>
           <div className="flex items-center gap-3 text-muted-foreground">
-            <span className="flex items-center gap-1">
-              <XCircle className="h-3.5 w-3.5 text-red-500" />
+            <span className="flex items-center gap-1" aria-label={`${totalErrors} errors`}>
+              <XCircle className="h-3.5 w-3.5 text-red-500" aria-hidden="true" />
               <span className="tabular-nums">{totalErrors}</span>
             </span>
-            <span className="flex items-center gap-1">
-              <AlertTriangle className="h-3.5 w-3.5 text-amber-500" />
+            <span className="flex items-center gap-1" aria-label={`${totalWarnings} warnings`}>
+              <AlertTriangle className="h-3.5 w-3.5 text-amber-500" aria-hidden="true" />
               <span className="tabular-nums">{totalWarnings}</span>
             </span>
           </div>
@@ -191,18 +197,18 @@ export function ProblemsPanel() {
           className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
           aria-label="Close Problems"
         >
-          <XCircle className="h-4 w-4" />
+          <XCircle className="h-4 w-4" aria-hidden="true" />
         </button>
       </div>
 
       {/* Content */}
-      <div className="flex-1 overflow-y-auto text-[13px]">
+      <div className="flex-1 overflow-y-auto text-[13px]" onKeyDown={handleListKeyDown}>
         {!hasProblems ? (
           <div className="px-4 py-3 text-muted-foreground">
             No problems have been detected in the workspace.
           </div>
         ) : (
-          <div className="py-1">
+          <ul className="py-1" aria-label="Files with problems">
             {sortedFiles.map((uri) => {
               const items = groupedDiagnostics[uri] || [];
               const isExpanded = expandedFiles.has(uri);
@@ -210,17 +216,18 @@ export function ProblemsPanel() {
               const warningCount = items.filter(i => i.severity === "warning").length;
               
               return (
-                <div key={uri}>
+                <li key={uri}>
                   {/* File header */}
                   <button
                     type="button"
                     onClick={() => toggleFile(uri)}
                     className="w-full flex items-center gap-1 px-2 py-0.5 hover:bg-muted/50 text-left"
+                    aria-expanded={isExpanded}
                   >
                     {isExpanded ? (
-                      <ChevronDown className="h-4 w-4 text-muted-foreground flex-shrink-0" />
+                      <ChevronDown className="h-4 w-4 text-muted-foreground flex-shrink-0" aria-hidden="true" />
                     ) : (
-                      <ChevronRight className="h-4 w-4 text-muted-foreground flex-shrink-0" />
+                      <ChevronRight className="h-4 w-4 text-muted-foreground flex-shrink-0" aria-hidden="true" />
                     )}
                     <span className="font-medium text-foreground truncate">
                       {getFileName(uri)}
@@ -230,14 +237,14 @@ export function ProblemsPanel() {
                     </span>
                     <span className="ml-auto flex items-center gap-2 flex-shrink-0 text-xs">
                       {errorCount > 0 && (
-                        <span className="flex items-center gap-0.5 text-red-500">
-                          <XCircle className="h-3 w-3" />
+                        <span className="flex items-center gap-0.5 text-red-500" aria-label={`${errorCount} errors`}>
+                          <XCircle className="h-3 w-3" aria-hidden="true" />
                           {errorCount}
                         </span>
                       )}
                       {warningCount > 0 && (
-                        <span className="flex items-center gap-0.5 text-amber-500">
-                          <AlertTriangle className="h-3 w-3" />
+                        <span className="flex items-center gap-0.5 text-amber-500" aria-label={`${warningCount} warnings`}>
+                          <AlertTriangle className="h-3 w-3" aria-hidden="true" />
                           {warningCount}
                         </span>
                       )}
@@ -246,41 +253,44 @@ export function ProblemsPanel() {
                   
                   {/* Diagnostics list */}
                   {isExpanded && (
-                    <div>
+                    <ul aria-label={`Problems in ${getFileName(uri)}`}>
                       {items.map((item, idx) => {
                         const iconInfo = severityIcon[item.severity];
                         return (
-                          <div
-                            key={`${item.uri}-${item.line}-${item.column}-${idx}`}
-                            className="flex items-start gap-2 pl-6 pr-2 py-0.5 hover:bg-muted/40 cursor-pointer"
-                            onClick={() => handleDiagnosticClick(item)}
-                          >
-                            <span className={iconInfo.color}>
-                              {iconInfo.icon}
-                            </span>
-                            <span className="flex-1 min-w-0">
-                              <span className="text-foreground break-words">{item.message}</span>
-                              {item.source && (
-                                <span className="text-muted-foreground ml-1">{item.source}</span>
-                              )}
-                              {item.code && (
-                                <span className="text-muted-foreground ml-1">({item.code})</span>
-                              )}
-                            </span>
-                            <span className="text-muted-foreground tabular-nums flex-shrink-0 text-xs">
-                              [{item.line}, {item.column}]
-                            </span>
-                          </div>
+                          <li key={`${item.uri}-${item.line}-${item.column}-${idx}`}>
+                            <button
+                              type="button"
+                              className="w-full flex items-start gap-2 pl-6 pr-2 py-0.5 hover:bg-muted/40 cursor-pointer text-left"
+                              onClick={() => handleDiagnosticClick(item)}
+                              aria-label={`${item.severity}: ${item.message}${item.source ? `, ${item.source}` : ""}, line ${item.line}, column ${item.column}`}
+                            >
+                              <span className={iconInfo.color} aria-hidden="true">
+                                {iconInfo.icon}
+                              </span>
+                              <span className="flex-1 min-w-0">
+                                <span className="text-foreground break-words">{item.message}</span>
+                                {item.source && (
+                                  <span className="text-muted-foreground ml-1">{item.source}</span>
+                                )}
+                                {item.code && (
+                                  <span className="text-muted-foreground ml-1">({item.code})</span>
+                                )}
+                              </span>
+                              <span className="text-muted-foreground tabular-nums flex-shrink-0 text-xs">
+                                [{item.line}, {item.column}]
+                              </span>
+                            </button>
+                          </li>
                         );
                       })}
-                    </div>
+                    </ul>
                   )}
-                </div>
+                </li>
               );
             })}
-          </div>
+          </ul>
         )}
       </div>
-    </div>
+    </section>
   );
 }
@@ -0,0 +1,243 @@
+"use client";
+
+import {
+  api,
+  describeApiError,
+  type Snapshot,
+  type SnapshotHover,
+} from "@/lib/api";
+import { disableBuiltinTypeScriptFeatures } from "@/lib/editor/setup";
+import {
+  copyToClipboard,
+  getFileLink,
+  getSnapshotLink,
+  type LineRange,
+} from "@/lib/permalinks";
+import { getSnapshotUri } from "@/lib/snapshots";
+import { useEditorStore } from "@/lib/store";
+import { getMonacoTheme } from "@/lib/theme";
+import { cn } from "@/lib/utils";
+import Editor, { Monaco } from "@monaco-editor/react";
+import type * as monaco from "monaco-editor";
+import { FileCode, Link, Loader2 } from "lucide-react";
+import React, { useEffect, useMemo, useRef, useState } from "react";
+
+function formatDate(timestamp: string): string {
+  return new Date(timestamp).toLocaleString(undefined, {
+    year: "numeric",
+    month: "short",
+    day: "numeric",
+    hour: "2-digit",
+    minute: "2-digit",
+  });
+}
+
+/**
+ * Read-only view of a shared snapshot. Hovers come from what the language
+ * servers answered when it was shared, so no language server is involved.
+ */
+export function SnapshotViewer({
+  id,
+  range,
+}: {
+  id: string;
+  range?: LineRange;
+}) {
+  const { resolvedTheme } = useEditorStore();
+  const [snapshot, setSnapshot] = useState<Snapshot | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [activeIndex, setActiveIndex] = useState(0);
+  const editorRef = useRef<monaco.editor.IStandaloneCodeEditor | null>(null);
+  const monacoRef = useRef<Monaco | null>(null);
+  const hoverProvidersRef = useRef<monaco.IDisposable[]>([]);
+
+  useEffect(() => {
+    api
+      .getSnapshot(id)
+      .then(setSnapshot)
+      .catch((err) => {
+        console.error("Error loading snapshot:", err);
+        setError(describeApiError(err));
+      });
+  }, [id]);
+
+  // Hovers by model URI, then line
+  const hoversByUri = useMemo(() => {
+    const byUri = new Map<string, Map<number, SnapshotHover[]>>();
+    for (const file of snapshot?.files ?? []) {
+      const byLine = new Map<number, SnapshotHover[]>();
+      for (const hover of file.hovers) {
+        byLine.set(hover.line, [...(byLine.get(hover.line) ?? []), hover]);
+      }
+      byUri.set(getSnapshotUri(id, file.path), byLine);
+    }
+    return byUri;
+  }, [id, snapshot]);
+
+  const registerHovers = (monacoInstance: Monaco) => {
+    hoverProvidersRef.current.forEach((provider) => provider.dispose());
+    const languages = new Set(snapshot?.files.map((file) => file.languageId));
+    hoverProvidersRef.current = Array.from(languages, (languageId) =>
+      monacoInstance.languages.registerHoverProvider(languageId, {
+        provideHover: (model, position) => {
+          const hovers = hoversByUri
+            .get(model.uri.toString())
+            ?.get(position.lineNumber);
+          const hover = hovers?.find(
+            (item) =>
+              position.column >= item.startColumn &&
+              position.column < item.endColumn,
+          );
+          if (!hover) return null;
+          return {
+            range: new monacoInstance.Range(
+              hover.line,
+              hover.startColumn,
+              hover.line,
+              hover.endColumn,
+            ),
+            contents: [{ value: hover.contents }],
+          };
+        },
+      }),
+    );
+  };
+
+  useEffect(() => {
+    if (monacoRef.current) registerHovers(monacoRef.current);
+  }, [hoversByUri]);
+
+  useEffect(
+    () => () => {
+      hoverProvidersRef.current.forEach((provider) => provider.dispose());
+    },
+    [],
+  );
+
+  const selectRange = () => {
+    const editor = editorRef.current;
+    const model = editor?.getModel();
+    if (!editor || !model || !range || activeIndex !== 0) return;
+    const endLine = Math.min(range.endLine, model.getLineCount());
+    editor.setSelection({
+      startLineNumber: range.startLine,
+      startColumn: 1,
+      endLineNumber: endLine,
+      endColumn: model.getLineMaxColumn(endLine),
+    });
+    editor.revealLinesInCenter(range.startLine, endLine);
+  };
+
+  useEffect(selectRange, [snapshot, activeIndex]);
+
+  if (error) {
+    return (
+      <main className="flex h-screen flex-col items-center justify-center gap-2 bg-background text-sm">
+        <div className="font-medium text-foreground">Snapshot unavailable</div>
+        <div className="text-muted-foreground">
+          It doesn&apos;t exist, was revoked or has expired. ({error})
+        </div>
+      </main>
+    );
+  }
+
+  if (!snapshot) {
+    return (
+      <main className="flex h-screen items-center justify-center bg-background">
+        <Loader2 className="h-5 w-5 animate-spin text-muted-foreground" />
+      </main>
+    );
+  }
+
+  const file = snapshot.files[activeIndex] ?? snapshot.files[0];
+
+  return (
+    <main className="flex h-screen flex-col overflow-hidden bg-background">
+      <header className="flex items-center gap-4 border-b bg-card/80 px-4 py-2 text-sm">
+        <div className="min-w-0 leading-tight">
+          <div className="truncate font-semibold">
+            {snapshot.title || file.path}
+          </div>
+          <div className="text-xs text-muted-foreground">
+            Read-only snapshot shared by {snapshot.createdBy} on{" "}
+            {formatDate(snapshot.createdAt)}
+            {snapshot.expiresAt
+              ? ` · expires ${formatDate(snapshot.expiresAt)}`
+              : " · never expires"}
+          </div>
+        </div>
+        <div className="flex-1" />
+        <button
+          type="button"
+          className="flex items-center gap-1 rounded-full border bg-muted/50 px-3 py-1 text-xs font-medium text-foreground transition hover:bg-background"
+          onClick={() =>
+            copyToClipboard(
+              getSnapshotLink(snapshot.id, range),
+              "Snapshot link",
+            )
+          }
+        >
+          <Link className="h-3.5 w-3.5" aria-hidden="true" />
+          Copy link
+        </button>
+        <a
+          href={getFileLink(file.path, activeIndex === 0 ? range : undefined)}
+          className="flex items-center gap-1 rounded-full border bg-muted/50 px-3 py-1 text-xs font-medium text-foreground transition hover:bg-background"
+          title="Open the current version of the file in the editor"
+        >
+          <FileCode className="h-3.5 w-3.5" aria-hidden="true" />
+          Open in workspace
+        </a>
+      </header>
+
+      {snapshot.files.length > 1 && (
+        <div className="flex gap-1 border-b px-2 text-xs" role="tablist">
+          {snapshot.files.map((item, index) => (
+            <button
+              key={item.path}
+              type="button"
+              role="tab"
+              aria-selected={index === activeIndex}
+              onClick={() => setActiveIndex(index)}
+              className={cn(
+                "border-b-2 px-2 py-1 font-mono",
+                index === activeIndex
+                  ? "border-primary text-foreground"
+                  : "border-transparent text-muted-foreground hover:text-foreground",
+              )}
+            >
+              {item.path}
+            </button>
+          ))}
+        </div>
+      )}
+
+      <div className="flex-1 overflow-hidden">
+        <Editor
+          height="100%"
+          path={getSnapshotUri(snapshot.id, file.path)}
+          defaultValue={file.content}
+          defaultLanguage={file.languageId}
+          theme={getMonacoTheme(resolvedTheme)}
+          onMount={(editor, monacoInstance) => {
+            editorRef.current = editor;
+            monacoRef.current = monacoInstance;
+            disableBuiltinTypeScriptFeatures(monacoInstance);
+            registerHovers(monacoInstance);
+            selectRange();
+          }}
+          options={{
+            readOnly: true,
+            domReadOnly: true,
+            ariaLabel: `Snapshot of ${file.path}`,
+            minimap: { enabled: false },
+            fontSize: 14,
+            automaticLayout: true,
+            padding: { top: 10 },
+            scrollBeyondLastLine: false,
+          }}
+        />
+      </div>
+    </main>
+  );
+}
@@ -0,0 +1,134 @@
+"use client";
+
+import { copyToClipboard, getSnapshotLink } from "@/lib/permalinks";
+import { fetchSnapshots, revokeSnapshot } from "@/lib/snapshots";
+import { useEditorStore } from "@/lib/store";
+import { ExternalLink, Link, RefreshCw, Trash2, XCircle } from "lucide-react";
+import React, { useEffect } from "react";
+
+const headerButton =
+  "rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground disabled:opacity-50";
+
+function formatDate(timestamp: string): string {
+  return new Date(timestamp).toLocaleString(undefined, {
+    month: "short",
+    day: "numeric",
+    hour: "2-digit",
+    minute: "2-digit",
+  });
+}
+
+export function SnapshotsPanel() {
+  const { snapshots, isSnapshotsOpen, setSnapshotsOpen } = useEditorStore();
+
+  useEffect(() => {
+    if (isSnapshotsOpen) fetchSnapshots();
+  }, [isSnapshotsOpen]);
+
+  if (!isSnapshotsOpen) return null;
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="Shared Snapshots"
+      data-focus-region="Shared Snapshots"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            Shared Snapshots
+          </span>
+          <span className="text-muted-foreground">{snapshots.length}</span>
+        </div>
+        <div className="flex items-center gap-1">
+          <button
+            type="button"
+            onClick={() => fetchSnapshots()}
+            className={headerButton}
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setSnapshotsOpen(false)}
+            className={headerButton}
+            aria-label="Close Shared Snapshots"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {snapshots.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No shared snapshots. Use Share Snapshot... in the editor&apos;s
+            command palette (F1) to share a read-only copy of a file.
+          </div>
+        ) : (
+          <div className="py-1">
+            {snapshots.map((snapshot) => (
+              <div
+                key={snapshot.id}
+                className="group flex items-center gap-2 px-3 py-0.5 hover:bg-muted/40"
+              >
+                <span
+                  className="truncate font-mono text-xs text-foreground"
+                  title={snapshot.files.join("\n")}
+                >
+                  {snapshot.title || snapshot.files[0]}
+                  {snapshot.files.length > 1 &&
+                    ` +${snapshot.files.length - 1}`}
+                </span>
+                <span className="text-xs text-muted-foreground">
+                  {snapshot.createdBy} · {formatDate(snapshot.createdAt)}
+                </span>
+                <span className="flex-1" />
+                <span className="text-xs text-muted-foreground">
+                  {snapshot.expiresAt
+                    ? `expires ${formatDate(snapshot.expiresAt)}`
+                    : "never expires"}
+                </span>
+                <button
+                  type="button"
+                  onClick={() =>
+                    copyToClipboard(
+                      getSnapshotLink(snapshot.id),
+                      "Snapshot link",
+                    )
+                  }
+                  className={headerButton}
+                  title="Copy link"
+                >
+                  <Link className="h-3.5 w-3.5" />
+                </button>
+                <a
+                  href={getSnapshotLink(snapshot.id)}
+                  target="_blank"
+                  rel="noopener noreferrer"
+                  className={headerButton}
+                  title="Open in a new browser tab"
+                >
+                  <ExternalLink className="h-3.5 w-3.5" />
+                </a>
+                <button
+                  type="button"
+                  onClick={() => revokeSnapshot(snapshot.id)}
+                  className={headerButton}
+                  title="Revoke"
+                >
+                  <Trash2 className="h-3.5 w-3.5" />
+                </button>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -1,8 +1,24 @@
 "use client";
 
+import { useCommentsStore } from "@/lib/comments";
+import { executeCommand } from "@/lib/extensions/host";
+import { useExtensionStore } from "@/lib/extensions/registry";
+import type { StatusBarContribution } from "@/lib/extensions/types";
+import { getLanguageLabel, LANGUAGES } from "@/lib/languages";
+import { useRunStore } from "@/lib/run";
 import { useEditorStore } from "@/lib/store";
+import { useTaskStore } from "@/lib/tasks";
+import { changeDocumentLanguage } from "@/lib/untitled";
 import { cn } from "@/lib/utils";
-import { AlertTriangle, XCircle } from "lucide-react";
+import {
+  AlertTriangle,
+  Bookmark,
+  ListTodo,
+  Lock,
+  MessageSquare,
+  Play,
+  XCircle,
+} from "lucide-react";
 import React from "react";
 
 export function StatusBar() {
@@ -13,7 +29,25 @@ export function StatusBar() {
     editorManager,
     diagnosticsByUri,
     setProblemsOpen,
+    bookmarks,
+    isBookmarksOpen,
+    setBookmarksOpen,
+    isCommentsOpen,
+    setCommentsOpen,
+    todos,
+    isTodoOpen,
+    setTodoOpen,
+    setOutputOpen,
   } = useEditorStore();
+  const openThreads = useCommentsStore(
+    (state) =>
+      state.threads.filter((thread) => thread.status === "open").length,
+  );
+  const runTaskId = useRunStore((state) => state.taskId);
+  const runTask = useTaskStore((state) =>
+    runTaskId ? state.tasks[runTaskId] : undefined,
+  );
+  const statusBarItems = useExtensionStore((state) => state.statusBarItems);
 
   const currentModel =
     currentFile && editorManager ? editorManager.getModel(currentFile) : null;
@@ -33,21 +67,16 @@ export function StatusBar() {
     currentModel?.getLanguageId() ||
     (currentFile ? "plaintext" : null);
 
-  const languageLabel = languageId
-    ? {
-        typescript: "TypeScript",
-        javascript: "JavaScript",
-        go: "Go",
-        json: "JSON",
-        markdown: "Markdown",
-        plaintext: "Plain Text",
-      }[languageId] || languageId
-    : "No file";
+  const languageLabel = languageId ? getLanguageLabel(languageId) : "No file";
 
   return (
-    <div className="h-6 border-t bg-muted/20 flex items-center px-2 text-xs gap-3">
+    <footer
+      className="h-6 border-t bg-muted/20 flex items-center px-2 text-xs gap-3"
+      data-focus-region="Status bar"
+    >
       <div className="flex items-center gap-2 text-muted-foreground">
         <div
+          aria-hidden="true"
           className={cn(
             "w-2 h-2 rounded-full",
             isConnected ? "bg-green-500" : "bg-red-500",
@@ -60,25 +89,148 @@ export function StatusBar() {
           <span>{currentFile}</span>
         </div>
       )}
+      {currentFile && editorManager?.isReadOnly(currentFile) && (
+        <span className="flex items-center gap-1 text-muted-foreground">
+          <Lock className="h-3 w-3" aria-hidden="true" />
+          Read-only
+        </span>
+      )}
       <button
         type="button"
         onClick={() => setProblemsOpen(true)}
         className="flex items-center gap-2 text-muted-foreground hover:text-foreground transition-colors"
         title="Open Problems"
+        aria-label={`Open Problems: ${totalErrors} errors, ${totalWarnings} warnings`}
       >
         <div className="flex items-center gap-1.5 rounded px-1.5 py-[2px] hover:bg-background/40 transition-colors">
-          <XCircle className="h-3.5 w-3.5" />
+          <XCircle className="h-3.5 w-3.5" aria-hidden="true" />
           <span className="tabular-nums text-foreground">{totalErrors}</span>
         </div>
         <div className="flex items-center gap-1.5 rounded px-1.5 py-[2px] hover:bg-background/40 transition-colors">
-          <AlertTriangle className="h-3.5 w-3.5" />
+          <AlertTriangle className="h-3.5 w-3.5" aria-hidden="true" />
           <span className="tabular-nums text-foreground">{totalWarnings}</span>
         </div>
       </button>
+      <button
+        type="button"
+        onClick={() => setBookmarksOpen(!isBookmarksOpen)}
+        className="flex items-center gap-1.5 rounded px-1.5 py-[2px] text-muted-foreground hover:bg-background/40 hover:text-foreground transition-colors"
+        title="Toggle Bookmarks"
+        aria-label={`Toggle Bookmarks: ${bookmarks.length}`}
+        aria-pressed={isBookmarksOpen}
+      >
+        <Bookmark className="h-3.5 w-3.5" aria-hidden="true" />
+        <span className="tabular-nums text-foreground">{bookmarks.length}</span>
+      </button>
+      <button
+        type="button"
+        onClick={() => setCommentsOpen(!isCommentsOpen)}
+        className="flex items-center gap-1.5 rounded px-1.5 py-[2px] text-muted-foreground hover:bg-background/40 hover:text-foreground transition-colors"
+        title="Toggle Comments"
+        aria-label={`Toggle Comments: ${openThreads} open`}
+        aria-pressed={isCommentsOpen}
+      >
+        <MessageSquare className="h-3.5 w-3.5" aria-hidden="true" />
+        <span className="tabular-nums text-foreground">{openThreads}</span>
+      </button>
+      <button
+        type="button"
+        onClick={() => setTodoOpen(!isTodoOpen)}
+        className="flex items-center gap-1.5 rounded px-1.5 py-[2px] text-muted-foreground hover:bg-background/40 hover:text-foreground transition-colors"
+        title="Toggle TODOs"
+        aria-label={`Toggle TODOs: ${todos.length}`}
+        aria-pressed={isTodoOpen}
+      >
+        <ListTodo className="h-3.5 w-3.5" aria-hidden="true" />
+        <span className="tabular-nums text-foreground">{todos.length}</span>
+      </button>
+      {runTask && (
+        <button
+          type="button"
+          onClick={() => setOutputOpen(true)}
+          className={cn(
+            "flex items-center gap-1.5 rounded px-1.5 py-[2px] hover:bg-background/40 transition-colors",
+            runTask.status === "failed"
+              ? "text-red-500"
+              : "text-muted-foreground hover:text-foreground",
+          )}
+          title={`${runTask.label}: ${runTask.status}`}
+          aria-label={`Open Output: ${runTask.label} ${
+            runTask.status === "running"
+              ? "running"
+              : `exited with code ${runTask.exitCode ?? "unknown"}`
+          }`}
+        >
+          <Play className="h-3.5 w-3.5" aria-hidden="true" />
+          <span className="tabular-nums">
+            {runTask.status === "running"
+              ? "Running"
+              : runTask.status === "killed"
+                ? "Stopped"
+                : `Exit ${runTask.exitCode ?? "?"}`}
+          </span>
+        </button>
+      )}
+      {statusBarItems
+        .filter((item) => item.alignment !== "right")
+        .map((item) => (
+          <ExtensionStatusItem key={item.id} item={item} />
+        ))}
       <div className="flex-1" />
-      <div>
-        <span className="font-medium">{languageLabel}</span>
-      </div>
-    </div>
+      {statusBarItems
+        .filter((item) => item.alignment === "right")
+        .map((item) => (
+          <ExtensionStatusItem key={item.id} item={item} />
+        ))}
+      {currentFile && languageId && !editorManager?.isReadOnly(currentFile) ? (
+        <select
+          className="rounded bg-transparent px-1 font-medium text-foreground hover:bg-background/40"
+          value={languageId}
+          onChange={(event) =>
+            changeDocumentLanguage(currentFile, event.target.value)
+          }
+          title="Select Language Mode"
+          aria-label="Language mode"
+        >
+          {!LANGUAGES.some((language) => language.id === languageId) && (
+            <option value={languageId}>{languageLabel}</option>
+          )}
+          {LANGUAGES.map((language) => (
+            <option key={language.id} value={language.id}>
+              {language.label}
+            </option>
+          ))}
+        </select>
+      ) : (
+        <div>
+          <span className="font-medium">{languageLabel}</span>
+        </div>
+      )}
+    </footer>
+  );
+}
+
+function ExtensionStatusItem({ item }: { item: StatusBarContribution }) {
+  if (!item.command) {
+    return (
+      <span className="text-muted-foreground" title={item.tooltip}>
+        {item.text}
+      </span>
+    );
+  }
+
+  return (
+    <button
+      type="button"
+      onClick={() => {
+        executeCommand(item.command!).catch((error) =>
+          console.error("[Extensions] Status bar command failed:", error),
+        );
+      }}
+      className="rounded px-1.5 py-[2px] text-muted-foreground hover:bg-background/40 hover:text-foreground transition-colors"
+      title={item.tooltip}
+    >
+      {item.text}
+    </button>
   );
 }
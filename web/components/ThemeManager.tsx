@@ -1,11 +1,21 @@
 "use client";
 
-import { getStoredThemeMode } from "@/lib/theme";
+import {
+  getActiveExtensionTheme,
+  useExtensionStore,
+} from "@/lib/extensions/registry";
+import {
+  applyResolvedTheme,
+  getStoredThemeMode,
+  resolveTheme,
+} from "@/lib/theme";
 import { useEditorStore } from "@/lib/store";
 import { useEffect } from "react";
 
 export function ThemeManager() {
-  const { themeMode, setThemeMode, setResolvedTheme } = useEditorStore();
+  const { themeMode, resolvedTheme, setThemeMode, setResolvedTheme } =
+    useEditorStore();
+  const extensionTheme = useExtensionStore(getActiveExtensionTheme);
 
   useEffect(() => {
     const storedMode = getStoredThemeMode() ?? "auto";
@@ -16,14 +26,31 @@ export function ThemeManager() {
     if (typeof window === "undefined") return;
     if (themeMode !== "auto") return;
 
-    const media = window.matchMedia("(prefers-color-scheme: dark)");
-    const handler = (event: MediaQueryListEvent) => {
-      setResolvedTheme(event.matches ? "dark" : "light");
-    };
+    const queries = [
+      "(prefers-color-scheme: dark)",
+      "(prefers-contrast: more)",
+      "(forced-colors: active)",
+    ].map((query) => window.matchMedia(query));
+    const handler = () => setResolvedTheme(resolveTheme("auto"));
 
-    media.addEventListener("change", handler);
-    return () => media.removeEventListener("change", handler);
+    queries.forEach((media) => media.addEventListener("change", handler));
+    return () =>
+      queries.forEach((media) => media.removeEventListener("change", handler));
   }, [themeMode, setResolvedTheme]);
 
+  // An extension theme overrides the light/dark mode and the CSS variables it lists
+  useEffect(() => {
+    if (typeof document === "undefined" || !extensionTheme) return;
+    const root = document.documentElement;
+    const variables = Object.entries(extensionTheme.cssVariables ?? {});
+
+    applyResolvedTheme(extensionTheme.base);
+    variables.forEach(([name, value]) => root.style.setProperty(name, value));
+    return () => {
+      variables.forEach(([name]) => root.style.removeProperty(name));
+      applyResolvedTheme(resolvedTheme);
+    };
+  }, [extensionTheme, resolvedTheme]);
+
   return null;
 }
@@ -0,0 +1,238 @@
+"use client";
+
+import { openWorkspaceFile } from "@/lib/navigation";
+import { type TodoItem, useEditorStore } from "@/lib/store";
+import { fetchTodos, updateTodoTags } from "@/lib/todos";
+import { cn } from "@/lib/utils";
+import {
+  ChevronDown,
+  ChevronRight,
+  ListTodo,
+  RefreshCw,
+  Settings,
+  XCircle,
+} from "lucide-react";
+import React, { useEffect, useMemo, useState } from "react";
+
+const tagColor: Record<string, string> = {
+  TODO: "text-blue-500",
+  FIXME: "text-red-500",
+  HACK: "text-amber-500",
+};
+
+function getFileName(path: string): string {
+  return path.split("/").pop() || path;
+}
+
+export function TodoPanel() {
+  const { todos, todoTags, isTodoOpen, setTodoOpen, lspManager } =
+    useEditorStore();
+  const [hiddenTags, setHiddenTags] = useState<Set<string>>(new Set());
+  const [collapsedFiles, setCollapsedFiles] = useState<Set<string>>(new Set());
+
+  useEffect(() => {
+    fetchTodos();
+  }, []);
+
+  // The server pushes a notification whenever files with TODOs change
+  useEffect(() => {
+    if (!lspManager) return;
+    const subscription = lspManager.onNotification(
+      "workspace/todosChanged",
+      () => {
+        fetchTodos();
+      },
+    );
+    return () => subscription.dispose();
+  }, [lspManager]);
+
+  const groupedTodos = useMemo(() => {
+    const groups: Record<string, TodoItem[]> = {};
+    for (const item of todos) {
+      if (hiddenTags.has(item.tag)) continue;
+      if (!groups[item.path]) {
+        groups[item.path] = [];
+      }
+      groups[item.path].push(item);
+    }
+    return groups;
+  }, [todos, hiddenTags]);
+
+  if (!isTodoOpen) return null;
+
+  const files = Object.keys(groupedTodos).sort();
+  const visibleCount = files.reduce(
+    (acc, path) => acc + groupedTodos[path].length,
+    0,
+  );
+
+  const toggleTag = (tag: string) => {
+    setHiddenTags((prev) => {
+      const next = new Set(prev);
+      if (next.has(tag)) {
+        next.delete(tag);
+      } else {
+        next.add(tag);
+      }
+      return next;
+    });
+  };
+
+  const toggleFile = (path: string) => {
+    setCollapsedFiles((prev) => {
+      const next = new Set(prev);
+      if (next.has(path)) {
+        next.delete(path);
+      } else {
+        next.add(path);
+      }
+      return next;
+    });
+  };
+
+  const configureTags = () => {
+    const value = prompt(
+      "Comment tags to index (comma separated):",
+      todoTags.join(", "),
+    );
+    if (value === null) return;
+    const tags = value
+      .split(",")
+      .map((tag) => tag.trim())
+      .filter(Boolean);
+    if (tags.length > 0) {
+      updateTodoTags(tags);
+    }
+  };
+
+  return (
+    <div
+      className="border-t bg-background flex flex-col"
+      style={{ height: "200px" }}
+      role="region"
+      aria-label="TODO"
+      data-focus-region="TODO"
+    >
+      {/* Header */}
+      <div className="flex items-center justify-between border-b px-3 py-1.5 text-xs bg-muted/30">
+        <div className="flex items-center gap-4">
+          <span className="font-medium text-foreground uppercase tracking-wide">
+            TODO
+          </span>
+          <span className="flex items-center gap-1 text-muted-foreground">
+            <ListTodo className="h-3.5 w-3.5" />
+            <span className="tabular-nums">{visibleCount}</span>
+          </span>
+          <div className="flex items-center gap-1">
+            {todoTags.map((tag) => (
+              <button
+                key={tag}
+                type="button"
+                onClick={() => toggleTag(tag)}
+                aria-pressed={!hiddenTags.has(tag)}
+                className={cn(
+                  "rounded-full border px-2 py-[1px] text-[11px] transition-colors",
+                  hiddenTags.has(tag)
+                    ? "text-muted-foreground opacity-60"
+                    : "bg-background text-foreground",
+                )}
+              >
+                {tag}
+              </button>
+            ))}
+          </div>
+        </div>
+        <div className="flex items-center gap-1">
+          <button
+            type="button"
+            onClick={configureTags}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            title="Configure Tags"
+          >
+            <Settings className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => fetchTodos()}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            title="Refresh"
+          >
+            <RefreshCw className="h-4 w-4" />
+          </button>
+          <button
+            type="button"
+            onClick={() => setTodoOpen(false)}
+            className="rounded p-0.5 hover:bg-muted text-muted-foreground hover:text-foreground"
+            aria-label="Close TODO"
+          >
+            <XCircle className="h-4 w-4" />
+          </button>
+        </div>
+      </div>
+
+      {/* Content */}
+      <div className="flex-1 overflow-y-auto text-[13px]">
+        {files.length === 0 ? (
+          <div className="px-4 py-3 text-muted-foreground">
+            No {todoTags.join("/")} comments found in the workspace.
+          </div>
+        ) : (
+          <div className="py-1">
+            {files.map((path) => {
+              const items = groupedTodos[path];
+              const isExpanded = !collapsedFiles.has(path);
+              return (
+                <div key={path}>
+                  <button
+                    type="button"
+                    onClick={() => toggleFile(path)}
+                    className="w-full flex items-center gap-1 px-2 py-0.5 hover:bg-muted/50 text-left"
+                  >
+                    {isExpanded ? (
+                      <ChevronDown className="h-4 w-4 text-muted-foreground flex-shrink-0" />
+                    ) : (
+                      <ChevronRight className="h-4 w-4 text-muted-foreground flex-shrink-0" />
+                    )}
+                    <span className="font-medium text-foreground truncate">
+                      {getFileName(path)}
+                    </span>
+                    <span className="text-muted-foreground truncate ml-1">{path}</span>
+                    <span className="ml-auto text-xs text-muted-foreground tabular-nums">
+                      {items.length}
+                    </span>
+                  </button>
+
+                  {isExpanded &&
+                    items.map((item) => (
+                      <div
+                        key={`${item.path}-${item.line}-${item.column}`}
+                        className="flex items-start gap-2 pl-6 pr-2 py-0.5 hover:bg-muted/40 cursor-pointer"
+                        onClick={() =>
+                          openWorkspaceFile(item.path, item.line, item.column)
+                        }
+                      >
+                        <span
+                          className={cn(
+                            "flex-shrink-0 font-mono text-xs font-semibold",
+                            tagColor[item.tag] ?? "text-emerald-500",
+                          )}
+                        >
+                          {item.tag}
+                        </span>
+                        <span className="flex-1 min-w-0 text-foreground break-words">
+                          {item.text}
+                        </span>
+                        <span className="text-muted-foreground tabular-nums flex-shrink-0 text-xs">
+                          [{item.line}, {item.column}]
+                        </span>
+                      </div>
+                    ))}
+                </div>
+              );
+            })}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
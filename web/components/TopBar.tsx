@@ -1,22 +1,55 @@
 "use client";
 
+import { useExtensionStore } from "@/lib/extensions/registry";
+import { runCurrentFile } from "@/lib/run";
 import { useEditorStore } from "@/lib/store";
+import { newUntitledDocument } from "@/lib/untitled";
 import { cn } from "@/lib/utils";
-import { Laptop, Moon, SunMedium } from "lucide-react";
+import { Accessibility, Contrast, Laptop, Moon, SunMedium } from "lucide-react";
 
 const themeOptions = [
   { value: "light", label: "Light", icon: SunMedium },
   { value: "dark", label: "Dark", icon: Moon },
+  { value: "hc-light", label: "HC Light", icon: Contrast },
+  { value: "hc-dark", label: "HC Dark", icon: Contrast },
   { value: "auto", label: "Auto", icon: Laptop },
 ] as const;
 
 export function TopBar() {
-  const { themeMode, resolvedTheme, setThemeMode } = useEditorStore();
+  const {
+    themeMode,
+    resolvedTheme,
+    setThemeMode,
+    setNewProjectOpen,
+    isAuditLogOpen,
+    setAuditLogOpen,
+    isGoModulesOpen,
+    setGoModulesOpen,
+    isNpmOpen,
+    setNpmOpen,
+    isOutputOpen,
+    setOutputOpen,
+    ports,
+    isPortsOpen,
+    setPortsOpen,
+    isHttpOpen,
+    setHttpOpen,
+    isSnapshotsOpen,
+    setSnapshotsOpen,
+    isScreenReaderMode,
+    setScreenReaderMode,
+  } = useEditorStore();
+  const { themes, activeThemeId, setActiveTheme } = useExtensionStore();
 
   return (
-    <div className="flex h-14 items-center gap-4 border-b bg-card/80 px-4 text-sm backdrop-blur">
+    <header
+      className="flex h-14 items-center gap-4 border-b bg-card/80 px-4 text-sm backdrop-blur"
+      data-focus-region="Top bar"
+    >
       <div className="flex items-center gap-2">
-        <div className="flex h-10 w-10 items-center justify-center rounded-xl bg-gradient-to-br from-indigo-500 to-sky-500 text-base font-semibold text-white shadow-sm">
+        <div
+          aria-hidden="true"
+          className="flex h-10 w-10 items-center justify-center rounded-xl bg-gradient-to-br from-indigo-500 to-sky-500 text-base font-semibold text-white shadow-sm">
           OE
         </div>
         <div className="leading-tight">
@@ -28,18 +61,154 @@ export function TopBar() {
       <div className="hidden h-8 w-px bg-border md:block" />
 
       <div className="hidden items-center gap-3 text-xs text-muted-foreground md:flex">
-        <span className="rounded-full border bg-muted/50 px-3 py-1 font-medium text-foreground">
+        <button
+          type="button"
+          className="rounded-full border bg-muted/50 px-3 py-1 font-medium text-foreground transition hover:bg-background"
+          onClick={() => newUntitledDocument()}
+          title="New Untitled File (Ctrl+Alt+N in the editor)"
+        >
+          Untitled
+        </button>
+        <button
+          type="button"
+          className="rounded-full border bg-muted/50 px-3 py-1 font-medium text-foreground transition hover:bg-background"
+          onClick={() => setNewProjectOpen(true)}
+          title="New Project from Template"
+        >
           Project
-        </span>
-        <span>File</span>
-        <span>Edit</span>
-        <span>View</span>
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isAuditLogOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setAuditLogOpen(!isAuditLogOpen)}
+          title="Toggle Audit Log"
+        >
+          Audit
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isGoModulesOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setGoModulesOpen(!isGoModulesOpen)}
+          title="Toggle Go Modules"
+        >
+          Modules
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isNpmOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setNpmOpen(!isNpmOpen)}
+          title="Toggle NPM Scripts"
+        >
+          Scripts
+        </button>
+        <button
+          type="button"
+          className="rounded-full border bg-muted/50 px-3 py-1 font-medium text-foreground transition hover:bg-background"
+          onClick={() => runCurrentFile()}
+          title="Run Current File (Ctrl+F5 in the editor)"
+        >
+          Run
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isOutputOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setOutputOpen(!isOutputOpen)}
+          title="Toggle Output"
+        >
+          Output
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isPortsOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setPortsOpen(!isPortsOpen)}
+          title="Toggle Ports"
+        >
+          Ports{ports.length > 0 ? ` ${ports.length}` : ""}
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isHttpOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setHttpOpen(!isHttpOpen)}
+          title="Toggle HTTP Response"
+        >
+          HTTP
+        </button>
+        <button
+          type="button"
+          className={cn(
+            "rounded-full border px-3 py-1 font-medium text-foreground transition hover:bg-background",
+            isSnapshotsOpen ? "bg-background" : "bg-muted/50",
+          )}
+          onClick={() => setSnapshotsOpen(!isSnapshotsOpen)}
+          title="Toggle Shared Snapshots"
+        >
+          Snapshots
+        </button>
+        <span aria-hidden="true">File</span>
+        <span aria-hidden="true">Edit</span>
+        <span aria-hidden="true">View</span>
       </div>
 
       <div className="flex-1" />
 
-      <div className="flex items-center gap-2 rounded-full border bg-muted/40 px-2 py-1">
-        <span className="px-2 text-xs font-medium text-muted-foreground">
+      {themes.length > 0 && (
+        <select
+          className="rounded-full border bg-muted/40 px-3 py-2 text-xs text-foreground"
+          value={themes.some((t) => t.id === activeThemeId) ? activeThemeId! : ""}
+          onChange={(event) => setActiveTheme(event.target.value || null)}
+          title="Extension Theme"
+          aria-label="Extension Theme"
+        >
+          <option value="">Default theme</option>
+          {themes.map((theme) => (
+            <option key={theme.id} value={theme.id}>
+              {theme.label}
+            </option>
+          ))}
+        </select>
+      )}
+
+      <button
+        type="button"
+        className={cn(
+          "flex items-center gap-1 rounded-full border px-3 py-2 text-xs font-medium text-foreground transition hover:bg-background",
+          isScreenReaderMode ? "bg-background" : "bg-muted/40",
+        )}
+        onClick={() => setScreenReaderMode(!isScreenReaderMode)}
+        aria-pressed={isScreenReaderMode}
+        title="Optimize the editor for screen readers"
+      >
+        <Accessibility className="h-4 w-4" aria-hidden="true" />
+        <span>Screen Reader</span>
+      </button>
+
+      <div
+        className="flex items-center gap-2 rounded-full border bg-muted/40 px-2 py-1"
+        role="group"
+        aria-labelledby="theme-label"
+      >
+        <span
+          id="theme-label"
+          className="px-2 text-xs font-medium text-muted-foreground"
+        >
           Theme
         </span>
         {themeOptions.map((option) => {
@@ -58,7 +227,7 @@ export function TopBar() {
               aria-pressed={isActive}
               type="button"
             >
-              <Icon className="h-4 w-4" />
+              <Icon className="h-4 w-4" aria-hidden="true" />
               <span className="font-medium">{option.label}</span>
               {option.value === "auto" && (
                 <span className="text-[11px] text-primary-foreground/80">
@@ -69,6 +238,6 @@ export function TopBar() {
           );
         })}
       </div>
-    </div>
+    </header>
   );
 }
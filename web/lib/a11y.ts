@@ -0,0 +1,118 @@
+const SCREEN_READER_STORAGE_KEY = "screen-reader-mode";
+
+// Event carrying a message for the screen reader live regions
+export const ANNOUNCE_EVENT = "a11y-announce";
+
+// Landmarks F6 and Shift+F6 move between, in document order
+export const FOCUS_REGION_ATTRIBUTE = "data-focus-region";
+// Regions that move focus inside themselves when focused, like the editor
+export const FOCUS_SELF_ATTRIBUTE = "data-focus-self";
+
+export type Politeness = "polite" | "assertive";
+
+export interface Announcement {
+  message: string;
+  politeness: Politeness;
+}
+
+export const getStoredScreenReaderMode = (): boolean => {
+  if (typeof window === "undefined") return false;
+  return window.localStorage.getItem(SCREEN_READER_STORAGE_KEY) === "on";
+};
+
+export const saveScreenReaderMode = (enabled: boolean) => {
+  if (typeof window === "undefined") return;
+  window.localStorage.setItem(SCREEN_READER_STORAGE_KEY, enabled ? "on" : "off");
+};
+
+/**
+ * Read a message out through the live regions; assertive messages
+ * interrupt whatever the screen reader is saying
+ */
+export const announce = (
+  message: string,
+  politeness: Politeness = "polite",
+) => {
+  if (typeof window === "undefined") return;
+  window.dispatchEvent(
+    new CustomEvent<Announcement>(ANNOUNCE_EVENT, {
+      detail: { message, politeness },
+    }),
+  );
+};
+
+const FOCUSABLE_SELECTOR = [
+  "a[href]",
+  "button:not([disabled])",
+  "input:not([disabled])",
+  "select:not([disabled])",
+  "textarea:not([disabled])",
+  '[tabindex]:not([tabindex="-1"])',
+].join(",");
+
+const isVisible = (element: HTMLElement) =>
+  element.offsetParent !== null || element.getClientRects().length > 0;
+
+/**
+ * Focus the element a region wants focused first: its current tree item or
+ * list entry (tabindex 0), otherwise its first focusable element, otherwise
+ * the region itself
+ */
+const focusRegion = (region: HTMLElement) => {
+  if (region.hasAttribute(FOCUS_SELF_ATTRIBUTE)) {
+    region.focus();
+    return;
+  }
+  const target =
+    region.querySelector<HTMLElement>('[tabindex="0"]') ??
+    Array.from(region.querySelectorAll<HTMLElement>(FOCUSABLE_SELECTOR)).find(
+      isVisible,
+    ) ??
+    region;
+  if (target === region && !region.hasAttribute("tabindex")) {
+    region.tabIndex = -1;
+  }
+  target.focus();
+};
+
+/**
+ * Move focus to the next (or previous) region of the page: top bar,
+ * explorer, editor, open panels and status bar
+ */
+export const cycleFocusRegion = (reverse = false) => {
+  if (typeof document === "undefined") return;
+  const regions = Array.from(
+    document.querySelectorAll<HTMLElement>(`[${FOCUS_REGION_ATTRIBUTE}]`),
+  ).filter(isVisible);
+  if (regions.length === 0) return;
+
+  const current = regions.findIndex((region) =>
+    region.contains(document.activeElement),
+  );
+  const step = reverse ? -1 : 1;
+  const next =
+    current === -1
+      ? reverse
+        ? regions.length - 1
+        : 0
+      : (current + step + regions.length) % regions.length;
+  const region = regions[next];
+  focusRegion(region);
+  announce(region.getAttribute(FOCUS_REGION_ATTRIBUTE) || "");
+};
+
+/**
+ * Handle F6 and Shift+F6 before the editor or any other element sees them
+ */
+export const installFocusRegionShortcuts = (): (() => void) => {
+  const handler = (event: KeyboardEvent) => {
+    if (event.key !== "F6" || event.ctrlKey || event.altKey || event.metaKey) {
+      return;
+    }
+    event.preventDefault();
+    event.stopPropagation();
+    cycleFocusRegion(event.shiftKey);
+  };
+  window.addEventListener("keydown", handler, true);
+  return () => window.removeEventListener("keydown", handler, true);
+};
@@ -0,0 +1,73 @@
+import { ApiClient, ApiRequestError, API_BASE_PATH } from "./generated";
+
+export * from "./generated";
+
+// API configuration
+const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:3001";
+
+// localStorage key for a token when the server runs with token auth
+export const API_TOKEN_STORAGE_KEY = "oneline-editor.apiToken";
+
+/**
+ * Token sent with API and WebSocket requests, if any
+ */
+export function getApiToken(): string | undefined {
+  if (process.env.NEXT_PUBLIC_API_TOKEN) {
+    return process.env.NEXT_PUBLIC_API_TOKEN;
+  }
+  if (typeof window === "undefined") return undefined;
+  return window.localStorage.getItem(API_TOKEN_STORAGE_KEY) ?? undefined;
+}
+
+let clientId: string | undefined;
+
+/**
+ * Random ID for this browser tab, sent with API and WebSocket requests so
+ * the server's audit log can tell sessions of the same user apart
+ */
+export function getClientId(): string {
+  if (!clientId) {
+    clientId =
+      typeof crypto !== "undefined" && "randomUUID" in crypto
+        ? crypto.randomUUID()
+        : Math.random().toString(36).slice(2);
+  }
+  return clientId;
+}
+
+export const api = new ApiClient({
+  baseUrl: `${API_BASE_URL}${API_BASE_PATH}`,
+  getToken: getApiToken,
+  headers: () => ({ "X-Client-Id": getClientId() }),
+});
+
+/**
+ * Absolute URL of a server path (e.g. a plugin route), for requests made
+ * outside the generated client
+ */
+export function resolveServerUrl(path: string): string {
+  return `${API_BASE_URL}${path}`;
+}
+
+/**
+ * Headers identifying this session to the server
+ */
+export function getAuthHeaders(): Record<string, string> {
+  const token = getApiToken();
+  return {
+    "X-Client-Id": getClientId(),
+    ...(token ? { Authorization: `Bearer ${token}` } : {}),
+  };
+}
+
+/**
+ * Human-readable message for an API failure, including validation details
+ */
+export function describeApiError(error: unknown): string {
+  if (error instanceof ApiRequestError) {
+    return error.details?.length
+      ? `${error.message}: ${error.details.join("; ")}`
+      : error.message;
+  }
+  return error instanceof Error ? error.message : "Unknown error";
+}
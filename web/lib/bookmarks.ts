@@ -0,0 +1,55 @@
+import { api } from "./api";
+import { type Bookmark, useEditorStore } from "./store";
+
+/**
+ * Load all workspace bookmarks into the store
+ */
+export async function fetchBookmarks(): Promise<void> {
+  try {
+    useEditorStore.getState().setBookmarks(await api.listBookmarks());
+  } catch (error) {
+    console.error("Error fetching bookmarks:", error);
+  }
+}
+
+/**
+ * Toggle a bookmark on a 1-based line of a workspace file
+ */
+export async function toggleBookmark(
+  path: string,
+  line: number,
+  label?: string,
+): Promise<Bookmark | undefined> {
+  try {
+    const result = await api.toggleBookmark({ path, line, label });
+    useEditorStore.getState().setBookmarks(result.bookmarks);
+    return result.bookmark;
+  } catch (error) {
+    console.error("Error toggling bookmark:", error);
+    return undefined;
+  }
+}
+
+/**
+ * Set or clear a bookmark label
+ */
+export async function labelBookmark(id: string, label: string): Promise<void> {
+  try {
+    await api.updateBookmark(id, { label });
+    await fetchBookmarks();
+  } catch (error) {
+    console.error("Error labeling bookmark:", error);
+  }
+}
+
+/**
+ * Remove a bookmark
+ */
+export async function removeBookmark(id: string): Promise<void> {
+  try {
+    await api.deleteBookmark(id);
+    await fetchBookmarks();
+  } catch (error) {
+    console.error("Error removing bookmark:", error);
+  }
+}
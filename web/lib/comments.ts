@@ -0,0 +1,232 @@
+import type * as Monaco from "monaco-editor";
+import { create } from "zustand";
+import { announce } from "./a11y";
+import { api, describeApiError, type CommentThread } from "./api";
+import type { FrontendLSPManager } from "./lsp/client";
+import { openWorkspaceFile, uriToWorkspacePath } from "./navigation";
+import { getSelectedLines } from "./permalinks";
+import { WORKSPACE_URI_SCHEME } from "./uris";
+
+/**
+ * A thread being written, before it is stored
+ */
+export interface CommentDraft {
+  path: string;
+  startLine: number;
+  endLine: number;
+}
+
+export type CommentThreadStatus = CommentThread["status"];
+export type CommentStatusFilter = CommentThreadStatus | "all";
+
+/**
+ * Review comment threads of the workspace, which of them are expanded in
+ * the editor and the Comments panel filters
+ */
+interface CommentsState {
+  threads: CommentThread[];
+  expanded: string[];
+  draft: CommentDraft | null;
+  authorFilter: string | null;
+  statusFilter: CommentStatusFilter;
+  setThreads: (threads: CommentThread[]) => void;
+  setExpanded: (id: string, expanded: boolean) => void;
+  setDraft: (draft: CommentDraft | null) => void;
+  setAuthorFilter: (author: string | null) => void;
+  setStatusFilter: (status: CommentStatusFilter) => void;
+}
+
+export const useCommentsStore = create<CommentsState>((set) => ({
+  threads: [],
+  expanded: [],
+  draft: null,
+  authorFilter: null,
+  statusFilter: "open",
+  setThreads: (threads) =>
+    set((state) => ({
+      threads,
+      // Forget expanded threads that were deleted
+      expanded: state.expanded.filter((id) =>
+        threads.some((thread) => thread.id === id),
+      ),
+    })),
+  setExpanded: (id, expanded) =>
+    set((state) => ({
+      expanded: expanded
+        ? [...state.expanded.filter((item) => item !== id), id]
+        : state.expanded.filter((item) => item !== id),
+    })),
+  setDraft: (draft) => set({ draft }),
+  setAuthorFilter: (author) => set({ authorFilter: author }),
+  setStatusFilter: (status) => set({ statusFilter: status }),
+}));
+
+/**
+ * Everyone who started or replied to a thread, sorted
+ */
+export function getCommentAuthors(threads: CommentThread[]): string[] {
+  const authors = new Set(
+    threads.flatMap((thread) =>
+      thread.comments.map((comment) => comment.author),
+    ),
+  );
+  return Array.from(authors).sort((a, b) => a.localeCompare(b));
+}
+
+/**
+ * Threads matching the Comments panel filters; the author filter matches
+ * threads the user started or replied to, like the API
+ */
+export function filterCommentThreads(
+  threads: CommentThread[],
+  author: string | null,
+  status: CommentStatusFilter,
+): CommentThread[] {
+  return threads.filter(
+    (thread) =>
+      (status === "all" || thread.status === status) &&
+      (!author ||
+        thread.comments.some((comment) => comment.author === author)),
+  );
+}
+
+/**
+ * Load all comment threads into the store
+ */
+export async function fetchCommentThreads(): Promise<void> {
+  try {
+    useCommentsStore.getState().setThreads(await api.listCommentThreads());
+  } catch (error) {
+    console.error("Error fetching comment threads:", error);
+  }
+}
+
+/**
+ * Reload threads when they are added, change or move, e.g. after a save
+ */
+export function watchCommentThreads(
+  lspManager: FrontendLSPManager,
+): Monaco.IDisposable {
+  return lspManager.onNotification("workspace/commentsChanged", () => {
+    void fetchCommentThreads();
+  });
+}
+
+/**
+ * Start writing a thread on the selected lines of the workspace file in the
+ * editor
+ */
+export function addCommentAtSelection(
+  editor: Monaco.editor.ICodeEditor,
+): void {
+  const model = editor.getModel();
+  const range = getSelectedLines(editor);
+  if (!model || !range) return;
+  if (model.uri.scheme !== WORKSPACE_URI_SCHEME) {
+    announce("Only workspace files can be commented on");
+    return;
+  }
+  useCommentsStore.getState().setDraft({
+    path: uriToWorkspacePath(model.uri.toString()),
+    ...range,
+  });
+}
+
+/**
+ * Expand the threads on a line of a file, or collapse them if any is expanded
+ */
+export function toggleThreadsAtLine(path: string, line: number): boolean {
+  const { threads, expanded, setExpanded } = useCommentsStore.getState();
+  const onLine = threads.filter(
+    (thread) =>
+      thread.path === path &&
+      thread.startLine <= line &&
+      line <= thread.endLine,
+  );
+  if (onLine.length === 0) return false;
+  const isOpen = onLine.some((thread) => expanded.includes(thread.id));
+  onLine.forEach((thread) => setExpanded(thread.id, !isOpen));
+  return true;
+}
+
+/**
+ * Store the draft as a new thread with its first comment
+ */
+export async function submitDraft(body: string): Promise<boolean> {
+  const { draft, setDraft, setExpanded } = useCommentsStore.getState();
+  if (!draft) return false;
+  try {
+    const thread = await api.createCommentThread({ ...draft, body });
+    setDraft(null);
+    await fetchCommentThreads();
+    setExpanded(thread.id, true);
+    announce("Comment added");
+    return true;
+  } catch (error) {
+    console.error("Error adding comment:", error);
+    alert(`Failed to add comment: ${describeApiError(error)}`);
+    return false;
+  }
+}
+
+/**
+ * Add a comment to a thread
+ */
+export async function replyToThread(
+  id: string,
+  body: string,
+): Promise<boolean> {
+  try {
+    await api.replyToCommentThread(id, { body });
+    await fetchCommentThreads();
+    announce("Reply added");
+    return true;
+  } catch (error) {
+    console.error("Error replying to comment thread:", error);
+    alert(`Failed to reply: ${describeApiError(error)}`);
+    return false;
+  }
+}
+
+/**
+ * Resolve or reopen a thread
+ */
+export async function setThreadStatus(
+  id: string,
+  status: CommentThreadStatus,
+): Promise<void> {
+  try {
+    await api.updateCommentThread(id, { status });
+    await fetchCommentThreads();
+    announce(status === "resolved" ? "Thread resolved" : "Thread reopened");
+  } catch (error) {
+    console.error("Error updating comment thread:", error);
+    alert(`Failed to update thread: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Delete a thread and all of its comments
+ */
+export async function deleteThread(id: string): Promise<void> {
+  if (!window.confirm("Delete this thread and all of its comments?")) return;
+  try {
+    await api.deleteCommentThread(id);
+    await fetchCommentThreads();
+    announce("Thread deleted");
+  } catch (error) {
+    console.error("Error deleting comment thread:", error);
+    alert(`Failed to delete thread: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Open the file of a thread at its lines and expand it
+ */
+export async function openCommentThread(
+  thread: CommentThread,
+): Promise<void> {
+  if (await openWorkspaceFile(thread.path, thread.startLine)) {
+    useCommentsStore.getState().setExpanded(thread.id, true);
+  }
+}
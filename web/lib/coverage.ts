@@ -0,0 +1,75 @@
+import {
+  api,
+  ApiRequestError,
+  describeApiError,
+  type FileCoverage,
+} from "./api";
+import { useEditorStore } from "./store";
+
+// Line coverage per file, for the report the store's summary describes
+const fileCache = new Map<string, Promise<FileCoverage | null>>();
+let cachedFor: string | undefined;
+
+/**
+ * Load the workspace's coverage summary into the store
+ */
+export async function fetchCoverage(): Promise<void> {
+  try {
+    useEditorStore.getState().setCoverage(await api.getCoverage());
+  } catch (error) {
+    console.error("Error fetching coverage:", error);
+  }
+}
+
+/**
+ * Covered and uncovered lines of a file, or null when the report doesn't
+ * include it. Results are cached until another report is imported.
+ */
+export function getFileCoverage(path: string): Promise<FileCoverage | null> {
+  const updatedAt = useEditorStore.getState().coverage?.updatedAt;
+  if (cachedFor !== updatedAt) {
+    fileCache.clear();
+    cachedFor = updatedAt;
+  }
+  let result = fileCache.get(path);
+  if (!result) {
+    result = api.getFileCoverage({ path }).catch((error) => {
+      if (!(error instanceof ApiRequestError && error.status === 404)) {
+        console.error("Error fetching file coverage:", error);
+      }
+      return null;
+    });
+    fileCache.set(path, result);
+  }
+  return result;
+}
+
+/**
+ * Import a coverage report from the workspace and show the overlay
+ */
+export async function importCoverage(path?: string): Promise<void> {
+  try {
+    const summary = await api.importCoverage(path ? { path } : {});
+    const { setCoverage, setCoverageVisible } = useEditorStore.getState();
+    setCoverage(summary);
+    setCoverageVisible(true);
+  } catch (error) {
+    console.error("Error importing coverage:", error);
+    alert(`Failed to load coverage: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Forget the workspace's coverage and hide the overlay
+ */
+export async function clearCoverage(): Promise<void> {
+  try {
+    await api.clearCoverage();
+    const { setCoverage, setCoverageVisible } = useEditorStore.getState();
+    setCoverage(null);
+    setCoverageVisible(false);
+  } catch (error) {
+    console.error("Error clearing coverage:", error);
+    alert(`Failed to clear coverage: ${describeApiError(error)}`);
+  }
+}
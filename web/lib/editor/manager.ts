@@ -1,4 +1,5 @@
 import * as monaco from "monaco-editor";
+import { toWorkspaceUri, WORKSPACE_URI_SCHEME } from "../uris";
 
 export interface EditorConfig {
   theme?: "vs-dark" | "vs-light";
@@ -11,6 +12,12 @@ export interface FileModel {
   uri: string;
   model: monaco.editor.ITextModel;
   languageId: string;
+  // Files outside the workspace, e.g. the Go standard library
+  readOnly?: boolean;
+}
+
+export interface OpenFileOptions {
+  readOnly?: boolean;
 }
 
 export class EditorManager {
@@ -41,9 +48,14 @@ export class EditorManager {
   /**
    * Open a file in the editor
    */
-  openFile(uri: string, content: string, languageId: string): void {
+  openFile(
+    uri: string,
+    content: string,
+    languageId: string,
+    options: OpenFileOptions = {},
+  ): void {
     // Always use Monaco URI string format for consistency
-    const monacoUri = monaco.Uri.parse(uri);
+    const monacoUri = monaco.Uri.parse(toWorkspaceUri(uri));
     const normalizedUri = monacoUri.toString();
     
     let fileModel = this.models.get(normalizedUri);
@@ -62,6 +74,7 @@ export class EditorManager {
         uri: normalizedUri,
         model,
         languageId,
+        readOnly: options.readOnly,
       };
 
       this.models.set(normalizedUri, fileModel);
@@ -70,16 +83,29 @@ export class EditorManager {
     // Set model to editor
     if (this.editor) {
       this.editor.setModel(fileModel.model);
+      this.editor.updateOptions({ readOnly: !!fileModel.readOnly });
       this.currentUri = normalizedUri;
       this.notifyFileOpen(normalizedUri);
     }
   }
 
+  /**
+   * Change the language of an open file
+   */
+  setLanguage(uri: string, languageId: string): void {
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
+    const fileModel = this.models.get(normalizedUri);
+    if (fileModel) {
+      monaco.editor.setModelLanguage(fileModel.model, languageId);
+      fileModel.languageId = languageId;
+    }
+  }
+
   /**
    * Close a file
    */
   closeFile(uri: string): void {
-    const normalizedUri = monaco.Uri.parse(uri).toString();
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
     const fileModel = this.models.get(normalizedUri);
     if (fileModel) {
       fileModel.model.dispose();
@@ -94,6 +120,67 @@ export class EditorManager {
     }
   }
 
+  /**
+   * Move the open models of a renamed file, or of the files inside a renamed
+   * folder, to their new URIs. Returns the old and new URI of each moved model.
+   */
+  renamePath(
+    oldPath: string,
+    newPath: string,
+    getLanguageId: (path: string) => string,
+  ): Array<{ oldUri: string; newUri: string }> {
+    const moved: Array<{ oldUri: string; newUri: string }> = [];
+    for (const [uri, fileModel] of Array.from(this.models)) {
+      const { scheme, path } = fileModel.model.uri;
+      if (scheme !== WORKSPACE_URI_SCHEME) continue;
+      if (path !== oldPath && !path.startsWith(`${oldPath}/`)) continue;
+
+      const targetPath = newPath + path.slice(oldPath.length);
+      const targetUri = monaco.Uri.parse(toWorkspaceUri(targetPath));
+      const languageId = getLanguageId(targetPath);
+      monaco.editor.getModel(targetUri)?.dispose();
+      const model = monaco.editor.createModel(
+        fileModel.model.getValue(),
+        languageId,
+        targetUri,
+      );
+
+      const isCurrent = this.currentUri === uri;
+      const viewState = isCurrent ? this.editor?.saveViewState() : null;
+      this.models.delete(uri);
+      this.models.set(targetUri.toString(), {
+        uri: targetUri.toString(),
+        model,
+        languageId,
+        readOnly: fileModel.readOnly,
+      });
+      if (isCurrent && this.editor) {
+        this.editor.setModel(model);
+        if (viewState) this.editor.restoreViewState(viewState);
+        this.currentUri = targetUri.toString();
+      }
+      fileModel.model.dispose();
+      moved.push({ oldUri: uri, newUri: targetUri.toString() });
+      this.notifyFileOpen(targetUri.toString());
+    }
+    return moved;
+  }
+
+  /**
+   * Close the open models of a deleted file or folder. Returns their URIs.
+   */
+  closePath(path: string): string[] {
+    const closed = Array.from(this.models.values())
+      .filter(
+        ({ model }) =>
+          model.uri.scheme === WORKSPACE_URI_SCHEME &&
+          (model.uri.path === path || model.uri.path.startsWith(`${path}/`)),
+      )
+      .map(({ uri }) => uri);
+    closed.forEach((uri) => this.closeFile(uri));
+    return closed;
+  }
+
   /**
    * Get current model
    */
@@ -112,10 +199,18 @@ export class EditorManager {
    * Get model by URI
    */
   getModel(uri: string): monaco.editor.ITextModel | null {
-    const normalizedUri = monaco.Uri.parse(uri).toString();
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
     return this.models.get(normalizedUri)?.model || null;
   }
 
+  /**
+   * Whether an open file can't be edited
+   */
+  isReadOnly(uri: string): boolean {
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
+    return !!this.models.get(normalizedUri)?.readOnly;
+  }
+
   /**
    * Get all open files
    */
@@ -127,7 +222,7 @@ export class EditorManager {
    * Update file content
    */
   updateFileContent(uri: string, content: string): void {
-    const normalizedUri = monaco.Uri.parse(uri).toString();
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
     const fileModel = this.models.get(normalizedUri);
     if (fileModel) {
       fileModel.model.setValue(content);
@@ -138,7 +233,7 @@ export class EditorManager {
    * Get file content
    */
   getFileContent(uri: string): string | null {
-    const normalizedUri = monaco.Uri.parse(uri).toString();
+    const normalizedUri = monaco.Uri.parse(toWorkspaceUri(uri)).toString();
     const fileModel = this.models.get(normalizedUri);
     return fileModel ? fileModel.model.getValue() : null;
   }
@@ -207,6 +302,27 @@ export class EditorManager {
     this.editor.focus();
   }
 
+  /**
+   * Select a 1-based range and scroll it into view, optionally focusing
+   * the editor
+   */
+  revealRange(
+    startLine: number,
+    startColumn: number,
+    endLine: number,
+    endColumn: number,
+    focus = true,
+  ): void {
+    if (!this.editor) return;
+
+    const range = new monaco.Range(startLine, startColumn, endLine, endColumn);
+    this.editor.setSelection(range);
+    this.editor.revealRangeInCenterIfOutsideViewport(range);
+    if (focus) {
+      this.editor.focus();
+    }
+  }
+
   /**
    * Dispose editor and all models
    */
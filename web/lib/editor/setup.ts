@@ -0,0 +1,101 @@
+import { loader } from "@monaco-editor/react";
+import * as monaco from "monaco-editor";
+
+const disabledTypeScriptFeatures = {
+  completionItems: false,
+  hovers: false,
+  documentSymbols: false,
+  definitions: false,
+  references: false,
+  documentHighlights: false,
+  rename: false,
+  diagnostics: false,
+  documentRangeFormattingEdits: false,
+  signatureHelp: false,
+  onTypeFormattingEdits: false,
+  codeActions: false,
+  inlayHints: false,
+};
+
+type MonacoEnvironmentShape = typeof self & {
+  MonacoEnvironment?: {
+    getWorker: (_: string, label: string) => Worker;
+  };
+};
+
+// Configure Monaco loader to use local files instead of CDN and register workers
+if (typeof window !== "undefined") {
+  const globalSelf = self as MonacoEnvironmentShape;
+
+  if (!globalSelf.MonacoEnvironment) {
+    globalSelf.MonacoEnvironment = {
+      getWorker(_moduleId, label) {
+        const workerOptions: WorkerOptions = { type: "module" };
+        switch (label) {
+          case "json":
+            return new Worker(
+              new URL(
+                "monaco-editor/esm/vs/language/json/json.worker.js",
+                import.meta.url,
+              ),
+              workerOptions,
+            );
+          case "css":
+          case "scss":
+          case "less":
+            return new Worker(
+              new URL(
+                "monaco-editor/esm/vs/language/css/css.worker.js",
+                import.meta.url,
+              ),
+              workerOptions,
+            );
+          case "html":
+          case "handlebars":
+          case "razor":
+            return new Worker(
+              new URL(
+                "monaco-editor/esm/vs/language/html/html.worker.js",
+                import.meta.url,
+              ),
+              workerOptions,
+            );
+          case "typescript":
+          case "javascript":
+            return new Worker(
+              new URL(
+                "monaco-editor/esm/vs/language/typescript/ts.worker.js",
+                import.meta.url,
+              ),
+              workerOptions,
+            );
+          default:
+            return new Worker(
+              new URL(
+                "monaco-editor/esm/vs/editor/editor.worker.js",
+                import.meta.url,
+              ),
+              workerOptions,
+            );
+        }
+      },
+    };
+  }
+
+  loader.config({ monaco });
+}
+
+/**
+ * Turn off Monaco's own JS/TS completion, hovers, diagnostics and so on, so
+ * they come from the language server (or, in snapshots, from its saved answers)
+ */
+export function disableBuiltinTypeScriptFeatures(
+  monacoInstance: typeof monaco,
+): void {
+  monacoInstance.languages.typescript.typescriptDefaults.setModeConfiguration(
+    disabledTypeScriptFeatures,
+  );
+  monacoInstance.languages.typescript.javascriptDefaults.setModeConfiguration(
+    disabledTypeScriptFeatures,
+  );
+}
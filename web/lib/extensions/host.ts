@@ -0,0 +1,374 @@
+import { api, getAuthHeaders, getApiToken, getClientId, resolveServerUrl } from "../api";
+import { openWorkspaceFile } from "../navigation";
+import { useEditorStore } from "../store";
+import { useExtensionStore } from "./registry";
+import {
+  EXTENSION_API_VERSION,
+  type Disposable,
+  type EditorSnapshot,
+  type ExtensionContext,
+  type ThemeContribution,
+  type WebExtension,
+} from "./types";
+
+interface ActiveExtension {
+  module: WebExtension;
+  context: ExtensionContext;
+}
+
+const active = new Map<string, ActiveExtension>();
+let loading: Promise<void> | null = null;
+
+/**
+ * Fetch the web extensions of active server plugins and activate them.
+ * Safe to call more than once; each extension is only loaded the first time.
+ */
+export function loadExtensions(): Promise<void> {
+  if (!loading) {
+    loading = (async () => {
+      let extensions: Awaited<ReturnType<typeof api.listWebExtensions>>;
+      try {
+        extensions = await api.listWebExtensions();
+      } catch (error) {
+        console.error("[Extensions] Failed to list extensions:", error);
+        return;
+      }
+      await Promise.all(
+        extensions.map((info) => loadExtension(info.name, info.url, info.version)),
+      );
+    })();
+  }
+  return loading;
+}
+
+/**
+ * Deactivate every extension and remove its contributions
+ */
+export async function unloadExtensions(): Promise<void> {
+  await loading;
+  loading = null;
+  await Promise.all([...active.keys()].map((name) => deactivate(name)));
+}
+
+/**
+ * Run a command contributed by an extension, or one of the editor's own
+ * actions (e.g. "bookmarks.toggle")
+ */
+export async function executeCommand(
+  id: string,
+  ...args: unknown[]
+): Promise<unknown> {
+  const command = useExtensionStore
+    .getState()
+    .commands.find((c) => c.id === id);
+  if (command) {
+    try {
+      return await command.run(...args);
+    } catch (error) {
+      console.error(`[Extension:${command.extension}] Command ${id} failed:`, error);
+      throw error;
+    }
+  }
+  const action = useEditorStore
+    .getState()
+    .editorManager?.getEditor()
+    ?.getAction(id);
+  if (action) {
+    return action.run();
+  }
+  throw new Error(`Unknown command: ${id}`);
+}
+
+async function loadExtension(
+  name: string,
+  url: string,
+  version?: string,
+): Promise<void> {
+  const { setExtension } = useExtensionStore.getState();
+  if (active.has(name)) return;
+
+  try {
+    // Bundles are served by the API server, which needs the token on the URL
+    // as module imports can't carry headers
+    const bundleUrl = new URL(resolveServerUrl(url));
+    bundleUrl.searchParams.set("client", getClientId());
+    const token = getApiToken();
+    if (token) {
+      bundleUrl.searchParams.set("token", token);
+    }
+    const imported = await import(/* webpackIgnore: true */ bundleUrl.toString());
+    const module: WebExtension | undefined = imported.default;
+
+    if (!module || typeof module.activate !== "function") {
+      throw new Error("Bundle has no default export with an activate() function");
+    }
+    if (module.apiVersion !== EXTENSION_API_VERSION) {
+      throw new Error(
+        `Extension targets API version ${module.apiVersion}, editor provides ${EXTENSION_API_VERSION}`,
+      );
+    }
+
+    const context = await createContext(name);
+    active.set(name, { module, context });
+    try {
+      await module.activate(context);
+    } catch (error) {
+      await deactivate(name);
+      throw error;
+    }
+
+    setExtension({ name, version, status: "active" });
+    console.log(`[Extensions] Activated ${name}${version ? ` ${version}` : ""}`);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    setExtension({ name, version, status: "failed", error: message });
+    console.error(`[Extensions] Failed to activate ${name}:`, error);
+  }
+}
+
+async function deactivate(name: string): Promise<void> {
+  const extension = active.get(name);
+  if (!extension) return;
+  active.delete(name);
+
+  try {
+    await extension.module.deactivate?.();
+  } catch (error) {
+    console.error(`[Extension:${name}] deactivate() failed:`, error);
+  }
+  for (const subscription of extension.context.subscriptions.splice(0).reverse()) {
+    try {
+      subscription.dispose();
+    } catch (error) {
+      console.error(`[Extension:${name}] Failed to dispose:`, error);
+    }
+  }
+}
+
+function getSnapshot(): EditorSnapshot {
+  const { currentFile, currentLanguageId, isConnected, diagnosticsByUri } =
+    useEditorStore.getState();
+  const diagnostics = Object.values(diagnosticsByUri).reduce(
+    (acc, d) => ({
+      errors: acc.errors + (d?.errors ?? 0),
+      warnings: acc.warnings + (d?.warnings ?? 0),
+    }),
+    { errors: 0, warnings: 0 },
+  );
+  return { currentFile, currentLanguageId, isConnected, diagnostics };
+}
+
+const sameSnapshot = (a: EditorSnapshot, b: EditorSnapshot) =>
+  a.currentFile === b.currentFile &&
+  a.currentLanguageId === b.currentLanguageId &&
+  a.isConnected === b.isConnected &&
+  a.diagnostics.errors === b.diagnostics.errors &&
+  a.diagnostics.warnings === b.diagnostics.warnings;
+
+/**
+ * Listen for a server notification, waiting for the LSP connection if it
+ * isn't up yet
+ */
+function onServerNotification(
+  method: string,
+  listener: (params: any) => void,
+): Disposable {
+  let registration: Disposable | null = null;
+  let unsubscribe: (() => void) | null = null;
+
+  const attach = () => {
+    const { lspManager } = useEditorStore.getState();
+    if (!lspManager) return false;
+    registration = lspManager.onNotification(method, listener);
+    return true;
+  };
+
+  if (!attach()) {
+    unsubscribe = useEditorStore.subscribe(() => {
+      if (attach()) {
+        unsubscribe?.();
+        unsubscribe = null;
+      }
+    });
+  }
+
+  return {
+    dispose: () => {
+      unsubscribe?.();
+      registration?.dispose();
+    },
+  };
+}
+
+async function createContext(name: string): Promise<ExtensionContext> {
+  const monaco = await import("monaco-editor");
+  const store = useExtensionStore.getState();
+  const subscriptions: Disposable[] = [];
+  const prefix = (id: string) => `${name}.${id}`;
+
+  // Track a registration so it is undone when the extension is deactivated
+  const track = (dispose: () => void): Disposable => {
+    let disposed = false;
+    const disposable = {
+      dispose: () => {
+        if (disposed) return;
+        disposed = true;
+        dispose();
+        const index = subscriptions.indexOf(disposable);
+        if (index >= 0) subscriptions.splice(index, 1);
+      },
+    };
+    subscriptions.push(disposable);
+    return disposable;
+  };
+
+  // Monaco can't forget a theme, so disposing one resets it to its base
+  const defineTheme = (
+    themeId: string,
+    editor: ThemeContribution["editor"],
+  ): Disposable => {
+    monaco.editor.defineTheme(themeId, editor);
+    return track(() =>
+      monaco.editor.defineTheme(themeId, {
+        base: editor.base,
+        inherit: true,
+        rules: [],
+        colors: {},
+      }),
+    );
+  };
+
+  const checkMethod = (method: string) => {
+    if (!method.startsWith(`${name}/`)) {
+      throw new Error(`Extension ${name} may only call ${name}/* methods, not ${method}`);
+    }
+  };
+
+  return {
+    apiVersion: EXTENSION_API_VERSION,
+    name,
+    subscriptions,
+    log: {
+      info: (...args) => console.info(`[Extension:${name}]`, ...args),
+      warn: (...args) => console.warn(`[Extension:${name}]`, ...args),
+      error: (...args) => console.error(`[Extension:${name}]`, ...args),
+    },
+
+    commands: {
+      register: (id, title, run) => {
+        const commandId = prefix(id);
+        store.add("commands", { id: commandId, title, extension: name, run });
+        return track(() => store.remove("commands", commandId));
+      },
+      execute: (id, ...args) => executeCommand(id, ...args),
+    },
+
+    panels: {
+      register: (id, title, render) => {
+        const panelId = prefix(id);
+        store.add("panels", { id: panelId, title, extension: name, render });
+        return track(() => store.remove("panels", panelId));
+      },
+      show: (id) => store.setOpenPanel(prefix(id)),
+      hide: () => {
+        if (useExtensionStore.getState().openPanelId?.startsWith(`${name}.`)) {
+          store.setOpenPanel(null);
+        }
+      },
+    },
+
+    statusBar: {
+      create: (id, options) => {
+        const itemId = prefix(id);
+        store.add("statusBarItems", { ...options, id: itemId, extension: name });
+        const disposable = track(() => store.remove("statusBarItems", itemId));
+        return {
+          update: (changes) => store.update("statusBarItems", itemId, changes),
+          dispose: disposable.dispose,
+        };
+      },
+    },
+
+    fileTree: {
+      registerContextMenuItem: (id, label, when, run) => {
+        const itemId = prefix(id);
+        store.add("contextMenuItems", { id: itemId, label, extension: name, when, run });
+        return track(() => store.remove("contextMenuItems", itemId));
+      },
+    },
+
+    themes: {
+      register: (id, label, base, editor, cssVariables) => {
+        const themeId = prefix(id);
+        const definition = defineTheme(themeId, editor);
+        store.add("themes", { id: themeId, label, extension: name, base, editor, cssVariables });
+        return track(() => {
+          store.remove("themes", themeId);
+          definition.dispose();
+        });
+      },
+      define: (id, editor) => defineTheme(prefix(id), editor),
+    },
+
+    languages: {
+      registerProvider: (register, ...args) => {
+        const registration = (
+          monaco.languages[register] as (...args: unknown[]) => Disposable
+        )(...args);
+        return track(() => registration.dispose());
+      },
+    },
+
+    monaco,
+
+    workspace: {
+      getSnapshot,
+      onDidChange: (listener) => {
+        let last = getSnapshot();
+        const unsubscribe = useEditorStore.subscribe(() => {
+          const next = getSnapshot();
+          if (sameSnapshot(last, next)) return;
+          last = next;
+          try {
+            listener(next);
+          } catch (error) {
+            console.error(`[Extension:${name}] Workspace listener failed:`, error);
+          }
+        });
+        return track(unsubscribe);
+      },
+      openFile: (path, line, column) => openWorkspaceFile(path, line, column),
+    },
+
+    server: {
+      request: async <T,>(method: string, params?: unknown) => {
+        checkMethod(method);
+        const { lspManager } = useEditorStore.getState();
+        if (!lspManager) {
+          throw new Error("Not connected to the server");
+        }
+        return lspManager.sendRequest<T>(method, params);
+      },
+      notify: (method, params) => {
+        checkMethod(method);
+        useEditorStore.getState().lspManager?.sendNotification(method, params);
+      },
+      onNotification: (method, listener) => {
+        checkMethod(method);
+        const registration = onServerNotification(method, (params) => {
+          try {
+            listener(params);
+          } catch (error) {
+            console.error(`[Extension:${name}] Notification listener for ${method} failed:`, error);
+          }
+        });
+        return track(() => registration.dispose());
+      },
+      fetch: (path, init) =>
+        fetch(resolveServerUrl(`/api/plugins/${name}${path}`), {
+          ...init,
+          headers: { ...getAuthHeaders(), ...(init?.headers as Record<string, string>) },
+        }),
+    },
+  };
+}
@@ -0,0 +1,113 @@
+import { create } from "zustand";
+import type {
+  CommandContribution,
+  ContextMenuContribution,
+  PanelContribution,
+  StatusBarContribution,
+  ThemeContribution,
+} from "./types";
+
+// localStorage key of the selected extension theme
+const THEME_STORAGE_KEY = "oneline-editor.extensionTheme";
+
+export interface LoadedExtension {
+  name: string;
+  version?: string;
+  status: "active" | "failed";
+  error?: string;
+}
+
+/**
+ * Contributions from web extensions. Components read from this store; only
+ * the extension host writes to it, so extensions never touch app state.
+ */
+interface ExtensionState {
+  extensions: LoadedExtension[];
+  commands: CommandContribution[];
+  panels: PanelContribution[];
+  openPanelId: string | null;
+  statusBarItems: StatusBarContribution[];
+  contextMenuItems: ContextMenuContribution[];
+  themes: ThemeContribution[];
+  activeThemeId: string | null;
+  setExtension: (extension: LoadedExtension) => void;
+  add: <K extends ContributionKey>(
+    key: K,
+    item: ExtensionState[K][number],
+  ) => void;
+  update: <K extends ContributionKey>(
+    key: K,
+    id: string,
+    changes: Partial<ExtensionState[K][number]>,
+  ) => void;
+  remove: (key: ContributionKey, id: string) => void;
+  setOpenPanel: (id: string | null) => void;
+  setActiveTheme: (id: string | null) => void;
+}
+
+type ContributionKey =
+  | "commands"
+  | "panels"
+  | "statusBarItems"
+  | "contextMenuItems"
+  | "themes";
+
+export const useExtensionStore = create<ExtensionState>((set) => ({
+  extensions: [],
+  commands: [],
+  panels: [],
+  openPanelId: null,
+  statusBarItems: [],
+  contextMenuItems: [],
+  themes: [],
+  activeThemeId:
+    typeof window !== "undefined"
+      ? window.localStorage.getItem(THEME_STORAGE_KEY)
+      : null,
+  setExtension: (extension) =>
+    set((state) => ({
+      extensions: [
+        ...state.extensions.filter((e) => e.name !== extension.name),
+        extension,
+      ],
+    })),
+  add: (key, item) =>
+    set((state) => ({
+      [key]: [
+        ...(state[key] as { id: string }[]).filter((i) => i.id !== item.id),
+        item,
+      ],
+    }) as Partial<ExtensionState>),
+  update: (key, id, changes) =>
+    set((state) => ({
+      [key]: (state[key] as { id: string }[]).map((i) =>
+        i.id === id ? { ...i, ...changes } : i,
+      ),
+    }) as Partial<ExtensionState>),
+  remove: (key, id) =>
+    set((state) => ({
+      [key]: (state[key] as { id: string }[]).filter((i) => i.id !== id),
+      openPanelId:
+        key === "panels" && state.openPanelId === id ? null : state.openPanelId,
+    }) as Partial<ExtensionState>),
+  setOpenPanel: (id) => set({ openPanelId: id }),
+  setActiveTheme: (id) => {
+    if (typeof window !== "undefined") {
+      if (id) {
+        window.localStorage.setItem(THEME_STORAGE_KEY, id);
+      } else {
+        window.localStorage.removeItem(THEME_STORAGE_KEY);
+      }
+    }
+    set({ activeThemeId: id });
+  },
+}));
+
+/**
+ * The selected extension theme, if its extension is loaded
+ */
+export function getActiveExtensionTheme(
+  state: Pick<ExtensionState, "themes" | "activeThemeId">,
+): ThemeContribution | undefined {
+  return state.themes.find((theme) => theme.id === state.activeThemeId);
+}
@@ -0,0 +1,170 @@
+import type * as monaco from "monaco-editor";
+
+/**
+ * Version of the extension API. Bumped on breaking changes; extensions
+ * declare the version they were written against and are skipped when it
+ * doesn't match.
+ */
+export const EXTENSION_API_VERSION = 1;
+
+export interface Disposable {
+  dispose(): void;
+}
+
+/**
+ * Default export of an extension bundle
+ */
+export interface WebExtension {
+  apiVersion: number;
+  activate(context: ExtensionContext): void | Promise<void>;
+  deactivate?(): void | Promise<void>;
+}
+
+export interface CommandContribution {
+  // Prefixed with the extension name, e.g. "word-count.show"
+  id: string;
+  title: string;
+  extension: string;
+  run: (...args: unknown[]) => unknown;
+}
+
+export interface PanelContribution {
+  id: string;
+  title: string;
+  extension: string;
+  // Render into a DOM node owned by the panel; return a cleanup function if needed
+  render: (container: HTMLElement) => void | (() => void);
+}
+
+export interface StatusBarItemOptions {
+  text: string;
+  tooltip?: string;
+  // Command executed on click
+  command?: string;
+  alignment?: "left" | "right";
+}
+
+export interface StatusBarContribution extends StatusBarItemOptions {
+  id: string;
+  extension: string;
+}
+
+export interface StatusBarItemHandle extends Disposable {
+  update(options: Partial<StatusBarItemOptions>): void;
+}
+
+export interface FileTreeTarget {
+  path: string;
+  type: "file" | "directory";
+}
+
+export interface ContextMenuContribution {
+  id: string;
+  label: string;
+  extension: string;
+  // Which nodes the item applies to; "empty" is the blank area of the tree
+  when: "file" | "directory" | "any" | "empty";
+  run: (target: FileTreeTarget | null) => unknown;
+}
+
+export interface ThemeContribution {
+  id: string;
+  label: string;
+  extension: string;
+  base: "light" | "dark";
+  // Monaco theme definition (https://microsoft.github.io/monaco-editor/typedoc/interfaces/editor.IStandaloneThemeData.html)
+  editor: monaco.editor.IStandaloneThemeData;
+  // CSS custom properties from globals.css to override, e.g. { "--background": "220 20% 10%" }
+  cssVariables?: Record<string, string>;
+}
+
+/**
+ * Functions of monaco.languages that register a provider, e.g.
+ * "registerHoverProvider"
+ */
+export type ProviderRegistration = {
+  [K in keyof typeof monaco.languages]: K extends `register${string}Provider`
+    ? K
+    : never;
+}[keyof typeof monaco.languages];
+
+/**
+ * Read-only snapshot of editor state. Extensions cannot change the store;
+ * they act through the context methods instead.
+ */
+export interface EditorSnapshot {
+  currentFile: string | null;
+  currentLanguageId: string | null;
+  isConnected: boolean;
+  diagnostics: { errors: number; warnings: number };
+}
+
+/**
+ * The API handed to an extension's activate(). Everything registered here
+ * is removed automatically when the extension is deactivated.
+ */
+export interface ExtensionContext {
+  apiVersion: number;
+  name: string;
+  subscriptions: Disposable[];
+  log: Pick<Console, "info" | "warn" | "error">;
+
+  commands: {
+    register(id: string, title: string, run: (...args: unknown[]) => unknown): Disposable;
+    execute(id: string, ...args: unknown[]): Promise<unknown>;
+  };
+  panels: {
+    register(id: string, title: string, render: PanelContribution["render"]): Disposable;
+    show(id: string): void;
+    hide(): void;
+  };
+  statusBar: {
+    create(id: string, options: StatusBarItemOptions): StatusBarItemHandle;
+  };
+  fileTree: {
+    registerContextMenuItem(
+      id: string,
+      label: string,
+      when: ContextMenuContribution["when"],
+      run: ContextMenuContribution["run"],
+    ): Disposable;
+  };
+  themes: {
+    register(
+      id: string,
+      label: string,
+      base: ThemeContribution["base"],
+      editor: ThemeContribution["editor"],
+      cssVariables?: Record<string, string>,
+    ): Disposable;
+    // Define a Monaco theme (as "<extension>.<id>") without listing it in
+    // the top bar, e.g. for monaco.editor.setTheme()
+    define(id: string, editor: ThemeContribution["editor"]): Disposable;
+  };
+  languages: {
+    // Register a Monaco language provider, e.g.
+    // registerProvider("registerHoverProvider", "go", { provideHover })
+    registerProvider<K extends ProviderRegistration>(
+      register: K,
+      ...args: Parameters<(typeof monaco.languages)[K]>
+    ): Disposable;
+  };
+  // Monaco namespace for models, editors and types. Register providers and
+  // themes through `languages` and `themes` so they go away with the
+  // extension.
+  monaco: typeof monaco;
+  workspace: {
+    getSnapshot(): EditorSnapshot;
+    onDidChange(listener: (snapshot: EditorSnapshot) => void): Disposable;
+    openFile(path: string, line?: number, column?: number): Promise<boolean>;
+  };
+  // Talks to the server plugin of the same name. Methods must be prefixed
+  // with "<name>/" so extensions can't impersonate LSP traffic.
+  server: {
+    request<T = unknown>(method: string, params?: unknown): Promise<T>;
+    notify(method: string, params?: unknown): void;
+    onNotification(method: string, listener: (params: any) => void): Disposable;
+    // fetch() against the plugin's REST routes (/api/plugins/<name><path>)
+    fetch(path: string, init?: RequestInit): Promise<Response>;
+  };
+}
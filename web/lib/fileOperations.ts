@@ -0,0 +1,144 @@
+import type * as Monaco from "monaco-editor";
+import type { TextEdit } from "vscode-languageserver-types";
+import { api } from "./api";
+import { detectLanguage } from "./languages";
+import type { FrontendLSPManager } from "./lsp/client";
+import { useEditorStore } from "./store";
+
+const NO_DIAGNOSTICS = { errors: 0, warnings: 0 };
+
+function isUnder(path: string, parent: string): boolean {
+  return path === parent || path.startsWith(`${parent}/`);
+}
+
+/**
+ * Move open files to their new paths, so they stay open where they were
+ * and the language server sees them under the new URI
+ */
+function handleRenamedFiles(
+  lspManager: FrontendLSPManager,
+  files: Array<{ oldPath: string; newPath: string }>,
+): void {
+  const {
+    editorManager,
+    currentFile,
+    setCurrentFile,
+    setCurrentLanguageId,
+    setDiagnostics,
+  } = useEditorStore.getState();
+  if (!editorManager) return;
+
+  for (const { oldPath, newPath } of files) {
+    const moved = editorManager.renamePath(
+      oldPath,
+      newPath,
+      (path) => detectLanguage(path).id,
+    );
+    for (const { oldUri } of moved) {
+      lspManager.didCloseTextDocument(oldUri);
+      setDiagnostics(oldUri, NO_DIAGNOSTICS, []);
+    }
+    if (currentFile && isUnder(currentFile, oldPath)) {
+      const renamed = newPath + currentFile.slice(oldPath.length);
+      setCurrentFile(renamed);
+      setCurrentLanguageId(detectLanguage(renamed).id);
+    }
+  }
+}
+
+/**
+ * Close open files that were deleted, along with their diagnostics
+ */
+function handleDeletedFiles(
+  lspManager: FrontendLSPManager,
+  paths: string[],
+): void {
+  const { editorManager, currentFile, setCurrentFile, setDiagnostics } =
+    useEditorStore.getState();
+  if (!editorManager) return;
+
+  for (const path of paths) {
+    for (const uri of editorManager.closePath(path)) {
+      lspManager.didCloseTextDocument(uri);
+      setDiagnostics(uri, NO_DIAGNOSTICS, []);
+    }
+    if (currentFile && isUnder(currentFile, path)) {
+      setCurrentFile(null);
+    }
+  }
+}
+
+/**
+ * Reload open files a language server edited on disk, e.g. imports updated
+ * after a rename. The change is pushed as an edit so it can be undone.
+ */
+async function handleEditedFiles(paths: string[]): Promise<void> {
+  const { editorManager } = useEditorStore.getState();
+  if (!editorManager) return;
+
+  for (const path of paths) {
+    const model = editorManager.getModel(path);
+    if (!model) continue;
+    try {
+      const { content } = await api.readFile({ path });
+      if (content === model.getValue()) continue;
+      model.pushEditOperations(
+        [],
+        [{ range: model.getFullModelRange(), text: content }],
+        () => null,
+      );
+    } catch (error) {
+      console.error(`Error reloading ${path}:`, error);
+    }
+  }
+}
+
+/**
+ * Apply edits a language server made to an open file, e.g. imports updated
+ * after a rename. They were computed against the model, unsaved changes
+ * included, so they go to it rather than the file on disk; they are pushed
+ * as an edit so they can be undone.
+ */
+function handleOpenFileEdits(path: string, edits: TextEdit[]): void {
+  const model = useEditorStore.getState().editorManager?.getModel(path);
+  if (!model) return;
+  model.pushEditOperations(
+    [],
+    edits.map((edit) => ({
+      range: {
+        startLineNumber: edit.range.start.line + 1,
+        startColumn: edit.range.start.character + 1,
+        endLineNumber: edit.range.end.line + 1,
+        endColumn: edit.range.end.character + 1,
+      },
+      text: edit.newText,
+    })),
+    () => null,
+  );
+}
+
+/**
+ * Follow files being renamed, deleted or edited by language servers on the
+ * server, keeping open models in step
+ */
+export function watchFileOperations(
+  lspManager: FrontendLSPManager,
+): Monaco.IDisposable {
+  const subscriptions = [
+    lspManager.onNotification("workspace/didRenameFiles", ({ files }) => {
+      handleRenamedFiles(lspManager, files);
+    }),
+    lspManager.onNotification("workspace/didDeleteFiles", ({ paths }) => {
+      handleDeletedFiles(lspManager, paths);
+    }),
+    lspManager.onNotification("workspace/didEditFiles", ({ paths }) => {
+      void handleEditedFiles(paths);
+    }),
+    lspManager.onNotification("workspace/applyFileEdits", ({ path, edits }) => {
+      handleOpenFileEdits(path, edits);
+    }),
+  ];
+  return {
+    dispose: () => subscriptions.forEach((subscription) => subscription.dispose()),
+  };
+}
@@ -0,0 +1,139 @@
+import type * as Monaco from "monaco-editor";
+import {
+  api,
+  describeApiError,
+  type GoModule,
+  type GoModuleCommandResult,
+} from "./api";
+import { type DiagnosticItem, useEditorStore } from "./store";
+import { toWorkspaceUri } from "./uris";
+
+// Marker owner and Problems source for module diagnostics, so they don't
+// replace the diagnostics gopls publishes for go.mod
+const DIAGNOSTICS_OWNER = "go-modules";
+
+type GoModuleCommand = "update" | "tidy" | "vendor";
+
+/**
+ * Workspace path of a module's go.mod
+ */
+export function getGoModPath(module: GoModule): string {
+  return `${module.path === "/" ? "" : module.path}/go.mod`;
+}
+
+const markersByUri = new Map<string, Monaco.editor.IMarkerData[]>();
+let modelListener: Monaco.IDisposable | undefined;
+
+async function applyDiagnostics(
+  previous: GoModule[],
+  modules: GoModule[],
+): Promise<void> {
+  // Loaded lazily so this module can be imported during server rendering
+  const monaco = await import("monaco-editor");
+  const { setDiagnostics } = useEditorStore.getState();
+
+  // go.mod files that no longer exist or have no problems are cleared
+  const stale = new Set(
+    previous.map((module) =>
+      monaco.Uri.parse(toWorkspaceUri(getGoModPath(module))).toString(),
+    ),
+  );
+  markersByUri.clear();
+
+  for (const module of modules) {
+    const uri = monaco.Uri.parse(toWorkspaceUri(getGoModPath(module))).toString();
+    stale.delete(uri);
+    const items: DiagnosticItem[] = module.diagnostics.map((diagnostic) => ({
+      uri,
+      message: diagnostic.message,
+      severity: diagnostic.severity,
+      line: diagnostic.line,
+      column: diagnostic.column,
+      source: "go modules",
+      code: diagnostic.code,
+    }));
+    setDiagnostics(
+      uri,
+      {
+        errors: 0,
+        warnings: items.filter((item) => item.severity === "warning").length,
+      },
+      items,
+      DIAGNOSTICS_OWNER,
+    );
+    markersByUri.set(
+      uri,
+      module.diagnostics.map((diagnostic) => ({
+        severity:
+          diagnostic.severity === "warning"
+            ? monaco.MarkerSeverity.Warning
+            : monaco.MarkerSeverity.Info,
+        startLineNumber: diagnostic.line,
+        startColumn: diagnostic.column,
+        endLineNumber: diagnostic.line,
+        endColumn: diagnostic.endColumn,
+        message: diagnostic.message,
+        code: diagnostic.code,
+        source: "go modules",
+      })),
+    );
+  }
+  for (const uri of stale) {
+    setDiagnostics(uri, { errors: 0, warnings: 0 }, [], DIAGNOSTICS_OWNER);
+  }
+
+  for (const model of monaco.editor.getModels()) {
+    monaco.editor.setModelMarkers(
+      model,
+      DIAGNOSTICS_OWNER,
+      markersByUri.get(model.uri.toString()) ?? [],
+    );
+  }
+
+  // go.mod files opened later get their markers when the model is created
+  modelListener ??= monaco.editor.onDidCreateModel((model) => {
+    const markers = markersByUri.get(model.uri.toString());
+    if (markers) {
+      monaco.editor.setModelMarkers(model, DIAGNOSTICS_OWNER, markers);
+    }
+  });
+}
+
+/**
+ * Load the workspace's Go modules into the store and show outdated and
+ * vulnerable dependencies in the Problems panel
+ */
+export async function fetchGoModules(): Promise<void> {
+  try {
+    const modules = await api.listGoModules();
+    const previous = useEditorStore.getState().goModules;
+    useEditorStore.getState().setGoModules(modules);
+    await applyDiagnostics(previous, modules);
+  } catch (error) {
+    console.error("Error fetching Go modules:", error);
+  }
+}
+
+/**
+ * Run go get -u, go mod tidy or go mod vendor in a module on the server
+ */
+export async function runGoModuleCommand(
+  path: string,
+  command: GoModuleCommand,
+  dependency?: string,
+): Promise<GoModuleCommandResult | undefined> {
+  try {
+    const result = await api.runGoModuleCommand({ path, command, dependency });
+    const { goModules, setGoModules } = useEditorStore.getState();
+    const modules = goModules.map((module) =>
+      module.path === result.module.path ? result.module : module,
+    );
+    setGoModules(modules);
+    await applyDiagnostics(goModules, modules);
+    return result;
+  } catch (error) {
+    console.error("Error running Go module command:", error);
+    alert(`Failed to run go ${command}: ${describeApiError(error)}`);
+    return undefined;
+  }
+}
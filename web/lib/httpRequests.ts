@@ -0,0 +1,316 @@
+import type * as Monaco from "monaco-editor";
+import { create } from "zustand";
+import { announce } from "./a11y";
+import {
+  api,
+  describeApiError,
+  type HttpExchange,
+  type HttpExchangeSummary,
+} from "./api";
+import { useEditorStore } from "./store";
+
+export const HTTP_LANGUAGE_ID = "http";
+
+// Monaco command behind the "Send Request" code lens
+const SEND_REQUEST_COMMAND = "http.sendRequest";
+
+// localStorage key of the environment picked in the response panel
+const ENVIRONMENT_STORAGE_KEY = "oneline-editor.httpEnvironment";
+
+const METHODS = [
+  "GET",
+  "POST",
+  "PUT",
+  "PATCH",
+  "DELETE",
+  "HEAD",
+  "OPTIONS",
+  "TRACE",
+  "CONNECT",
+];
+const METHOD_PATTERN = new RegExp(`^(${METHODS.join("|")})\\s+\\S`);
+const URL_PATTERN = /^(https?:\/\/|\{\{)/;
+const SEPARATOR = /^###/;
+const SKIPPED_LINE = /^\s*(#|\/\/|@[\w.-]+\s*=|$)/;
+
+/**
+ * The latest response, the environments of the .http file it came from and
+ * the request history
+ */
+interface HttpState {
+  exchange: HttpExchange | null;
+  isSending: boolean;
+  history: HttpExchangeSummary[];
+  environments: string[];
+  envFile: string | null;
+  env: string | null;
+  setExchange: (exchange: HttpExchange | null) => void;
+  setSending: (sending: boolean) => void;
+  setHistory: (history: HttpExchangeSummary[]) => void;
+  setEnvironments: (environments: string[], envFile: string | null) => void;
+  setEnv: (env: string | null) => void;
+}
+
+export const useHttpStore = create<HttpState>((set) => ({
+  exchange: null,
+  isSending: false,
+  history: [],
+  environments: [],
+  envFile: null,
+  env: loadEnvironment(),
+  setExchange: (exchange) => set({ exchange }),
+  setSending: (sending) => set({ isSending: sending }),
+  setHistory: (history) => set({ history }),
+  setEnvironments: (environments, envFile) => set({ environments, envFile }),
+  setEnv: (env) => {
+    saveEnvironment(env);
+    set({ env });
+  },
+}));
+
+function loadEnvironment(): string | null {
+  if (typeof window === "undefined") return null;
+  return window.localStorage.getItem(ENVIRONMENT_STORAGE_KEY);
+}
+
+function saveEnvironment(env: string | null): void {
+  if (typeof window === "undefined") return;
+  if (env) {
+    window.localStorage.setItem(ENVIRONMENT_STORAGE_KEY, env);
+  } else {
+    window.localStorage.removeItem(ENVIRONMENT_STORAGE_KEY);
+  }
+}
+
+/**
+ * Whether a document is a .http / .rest request file
+ */
+export function isHttpFile(uri: string, languageId?: string): boolean {
+  return languageId === HTTP_LANGUAGE_ID || /\.(http|rest)$/i.test(uri);
+}
+
+/**
+ * 1-based request lines of a .http file: the first line of each ###-separated
+ * block that isn't blank, a comment or a variable
+ */
+export function findRequestLines(lines: string[]): number[] {
+  const requestLines: number[] = [];
+  let inBlock = true;
+  lines.forEach((text, index) => {
+    if (SEPARATOR.test(text)) {
+      inBlock = true;
+      return;
+    }
+    if (!inBlock || SKIPPED_LINE.test(text)) return;
+    inBlock = false;
+    const line = text.trim();
+    if (METHOD_PATTERN.test(line) || URL_PATTERN.test(line)) {
+      requestLines.push(index + 1);
+    }
+  });
+  return requestLines;
+}
+
+let registered = false;
+
+/**
+ * Register the http language: highlighting, a "Send Request" code lens above
+ * every request and the command behind it
+ */
+export function registerHttpLanguage(monaco: typeof Monaco): void {
+  if (registered) return;
+  registered = true;
+
+  monaco.languages.register({
+    id: HTTP_LANGUAGE_ID,
+    extensions: [".http", ".rest"],
+    aliases: ["HTTP", "http"],
+  });
+  monaco.languages.setLanguageConfiguration(HTTP_LANGUAGE_ID, {
+    comments: { lineComment: "#" },
+    brackets: [
+      ["{", "}"],
+      ["[", "]"],
+    ],
+    autoClosingPairs: [
+      { open: "{", close: "}" },
+      { open: "[", close: "]" },
+      { open: '"', close: '"' },
+    ],
+  });
+  monaco.languages.setMonarchTokensProvider(HTTP_LANGUAGE_ID, {
+    methods: METHODS,
+    tokenizer: {
+      root: [
+        [/^###.*$/, "comment.doc"],
+        [/^\s*(#|\/\/)\s*@name\b.*$/, "annotation"],
+        [/^\s*(#|\/\/).*$/, "comment"],
+        [/^(@[\w.-]+)(\s*=)/, ["variable", "delimiter"]],
+        [/\{\{[^}]*\}\}/, "variable"],
+        [
+          /^[A-Z]+(?=\s)/,
+          { cases: { "@methods": "keyword", "@default": "" } },
+        ],
+        [/HTTP\/[\d.]+/, "keyword"],
+        [/^[\w-]+(?=\s*:)/, "attribute.name"],
+        [/^<\s+\S.*$/, "string.link"],
+        [/"([^"\\]|\\.)*"/, "string"],
+        [/\b\d+(\.\d+)?\b/, "number"],
+        [/\b(true|false|null)\b/, "keyword"],
+      ],
+    },
+  } as Monaco.languages.IMonarchLanguage);
+
+  monaco.editor.registerCommand(
+    SEND_REQUEST_COMMAND,
+    (_accessor, uri: string, line: number) => {
+      void sendHttpRequest(uri, line);
+    },
+  );
+
+  monaco.languages.registerCodeLensProvider(HTTP_LANGUAGE_ID, {
+    provideCodeLenses: (model) => ({
+      lenses: findRequestLines(model.getLinesContent()).map((line) => ({
+        range: new monaco.Range(line, 1, line, 1),
+        command: {
+          id: SEND_REQUEST_COMMAND,
+          title: "Send Request",
+          arguments: [model.uri.toString(), line],
+        },
+      })),
+      dispose: () => {},
+    }),
+  });
+}
+
+/**
+ * Send the request around a line of a .http file from the server and show
+ * the response. Unsaved edits are included: open documents are kept in sync
+ * on disk.
+ */
+export async function sendHttpRequest(
+  uri: string,
+  line: number,
+): Promise<HttpExchange | undefined> {
+  const { setHttpOpen } = useEditorStore.getState();
+  const { env, setSending, setExchange } = useHttpStore.getState();
+  setHttpOpen(true);
+  await fetchHttpEnvironments(uri);
+
+  // The saved environment may not exist next to this file
+  const { environments } = useHttpStore.getState();
+  const selected = env && environments.includes(env) ? env : undefined;
+
+  setSending(true);
+  let exchange: HttpExchange;
+  try {
+    exchange = await api.sendHttpRequest({ uri, line, env: selected });
+  } catch (error) {
+    console.error("Error sending request:", error);
+    alert(`Failed to send request: ${describeApiError(error)}`);
+    return undefined;
+  } finally {
+    setSending(false);
+  }
+
+  setExchange(exchange);
+  announce(
+    exchange.error
+      ? `Request failed: ${exchange.error}`
+      : `${exchange.status} ${exchange.statusText} in ${exchange.durationMs} ms`,
+  );
+  void fetchHttpHistory();
+  return exchange;
+}
+
+/**
+ * Send the request under the cursor
+ */
+export async function sendRequestAtCursor(
+  editor: Monaco.editor.ICodeEditor,
+): Promise<HttpExchange | undefined> {
+  const model = editor.getModel();
+  const position = editor.getPosition();
+  if (!model || !position) return undefined;
+  const uri = model.uri.toString();
+  if (!isHttpFile(uri, model.getLanguageId())) {
+    announce("Requests can only be sent from .http and .rest files");
+    return undefined;
+  }
+  return sendHttpRequest(uri, position.lineNumber);
+}
+
+/**
+ * Load the environments of the env file nearest to a .http file
+ */
+export async function fetchHttpEnvironments(uri: string): Promise<void> {
+  try {
+    const { environments, file } = await api.listHttpEnvironments({ uri });
+    useHttpStore.getState().setEnvironments(environments, file ?? null);
+  } catch (error) {
+    console.error("Error fetching HTTP environments:", error);
+    useHttpStore.getState().setEnvironments([], null);
+  }
+}
+
+/**
+ * Load the request history of the workspace
+ */
+export async function fetchHttpHistory(): Promise<void> {
+  try {
+    useHttpStore.getState().setHistory(await api.listHttpHistory());
+  } catch (error) {
+    console.error("Error fetching HTTP history:", error);
+  }
+}
+
+/**
+ * Show a past response in the panel
+ */
+export async function openHttpHistoryEntry(id: string): Promise<void> {
+  try {
+    useHttpStore.getState().setExchange(await api.getHttpHistoryEntry(id));
+  } catch (error) {
+    console.error("Error loading HTTP history entry:", error);
+    alert(`Failed to load the response: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Forget the request history of the workspace
+ */
+export async function clearHttpHistory(): Promise<void> {
+  try {
+    await api.clearHttpHistory();
+    useHttpStore.getState().setHistory([]);
+  } catch (error) {
+    console.error("Error clearing HTTP history:", error);
+    alert(`Failed to clear the history: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Response body for display: JSON is pretty-printed
+ */
+export function formatResponseBody(exchange: HttpExchange): string {
+  const contentType = Object.entries(exchange.headers).find(
+    ([name]) => name.toLowerCase() === "content-type",
+  )?.[1];
+  if (!contentType?.includes("json") || exchange.truncated) {
+    return exchange.body;
+  }
+  try {
+    return JSON.stringify(JSON.parse(exchange.body), null, 2);
+  } catch {
+    return exchange.body;
+  }
+}
+
+/**
+ * Human-readable byte size, e.g. "1.2 KB"
+ */
+export function formatSize(bytes: number): string {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${(bytes / 1024 / 1024).toFixed(1)} MB`;
+}
@@ -0,0 +1,99 @@
+/**
+ * Languages the editor recognises. `id` is the Monaco language ID, which is
+ * also the languageId sent to the server in textDocument/didOpen.
+ */
+export interface LanguageDefinition {
+  id: string;
+  label: string;
+  extensions: string[];
+  fileNames?: string[];
+  // Whether the server has a language server for it by default
+  lsp: boolean;
+}
+
+export const LANGUAGES: LanguageDefinition[] = [
+  { id: "go", label: "Go", extensions: [".go"], lsp: true },
+  {
+    id: "typescript",
+    label: "TypeScript",
+    extensions: [".ts", ".tsx", ".mts", ".cts"],
+    lsp: true,
+  },
+  {
+    id: "javascript",
+    label: "JavaScript",
+    extensions: [".js", ".jsx", ".mjs", ".cjs"],
+    lsp: true,
+  },
+  { id: "python", label: "Python", extensions: [".py", ".pyi"], lsp: true },
+  { id: "rust", label: "Rust", extensions: [".rs"], lsp: true },
+  {
+    id: "json",
+    label: "JSON",
+    extensions: [".json", ".jsonc"],
+    fileNames: [".babelrc", ".eslintrc", ".prettierrc"],
+    // Monaco's JSON worker validates against the server's schema registry;
+    // the language server is only used for formatting
+    lsp: false,
+  },
+  { id: "yaml", label: "YAML", extensions: [".yaml", ".yml"], lsp: true },
+  { id: "html", label: "HTML", extensions: [".html", ".htm"], lsp: true },
+  { id: "css", label: "CSS", extensions: [".css"], lsp: true },
+  { id: "scss", label: "SCSS", extensions: [".scss"], lsp: true },
+  { id: "less", label: "Less", extensions: [".less"], lsp: true },
+  {
+    id: "markdown",
+    label: "Markdown",
+    extensions: [".md", ".markdown"],
+    lsp: false,
+  },
+  {
+    id: "shell",
+    label: "Shell",
+    extensions: [".sh", ".bash"],
+    lsp: false,
+  },
+  {
+    id: "dockerfile",
+    label: "Dockerfile",
+    extensions: [".dockerfile"],
+    fileNames: ["dockerfile"],
+    lsp: false,
+  },
+  {
+    id: "http",
+    label: "HTTP",
+    extensions: [".http", ".rest"],
+    lsp: false,
+  },
+  { id: "plaintext", label: "Plain Text", extensions: [".txt"], lsp: false },
+];
+
+/**
+ * Detect the language of a workspace path from its file name or extension
+ */
+export function detectLanguage(path: string): LanguageDefinition {
+  const name = path.slice(path.lastIndexOf("/") + 1).toLowerCase();
+  const dot = name.lastIndexOf(".");
+  const extension = dot > 0 ? name.slice(dot) : "";
+
+  return (
+    LANGUAGES.find((language) => language.fileNames?.includes(name)) ??
+    LANGUAGES.find(
+      (language) => extension && language.extensions.includes(extension),
+    ) ??
+    LANGUAGES[LANGUAGES.length - 1]
+  );
+}
+
+/**
+ * Display name for a language ID, falling back to the ID itself
+ */
+export function getLanguageLabel(languageId: string): string {
+  return LANGUAGES.find((language) => language.id === languageId)?.label ?? languageId;
+}
+
+// Language IDs that get LSP-backed Monaco providers
+export const LSP_LANGUAGE_IDS = LANGUAGES.filter((language) => language.lsp).map(
+  (language) => language.id,
+);
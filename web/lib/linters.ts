@@ -0,0 +1,205 @@
+import type * as Monaco from "monaco-editor";
+import { api, describeApiError, type LintDiagnostic } from "./api";
+import type { FrontendLSPManager } from "./lsp/client";
+import { type DiagnosticItem, useEditorStore } from "./store";
+import { toWorkspaceUri } from "./uris";
+
+// Marker owner and Problems source for external linters, so their
+// diagnostics are kept next to the language servers' rather than replacing them
+const DIAGNOSTICS_OWNER = "lint";
+
+interface LintedFile {
+  diagnostics: LintDiagnostic[];
+  // Model version the fixes apply to; unset until the file is opened
+  versionId?: number;
+}
+
+const filesByUri = new Map<string, LintedFile>();
+let modelListener: Monaco.IDisposable | undefined;
+let codeActionProvider: Monaco.IDisposable | undefined;
+
+function toMarkerSeverity(
+  monaco: typeof Monaco,
+  severity: LintDiagnostic["severity"],
+): Monaco.MarkerSeverity {
+  if (severity === "error") return monaco.MarkerSeverity.Error;
+  if (severity === "warning") return monaco.MarkerSeverity.Warning;
+  return monaco.MarkerSeverity.Info;
+}
+
+function setMarkers(
+  monaco: typeof Monaco,
+  model: Monaco.editor.ITextModel,
+  file: LintedFile,
+): void {
+  file.versionId = model.getVersionId();
+  monaco.editor.setModelMarkers(
+    model,
+    DIAGNOSTICS_OWNER,
+    file.diagnostics.map((diagnostic) => ({
+      severity: toMarkerSeverity(monaco, diagnostic.severity),
+      startLineNumber: diagnostic.line,
+      startColumn: diagnostic.column,
+      endLineNumber: diagnostic.endLine,
+      endColumn: diagnostic.endColumn,
+      message: diagnostic.message,
+      code: diagnostic.code,
+      source: diagnostic.source,
+    })),
+  );
+}
+
+/**
+ * Offer the fixes linters attach to their diagnostics as quick fixes, as
+ * long as the file hasn't changed since it was linted
+ */
+function registerCodeActions(monaco: typeof Monaco): void {
+  codeActionProvider ??= monaco.languages.registerCodeActionProvider("*", {
+    provideCodeActions: (model, range) => {
+      const file = filesByUri.get(model.uri.toString());
+      if (!file || file.versionId !== model.getVersionId()) {
+        return { actions: [], dispose: () => {} };
+      }
+      const actions: Monaco.languages.CodeAction[] = [];
+      for (const diagnostic of file.diagnostics) {
+        const diagnosticRange = new monaco.Range(
+          diagnostic.line,
+          diagnostic.column,
+          diagnostic.endLine,
+          diagnostic.endColumn,
+        );
+        if (!monaco.Range.areIntersectingOrTouching(range, diagnosticRange)) {
+          continue;
+        }
+        for (const fix of diagnostic.fixes) {
+          actions.push({
+            title: `${fix.title} [${diagnostic.source}]`,
+            kind: "quickfix",
+            diagnostics: [
+              {
+                severity: toMarkerSeverity(monaco, diagnostic.severity),
+                startLineNumber: diagnostic.line,
+                startColumn: diagnostic.column,
+                endLineNumber: diagnostic.endLine,
+                endColumn: diagnostic.endColumn,
+                message: diagnostic.message,
+                source: diagnostic.source,
+              },
+            ],
+            edit: {
+              edits: fix.edits.map((edit) => ({
+                resource: model.uri,
+                versionId: file.versionId,
+                textEdit: {
+                  range: new monaco.Range(
+                    edit.startLine,
+                    edit.startColumn,
+                    edit.endLine,
+                    edit.endColumn,
+                  ),
+                  text: edit.text,
+                },
+              })),
+            },
+          });
+        }
+      }
+      return { actions, dispose: () => {} };
+    },
+  });
+}
+
+async function applyDiagnostics(
+  path: string,
+  diagnostics: LintDiagnostic[],
+): Promise<void> {
+  // Loaded lazily so this module can be imported during server rendering
+  const monaco = await import("monaco-editor");
+  const uri = monaco.Uri.parse(toWorkspaceUri(path)).toString();
+
+  const items: DiagnosticItem[] = diagnostics.map((diagnostic) => ({
+    uri,
+    message: diagnostic.message,
+    severity: diagnostic.severity,
+    line: diagnostic.line,
+    column: diagnostic.column,
+    source: diagnostic.source,
+    code: diagnostic.code,
+  }));
+  useEditorStore.getState().setDiagnostics(
+    uri,
+    {
+      errors: items.filter((item) => item.severity === "error").length,
+      warnings: items.filter((item) => item.severity === "warning").length,
+    },
+    items,
+    DIAGNOSTICS_OWNER,
+  );
+
+  const file: LintedFile = { diagnostics };
+  if (diagnostics.length > 0) {
+    filesByUri.set(uri, file);
+  } else {
+    filesByUri.delete(uri);
+  }
+  const model = monaco.editor.getModel(monaco.Uri.parse(uri));
+  if (model) {
+    setMarkers(monaco, model, file);
+  }
+
+  // Files opened later get their markers when the model is created
+  modelListener ??= monaco.editor.onDidCreateModel((created) => {
+    const linted = filesByUri.get(created.uri.toString());
+    if (linted) {
+      setMarkers(monaco, created, linted);
+    }
+  });
+  registerCodeActions(monaco);
+}
+
+/**
+ * Load the latest external linter diagnostics of the workspace into the
+ * Problems panel and the editor
+ */
+export async function fetchLintDiagnostics(): Promise<void> {
+  try {
+    const files = await api.listLintDiagnostics();
+    for (const file of files) {
+      await applyDiagnostics(file.path, file.diagnostics);
+    }
+  } catch (error) {
+    console.error("Error fetching lint diagnostics:", error);
+  }
+}
+
+/**
+ * Follow the diagnostics the server reports as linters run on save
+ */
+export function watchLintDiagnostics(
+  lspManager: FrontendLSPManager,
+): Monaco.IDisposable {
+  return lspManager.onNotification(
+    "workspace/lintDiagnostics",
+    ({ path, diagnostics }) => {
+      void applyDiagnostics(path, diagnostics);
+    },
+  );
+}
+
+/**
+ * Run the linters configured for a file now, whether or not they run on save
+ */
+export async function lintFile(path: string): Promise<void> {
+  try {
+    const result = await api.lintFile({ path });
+    await applyDiagnostics(result.path, result.diagnostics);
+    if (result.linters.length === 0) {
+      alert(`No linter is configured for ${path}`);
+    } else if (result.errors.length > 0) {
+      alert(`Some linters failed:\n${result.errors.join("\n")}`);
+    }
+  } catch (error) {
+    console.error("Error linting file:", error);
+    alert(`Failed to lint ${path}: ${describeApiError(error)}`);
+  }
+}
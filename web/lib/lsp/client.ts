@@ -2,6 +2,8 @@ import { LanguageClient } from "@lewin671/lsp-client";
 import * as monaco from "monaco-editor";
 import { TextEdit } from "vscode-languageserver-types";
 import { EditorManager } from "../editor/manager";
+import { getApiToken, getClientId } from "../api";
+import { LSP_LANGUAGE_IDS } from "../languages";
 import { WebSocketTransport } from "../transport/websocket";
 import { BrowserHost, BrowserWindow } from "./host";
 
@@ -38,6 +40,16 @@ export class FrontendLSPManager {
 
     console.log("[LSP Manager] Initializing with WebSocket URL:", wsUrl);
 
+    // Browsers can't set headers on WebSocket upgrades, so auth and the
+    // session ID travel as query parameters
+    const url = new URL(wsUrl);
+    url.searchParams.set("client", getClientId());
+    const token = getApiToken();
+    if (token) {
+      url.searchParams.set("token", token);
+    }
+    wsUrl = url.toString();
+
     // Create host
     this.host = new BrowserHost();
 
@@ -186,9 +198,7 @@ export class FrontendLSPManager {
       return;
     }
 
-    const languages = ["typescript", "javascript", "go"];
-
-    languages.forEach((languageId) => {
+    LSP_LANGUAGE_IDS.forEach((languageId) => {
       console.log(
         `[LSP Manager] Registering completion provider for language: ${languageId}`,
       );
@@ -593,6 +603,29 @@ export class FrontendLSPManager {
     });
   }
 
+  /**
+   * Open a document again in another language, so the server for the new
+   * language takes it over from the old one
+   */
+  reopenTextDocument(
+    uri: string,
+    languageId: string,
+    version: number,
+    text: string,
+  ): void {
+    if (!this.client) return;
+
+    this.openedDocuments.add(uri);
+    this.client.didOpen({
+      textDocument: {
+        uri,
+        languageId,
+        version,
+        text,
+      },
+    });
+  }
+
   /**
    * Notify LSP server that a document was closed
    */
@@ -750,6 +783,36 @@ export class FrontendLSPManager {
     });
   }
 
+  /**
+   * Listen for a server notification outside the LSP spec
+   */
+  onNotification(
+    method: string,
+    listener: (params: any) => void,
+  ): monaco.IDisposable {
+    if (!this.transport) {
+      return { dispose: () => {} };
+    }
+    return this.transport.onNotification(method, listener);
+  }
+
+  /**
+   * Send a request for a method outside the LSP spec (e.g. a server plugin's)
+   */
+  async sendRequest<T = unknown>(method: string, params?: unknown): Promise<T> {
+    if (!this.client) {
+      throw new Error("LSP client not initialized");
+    }
+    return this.client.sendRequest(method, params) as Promise<T>;
+  }
+
+  /**
+   * Send a notification for a method outside the LSP spec
+   */
+  sendNotification(method: string, params?: unknown): void {
+    this.transport?.sendNotification(method, params);
+  }
+
   /**
    * Get the LSP client instance
    */
@@ -63,22 +63,10 @@ export class BrowserWindow implements IWindow {
 
   publishDiagnostics(uri: string, diagnostics: Diagnostic[]): void {
     console.log(`[LSP] publishDiagnostics for ${uri}, count: ${diagnostics.length}`);
-    let targetUri = uri;
-    let model = monaco.editor.getModel(monaco.Uri.parse(uri));
-
-    if (!model) {
-      const targetPath = monaco.Uri.parse(uri).path;
-      const found = monaco.editor
-        .getModels()
-        .find((m) => targetPath.endsWith(m.uri.path) || m.uri.path.endsWith(targetPath));
-      model = found ?? null;
-      if (model) {
-        targetUri = model.uri.toString();
-      }
-    }
-
-    this.diagnosticsMap.set(targetUri, diagnostics);
+    // The server maps URIs to the editor's model URIs, so they match exactly
+    this.diagnosticsMap.set(uri, diagnostics);
 
+    const model = monaco.editor.getModel(monaco.Uri.parse(uri));
     if (model) {
       const markers = diagnostics.map((d) => this.convertDiagnosticToMarker(d));
       monaco.editor.setModelMarkers(model, "lsp", markers);
@@ -102,7 +90,7 @@ export class BrowserWindow implements IWindow {
     const errors = diagnostics.filter((d) => d.severity === 1).length;
     const warnings = diagnostics.filter((d) => d.severity === 2).length;
     const details = diagnostics.map((d) => ({
-      uri: targetUri,
+      uri,
       message: d.message,
       severity: severityToLabel(d.severity),
       line: d.range.start.line + 1,
@@ -111,8 +99,8 @@ export class BrowserWindow implements IWindow {
       code: d.code?.toString(),
     }));
 
-    console.log(`[LSP] setDiagnostics: uri=${targetUri}, errors=${errors}, warnings=${warnings}, details=${details.length}`);
-    useEditorStore.getState().setDiagnostics(targetUri, { errors, warnings }, details);
+    console.log(`[LSP] setDiagnostics: uri=${uri}, errors=${errors}, warnings=${warnings}, details=${details.length}`);
+    useEditorStore.getState().setDiagnostics(uri, { errors, warnings }, details);
   }
 
   private convertDiagnosticToMarker(
@@ -161,26 +149,7 @@ export class BrowserWindow implements IWindow {
 
   reapplyDiagnostics(uri: string): void {
     console.log(`[LSP] reapplyDiagnostics for ${uri}`);
-    let diagnostics = this.diagnosticsMap.get(uri);
-
-    // If not found, try fuzzy matching
-    if (!diagnostics) {
-      const targetPath = monaco.Uri.parse(uri).path;
-      Array.from(this.diagnosticsMap.entries()).forEach(([key, value]) => {
-        if (diagnostics) return; // already found
-        const keyPath = monaco.Uri.parse(key).path;
-        // Check if paths match (ignoring scheme/host differences)
-        if (
-          keyPath === targetPath ||
-          (keyPath.length > 1 && targetPath.endsWith(keyPath)) ||
-          (targetPath.length > 1 && keyPath.endsWith(targetPath))
-        ) {
-          console.log(`[LSP] Found fuzzy match for ${uri} -> ${key}`);
-          diagnostics = value;
-        }
-      });
-    }
-
+    const diagnostics = this.diagnosticsMap.get(uri);
     if (diagnostics) {
       const model = monaco.editor.getModel(monaco.Uri.parse(uri));
       if (model) {
@@ -192,7 +161,7 @@ export class BrowserWindow implements IWindow {
 }
 
 export class BrowserWorkspace implements IWorkspace {
-  rootUri = "file:///workspace";
+  rootUri = "workspace:///";
 
   setRootUri(uri: string): void {
     this.rootUri = uri;
@@ -0,0 +1,166 @@
+import type * as Monaco from "monaco-editor";
+import { api } from "./api";
+import { detectLanguage } from "./languages";
+import type { FrontendLSPManager } from "./lsp/client";
+import { useEditorStore } from "./store";
+import { WORKSPACE_URI_SCHEME } from "./uris";
+import {
+  isVirtualDocumentUri,
+  openVirtualDocument,
+  VIRTUAL_DOCUMENT_SCHEME,
+} from "./virtualDocuments";
+
+/**
+ * Convert an editor model URI (workspace:///src/main.go) to a workspace path (/src/main.go)
+ */
+export const uriToWorkspacePath = (uri: string): string => {
+  try {
+    return decodeURIComponent(new URL(uri).pathname);
+  } catch {
+    return uri;
+  }
+};
+
+/**
+ * Open a workspace file in the editor (fetching it if it isn't open yet)
+ * and optionally reveal a 1-based line/column. deps: URIs open read-only.
+ */
+export async function openWorkspaceFile(
+  path: string,
+  line?: number,
+  column = 1,
+): Promise<boolean> {
+  const { editorManager, setCurrentFile, setCurrentLanguageId } =
+    useEditorStore.getState();
+  if (!editorManager) return false;
+  if (isVirtualDocumentUri(path)) {
+    return openVirtualDocument(path, line, column);
+  }
+
+  const languageId = detectLanguage(path).id;
+  const existing = editorManager.getModel(path);
+
+  if (existing) {
+    editorManager.openFile(path, existing.getValue(), languageId);
+  } else {
+    try {
+      const { content } = await api.readFile({ path });
+      editorManager.openFile(path, content, languageId);
+    } catch (error) {
+      console.error("Error loading file:", error);
+      return false;
+    }
+  }
+
+  setCurrentFile(path);
+  setCurrentLanguageId(languageId);
+
+  if (line !== undefined) {
+    editorManager.revealPosition(line, column);
+  }
+  return true;
+}
+
+/**
+ * Let go-to-definition and peek open workspace files and read-only
+ * documents that have no model until they are fetched
+ */
+export function registerWorkspaceOpener(
+  monaco: typeof Monaco,
+): Monaco.IDisposable {
+  return monaco.editor.registerEditorOpener({
+    openCodeEditor: (_source, resource, selectionOrPosition) => {
+      if (
+        resource.scheme !== WORKSPACE_URI_SCHEME &&
+        resource.scheme !== VIRTUAL_DOCUMENT_SCHEME
+      ) {
+        return false;
+      }
+      const position =
+        selectionOrPosition && "startLineNumber" in selectionOrPosition
+          ? {
+              lineNumber: selectionOrPosition.startLineNumber,
+              column: selectionOrPosition.startColumn,
+            }
+          : selectionOrPosition;
+      const uri = resource.toString();
+      void openWorkspaceFile(
+        isVirtualDocumentUri(uri) ? uri : uriToWorkspacePath(uri),
+        position?.lineNumber,
+        position?.column,
+      );
+      return true;
+    },
+  });
+}
+
+interface ShowDocumentParams {
+  uri: string;
+  external?: boolean;
+  takeFocus?: boolean;
+  // Zero-based, as in LSP
+  selection?: {
+    start: { line: number; character: number };
+    end: { line: number; character: number };
+  };
+}
+
+const EXTERNAL_SCHEMES = ["http:", "https:", "mailto:"];
+
+/**
+ * Open a URL in a new browser tab once the user agrees. Only web and mail
+ * links are opened.
+ */
+function openExternalUrl(uri: string): void {
+  let url: URL;
+  try {
+    url = new URL(uri);
+  } catch {
+    console.warn(`Can't open ${uri}`);
+    return;
+  }
+  if (!EXTERNAL_SCHEMES.includes(url.protocol)) {
+    console.warn(`Refusing to open ${uri}`);
+    return;
+  }
+  if (confirm(`The server asks to open ${url.href}\n\nOpen it in a new tab?`)) {
+    window.open(url.href, "_blank", "noopener,noreferrer");
+  }
+}
+
+/**
+ * Open documents and URLs that language servers and plugins ask for with
+ * window/showDocument
+ */
+export function watchShowDocument(
+  lspManager: FrontendLSPManager,
+): Monaco.IDisposable {
+  return lspManager.onNotification(
+    "window/showDocument",
+    async ({ uri, external, takeFocus, selection }: ShowDocumentParams) => {
+      const isEditorUri =
+        uri.startsWith(`${WORKSPACE_URI_SCHEME}:`) || isVirtualDocumentUri(uri);
+      if (external || !isEditorUri) {
+        openExternalUrl(uri);
+        return;
+      }
+
+      const opened = await openWorkspaceFile(
+        isVirtualDocumentUri(uri) ? uri : uriToWorkspacePath(uri),
+      );
+      const { editorManager } = useEditorStore.getState();
+      if (!opened || !editorManager) return;
+      if (selection) {
+        editorManager.revealRange(
+          selection.start.line + 1,
+          selection.start.character + 1,
+          selection.end.line + 1,
+          selection.end.character + 1,
+          !!takeFocus,
+        );
+      } else if (takeFocus) {
+        editorManager.focus();
+      }
+    },
+  );
+}
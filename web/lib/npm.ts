@@ -0,0 +1,53 @@
+import { api, describeApiError, type TaskInfo } from "./api";
+import { useEditorStore } from "./store";
+import { trackTask } from "./tasks";
+
+type NpmPackageCommand = "install" | "update";
+
+/**
+ * Load the workspace's package.json files into the store
+ */
+export async function fetchNpmPackages(): Promise<void> {
+  try {
+    useEditorStore.getState().setNpmPackages(await api.listNpmPackages());
+  } catch (error) {
+    console.error("Error fetching npm packages:", error);
+  }
+}
+
+/**
+ * Start a package.json script on the server
+ */
+export async function runNpmScript(
+  path: string,
+  script: string,
+): Promise<TaskInfo | undefined> {
+  try {
+    const task = await api.runNpmScript({ path, script });
+    await trackTask(task);
+    return task;
+  } catch (error) {
+    console.error("Error running npm script:", error);
+    alert(`Failed to run ${script}: ${describeApiError(error)}`);
+    return undefined;
+  }
+}
+
+/**
+ * Start an install, or an update of all or one dependency, on the server
+ */
+export async function runNpmCommand(
+  path: string,
+  command: NpmPackageCommand,
+  dependency?: string,
+): Promise<TaskInfo | undefined> {
+  try {
+    const task = await api.runNpmCommand({ path, command, dependency });
+    await trackTask(task);
+    return task;
+  } catch (error) {
+    console.error("Error running package manager:", error);
+    alert(`Failed to run ${command}: ${describeApiError(error)}`);
+    return undefined;
+  }
+}
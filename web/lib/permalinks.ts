@@ -0,0 +1,154 @@
+import type * as Monaco from "monaco-editor";
+import { announce } from "./a11y";
+import { openWorkspaceFile, uriToWorkspacePath } from "./navigation";
+import { useEditorStore } from "./store";
+import { WORKSPACE_URI_SCHEME } from "./uris";
+
+// Links open the app with ?file=/src/main.go#L10-L20 or ?snapshot=<id>#L10
+const FILE_PARAM = "file";
+const SNAPSHOT_PARAM = "snapshot";
+const LINE_RANGE_PATTERN = /^#?L(\d+)(?:-L?(\d+))?$/;
+
+/**
+ * 1-based, inclusive line range
+ */
+export interface LineRange {
+  startLine: number;
+  endLine: number;
+}
+
+/**
+ * URL hash of a line range: "#L10" or "#L10-L20"
+ */
+export function formatLineRange({ startLine, endLine }: LineRange): string {
+  return endLine > startLine ? `#L${startLine}-L${endLine}` : `#L${startLine}`;
+}
+
+/**
+ * Parse a "#L10" or "#L10-L20" URL hash
+ */
+export function parseLineRange(hash: string): LineRange | undefined {
+  const match = LINE_RANGE_PATTERN.exec(hash);
+  if (!match) return undefined;
+  const first = Number(match[1]);
+  const second = match[2] ? Number(match[2]) : first;
+  return {
+    startLine: Math.min(first, second),
+    endLine: Math.max(first, second),
+  };
+}
+
+function getAppUrl(param: string, value: string, range?: LineRange): string {
+  const url = new URL(window.location.pathname, window.location.origin);
+  url.searchParams.set(param, value);
+  return `${url.toString()}${range ? formatLineRange(range) : ""}`;
+}
+
+/**
+ * Link that opens a workspace file in the editor, optionally selecting lines
+ */
+export function getFileLink(path: string, range?: LineRange): string {
+  return getAppUrl(FILE_PARAM, path, range);
+}
+
+/**
+ * Link to the read-only viewer of a snapshot
+ */
+export function getSnapshotLink(id: string, range?: LineRange): string {
+  return getAppUrl(SNAPSHOT_PARAM, id, range);
+}
+
+export type OpenedLink =
+  | { file: string; range?: LineRange }
+  | { snapshot: string; range?: LineRange };
+
+/**
+ * What the page was opened with: a file link, a snapshot link or neither
+ */
+export function getOpenedLink(): OpenedLink | undefined {
+  if (typeof window === "undefined") return undefined;
+  const params = new URLSearchParams(window.location.search);
+  const range = parseLineRange(window.location.hash);
+  const snapshot = params.get(SNAPSHOT_PARAM);
+  if (snapshot) return { snapshot, range };
+  const file = params.get(FILE_PARAM);
+  if (file) return { file, range };
+  return undefined;
+}
+
+/**
+ * Lines of the selection, or the cursor line
+ */
+export function getSelectedLines(
+  editor: Monaco.editor.ICodeEditor,
+): LineRange | undefined {
+  const selection = editor.getSelection();
+  if (!selection) return undefined;
+  let endLine = selection.endLineNumber;
+  // A selection ending at the start of a line doesn't include that line
+  if (endLine > selection.startLineNumber && selection.endColumn === 1) {
+    endLine--;
+  }
+  return { startLine: selection.startLineNumber, endLine };
+}
+
+/**
+ * Copy text to the clipboard, announcing the outcome
+ */
+export async function copyToClipboard(
+  text: string,
+  description: string,
+): Promise<boolean> {
+  try {
+    await navigator.clipboard.writeText(text);
+    announce(`${description} copied to the clipboard`);
+    return true;
+  } catch (error) {
+    console.error("Failed to copy to the clipboard:", error);
+    window.prompt(`Copy the ${description.toLowerCase()}:`, text);
+    return false;
+  }
+}
+
+/**
+ * Copy a link to the selected lines of the workspace file in the editor
+ */
+export async function copyLinkToSelection(
+  editor: Monaco.editor.ICodeEditor,
+): Promise<string | undefined> {
+  const model = editor.getModel();
+  const range = getSelectedLines(editor);
+  if (!model || !range) return undefined;
+  if (model.uri.scheme !== WORKSPACE_URI_SCHEME) {
+    announce("Only workspace files can be linked to");
+    return undefined;
+  }
+  const link = getFileLink(uriToWorkspacePath(model.uri.toString()), range);
+  await copyToClipboard(link, "Link");
+  return link;
+}
+
+/**
+ * Open the file a link points at once the editor is ready, selecting its lines
+ */
+export async function openLinkedFile(): Promise<boolean> {
+  const link = getOpenedLink();
+  if (!link || !("file" in link)) return false;
+  const { file, range } = link;
+  if (!(await openWorkspaceFile(file, range?.startLine))) {
+    announce(`Linked file not found: ${file}`);
+    return false;
+  }
+  const { editorManager } = useEditorStore.getState();
+  const model = editorManager?.getCurrentModel();
+  if (range && model) {
+    const endLine = Math.min(range.endLine, model.getLineCount());
+    editorManager!.revealRange(
+      range.startLine,
+      1,
+      endLine,
+      model.getLineMaxColumn(endLine),
+    );
+  }
+  return true;
+}
@@ -0,0 +1,47 @@
+import type * as Monaco from "monaco-editor";
+import { api, getApiToken, resolveServerUrl, type ListeningPort } from "./api";
+import type { FrontendLSPManager } from "./lsp/client";
+import { useEditorStore } from "./store";
+
+/**
+ * Load the ports workspace processes listen on into the store
+ */
+export async function fetchPorts(refresh = false): Promise<void> {
+  try {
+    useEditorStore.getState().setPorts(await api.listPorts({ refresh }));
+  } catch (error) {
+    console.error("Error fetching ports:", error);
+  }
+}
+
+/**
+ * Follow ports opening and closing as dev servers start and stop
+ */
+export function watchPorts(lspManager: FrontendLSPManager): Monaco.IDisposable {
+  return lspManager.onNotification(
+    "workspace/portsChanged",
+    ({ ports }: { ports: ListeningPort[] }) => {
+      useEditorStore.getState().setPorts(ports);
+    },
+  );
+}
+
+/**
+ * URL of a port behind the server's preview proxy. The API token, if any,
+ * is passed once in the URL; the proxy swaps it for a cookie.
+ */
+export function getPreviewUrl(port: number, path = "/"): string {
+  const url = resolveServerUrl(
+    `/preview/${port}/${path.replace(/^\/+/, "")}`,
+  );
+  const token = getApiToken();
+  if (!token) return url;
+  return `${url}${url.includes("?") ? "&" : "?"}token=${encodeURIComponent(token)}`;
+}
+
+/**
+ * Show a port in the preview pane
+ */
+export function openPreview(port: number): void {
+  useEditorStore.getState().setPreviewPort(port);
+}
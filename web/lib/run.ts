@@ -0,0 +1,312 @@
+import type * as Monaco from "monaco-editor";
+import { create } from "zustand";
+import { announce } from "./a11y";
+import { api, describeApiError, type TaskInfo } from "./api";
+import type { FrontendLSPManager } from "./lsp/client";
+import { openWorkspaceFile, uriToWorkspacePath } from "./navigation";
+import { type DiagnosticItem, useEditorStore } from "./store";
+import { trackTask } from "./tasks";
+import { isUntitledUri, WORKSPACE_URI_SCHEME } from "./uris";
+
+// Marker owner and Problems source for errors found in a run's output
+const DIAGNOSTICS_OWNER = "run";
+
+// localStorage key of the arguments and environment per file
+const CONFIGURATIONS_STORAGE_KEY = "oneline-editor.runConfigurations";
+
+// Files the server knows how to run: go run, node or tsx
+const RUNNABLE_PATTERN = /\.(go|[cm]?js|[cm]?ts|tsx)$/i;
+const RUNNABLE_LANGUAGES = new Set(["go", "javascript", "typescript"]);
+
+/**
+ * A source location the server found in a run's output
+ */
+export interface RunProblem {
+  uri: string;
+  line: number;
+  column: number;
+  // Only set for errors
+  message?: string;
+  outputLine: number;
+}
+
+export interface RunConfiguration {
+  args: string[];
+  env: Record<string, string>;
+}
+
+/**
+ * The latest run and the locations found in its output once it finished
+ */
+interface RunState {
+  taskId: string | null;
+  uri: string | null;
+  problems: RunProblem[];
+  setRun: (taskId: string, uri: string) => void;
+  setProblems: (problems: RunProblem[]) => void;
+}
+
+export const useRunStore = create<RunState>((set) => ({
+  taskId: null,
+  uri: null,
+  problems: [],
+  setRun: (taskId, uri) => set({ taskId, uri, problems: [] }),
+  setProblems: (problems) => set({ problems }),
+}));
+
+/**
+ * Whether a document can be run, by its URI or, for untitled buffers, its language
+ */
+export function isRunnable(uri: string, languageId?: string): boolean {
+  if (isUntitledUri(uri)) {
+    return languageId !== undefined && RUNNABLE_LANGUAGES.has(languageId);
+  }
+  return RUNNABLE_PATTERN.test(uri);
+}
+
+function loadConfigurations(): Record<string, RunConfiguration> {
+  if (typeof window === "undefined") return {};
+  try {
+    return JSON.parse(
+      window.localStorage.getItem(CONFIGURATIONS_STORAGE_KEY) ?? "{}",
+    );
+  } catch {
+    return {};
+  }
+}
+
+export function getRunConfiguration(uri: string): RunConfiguration {
+  return loadConfigurations()[uri] ?? { args: [], env: {} };
+}
+
+export function saveRunConfiguration(
+  uri: string,
+  configuration: RunConfiguration,
+): void {
+  if (typeof window === "undefined") return;
+  const configurations = loadConfigurations();
+  if (
+    configuration.args.length === 0 &&
+    Object.keys(configuration.env).length === 0
+  ) {
+    delete configurations[uri];
+  } else {
+    configurations[uri] = configuration;
+  }
+  window.localStorage.setItem(
+    CONFIGURATIONS_STORAGE_KEY,
+    JSON.stringify(configurations),
+  );
+}
+
+/**
+ * Split a command line into arguments, honouring single and double quotes
+ */
+export function splitArgs(input: string): string[] {
+  const args: string[] = [];
+  const pattern = /"((?:\\.|[^"\\])*)"|'([^']*)'|(\S+)/g;
+  for (const match of input.matchAll(pattern)) {
+    args.push(
+      match[1] !== undefined
+        ? match[1].replace(/\\(.)/g, "$1")
+        : (match[2] ?? match[3]),
+    );
+  }
+  return args;
+}
+
+const quoteArg = (arg: string): string =>
+  /^[^\s"'\\]+$/.test(arg) ? arg : `"${arg.replace(/(["\\])/g, "\\$1")}"`;
+
+/**
+ * Ask for the arguments and environment variables a file runs with. They
+ * are remembered per file in this browser.
+ */
+export function configureRun(uri: string): RunConfiguration | null {
+  const current = getRunConfiguration(uri);
+  const args = prompt(
+    "Program arguments:",
+    current.args.map(quoteArg).join(" "),
+  );
+  if (args === null) return null;
+  const env = prompt(
+    "Environment variables (NAME=value, separated by spaces):",
+    Object.entries(current.env)
+      .map(([name, value]) => `${name}=${quoteArg(value)}`)
+      .join(" "),
+  );
+  if (env === null) return null;
+
+  const configuration: RunConfiguration = { args: splitArgs(args), env: {} };
+  for (const entry of splitArgs(env)) {
+    const separator = entry.indexOf("=");
+    if (separator <= 0) {
+      alert(`Ignoring "${entry}": expected NAME=value`);
+      continue;
+    }
+    configuration.env[entry.slice(0, separator)] = entry.slice(separator + 1);
+  }
+  saveRunConfiguration(uri, configuration);
+  return configuration;
+}
+
+/**
+ * Run a Go, JavaScript or TypeScript document on the server and show its
+ * output. Unsaved edits are included: open documents are kept in sync on disk.
+ */
+export async function runFile(uri: string): Promise<TaskInfo | undefined> {
+  const { setOutputOpen } = useEditorStore.getState();
+  const { args, env } = getRunConfiguration(uri);
+  let task: TaskInfo;
+  try {
+    task = await api.runFile({ uri, args, env });
+  } catch (error) {
+    console.error("Error running file:", error);
+    alert(`Failed to run: ${describeApiError(error)}`);
+    return undefined;
+  }
+
+  await applyProblems([]);
+  useRunStore.getState().setRun(task.id, uri);
+  setOutputOpen(true);
+  announce(`${task.label} started`);
+  await trackTask(task);
+  return task;
+}
+
+/**
+ * Run the document in the editor
+ */
+export async function runCurrentFile(): Promise<TaskInfo | undefined> {
+  const model = useEditorStore.getState().editorManager?.getCurrentModel();
+  if (!model) return undefined;
+  const uri = model.uri.toString();
+  if (!isRunnable(uri, model.getLanguageId())) {
+    announce("Only Go, JavaScript and TypeScript files can be run");
+    return undefined;
+  }
+  return runFile(uri);
+}
+
+// URIs the previous run reported errors for, cleared by the next run
+let problemUris: string[] = [];
+let modelListener: Monaco.IDisposable | undefined;
+
+function setMarkers(
+  monaco: typeof Monaco,
+  model: Monaco.editor.ITextModel,
+  problems: RunProblem[],
+): void {
+  monaco.editor.setModelMarkers(
+    model,
+    DIAGNOSTICS_OWNER,
+    problems.map((problem) => ({
+      severity: monaco.MarkerSeverity.Error,
+      startLineNumber: problem.line,
+      startColumn: problem.column,
+      endLineNumber: problem.line,
+      endColumn: model.getLineMaxColumn(
+        Math.min(problem.line, model.getLineCount()),
+      ),
+      message: problem.message!,
+      source: DIAGNOSTICS_OWNER,
+    })),
+  );
+}
+
+/**
+ * Show the errors of a run in the Problems panel and the editor, replacing
+ * those of the previous run
+ */
+async function applyProblems(problems: RunProblem[]): Promise<void> {
+  // Loaded lazily so this module can be imported during server rendering
+  const monaco = await import("monaco-editor");
+  const { setDiagnostics } = useEditorStore.getState();
+  const errorsByUri = new Map<string, RunProblem[]>();
+  for (const problem of problems) {
+    if (!problem.message) continue;
+    errorsByUri.set(problem.uri, [
+      ...(errorsByUri.get(problem.uri) ?? []),
+      problem,
+    ]);
+  }
+
+  for (const uri of new Set([...problemUris, ...errorsByUri.keys()])) {
+    const errors = errorsByUri.get(uri) ?? [];
+    const items: DiagnosticItem[] = errors.map((problem) => ({
+      uri,
+      message: problem.message!,
+      severity: "error",
+      line: problem.line,
+      column: problem.column,
+      source: DIAGNOSTICS_OWNER,
+    }));
+    setDiagnostics(
+      uri,
+      { errors: items.length, warnings: 0 },
+      items,
+      DIAGNOSTICS_OWNER,
+    );
+    const model = monaco.editor.getModel(monaco.Uri.parse(uri));
+    if (model) {
+      setMarkers(monaco, model, errors);
+    }
+  }
+  problemUris = [...errorsByUri.keys()];
+
+  // Files opened later get their markers when the model is created
+  modelListener ??= monaco.editor.onDidCreateModel((created) => {
+    const errors = useRunStore
+      .getState()
+      .problems.filter(
+        (problem) =>
+          problem.message && problem.uri === created.uri.toString(),
+      );
+    if (errors.length > 0) {
+      setMarkers(monaco, created, errors);
+    }
+  });
+}
+
+/**
+ * Follow the locations the server finds in the output of finished runs
+ */
+export function watchRunProblems(
+  lspManager: FrontendLSPManager,
+): Monaco.IDisposable {
+  return lspManager.onNotification(
+    "workspace/runProblems",
+    ({ taskId, problems }) => {
+      const { taskId: latest, setProblems } = useRunStore.getState();
+      if (taskId !== latest) return;
+      setProblems(problems);
+      void applyProblems(problems);
+    },
+  );
+}
+
+/**
+ * Reveal a location from a run's output in the editor
+ */
+export async function openRunProblem(problem: RunProblem): Promise<void> {
+  if (!isUntitledUri(problem.uri)) {
+    await openWorkspaceFile(
+      problem.uri.startsWith(`${WORKSPACE_URI_SCHEME}:`)
+        ? uriToWorkspacePath(problem.uri)
+        : problem.uri,
+      problem.line,
+      problem.column,
+    );
+    return;
+  }
+
+  // Untitled buffers only exist in the editor
+  const { editorManager, setCurrentFile, setCurrentLanguageId } =
+    useEditorStore.getState();
+  const model = editorManager?.getModel(problem.uri);
+  if (!editorManager || !model) return;
+  editorManager.openFile(problem.uri, model.getValue(), model.getLanguageId());
+  setCurrentFile(problem.uri);
+  setCurrentLanguageId(model.getLanguageId());
+  editorManager.revealPosition(problem.line, problem.column);
+}
@@ -0,0 +1,92 @@
+import * as monaco from "monaco-editor";
+import {
+  api,
+  describeApiError,
+  type SchemaIndex,
+  type SchemaMapping,
+} from "./api";
+
+// Workspace paths of the schema files currently loaded into Monaco
+let workspaceSchemaPaths = new Set<string>();
+
+/**
+ * Fetch every schema from the server registry and hand them to Monaco's
+ * JSON worker, which provides diagnostics, completion and hover from them.
+ * Schemas are never fetched from the network, so this works offline.
+ */
+export async function loadSchemas(): Promise<SchemaIndex | undefined> {
+  try {
+    const index = await api.listSchemas();
+    const schemas = await Promise.all(
+      index.schemas.map(async (info) => ({
+        uri: `oneline-schema://${info.source}/${encodeURIComponent(info.id)}`,
+        fileMatch: info.fileMatch,
+        schema: await api.getSchema({ id: info.id }),
+      })),
+    );
+
+    monaco.languages.json.jsonDefaults.setDiagnosticsOptions({
+      validate: true,
+      allowComments: true,
+      enableSchemaRequest: false,
+      schemas,
+    });
+    workspaceSchemaPaths = new Set(
+      index.schemas
+        .filter((info) => info.source === "workspace")
+        .map((info) => info.id),
+    );
+    return index;
+  } catch (error) {
+    console.error("Error loading JSON schemas:", error);
+    return undefined;
+  }
+}
+
+/**
+ * Whether a workspace file is one of the loaded schemas, so editing it
+ * should reload them
+ */
+export function isWorkspaceSchema(path: string): boolean {
+  return workspaceSchemaPaths.has(path);
+}
+
+/**
+ * Replace the workspace's schema mappings and reload the schemas
+ */
+export async function updateSchemaMappings(
+  mappings: SchemaMapping[],
+): Promise<void> {
+  try {
+    await api.setSchemaMappings({ mappings });
+    await loadSchemas();
+  } catch (error) {
+    console.error("Error updating schema mappings:", error);
+    alert(`Failed to update schema mappings: ${describeApiError(error)}`);
+  }
+}
+
+/**
+ * Associate a workspace file with a schema, given a built-in schema ID or
+ * the workspace path of a schema file
+ */
+export async function associateSchema(
+  filePath: string,
+  schema: string,
+): Promise<void> {
+  const { mappings } = await api.listSchemas();
+  const fileMatch = filePath.replace(/^\/+/, "");
+  const others = mappings
+    .map((mapping) => ({
+      ...mapping,
+      fileMatch: mapping.fileMatch.filter((pattern) => pattern !== fileMatch),
+    }))
+    .filter((mapping) => mapping.fileMatch.length > 0);
+  const existing = others.find((mapping) => mapping.schema === schema);
+  if (existing) {
+    existing.fileMatch.push(fileMatch);
+  } else {
+    others.push({ fileMatch: [fileMatch], schema });
+  }
+  await updateSchemaMappings(others);
+}
@@ -0,0 +1,241 @@
+import type * as Monaco from "monaco-editor";
+import { announce } from "./a11y";
+import {
+  api,
+  describeApiError,
+  type SnapshotHover,
+  type SnapshotSummary,
+} from "./api";
+import { uriToWorkspacePath } from "./navigation";
+import {
+  copyToClipboard,
+  getSelectedLines,
+  getSnapshotLink,
+  type LineRange,
+} from "./permalinks";
+import { useEditorStore } from "./store";
+import { WORKSPACE_URI_SCHEME } from "./uris";
+
+// Model URIs of snapshot files in the viewer: snapshot:///<id>/src/main.go
+export const SNAPSHOT_URI_SCHEME = "snapshot";
+
+// Hovers are captured by asking the language server about every identifier;
+// keep that bounded for big files
+const MAX_HOVER_REQUESTS = 2000;
+const HOVER_CONCURRENCY = 8;
+const MAX_HOVER_LENGTH = 4000;
+const IDENTIFIER_PATTERN = /[A-Za-z_$][\w$]*/g;
+
+const DEFAULT_EXPIRY_DAYS = 7;
+
+/**
+ * Editor model URI of a file in a snapshot
+ */
+export function getSnapshotUri(id: string, path: string): string {
+  return `${SNAPSHOT_URI_SCHEME}:///${id}${path}`;
+}
+
+/**
+ * Markdown of an LSP hover result
+ */
+function hoverToMarkdown(contents: unknown): string {
+  const parts = Array.isArray(contents) ? contents : [contents];
+  return parts
+    .map((part: any) => {
+      if (typeof part === "string") return part;
+      if (part?.language) {
+        return `\`\`\`${part.language}\n${part.value}\n\`\`\``;
+      }
+      return part?.value ?? "";
+    })
+    .filter(Boolean)
+    .join("\n\n");
+}
+
+/**
+ * Ask the language server for the hover of every identifier in a document,
+ * so the snapshot viewer can show them without one
+ */
+export async function collectHovers(
+  model: Monaco.editor.ITextModel,
+): Promise<SnapshotHover[]> {
+  const { lspManager } = useEditorStore.getState();
+  const uri = model.uri.toString();
+  if (!lspManager?.isRunning() || !lspManager.isDocumentOpen(uri)) return [];
+
+  const positions: Array<{ line: number; column: number; end: number }> = [];
+  for (let line = 1; line <= model.getLineCount(); line++) {
+    const text = model.getLineContent(line);
+    for (const match of text.matchAll(IDENTIFIER_PATTERN)) {
+      if (positions.length >= MAX_HOVER_REQUESTS) break;
+      const column = match.index! + 1;
+      positions.push({ line, column, end: column + match[0].length });
+    }
+  }
+
+  const hovers: SnapshotHover[] = [];
+  const seen = new Set<string>();
+  let next = 0;
+  const worker = async () => {
+    while (next < positions.length) {
+      const { line, column, end } = positions[next++];
+      try {
+        const result: any = await lspManager.requestHover(
+          uri,
+          line - 1,
+          column - 1,
+        );
+        const contents = result?.contents
+          ? hoverToMarkdown(result.contents).slice(0, MAX_HOVER_LENGTH)
+          : "";
+        if (!contents) continue;
+        // Prefer the range the server reports when it stays on one line
+        const range = result.range;
+        const hover: SnapshotHover =
+          range && range.start.line === range.end.line
+            ? {
+                line: range.start.line + 1,
+                startColumn: range.start.character + 1,
+                endColumn: range.end.character + 1,
+                contents,
+              }
+            : { line, startColumn: column, endColumn: end, contents };
+        const key = `${hover.line}:${hover.startColumn}:${hover.endColumn}`;
+        if (!seen.has(key)) {
+          seen.add(key);
+          hovers.push(hover);
+        }
+      } catch {
+        // No hover here
+      }
+    }
+  };
+  await Promise.all(Array.from({ length: HOVER_CONCURRENCY }, worker));
+  return hovers.sort(
+    (a, b) => a.line - b.line || a.startColumn - b.startColumn,
+  );
+}
+
+/**
+ * Ask how long a snapshot should live; undefined when cancelled
+ */
+function promptExpiry(): number | undefined {
+  const answer = window.prompt(
+    "Expire the snapshot after how many days? (0 = never)",
+    String(DEFAULT_EXPIRY_DAYS),
+  );
+  if (answer === null) return undefined;
+  const days = Number(answer.trim() || DEFAULT_EXPIRY_DAYS);
+  if (!Number.isInteger(days) || days < 0 || days > 365) {
+    alert("Enter a whole number of days between 0 and 365");
+    return undefined;
+  }
+  return days;
+}
+
+/**
+ * Store a read-only copy of workspace files on the server and copy a link
+ * to it. Hovers are captured from the files' language servers first. The
+ * range selects lines of the first file in the viewer.
+ */
+export async function shareSnapshot(
+  paths: string[],
+  range?: LineRange,
+): Promise<SnapshotSummary | undefined> {
+  const expiresInDays = promptExpiry();
+  if (expiresInDays === undefined) return undefined;
+
+  const { editorManager } = useEditorStore.getState();
+  announce("Capturing hovers for the snapshot");
+  const files = await Promise.all(
+    paths.map(async (path) => {
+      const model = editorManager?.getModel(path);
+      return { path, hovers: model ? await collectHovers(model) : [] };
+    }),
+  );
+
+  let snapshot: SnapshotSummary;
+  try {
+    snapshot = await api.createSnapshot({ files, expiresInDays });
+  } catch (error) {
+    console.error("Error sharing snapshot:", error);
+    alert(`Failed to share snapshot: ${describeApiError(error)}`);
+    return undefined;
+  }
+  await copyToClipboard(
+    getSnapshotLink(snapshot.id, range),
+    "Snapshot link",
+  );
+  void fetchSnapshots();
+  return snapshot;
+}
+
+/**
+ * Share the file in the editor, keeping the selected lines in the link
+ */
+export async function shareCurrentFile(
+  editor: Monaco.editor.ICodeEditor,
+): Promise<SnapshotSummary | undefined> {
+  const model = editor.getModel();
+  if (!model) return undefined;
+  if (model.uri.scheme !== WORKSPACE_URI_SCHEME) {
+    announce("Only workspace files can be shared");
+    return undefined;
+  }
+  const range = getSelectedLines(editor);
+  const hasSelection = editor.getSelection()?.isEmpty() === false;
+  return shareSnapshot(
+    [uriToWorkspacePath(model.uri.toString())],
+    hasSelection ? range : undefined,
+  );
+}
+
+/**
+ * Share every workspace file open in the editor, the current one first
+ */
+export async function shareOpenFiles(): Promise<
+  SnapshotSummary | undefined
+> {
+  const { editorManager } = useEditorStore.getState();
+  if (!editorManager) return undefined;
+  const current = editorManager.getCurrentUri();
+  const uris = editorManager
+    .getOpenFiles()
+    .filter((uri) => uri.startsWith(`${WORKSPACE_URI_SCHEME}:`))
+    .sort((a, b) => Number(b === current) - Number(a === current));
+  if (uris.length === 0) {
+    announce("No workspace files are open");
+    return undefined;
+  }
+  return shareSnapshot(uris.map(uriToWorkspacePath));
+}
+
+/**
+ * Load the snapshots the user can revoke into the store
+ */
+export async function fetchSnapshots(): Promise<void> {
+  try {
+    useEditorStore.getState().setSnapshots(await api.listSnapshots());
+  } catch (error) {
+    console.error("Error fetching snapshots:", error);
+  }
+}
+
+/**
+ * Delete a snapshot so its link stops working
+ */
+export async function revokeSnapshot(id: string): Promise<boolean> {
+  if (!window.confirm("Revoke this snapshot? Its link will stop working.")) {
+    return false;
+  }
+  try {
+    await api.revokeSnapshot(id);
+  } catch (error) {
+    console.error("Error revoking snapshot:", error);
+    alert(`Failed to revoke snapshot: ${describeApiError(error)}`);
+    return false;
+  }
+  announce("Snapshot revoked");
+  await fetchSnapshots();
+  return true;
+}
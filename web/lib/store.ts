@@ -1,4 +1,14 @@
 import { create } from "zustand";
+import type {
+  Bookmark,
+  CoverageSummary,
+  GoModule,
+  ListeningPort,
+  NpmPackage,
+  SnapshotSummary,
+  TodoItem,
+} from "./api";
+import { saveScreenReaderMode } from "./a11y";
 import { EditorManager } from "./editor/manager";
 import { FrontendLSPManager } from "./lsp/client";
 import {
@@ -26,23 +36,68 @@ export interface DiagnosticItem {
   code?: string;
 }
 
+export type {
+  Bookmark,
+  CoverageSummary,
+  GoModule,
+  ListeningPort,
+  NpmPackage,
+  SnapshotSummary,
+  TodoItem,
+};
+
 interface EditorState {
   editorManager: EditorManager | null;
   lspManager: FrontendLSPManager | null;
   currentFile: string | null;
   currentLanguageId: string | null;
   files: string[];
+  // Bumped when files change outside the Explorer, e.g. Save As
+  fileTreeVersion: number;
   isConnected: boolean;
   themeMode: ThemeMode;
   resolvedTheme: ResolvedTheme;
+  // Monaco's screen reader optimizations, on top of its own detection
+  isScreenReaderMode: boolean;
   diagnosticsByUri: Record<string, DiagnosticsSummary>;
   diagnosticItemsByUri: Record<string, DiagnosticItem[]>;
+  // Diagnostics per URI and producer (e.g. "lsp", "go-modules"); the two
+  // maps above are merged from these
+  diagnosticSources: Record<
+    string,
+    Record<string, { summary: DiagnosticsSummary; items: DiagnosticItem[] }>
+  >;
   isProblemsOpen: boolean;
+  isNewProjectOpen: boolean;
+  bookmarks: Bookmark[];
+  isBookmarksOpen: boolean;
+  isCommentsOpen: boolean;
+  todos: TodoItem[];
+  todoTags: string[];
+  isTodoOpen: boolean;
+  isAuditLogOpen: boolean;
+  goModules: GoModule[];
+  isGoModulesOpen: boolean;
+  npmPackages: NpmPackage[];
+  isNpmOpen: boolean;
+  isOutputOpen: boolean;
+  isHttpOpen: boolean;
+  snapshots: SnapshotSummary[];
+  isSnapshotsOpen: boolean;
+  ports: ListeningPort[];
+  isPortsOpen: boolean;
+  // Port shown in the preview pane, if it is open
+  previewPort: number | null;
+  // Bumped on save so the preview pane reloads
+  previewReloadVersion: number;
+  coverage: CoverageSummary | null;
+  isCoverageVisible: boolean;
   setEditorManager: (manager: EditorManager) => void;
   setLSPManager: (manager: FrontendLSPManager) => void;
   setCurrentFile: (file: string | null) => void;
   setCurrentLanguageId: (languageId: string | null) => void;
   setFiles: (files: string[]) => void;
+  refreshFileTree: () => void;
   setIsConnected: (connected: boolean) => void;
   setThemeMode: (mode: ThemeMode) => void;
   setResolvedTheme: (theme: ResolvedTheme) => void;
@@ -50,8 +105,31 @@ interface EditorState {
     uri: string,
     summary: DiagnosticsSummary,
     items?: DiagnosticItem[],
+    owner?: string,
   ) => void;
   setProblemsOpen: (open: boolean) => void;
+  setNewProjectOpen: (open: boolean) => void;
+  setBookmarks: (bookmarks: Bookmark[]) => void;
+  setBookmarksOpen: (open: boolean) => void;
+  setCommentsOpen: (open: boolean) => void;
+  setTodos: (todos: TodoItem[], tags: string[]) => void;
+  setTodoOpen: (open: boolean) => void;
+  setAuditLogOpen: (open: boolean) => void;
+  setGoModules: (modules: GoModule[]) => void;
+  setGoModulesOpen: (open: boolean) => void;
+  setNpmPackages: (packages: NpmPackage[]) => void;
+  setNpmOpen: (open: boolean) => void;
+  setOutputOpen: (open: boolean) => void;
+  setHttpOpen: (open: boolean) => void;
+  setSnapshots: (snapshots: SnapshotSummary[]) => void;
+  setSnapshotsOpen: (open: boolean) => void;
+  setPorts: (ports: ListeningPort[]) => void;
+  setPortsOpen: (open: boolean) => void;
+  setPreviewPort: (port: number | null) => void;
+  reloadPreview: () => void;
+  setCoverage: (coverage: CoverageSummary | null) => void;
+  setCoverageVisible: (visible: boolean) => void;
+  setScreenReaderMode: (enabled: boolean) => void;
 }
 
 export const useEditorStore = create<EditorState>((set, get) => ({
@@ -60,29 +138,68 @@ export const useEditorStore = create<EditorState>((set, get) => ({
   currentFile: null,
   currentLanguageId: null,
   files: [],
+  fileTreeVersion: 0,
   isConnected: false,
   themeMode: "auto",
   resolvedTheme: "light",
+  isScreenReaderMode: false,
   diagnosticsByUri: {},
   diagnosticItemsByUri: {},
+  diagnosticSources: {},
   isProblemsOpen: false,
+  isNewProjectOpen: false,
+  bookmarks: [],
+  isBookmarksOpen: false,
+  isCommentsOpen: false,
+  todos: [],
+  todoTags: [],
+  isTodoOpen: false,
+  isAuditLogOpen: false,
+  goModules: [],
+  isGoModulesOpen: false,
+  npmPackages: [],
+  isNpmOpen: false,
+  isOutputOpen: false,
+  isHttpOpen: false,
+  snapshots: [],
+  isSnapshotsOpen: false,
+  ports: [],
+  isPortsOpen: false,
+  previewPort: null,
+  previewReloadVersion: 0,
+  coverage: null,
+  isCoverageVisible: false,
   setEditorManager: (manager) => set({ editorManager: manager }),
   setLSPManager: (manager) => set({ lspManager: manager }),
   setCurrentFile: (file) => set({ currentFile: file }),
   setCurrentLanguageId: (languageId) => set({ currentLanguageId: languageId }),
   setFiles: (files) => set({ files }),
+  refreshFileTree: () =>
+    set((state) => ({ fileTreeVersion: state.fileTreeVersion + 1 })),
   setIsConnected: (connected) => set({ isConnected: connected }),
-  setDiagnostics: (uri, summary, items = []) => {
-    console.log(`[Store] setDiagnostics called: uri=${uri}, errors=${summary.errors}, warnings=${summary.warnings}, items=${items.length}`);
+  setDiagnostics: (uri, summary, items = [], owner = "lsp") => {
+    console.log(`[Store] setDiagnostics called: uri=${uri}, owner=${owner}, errors=${summary.errors}, warnings=${summary.warnings}, items=${items.length}`);
     set((state) => {
+      const sources = {
+        ...state.diagnosticSources[uri],
+        [owner]: { summary, items },
+      };
+      const merged = Object.values(sources);
       const newState = {
+        diagnosticSources: {
+          ...state.diagnosticSources,
+          [uri]: sources,
+        },
         diagnosticsByUri: {
           ...state.diagnosticsByUri,
-          [uri]: summary,
+          [uri]: {
+            errors: merged.reduce((acc, s) => acc + s.summary.errors, 0),
+            warnings: merged.reduce((acc, s) => acc + s.summary.warnings, 0),
+          },
         },
         diagnosticItemsByUri: {
           ...state.diagnosticItemsByUri,
-          [uri]: items,
+          [uri]: merged.flatMap((s) => s.items),
         },
       };
       console.log(`[Store] New diagnosticsByUri keys:`, Object.keys(newState.diagnosticsByUri));
@@ -90,6 +207,28 @@ export const useEditorStore = create<EditorState>((set, get) => ({
     });
   },
   setProblemsOpen: (open) => set({ isProblemsOpen: open }),
+  setNewProjectOpen: (open) => set({ isNewProjectOpen: open }),
+  setBookmarks: (bookmarks) => set({ bookmarks }),
+  setBookmarksOpen: (open) => set({ isBookmarksOpen: open }),
+  setCommentsOpen: (open) => set({ isCommentsOpen: open }),
+  setTodos: (todos, tags) => set({ todos, todoTags: tags }),
+  setTodoOpen: (open) => set({ isTodoOpen: open }),
+  setAuditLogOpen: (open) => set({ isAuditLogOpen: open }),
+  setGoModules: (modules) => set({ goModules: modules }),
+  setGoModulesOpen: (open) => set({ isGoModulesOpen: open }),
+  setNpmPackages: (packages) => set({ npmPackages: packages }),
+  setNpmOpen: (open) => set({ isNpmOpen: open }),
+  setOutputOpen: (open) => set({ isOutputOpen: open }),
+  setHttpOpen: (open) => set({ isHttpOpen: open }),
+  setSnapshots: (snapshots) => set({ snapshots }),
+  setSnapshotsOpen: (open) => set({ isSnapshotsOpen: open }),
+  setPorts: (ports) => set({ ports }),
+  setPortsOpen: (open) => set({ isPortsOpen: open }),
+  setPreviewPort: (port) => set({ previewPort: port }),
+  reloadPreview: () =>
+    set((state) => ({ previewReloadVersion: state.previewReloadVersion + 1 })),
+  setCoverage: (coverage) => set({ coverage }),
+  setCoverageVisible: (visible) => set({ isCoverageVisible: visible }),
   setThemeMode: (mode) => {
     const resolvedTheme = resolveTheme(mode);
     applyResolvedTheme(resolvedTheme);
@@ -100,4 +239,8 @@ export const useEditorStore = create<EditorState>((set, get) => ({
     applyResolvedTheme(theme);
     set({ resolvedTheme: theme, themeMode: get().themeMode });
   },
+  setScreenReaderMode: (enabled) => {
+    saveScreenReaderMode(enabled);
+    set({ isScreenReaderMode: enabled });
+  },
 }));
@@ -0,0 +1,85 @@
+import { create } from "zustand";
+import { api, describeApiError, type TaskInfo } from "./api";
+import type { FrontendLSPManager } from "./lsp/client";
+
+// Output kept per task in the browser, like the server's own limit
+const MAX_OUTPUT_LENGTH = 1024 * 1024;
+
+/**
+ * Server tasks (scripts, installs, module commands) and their output,
+ * kept up to date from the server's tasks/* notifications
+ */
+interface TaskState {
+  tasks: Record<string, TaskInfo>;
+  output: Record<string, string>;
+  setTask: (task: TaskInfo) => void;
+  appendOutput: (taskId: string, data: string) => void;
+  setOutput: (taskId: string, output: string) => void;
+}
+
+export const useTaskStore = create<TaskState>((set) => ({
+  tasks: {},
+  output: {},
+  setTask: (task) =>
+    set((state) => ({ tasks: { ...state.tasks, [task.id]: task } })),
+  appendOutput: (taskId, data) =>
+    set((state) => {
+      const output = (state.output[taskId] ?? "") + data;
+      return {
+        output: {
+          ...state.output,
+          [taskId]:
+            output.length > MAX_OUTPUT_LENGTH
+              ? output.slice(output.length - MAX_OUTPUT_LENGTH)
+              : output,
+        },
+      };
+    }),
+  setOutput: (taskId, output) =>
+    set((state) => ({ output: { ...state.output, [taskId]: output } })),
+}));
+
+let watchedManager: FrontendLSPManager | undefined;
+
+/**
+ * Follow task notifications on the LSP connection. Safe to call from
+ * several components; only the first call for a connection subscribes.
+ */
+export function watchTasks(lspManager: FrontendLSPManager): void {
+  if (watchedManager === lspManager) return;
+  watchedManager = lspManager;
+  const { setTask, appendOutput } = useTaskStore.getState();
+  lspManager.onNotification("tasks/started", ({ task }) => setTask(task));
+  lspManager.onNotification("tasks/output", ({ taskId, data }) =>
+    appendOutput(taskId, data),
+  );
+  lspManager.onNotification("tasks/finished", ({ task }) => setTask(task));
+}
+
+/**
+ * Track a task started through the API. Its captured output is loaded in
+ * case the first chunks arrived before the task was known.
+ */
+export async function trackTask(task: TaskInfo): Promise<void> {
+  useTaskStore.getState().setTask(task);
+  try {
+    const { task: latest, output } = await api.getTask(task.id);
+    const { setTask, setOutput } = useTaskStore.getState();
+    setTask(latest);
+    setOutput(task.id, output);
+  } catch (error) {
+    console.error("Error loading task output:", error);
+  }
+}
+
+/**
+ * Stop a running task
+ */
+export async function killTask(taskId: string): Promise<void> {
+  try {
+    await api.killTask(taskId);
+  } catch (error) {
+    console.error("Error stopping task:", error);
+    alert(`Failed to stop task: ${describeApiError(error)}`);
+  }
+}
@@ -1,10 +1,14 @@
-export type ThemeMode = "light" | "dark" | "auto";
-export type ResolvedTheme = "light" | "dark";
+export type ThemeMode = "light" | "dark" | "hc-light" | "hc-dark" | "auto";
+export type ResolvedTheme = Exclude<ThemeMode, "auto">;
 
 const STORAGE_KEY = "theme-preference";
 
 const isThemeMode = (value: string | null): value is ThemeMode =>
-  value === "light" || value === "dark" || value === "auto";
+  value === "light" ||
+  value === "dark" ||
+  value === "hc-light" ||
+  value === "hc-dark" ||
+  value === "auto";
 
 export const getStoredThemeMode = (): ThemeMode | null => {
   if (typeof window === "undefined") return null;
@@ -17,11 +21,37 @@ export const saveThemeMode = (mode: ThemeMode) => {
   window.localStorage.setItem(STORAGE_KEY, mode);
 };
 
+export const isDarkTheme = (theme: ResolvedTheme): boolean =>
+  theme === "dark" || theme === "hc-dark";
+
+export const isHighContrastTheme = (theme: ResolvedTheme): boolean =>
+  theme === "hc-light" || theme === "hc-dark";
+
+// Monaco's built-in theme for each UI theme
+export const getMonacoTheme = (theme: ResolvedTheme): string => {
+  switch (theme) {
+    case "dark":
+      return "vs-dark";
+    case "hc-light":
+      return "hc-light";
+    case "hc-dark":
+      return "hc-black";
+    default:
+      return "vs";
+  }
+};
+
 export const resolveTheme = (mode: ThemeMode): ResolvedTheme => {
   if (mode === "auto") {
-    const prefersDark =
-      typeof window !== "undefined" &&
-      window.matchMedia("(prefers-color-scheme: dark)").matches;
+    if (typeof window === "undefined") return "light";
+    const prefersDark = window.matchMedia(
+      "(prefers-color-scheme: dark)",
+    ).matches;
+    // Follow the OS high-contrast setting as well as light/dark
+    const prefersContrast =
+      window.matchMedia("(prefers-contrast: more)").matches ||
+      window.matchMedia("(forced-colors: active)").matches;
+    if (prefersContrast) return prefersDark ? "hc-dark" : "hc-light";
     return prefersDark ? "dark" : "light";
   }
 
@@ -32,6 +62,7 @@ export const applyResolvedTheme = (theme: ResolvedTheme) => {
   if (typeof document === "undefined") return;
   const root = document.documentElement;
 
-  root.classList.toggle("dark", theme === "dark");
-  root.style.colorScheme = theme;
+  root.classList.toggle("dark", isDarkTheme(theme));
+  root.classList.toggle("high-contrast", isHighContrastTheme(theme));
+  root.style.colorScheme = isDarkTheme(theme) ? "dark" : "light";
 };
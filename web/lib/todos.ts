@@ -0,0 +1,27 @@
+import { api, describeApiError } from "./api";
+import { useEditorStore } from "./store";
+
+/**
+ * Load the workspace TODO index into the store
+ */
+export async function fetchTodos(): Promise<void> {
+  try {
+    const { items, tags } = await api.listTodos();
+    useEditorStore.getState().setTodos(items, tags);
+  } catch (error) {
+    console.error("Error fetching TODOs:", error);
+  }
+}
+
+/**
+ * Change which comment tags are indexed for this workspace
+ */
+export async function updateTodoTags(tags: string[]): Promise<void> {
+  try {
+    const { items, tags: updated } = await api.setTodoTags({ tags });
+    useEditorStore.getState().setTodos(items, updated);
+  } catch (error) {
+    console.error("Error updating TODO tags:", error);
+    alert(`Failed to update TODO tags: ${describeApiError(error)}`);
+  }
+}
@@ -17,6 +17,8 @@ export class WebSocketTransport implements ITransport {
   private reconnectDelay = 1000; // Start with 1 second
   private reconnectTimer: number | null = null;
   private connectionStateListeners: Array<(connected: boolean) => void> = [];
+  private notificationListeners: Map<string, Array<(params: any) => void>> =
+    new Map();
 
   constructor(private url: string) {}
 
@@ -24,6 +26,55 @@ export class WebSocketTransport implements ITransport {
     this.connectionStateListeners.push(listener);
   }
 
+  /**
+   * Listen for server notifications that aren't part of LSP
+   * (e.g. workspace/todosChanged). Messages are still passed on to the client.
+   */
+  onNotification(method: string, listener: (params: any) => void): Disposable {
+    const listeners = this.notificationListeners.get(method) ?? [];
+    listeners.push(listener);
+    this.notificationListeners.set(method, listeners);
+    return {
+      dispose: () => {
+        const current = this.notificationListeners.get(method) ?? [];
+        const index = current.indexOf(listener);
+        if (index >= 0) {
+          current.splice(index, 1);
+        }
+      },
+    };
+  }
+
+  /**
+   * Send a notification outside the LSP client (e.g. to a server plugin)
+   */
+  sendNotification(method: string, params?: unknown): void {
+    if (!this.writer) {
+      console.warn(`[WebSocket] Not connected, dropping ${method}`);
+      return;
+    }
+    this.writer
+      .write({ jsonrpc: "2.0", method, params } as Message)
+      .catch((error) =>
+        console.error(`[WebSocket] Failed to send ${method}:`, error),
+      );
+  }
+
+  private dispatchNotification(message: any): void {
+    if (!message?.method || message.id !== undefined) return;
+    const listeners = this.notificationListeners.get(message.method);
+    listeners?.forEach((listener) => {
+      try {
+        listener(message.params);
+      } catch (error) {
+        console.error(
+          `[WebSocket] Notification listener for ${message.method} failed:`,
+          error,
+        );
+      }
+    });
+  }
+
   private notifyConnectionState(connected: boolean): void {
     this.connectionStateListeners.forEach((listener) => listener(connected));
   }
@@ -39,7 +90,9 @@ export class WebSocketTransport implements ITransport {
           this.reconnectDelay = 1000;
           this.notifyConnectionState(true);
 
-          this.reader = new WebSocketMessageReader(this.socket!);
+          this.reader = new WebSocketMessageReader(this.socket!, (message) =>
+            this.dispatchNotification(message),
+          );
           this.writer = new WebSocketMessageWriter(this.socket!);
 
           resolve({ reader: this.reader, writer: this.writer });
@@ -117,10 +170,14 @@ class WebSocketMessageReader implements MessageReader {
   private closeEmitter: Array<() => void> = [];
   private partialMessageEmitter: Array<(info: PartialMessageInfo) => void> = [];
 
-  constructor(private socket: WebSocket) {
+  constructor(
+    private socket: WebSocket,
+    private onNotification?: (message: any) => void,
+  ) {
     this.socket.addEventListener("message", (event) => {
       try {
         const message = JSON.parse(event.data);
+        this.onNotification?.(message);
         if (this.callback) {
           this.callback(message);
         }
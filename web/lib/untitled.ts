@@ -0,0 +1,86 @@
+import { announce } from "./a11y";
+import { api, describeApiError } from "./api";
+import { getLanguageLabel, LANGUAGES } from "./languages";
+import { openWorkspaceFile } from "./navigation";
+import { useEditorStore } from "./store";
+import { isUntitledUri, UNTITLED_URI_SCHEME } from "./uris";
+
+/**
+ * Open a new, empty untitled buffer (untitled:Untitled-<n>) in a language.
+ * It gets language server features through a temporary file on the server
+ * until it is saved into the workspace or closed.
+ */
+export function newUntitledDocument(languageId = "plaintext"): string | null {
+  const { editorManager, setCurrentFile, setCurrentLanguageId } =
+    useEditorStore.getState();
+  if (!editorManager) return null;
+
+  const taken = new Set(editorManager.getOpenFiles());
+  let index = 1;
+  while (taken.has(`${UNTITLED_URI_SCHEME}:Untitled-${index}`)) index++;
+  const uri = `${UNTITLED_URI_SCHEME}:Untitled-${index}`;
+
+  editorManager.openFile(uri, "", languageId);
+  setCurrentFile(uri);
+  setCurrentLanguageId(languageId);
+  editorManager.focus();
+  return uri;
+}
+
+/**
+ * Switch an open document to another language. The model is re-highlighted
+ * and the document is reopened in the new language's server.
+ */
+export function changeDocumentLanguage(uri: string, languageId: string): void {
+  const { editorManager, lspManager, currentFile, setCurrentLanguageId } =
+    useEditorStore.getState();
+  const model = editorManager?.getModel(uri);
+  if (!editorManager || !model || model.getLanguageId() === languageId) return;
+
+  editorManager.setLanguage(uri, languageId);
+  lspManager?.reopenTextDocument(
+    model.uri.toString(),
+    languageId,
+    model.getVersionId(),
+    model.getValue(),
+  );
+  if (currentFile === uri) {
+    setCurrentLanguageId(languageId);
+  }
+  announce(`Language mode: ${getLanguageLabel(languageId)}`);
+}
+
+/**
+ * Save an untitled buffer as a new workspace file, which then replaces the
+ * buffer in the editor
+ */
+export async function saveUntitledAs(uri: string): Promise<boolean> {
+  const { editorManager, lspManager, refreshFileTree } =
+    useEditorStore.getState();
+  const model = editorManager?.getModel(uri);
+  if (!editorManager || !model || !isUntitledUri(uri)) return false;
+
+  const languageId = model.getLanguageId();
+  const extension =
+    LANGUAGES.find((language) => language.id === languageId)?.extensions[0] ??
+    ".txt";
+  const name = uri.slice(UNTITLED_URI_SCHEME.length + 1);
+  const input = prompt("Save as (workspace path):", `/${name}${extension}`);
+  if (!input?.trim()) return false;
+  const path = `/${input.trim().replace(/^\/+/, "")}`;
+
+  try {
+    await api.createFile({ path, content: model.getValue(), languageId });
+  } catch (error) {
+    console.error("Error saving file:", error);
+    alert(`Failed to save ${path}: ${describeApiError(error)}`);
+    return false;
+  }
+
+  lspManager?.didCloseTextDocument(model.uri.toString());
+  editorManager.closeFile(uri);
+  refreshFileTree();
+  await openWorkspaceFile(path);
+  announce(`Saved as ${path}`);
+  return true;
+}
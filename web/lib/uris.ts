@@ -0,0 +1,30 @@
+// Scheme of workspace files in the editor, e.g. workspace:///src/main.go.
+// The server translates these to and from the file:// URIs language
+// servers see, so models, markers and diagnostics share one URI.
+export const WORKSPACE_URI_SCHEME = "workspace";
+
+// Scheme of in-memory buffers not saved into the workspace yet, e.g.
+// untitled:Untitled-1. The server keeps them in temporary files.
+export const UNTITLED_URI_SCHEME = "untitled";
+
+export const isUntitledUri = (uri: string): boolean =>
+  uri.startsWith(`${UNTITLED_URI_SCHEME}:`);
+
+const SCHEME_PATTERN = /^[a-z][a-z0-9+.-]*:/i;
+
+// Percent-encode a path segment the way Monaco's Uri.toString() does
+const encodeSegment = (segment: string): string =>
+  encodeURIComponent(segment).replace(
+    /[!'()*]/g,
+    (char) => `%${char.charCodeAt(0).toString(16).toUpperCase()}`,
+  );
+
+/**
+ * Editor model URI of a workspace path (/src/main.go ->
+ * workspace:///src/main.go). URIs are returned unchanged.
+ */
+export function toWorkspaceUri(pathOrUri: string): string {
+  if (SCHEME_PATTERN.test(pathOrUri)) return pathOrUri;
+  const segments = pathOrUri.replace(/^\/+/, "").split("/");
+  return `${WORKSPACE_URI_SCHEME}:///${segments.map(encodeSegment).join("/")}`;
+}
@@ -0,0 +1,50 @@
+import { api, describeApiError } from "./api";
+import { announce } from "./a11y";
+import { detectLanguage } from "./languages";
+import { useEditorStore } from "./store";
+
+// Scheme of read-only files outside the workspace that definitions point
+// into, e.g. deps:///goroot/src/fmt/print.go for the Go standard library
+export const VIRTUAL_DOCUMENT_SCHEME = "deps";
+
+export const isVirtualDocumentUri = (uri: string): boolean =>
+  uri.startsWith(`${VIRTUAL_DOCUMENT_SCHEME}:`);
+
+/**
+ * Open a read-only document outside the workspace, fetching it from the
+ * server if it isn't open yet, and optionally reveal a 1-based line/column
+ */
+export async function openVirtualDocument(
+  uri: string,
+  line?: number,
+  column = 1,
+): Promise<boolean> {
+  const { editorManager, setCurrentFile, setCurrentLanguageId } =
+    useEditorStore.getState();
+  if (!editorManager) return false;
+
+  const languageId = detectLanguage(uri).id;
+  const existing = editorManager.getModel(uri);
+  if (existing) {
+    editorManager.openFile(uri, existing.getValue(), languageId, {
+      readOnly: true,
+    });
+  } else {
+    try {
+      const { content } = await api.readVirtualDocument({ uri });
+      editorManager.openFile(uri, content, languageId, { readOnly: true });
+    } catch (error) {
+      console.error("Error loading read-only document:", error);
+      announce(`Failed to open ${uri}: ${describeApiError(error)}`, "assertive");
+      return false;
+    }
+  }
+
+  setCurrentFile(uri);
+  setCurrentLanguageId(languageId);
+
+  if (line !== undefined) {
+    editorManager.revealPosition(line, column);
+  }
+  return true;
+}